@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kagent-dev/tools/internal/approval"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// decideWhenParked waits for the next call to be parked and decides it like an operator would
+func decideWhenParked(t *testing.T, gate *approval.Gate, approve bool) {
+	t.Helper()
+	go func() {
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			if pending := gate.Pending(); len(pending) > 0 {
+				if _, err := gate.Decide(pending[0].ID, approve, "operator", "reviewed"); err != nil {
+					t.Errorf("failed to decide: %v", err)
+				}
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		t.Error("call was never parked")
+	}()
+}
+
+// TestWrapToolHandlersWithApproval verifies that only gated tools are parked and that they
+// run once approved and never run when denied.
+func TestWrapToolHandlersWithApproval(t *testing.T) {
+	gate, err := approval.NewGate(approval.DefaultTools, 2*time.Second)
+	if err != nil {
+		t.Fatalf("failed to create gate: %v", err)
+	}
+	s := server.NewMCPServer("test-server", "test")
+
+	calls := map[string]int{}
+	for _, name := range []string{"helm_uninstall", "helm_list_releases"} {
+		toolName := name
+		s.AddTool(mcp.NewTool(toolName), func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			calls[toolName]++
+			return mcp.NewToolResultText("ok"), nil
+		})
+	}
+	wrapToolHandlersWithApproval(s, gate)
+	tools := s.ListTools()
+
+	// Tools outside the gate run straight away
+	if _, err := tools["helm_list_releases"].Handler(context.Background(), mcp.CallToolRequest{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls["helm_list_releases"] != 1 || len(gate.History()) != 0 {
+		t.Fatalf("expected ungated tool to run without approval")
+	}
+
+	decideWhenParked(t, gate, true)
+	result, err := tools["helm_uninstall"].Handler(context.Background(), mcp.CallToolRequest{})
+	if err != nil || result.IsError {
+		t.Fatalf("expected approved call to succeed: %v %v", err, result)
+	}
+	if calls["helm_uninstall"] != 1 {
+		t.Errorf("expected approved call to run once, got %d", calls["helm_uninstall"])
+	}
+
+	decideWhenParked(t, gate, false)
+	result, err = tools["helm_uninstall"].Handler(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("unexpected Go error: %v", err)
+	}
+	if !result.IsError {
+		t.Fatal("expected result.IsError=true for a denied call")
+	}
+	if calls["helm_uninstall"] != 1 {
+		t.Error("denied call should not run")
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "APPROVAL_DENIED") || !strings.Contains(text, "operator") {
+		t.Errorf("expected denial details in error, got %q", text)
+	}
+
+	history := gate.History()
+	if len(history) != 2 || history[0].Status != approval.StatusApproved || history[1].Status != approval.StatusDenied {
+		t.Errorf("expected approved and denied decisions in history, got %+v", history)
+	}
+	if history[1].Caller != "anonymous" {
+		t.Errorf("expected caller to be recorded, got %q", history[1].Caller)
+	}
+}
+
+// TestAdminHandlerRequiresToken verifies that the admin endpoints of both the approvals and
+// the caches refuse calls without the admin token, so parked calls cannot be approved.
+func TestAdminHandlerRequiresToken(t *testing.T) {
+	gate, err := approval.NewGate(approval.DefaultTools, time.Minute)
+	if err != nil {
+		t.Fatalf("failed to create gate: %v", err)
+	}
+	parked := gate.Submit("k8s_delete_resource", map[string]any{"name": "web"}, "alice")
+
+	for _, tt := range []struct {
+		name   string
+		token  string
+		header string
+		want   int
+	}{
+		{name: "without an admin token", header: "Bearer ", want: http.StatusForbidden},
+		{name: "without credentials", token: "s3cret", want: http.StatusUnauthorized},
+		{name: "with a wrong token", token: "s3cret", header: "Bearer wrong", want: http.StatusUnauthorized},
+	} {
+		handler := adminHandler(gate, tt.token, false)
+		for _, path := range []string{"/admin/approvals/" + parked.ID + "/approve", "/admin/cache/invalidate"} {
+			req := httptest.NewRequest(http.MethodPost, path, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("%s: expected %d for %s, got %d", tt.name, tt.want, path, rec.Code)
+			}
+		}
+	}
+	if req, err := gate.Get(parked.ID); err != nil || req.Status != approval.StatusPending {
+		t.Fatalf("expected the call to stay pending, got %+v %v", req, err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/approvals/"+parked.ID+"/approve", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	rec := httptest.NewRecorder()
+	adminHandler(gate, "s3cret", false).ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected the admin to approve the call, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
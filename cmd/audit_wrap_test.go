@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/kagent-dev/tools/internal/audit"
+	toolerrors "github.com/kagent-dev/tools/internal/errors"
+	"github.com/kagent-dev/tools/internal/policy"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// TestWrapToolHandlersWithAudit verifies that calls are recorded with their outcome and
+// redacted arguments, and that read-only calls are skipped when --audit-reads is off.
+func TestWrapToolHandlersWithAudit(t *testing.T) {
+	var buf bytes.Buffer
+	auditor := audit.NewAuditor(false, audit.NewStreamSink("buffer", &buf))
+	s := server.NewMCPServer("test-server", "test")
+
+	s.AddTool(mcp.NewTool("k8s_get_resources"), func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("pods"), nil
+	})
+	s.AddTool(mcp.NewTool("k8s_delete_resource"), func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return toolerrors.NewPolicyError("k8s_delete_resource", policy.CodeDenied, "protect", "kube-system is protected").ToMCPResult(), nil
+	})
+	wrapToolHandlersWithAudit(s, auditor, map[string]string{"k8s_get_resources": "k8s", "k8s_delete_resource": "k8s"}, policy.DefaultVerb)
+	tools := s.ListTools()
+
+	if _, err := tools["k8s_get_resources"].Handler(context.Background(), mcp.CallToolRequest{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("expected read-only call not to be audited, got %q", buf.String())
+	}
+
+	req := mcp.CallToolRequest{Header: map[string][]string{"X-Remote-User": {"alice"}}}
+	req.Params.Arguments = map[string]any{"namespace": "kube-system", "resource_name": "coredns", "token": "s3cret"}
+	if _, err := tools["k8s_delete_resource"].Handler(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected one audit event, got %d: %q", len(lines), buf.String())
+	}
+	var event audit.Event
+	if err := json.Unmarshal([]byte(lines[0]), &event); err != nil {
+		t.Fatalf("failed to parse audit event: %v", err)
+	}
+
+	if event.Tool != "k8s_delete_resource" || event.Provider != "k8s" || !event.Mutating {
+		t.Errorf("unexpected tool fields: %+v", event)
+	}
+	if event.Caller.Subject != "alice" || event.Namespace != "kube-system" {
+		t.Errorf("expected caller and namespace to be recorded, got %+v", event)
+	}
+	if event.Status != audit.StatusError || event.Error != "POLICY_DENIED: kube-system is protected" {
+		t.Errorf("expected policy denial in the event, got status %q error %q", event.Status, event.Error)
+	}
+	if event.Arguments["token"] == "s3cret" {
+		t.Error("expected token argument to be redacted")
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kagent-dev/tools/internal/auth"
+	"github.com/kagent-dev/tools/internal/policy"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// TestHTTPAuthorization runs the streamable HTTP transport behind the auth middleware and
+// verifies that unauthenticated requests are rejected, that tools/list only shows the tools
+// the caller's roles grant and that calls to other tools are refused.
+func TestHTTPAuthorization(t *testing.T) {
+	config, err := auth.Parse([]byte(`
+apiKeys:
+- key: viewer-key
+  subject: ci-bot
+  roles: [viewer]
+roles:
+  viewer:
+    verbs: [read]
+`))
+	if err != nil {
+		t.Fatalf("failed to parse auth config: %v", err)
+	}
+	authenticator, err := auth.New(config, policy.DefaultVerb)
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %v", err)
+	}
+
+	s := server.NewMCPServer("test-server", "test", server.WithToolFilter(authToolFilter(authenticator)))
+	calls := 0
+	for _, name := range []string{"k8s_get_resources", "k8s_delete_resource"} {
+		s.AddTool(mcp.NewTool(name), func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			calls++
+			return mcp.NewToolResultText("ok"), nil
+		})
+	}
+	wrapToolHandlersWithAuthorization(s, authenticator)
+
+	httpServer := httptest.NewServer(authenticator.Middleware(server.NewStreamableHTTPServer(s)))
+	defer httpServer.Close()
+
+	sessionID := ""
+	post := func(key, body string) (*http.Response, map[string]any) {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPost, httpServer.URL+"/mcp", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		if sessionID != "" {
+			req.Header.Set("Mcp-Session-Id", sessionID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		var message map[string]any
+		_ = json.NewDecoder(resp.Body).Decode(&message)
+		return resp, message
+	}
+
+	initialize := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`
+	if resp, _ := post("", initialize); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without credentials, got %d", resp.StatusCode)
+	}
+	if resp, _ := post("wrong-key", initialize); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for an unknown key, got %d", resp.StatusCode)
+	}
+
+	resp, _ := post("viewer-key", initialize)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected initialize to succeed, got %d", resp.StatusCode)
+	}
+	sessionID = resp.Header.Get("Mcp-Session-Id")
+
+	_, listed := post("viewer-key", `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	data, _ := json.Marshal(listed["result"])
+	var result mcp.ListToolsResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("failed to parse tools/list result: %v", err)
+	}
+	if len(result.Tools) != 1 || result.Tools[0].Name != "k8s_get_resources" {
+		t.Errorf("expected only the read tool to be listed, got %+v", result.Tools)
+	}
+
+	_, called := post("viewer-key", `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"k8s_delete_resource","arguments":{}}}`)
+	text, _ := json.Marshal(called["result"])
+	if !strings.Contains(string(text), "AUTH_FORBIDDEN") || !strings.Contains(string(text), "ci-bot") {
+		t.Errorf("expected a forbidden error naming the caller, got %s", text)
+	}
+
+	_, called = post("viewer-key", `{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"k8s_get_resources","arguments":{}}}`)
+	if text, _ := json.Marshal(called["result"]); strings.Contains(string(text), `"isError":true`) {
+		t.Errorf("expected the read tool to run, got %s", text)
+	}
+	if calls != 1 {
+		t.Errorf("expected only the granted call to run, got %d calls", calls)
+	}
+}
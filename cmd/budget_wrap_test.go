@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/kagent-dev/tools/internal/budget"
+	"github.com/kagent-dev/tools/internal/identity"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// TestWrapToolHandlersWithResultBudget verifies that large results are cut with a cursor and
+// that the cursor reads the next page for the same caller without running the tool again.
+func TestWrapToolHandlersWithResultBudget(t *testing.T) {
+	s := server.NewMCPServer("test-server", "test")
+
+	calls := 0
+	output := strings.Repeat("0123456789abcdef\n", 1000)
+	s.AddTool(mcp.NewTool("k8s_get_events"), func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls++
+		return mcp.NewToolResultText(output), nil
+	})
+	s.AddTool(mcp.NewTool("datetime_get_current_time"), func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("2026-01-01T00:00:00Z"), nil
+	})
+
+	limiter, err := budget.NewLimiter(4096, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer limiter.Close()
+	wrapToolHandlersWithResultBudget(s, limiter)
+
+	tools := s.ListTools()
+	if _, ok := tools["k8s_get_events"].Tool.InputSchema.Properties[budget.CursorArgument]; !ok {
+		t.Error("expected the cursor argument on every tool")
+	}
+
+	call := func(ctx context.Context, name string, args map[string]any) string {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = args
+		result, err := tools[name].Handler(ctx, req)
+		if err != nil {
+			t.Fatalf("unexpected Go error: %v", err)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	alice := identity.WithPrincipal(context.Background(), identity.Principal{Subject: "alice"})
+	if got := call(alice, "datetime_get_current_time", nil); got != "2026-01-01T00:00:00Z" {
+		t.Errorf("expected a small result unchanged, got %q", got)
+	}
+
+	first := call(alice, "k8s_get_events", nil)
+	if len(first) > 4096 {
+		t.Errorf("expected the result cut to 4096 bytes, got %d", len(first))
+	}
+	match := regexp.MustCompile(`cursor="([^"]+)"`).FindStringSubmatch(first)
+	if match == nil {
+		t.Fatalf("expected a cursor in the truncated result: %q", first)
+	}
+
+	page := call(alice, "k8s_get_events", map[string]any{budget.CursorArgument: match[1]})
+	if !strings.HasPrefix(page, "0123456789abcdef\n") || calls != 1 {
+		t.Errorf("expected the next page without running the tool again, got %d calls and %q", calls, page[:32])
+	}
+
+	bob := identity.WithPrincipal(context.Background(), identity.Principal{Subject: "bob"})
+	if page := call(bob, "k8s_get_events", map[string]any{budget.CursorArgument: match[1]}); !strings.Contains(page, "unknown or expired") {
+		t.Errorf("expected another caller's cursor to be rejected, got %q", page)
+	}
+}
+
+// testReleaseList is the structured output of the schema'd tool of the budget tests
+type testReleaseList struct {
+	Releases []string `json:"releases"`
+}
+
+// TestWrapToolHandlersWithResultBudgetStructured verifies that tools with an output schema
+// declare the fields of a cut result and that cut results and their pages carry structured
+// content in that form.
+func TestWrapToolHandlersWithResultBudgetStructured(t *testing.T) {
+	s := server.NewMCPServer("test-server", "test")
+
+	var releases testReleaseList
+	for i := 0; i < 200; i++ {
+		releases.Releases = append(releases.Releases, fmt.Sprintf("release-%03d-%s", i, strings.Repeat("x", 40)))
+	}
+	s.AddTool(mcp.NewTool("helm_list_releases", mcp.WithOutputSchema[testReleaseList]()), func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultStructuredOnly(releases), nil
+	})
+
+	limiter, err := budget.NewLimiter(4096, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer limiter.Close()
+	wrapToolHandlersWithResultBudget(s, limiter)
+
+	st := s.ListTools()["helm_list_releases"]
+	for _, field := range []string{"releases", budget.TruncatedField, budget.CursorField} {
+		if _, ok := st.Tool.OutputSchema.Properties[field]; !ok {
+			t.Errorf("expected %s in the output schema, got %v", field, st.Tool.OutputSchema.Properties)
+		}
+	}
+
+	req := mcp.CallToolRequest{}
+	var read []any
+	for pages := 0; ; pages++ {
+		if pages == 100 {
+			t.Fatal("paging does not end")
+		}
+		result, err := st.Handler(context.Background(), req)
+		if err != nil || result.IsError {
+			t.Fatalf("unexpected error: %v %v", err, result)
+		}
+		page, ok := result.StructuredContent.(map[string]any)
+		if !ok {
+			t.Fatalf("expected structured content on every page, got %T", result.StructuredContent)
+		}
+		items, ok := page["releases"].([]any)
+		if !ok || len(items) == 0 {
+			t.Fatalf("expected the required releases field on every page, got %v", page)
+		}
+		if text := result.Content[0].(mcp.TextContent).Text; len(text) > 4096 {
+			t.Errorf("expected the page cut to 4096 bytes, got %d", len(text))
+		}
+		read = append(read, items...)
+
+		cursor, ok := page[budget.CursorField].(string)
+		if !ok {
+			break
+		}
+		if page[budget.TruncatedField] != true {
+			t.Errorf("expected a cut page to be marked truncated, got %v", page[budget.TruncatedField])
+		}
+		req.Params.Arguments = map[string]any{budget.CursorArgument: cursor}
+	}
+	if len(read) != len(releases.Releases) || read[199] != releases.Releases[199] {
+		t.Errorf("expected the pages to hold every release, got %d", len(read))
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kagent-dev/tools/internal/cache"
+	"github.com/kagent-dev/tools/internal/policy"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// TestWrapToolHandlersWithCacheFreshness verifies that read tools of cluster-aware providers
+// advertise bypass_cache and max_age and pass the age limit on in the request context.
+func TestWrapToolHandlersWithCacheFreshness(t *testing.T) {
+	s := server.NewMCPServer("test-server", "test")
+
+	var maxAge time.Duration
+	var limited bool
+	s.AddTool(mcp.NewTool("k8s_get_resources"), func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		maxAge, limited = cache.MaxAgeFromContext(ctx)
+		return mcp.NewToolResultText("ok"), nil
+	})
+	for _, name := range []string{"k8s_delete_resource", "k8s_list_clusters", "datetime_get_current_time"} {
+		s.AddTool(mcp.NewTool(name), func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("ok"), nil
+		})
+	}
+
+	wrapToolHandlersWithCacheFreshness(s, map[string]string{
+		"k8s_get_resources":         "k8s",
+		"k8s_delete_resource":       "k8s",
+		"k8s_list_clusters":         "k8s",
+		"datetime_get_current_time": "utils",
+	}, policy.DefaultVerb)
+
+	tools := s.ListTools()
+	if _, ok := tools["k8s_get_resources"].Tool.InputSchema.Properties[cache.BypassArgument]; !ok {
+		t.Error("expected bypass_cache argument on a k8s read tool")
+	}
+	for _, name := range []string{"k8s_delete_resource", "k8s_list_clusters", "datetime_get_current_time"} {
+		if _, ok := tools[name].Tool.InputSchema.Properties[cache.MaxAgeArgument]; ok {
+			t.Errorf("did not expect max_age argument on %s", name)
+		}
+	}
+
+	call := func(args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		limited = false
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = args
+		result, err := tools["k8s_get_resources"].Handler(context.Background(), req)
+		if err != nil {
+			t.Fatalf("unexpected Go error: %v", err)
+		}
+		return result
+	}
+
+	call(nil)
+	if limited {
+		t.Error("expected no age limit when the arguments are omitted")
+	}
+	call(map[string]any{cache.BypassArgument: true})
+	if !limited || maxAge != 0 {
+		t.Errorf("expected bypass_cache to set a max age of 0, got %v (limited=%v)", maxAge, limited)
+	}
+	call(map[string]any{cache.MaxAgeArgument: "30s"})
+	if !limited || maxAge != 30*time.Second {
+		t.Errorf("expected a max age of 30s, got %v (limited=%v)", maxAge, limited)
+	}
+	if result := call(map[string]any{cache.MaxAgeArgument: "fresh"}); !result.IsError || limited {
+		t.Error("expected an invalid max_age to be rejected before the tool runs")
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kagent-dev/tools/internal/clusters"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// newTestRegistry installs a registry with a single "prod" cluster for the duration of the test
+func newTestRegistry(t *testing.T) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config")
+	kubeconfig := `apiVersion: v1
+kind: Config
+current-context: prod
+clusters:
+- name: prod
+  cluster:
+    server: https://prod.example.com
+contexts:
+- name: prod
+  context:
+    cluster: prod
+    user: prod
+users:
+- name: prod
+  user:
+    token: test
+`
+	if err := os.WriteFile(path, []byte(kubeconfig), 0600); err != nil {
+		t.Fatalf("failed to write kubeconfig: %v", err)
+	}
+	registry, err := clusters.Load(path)
+	if err != nil {
+		t.Fatalf("failed to load registry: %v", err)
+	}
+
+	original := clusters.GetRegistry()
+	clusters.SetRegistry(registry)
+	t.Cleanup(func() { clusters.SetRegistry(original) })
+}
+
+// TestWrapToolHandlersWithClusterSelection_RoutesToCluster verifies that the cluster
+// argument is advertised on cluster-aware tools and resolved into the request context.
+func TestWrapToolHandlersWithClusterSelection_RoutesToCluster(t *testing.T) {
+	newTestRegistry(t)
+	s := server.NewMCPServer("test-server", "test")
+
+	var selected clusters.Cluster
+	var found bool
+	s.AddTool(mcp.NewTool("k8s_get_resources"), func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		selected, found = clusters.FromContext(ctx)
+		return mcp.NewToolResultText("ok"), nil
+	})
+	s.AddTool(mcp.NewTool("datetime_get_current_time"), func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("now"), nil
+	})
+
+	wrapToolHandlersWithClusterSelection(s, map[string]string{
+		"k8s_get_resources":         "k8s",
+		"datetime_get_current_time": "utils",
+	})
+
+	tools := s.ListTools()
+	if len(tools) != 2 {
+		t.Fatalf("expected both tools to be kept, got %d", len(tools))
+	}
+	if _, ok := tools["k8s_get_resources"].Tool.InputSchema.Properties[clusters.ArgumentName]; !ok {
+		t.Error("expected cluster argument on k8s tool")
+	}
+	if _, ok := tools["datetime_get_current_time"].Tool.InputSchema.Properties[clusters.ArgumentName]; ok {
+		t.Error("did not expect cluster argument on utils tool")
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{clusters.ArgumentName: "prod"}
+	result, err := tools["k8s_get_resources"].Handler(context.Background(), req)
+	if err != nil || result.IsError {
+		t.Fatalf("unexpected failure: %v %v", err, result)
+	}
+	if !found || selected.Context != "prod" {
+		t.Errorf("expected prod cluster in context, got %+v (found=%v)", selected, found)
+	}
+
+	found = false
+	if _, err := tools["k8s_get_resources"].Handler(context.Background(), mcp.CallToolRequest{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if found {
+		t.Error("expected no cluster in context when the argument is omitted")
+	}
+}
+
+// TestWrapToolHandlersWithClusterSelection_UnknownCluster verifies that an unknown
+// cluster name is rejected before the tool runs.
+func TestWrapToolHandlersWithClusterSelection_UnknownCluster(t *testing.T) {
+	newTestRegistry(t)
+	s := server.NewMCPServer("test-server", "test")
+
+	called := false
+	s.AddTool(mcp.NewTool("helm_list_releases"), func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		called = true
+		return mcp.NewToolResultText("ok"), nil
+	})
+	wrapToolHandlersWithClusterSelection(s, map[string]string{"helm_list_releases": "helm"})
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{clusters.ArgumentName: "staging"}
+	result, err := s.ListTools()["helm_list_releases"].Handler(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected Go error: %v", err)
+	}
+	if !result.IsError {
+		t.Fatal("expected result.IsError=true for an unknown cluster")
+	}
+	if called {
+		t.Error("handler should not run for an unknown cluster")
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "available clusters are prod") {
+		t.Errorf("expected available clusters in error, got %q", text)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kagent-dev/tools/internal/credentials"
+	"github.com/kagent-dev/tools/internal/identity"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// TestWrapToolHandlersWithTokenPassthrough verifies that the caller's token reaches the
+// tool through the context and that cluster tools refuse to run without one.
+func TestWrapToolHandlersWithTokenPassthrough(t *testing.T) {
+	t.Setenv(credentials.EnvTokenPassthrough, "true")
+	s := server.NewMCPServer("test-server", "test")
+
+	tokens := map[string]string{}
+	for _, name := range []string{"helm_list_releases", "datetime_get_current_time"} {
+		toolName := name
+		s.AddTool(mcp.NewTool(toolName), func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			tokens[toolName] = credentials.TokenFromContext(ctx)
+			return mcp.NewToolResultText("ok"), nil
+		})
+	}
+	wrapToolHandlersWithCallerCredentials(s, map[string]string{"helm_list_releases": "helm", "datetime_get_current_time": "utils"})
+	tools := s.ListTools()
+
+	result, err := tools["helm_list_releases"].Handler(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "TOKEN_REQUIRED") {
+		t.Errorf("expected a helm call without a token to fail, got %v", result)
+	}
+	if _, called := tokens["helm_list_releases"]; called {
+		t.Error("expected the helm tool not to run without a token")
+	}
+
+	if _, err := tools["datetime_get_current_time"].Handler(context.Background(), mcp.CallToolRequest{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, called := tokens["datetime_get_current_time"]; !called {
+		t.Error("expected tools that do not reach a cluster to run without a token")
+	}
+
+	req := mcp.CallToolRequest{Header: map[string][]string{"Authorization": {"Bearer caller-token"}}}
+	if result, _ := tools["helm_list_releases"].Handler(context.Background(), req); result.IsError {
+		t.Fatalf("expected the call with a token to run, got %v", result)
+	}
+	if tokens["helm_list_releases"] != "caller-token" {
+		t.Errorf("expected the caller's token in the context, got %q", tokens["helm_list_releases"])
+	}
+}
+
+// TestWrapToolHandlersWithImpersonation verifies that the user and groups of the trusted
+// headers reach the tool and that cluster tools refuse to run without a user.
+func TestWrapToolHandlersWithImpersonation(t *testing.T) {
+	credentials.EnableImpersonation(credentials.ImpersonationHeaders{User: "X-Remote-User", Group: "X-Remote-Group", TrustedProxies: []string{"gateway"}})
+	defer credentials.DisableImpersonation()
+	gateway := identity.WithPrincipal(context.Background(), identity.Principal{Subject: "gateway"})
+
+	s := server.NewMCPServer("test-server", "test")
+	var got credentials.Impersonation
+	calls := 0
+	s.AddTool(mcp.NewTool("k8s_get_resources"), func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls++
+		got, _ = credentials.ImpersonationFromContext(ctx)
+		return mcp.NewToolResultText("ok"), nil
+	})
+	wrapToolHandlersWithCallerCredentials(s, map[string]string{"k8s_get_resources": "k8s"})
+	handler := s.ListTools()["k8s_get_resources"].Handler
+
+	result, err := handler(gateway, mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "IMPERSONATION_REQUIRED") || calls != 0 {
+		t.Errorf("expected a call without the user header to fail, got %v", result)
+	}
+
+	req := mcp.CallToolRequest{Header: map[string][]string{
+		"X-Remote-User":  {"alice@example.com"},
+		"X-Remote-Group": {"sre, oncall", "dev"},
+	}}
+
+	// Callers other than the trusted proxy cannot name who to impersonate
+	untrusted := identity.WithPrincipal(context.Background(), identity.Principal{Subject: "agent"})
+	for _, ctx := range []context.Context{context.Background(), untrusted} {
+		result, _ := handler(ctx, req)
+		if !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "IMPERSONATION_UNTRUSTED") || calls != 0 {
+			t.Errorf("expected the headers of an untrusted caller to be rejected, got %v", result)
+		}
+	}
+
+	if result, _ := handler(gateway, req); result.IsError {
+		t.Fatalf("expected the call to run, got %v", result)
+	}
+	if got.User != "alice@example.com" || strings.Join(got.Groups, ",") != "sre,oncall,dev" {
+		t.Errorf("unexpected impersonation %+v", got)
+	}
+}
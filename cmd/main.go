@@ -15,12 +15,26 @@ import (
 	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/kagent-dev/tools/internal/admin"
+	"github.com/kagent-dev/tools/internal/approval"
+	"github.com/kagent-dev/tools/internal/audit"
+	"github.com/kagent-dev/tools/internal/auth"
+	"github.com/kagent-dev/tools/internal/budget"
+	"github.com/kagent-dev/tools/internal/cache"
+	"github.com/kagent-dev/tools/internal/clusters"
+	"github.com/kagent-dev/tools/internal/credentials"
+	toolerrors "github.com/kagent-dev/tools/internal/errors"
+	"github.com/kagent-dev/tools/internal/identity"
 	"github.com/kagent-dev/tools/internal/logger"
 	"github.com/kagent-dev/tools/internal/metrics"
+	"github.com/kagent-dev/tools/internal/policy"
 	"github.com/kagent-dev/tools/internal/telemetry"
 	"github.com/kagent-dev/tools/internal/version"
+	"github.com/kagent-dev/tools/pkg/alertmanager"
 	"github.com/kagent-dev/tools/pkg/argo"
+	cachetools "github.com/kagent-dev/tools/pkg/cache"
 	"github.com/kagent-dev/tools/pkg/cilium"
+	"github.com/kagent-dev/tools/pkg/grafana"
 	"github.com/kagent-dev/tools/pkg/helm"
 	"github.com/kagent-dev/tools/pkg/istio"
 	"github.com/kagent-dev/tools/pkg/k8s"
@@ -45,6 +59,44 @@ var (
 	kubeconfig  *string
 	showVersion bool
 	readOnly    bool
+	k8sBackend  string
+	clusterPath string
+	policyPath  string
+	auditSinks  []string
+	auditReads  bool
+	cacheWatch  []string
+
+	informerCache             bool
+	informerCacheMaxInformers int
+	informerCacheMaxObjects   int
+
+	authConfigPath string
+	tlsCertFile    string
+	tlsKeyFile     string
+
+	impersonate            bool
+	impersonateUserHeader  string
+	impersonateGroupHeader string
+	impersonateProxies     []string
+
+	requireApproval bool
+	approvalTools   []string
+	approvalTimeout time.Duration
+	adminPort       int
+	adminToken      string
+
+	maxResultBytes  int
+	maxResultTokens int
+
+	prometheusConfigPath string
+	prometheusDataSource prometheus.DataSource
+	prometheusConfig     *prometheus.Config
+
+	alertmanagerConfigFlags alertmanager.Config
+	alertmanagerConfig      *alertmanager.Config
+
+	grafanaConfigFlags grafana.Config
+	grafanaConfig      *grafana.Config
 
 	// These variables should be set during build time using -ldflags
 	Name      = "kagent-tools-server"
@@ -67,6 +119,53 @@ func init() {
 	rootCmd.Flags().BoolVarP(&showVersion, "version", "v", false, "Show version information and exit")
 	rootCmd.Flags().BoolVar(&readOnly, "read-only", false, "Run in read-only mode (disable tools that perform write operations)")
 	kubeconfig = rootCmd.Flags().String("kubeconfig", "", "kubeconfig file path (optional, defaults to in-cluster config)")
+	rootCmd.Flags().StringVar(&clusterPath, "clusters", "", "kubeconfig file or directory of kubeconfigs listing the clusters tools can target with the cluster argument (defaults to --kubeconfig)")
+	rootCmd.Flags().StringVar(&k8sBackend, "k8s-backend", k8s.BackendKubectl, "Backend for the k8s tools: kubectl or client-go (client-go falls back to kubectl for unsupported operations)")
+	rootCmd.Flags().BoolVar(&informerCache, "k8s-informer-cache", false, "Serve k8s_get_resources and k8s_get_events for pods, deployments, services, events and nodes from shared informers started on first use")
+	rootCmd.Flags().IntVar(&informerCacheMaxInformers, "k8s-informer-cache-max-informers", k8s.DefaultInformerMaxInformers, "Informers (one per cluster, resource and namespace) kept running by --k8s-informer-cache; the least recently used stops first")
+	rootCmd.Flags().IntVar(&informerCacheMaxObjects, "k8s-informer-cache-max-objects", k8s.DefaultInformerMaxObjects, "Objects one informer may hold; larger resources are read from the API server")
+	rootCmd.Flags().StringSliceVar(&cacheWatch, "cache-watch", []string{}, "Resources to watch in the default cluster so that changes made outside the server evict cached results, e.g. pods,deployments.apps")
+	rootCmd.Flags().StringVar(&policyPath, "policy", "", "YAML tool policy file allowing or denying calls by tool, verb, cluster, namespace and resource type (reloaded when it changes)")
+	rootCmd.Flags().StringSliceVar(&auditSinks, "audit-sink", []string{"stdout"}, "Where to record audit events: stdout, stderr, file:<path>, webhook:<url> or none (repeatable)")
+	rootCmd.Flags().BoolVar(&auditReads, "audit-reads", true, "Audit read-only tool calls as well (mutating calls are always audited)")
+	rootCmd.Flags().StringVar(&authConfigPath, "auth-config", "", "YAML file enabling authentication of HTTP callers (API keys, JWT, mTLS) and the roles that decide which tools they can list and call")
+	rootCmd.Flags().StringVar(&tlsCertFile, "tls-cert", "", "TLS certificate file to serve HTTP over TLS (required for mTLS client authentication)")
+	rootCmd.Flags().StringVar(&tlsKeyFile, "tls-key", "", "TLS private key file for --tls-cert")
+	rootCmd.Flags().BoolVar(&impersonate, "impersonate", false, "Run cluster tools with the server's credentials impersonating the user and groups named in trusted request headers")
+	rootCmd.Flags().StringVar(&impersonateUserHeader, "impersonate-user-header", "X-Remote-User", "Trusted header naming the user to impersonate with --impersonate")
+	rootCmd.Flags().StringVar(&impersonateGroupHeader, "impersonate-group-header", "X-Remote-Group", "Trusted header listing the groups to impersonate with --impersonate (repeatable or comma separated)")
+	rootCmd.Flags().StringSliceVar(&impersonateProxies, "impersonate-trusted-proxies", nil, "Subjects, as authenticated by --auth-config, of the proxies trusted to set the impersonation headers (path.Match patterns)")
+	rootCmd.Flags().BoolVar(&requireApproval, "require-approval", false, "Park destructive tool calls until they are approved through the admin endpoint or an MCP elicitation prompt")
+	rootCmd.Flags().StringSliceVar(&approvalTools, "approval-tools", approval.DefaultTools, "Tool name patterns that require approval when --require-approval is set")
+	rootCmd.Flags().DurationVar(&approvalTimeout, "approval-timeout", approval.DefaultTimeout, "How long a parked tool call waits for a decision before it expires")
+	rootCmd.Flags().IntVar(&adminPort, "admin-port", 0, "Port to run the admin endpoints on (default 0: same as --port)")
+	rootCmd.Flags().StringVar(&adminToken, "admin-token", "", "Bearer token required by the admin endpoints (defaults to $KAGENT_TOOLS_ADMIN_TOKEN)")
+	rootCmd.Flags().IntVar(&maxResultBytes, "max-result-bytes", budget.DefaultMaxBytes, "Bytes of text a tool result may hold; larger results are cut to their head and tail and paged with a cursor (0 for no limit)")
+	rootCmd.Flags().IntVar(&maxResultTokens, "max-result-tokens", 0, "Tokens a tool result may hold, estimated at 4 bytes per token; the smaller of this and --max-result-bytes applies (0 for no limit)")
+	rootCmd.Flags().StringVar(&prometheusConfigPath, "prometheus-config", "", "YAML file of named Prometheus data sources (URL, credentials, TLS, headers) the prometheus tools can select with their datasource argument")
+	rootCmd.Flags().StringVar(&prometheusDataSource.URL, "prometheus-url", "", "URL of the default Prometheus data source (defaults to $PROMETHEUS_URL, else http://localhost:9090)")
+	rootCmd.Flags().StringVar(&prometheusDataSource.Username, "prometheus-username", "", "Basic auth user of the default Prometheus data source (defaults to $PROMETHEUS_USERNAME)")
+	rootCmd.Flags().StringVar(&prometheusDataSource.Password, "prometheus-password", "", "Basic auth password of the default Prometheus data source (defaults to $PROMETHEUS_PASSWORD)")
+	rootCmd.Flags().StringVar(&prometheusDataSource.BearerTokenFile, "prometheus-bearer-token-file", "", "File holding the bearer token of the default Prometheus data source, read on every request (defaults to $PROMETHEUS_BEARER_TOKEN_FILE)")
+	rootCmd.Flags().StringVar(&prometheusDataSource.CAFile, "prometheus-ca-file", "", "CA certificates verifying the default Prometheus data source (defaults to $PROMETHEUS_CA_FILE)")
+	rootCmd.Flags().BoolVar(&prometheusDataSource.InsecureSkipVerify, "prometheus-insecure-skip-verify", false, "Skip TLS verification of the default Prometheus data source")
+	rootCmd.Flags().StringToStringVar(&prometheusDataSource.Headers, "prometheus-headers", nil, "Extra headers sent to the default Prometheus data source, e.g. X-Org=ops")
+	rootCmd.Flags().StringVar(&prometheusDataSource.TenantID, "prometheus-tenant-id", "", "Tenant of the default data source for Mimir, Cortex or Thanos (defaults to $PROMETHEUS_TENANT_ID)")
+	rootCmd.Flags().StringVar(&prometheusDataSource.TenantHeader, "prometheus-tenant-header", prometheus.DefaultTenantHeader, "Header carrying --prometheus-tenant-id, e.g. THANOS-TENANT for Thanos")
+	rootCmd.Flags().StringVar(&alertmanagerConfigFlags.URL, "alertmanager-url", "", "URL of the Alertmanager of the alertmanager tools (defaults to $ALERTMANAGER_URL, else http://localhost:9093)")
+	rootCmd.Flags().StringVar(&alertmanagerConfigFlags.Username, "alertmanager-username", "", "Basic auth user of the Alertmanager (defaults to $ALERTMANAGER_USERNAME)")
+	rootCmd.Flags().StringVar(&alertmanagerConfigFlags.Password, "alertmanager-password", "", "Basic auth password of the Alertmanager (defaults to $ALERTMANAGER_PASSWORD)")
+	rootCmd.Flags().StringVar(&alertmanagerConfigFlags.BearerTokenFile, "alertmanager-bearer-token-file", "", "File holding the bearer token of the Alertmanager, read on every request (defaults to $ALERTMANAGER_BEARER_TOKEN_FILE)")
+	rootCmd.Flags().StringVar(&alertmanagerConfigFlags.CAFile, "alertmanager-ca-file", "", "CA certificates verifying the Alertmanager (defaults to $ALERTMANAGER_CA_FILE)")
+	rootCmd.Flags().BoolVar(&alertmanagerConfigFlags.InsecureSkipVerify, "alertmanager-insecure-skip-verify", false, "Skip TLS verification of the Alertmanager")
+	rootCmd.Flags().StringVar(&alertmanagerConfigFlags.TenantID, "alertmanager-tenant-id", "", "Tenant of the Mimir or Cortex Alertmanager (defaults to $ALERTMANAGER_TENANT_ID)")
+	rootCmd.Flags().StringVar(&grafanaConfigFlags.URL, "grafana-url", "", "URL of the Grafana of the grafana tools (defaults to $GRAFANA_URL, else http://localhost:3000)")
+	rootCmd.Flags().StringVar(&grafanaConfigFlags.APIKey, "grafana-api-key", "", "Service account token or API key of Grafana (defaults to $GRAFANA_API_KEY)")
+	rootCmd.Flags().StringVar(&grafanaConfigFlags.Username, "grafana-username", "", "Basic auth user of Grafana, instead of an API key (defaults to $GRAFANA_USERNAME)")
+	rootCmd.Flags().StringVar(&grafanaConfigFlags.Password, "grafana-password", "", "Basic auth password of Grafana (defaults to $GRAFANA_PASSWORD)")
+	rootCmd.Flags().StringVar(&grafanaConfigFlags.CAFile, "grafana-ca-file", "", "CA certificates verifying Grafana (defaults to $GRAFANA_CA_FILE)")
+	rootCmd.Flags().BoolVar(&grafanaConfigFlags.InsecureSkipVerify, "grafana-insecure-skip-verify", false, "Skip TLS verification of Grafana")
+	rootCmd.Flags().StringVar(&grafanaConfigFlags.OrgID, "grafana-org-id", "", "Organization of Grafana the tools work in (defaults to $GRAFANA_ORG_ID, else the organization of the credentials)")
 
 	// if found .env file, load it
 	if _, err := os.Stat(".env"); err == nil {
@@ -102,6 +201,12 @@ func run(cmd *cobra.Command, args []string) {
 	if metricsPort == 0 {
 		metricsPort = port
 	}
+	if adminPort == 0 {
+		adminPort = port
+	}
+	if adminToken == "" {
+		adminToken = os.Getenv("KAGENT_TOOLS_ADMIN_TOKEN")
+	}
 
 	logger.Init(stdio)
 	defer logger.Sync()
@@ -133,6 +238,13 @@ func run(cmd *cobra.Command, args []string) {
 		attribute.Int("server.port", port),
 		attribute.StringSlice("server.tools", tools),
 		attribute.Bool("server.read_only", readOnly),
+		attribute.String("server.k8s_backend", k8sBackend),
+		attribute.Bool("server.k8s_informer_cache", informerCache),
+		attribute.Bool("server.require_approval", requireApproval),
+		attribute.String("server.policy", policyPath),
+		attribute.Bool("server.auth", authConfigPath != "" && !stdio),
+		attribute.Bool("server.tls", tlsCertFile != "" && !stdio),
+		attribute.Bool("server.impersonate", impersonate),
 	)
 
 	logger.Get().Info("Starting "+Name, "version", Version, "git_commit", GitCommit, "build_date", BuildDate)
@@ -140,17 +252,180 @@ func run(cmd *cobra.Command, args []string) {
 		logger.Get().Info("Running in read-only mode - write operations are disabled")
 	}
 
+	// Make --kubeconfig visible to the providers that read it from utils
+	utils.SetKubeconfig(*kubeconfig)
+
+	if clusterPath == "" {
+		clusterPath = *kubeconfig
+	}
+	if registry, err := clusters.Load(clusterPath); err != nil {
+		logger.Get().Warn("Failed to load cluster registry, per-call cluster selection is disabled", "path", clusterPath, "error", err)
+	} else {
+		clusters.SetRegistry(registry)
+		logger.Get().Info("Loaded cluster registry", "clusters", len(registry.List()), "default", registry.Default())
+	}
+
+	if len(cacheWatch) > 0 {
+		go watchCacheInvalidation(ctx, *kubeconfig, cacheWatch)
+	}
+
+	var gate *approval.Gate
+	if requireApproval {
+		// Without a token any caller could approve their own parked calls
+		if adminToken == "" {
+			logger.Get().Error("--require-approval needs --admin-token or $KAGENT_TOOLS_ADMIN_TOKEN to protect the approval endpoints")
+			os.Exit(1)
+		}
+		gate, err = approval.NewGate(approvalTools, approvalTimeout)
+		if err != nil {
+			logger.Get().Error("Invalid approval configuration", "error", err)
+			os.Exit(1)
+		}
+		logger.Get().Info("Destructive tool calls require approval", "tools", strings.Join(approvalTools, ","), "timeout", approvalTimeout)
+	}
+
+	var policyEngine *policy.Engine
+	if policyPath != "" {
+		policyEngine, err = policy.NewEngine(policyPath)
+		if err != nil {
+			logger.Get().Error("Failed to load tool policy", "file", policyPath, "error", err)
+			os.Exit(1)
+		}
+	}
+
+	if (tlsCertFile == "") != (tlsKeyFile == "") {
+		logger.Get().Error("--tls-cert and --tls-key must be set together")
+		os.Exit(1)
+	}
+
+	// Only the HTTP transport has callers to authenticate; a stdio client is the process owner
+	var authenticator *auth.Authenticator
+	if authConfigPath != "" && stdio {
+		logger.Get().Warn("Ignoring --auth-config in stdio mode")
+	} else if authConfigPath != "" {
+		authConfig, err := auth.Load(authConfigPath)
+		if err == nil {
+			authenticator, err = auth.New(authConfig, toolVerb(policyEngine))
+		}
+		if err != nil {
+			logger.Get().Error("Failed to load auth config", "file", authConfigPath, "error", err)
+			os.Exit(1)
+		}
+		if authenticator.TLSConfig() != nil && tlsCertFile == "" {
+			logger.Get().Error("mTLS authentication needs --tls-cert and --tls-key")
+			os.Exit(1)
+		}
+		logger.Get().Info("HTTP callers must authenticate", "file", authConfigPath, "public_paths", strings.Join(authConfig.PublicPaths, ","))
+	}
+
+	if impersonate {
+		if stdio || credentials.PassthroughEnabled() {
+			logger.Get().Error("--impersonate needs the HTTP transport and cannot be combined with TOKEN_PASSTHROUGH")
+			os.Exit(1)
+		}
+		// The headers name any user and group, so only authenticated proxies may set them
+		if authenticator == nil || len(impersonateProxies) == 0 {
+			logger.Get().Error("--impersonate needs --auth-config and --impersonate-trusted-proxies naming the proxies allowed to set the impersonation headers")
+			os.Exit(1)
+		}
+		credentials.EnableImpersonation(credentials.ImpersonationHeaders{
+			User:           impersonateUserHeader,
+			Group:          impersonateGroupHeader,
+			TrustedProxies: impersonateProxies,
+		})
+		logger.Get().Info("Cluster tools impersonate the caller", "user_header", impersonateUserHeader, "group_header", impersonateGroupHeader,
+			"trusted_proxies", strings.Join(impersonateProxies, ","))
+	}
+
+	prometheusConfig, err = prometheus.LoadConfig(prometheusConfigPath, prometheusDataSource)
+	if err != nil {
+		logger.Get().Error("Failed to load Prometheus data sources", "file", prometheusConfigPath, "error", err)
+		os.Exit(1)
+	}
+
+	alertmanagerConfig, err = alertmanager.LoadConfig(alertmanagerConfigFlags)
+	if err != nil {
+		logger.Get().Error("Invalid Alertmanager configuration", "error", err)
+		os.Exit(1)
+	}
+
+	// Panel queries of the grafana tools run against the Prometheus data sources
+	grafanaConfigFlags.Prometheus = prometheusConfig
+	grafanaConfig, err = grafana.LoadConfig(grafanaConfigFlags)
+	if err != nil {
+		logger.Get().Error("Invalid Grafana configuration", "error", err)
+		os.Exit(1)
+	}
+
+	limiter, err := budget.NewLimiter(maxResultBytes, maxResultTokens)
+	if err != nil {
+		logger.Get().Error("Invalid result budget", "error", err)
+		os.Exit(1)
+	}
+
+	auditor, err := newAuditor(auditSinks, auditReads, stdio)
+	if err != nil {
+		logger.Get().Error("Invalid audit configuration", "error", err)
+		os.Exit(1)
+	}
+	defer func() {
+		if err := auditor.Close(); err != nil {
+			logger.Get().Error("Failed to close audit sinks", "error", err)
+		}
+	}()
+
+	serverOptions := []server.ServerOption{server.WithElicitation()}
+	if policyEngine != nil {
+		serverOptions = append(serverOptions, server.WithToolFilter(policyToolFilter(policyEngine)))
+	}
+	if authenticator != nil {
+		serverOptions = append(serverOptions, server.WithToolFilter(authToolFilter(authenticator)))
+	}
 	mcp := server.NewMCPServer(
 		Name,
 		Version,
+		serverOptions...,
 	)
 
 	// Register tools and wrap handlers with metrics instrumentation.
 	// registerMCP returns a map of tool_name -> tool_provider so that
 	// wrapToolHandlersWithMetrics knows which provider each tool belongs to.
+	// The approval gate is applied first so that it sits right in front of the
+	// tool and only parks calls that passed argument, cluster and policy validation.
+	// The result budget sits outside the calls it pages, so that reading a page of a
+	// result neither runs the tool again nor needs approval, but inside role
+	// authorization. The audit wrapper goes outside all of them so that rejected calls
+	// are recorded too.
 	toolProviders := registerMCP(mcp, tools, *kubeconfig, readOnly)
+	if gate != nil {
+		wrapToolHandlersWithApproval(mcp, gate)
+	}
+	if policyEngine != nil {
+		wrapToolHandlersWithPolicy(mcp, policyEngine)
+	}
+	wrapToolHandlersWithClusterSelection(mcp, toolProviders)
+	wrapToolHandlersWithCacheFreshness(mcp, toolProviders, toolVerb(policyEngine))
+	if credentials.PassthroughEnabled() {
+		logger.Get().Info("Token passthrough is enabled - cluster tools run with the caller's bearer token")
+	}
+	if credentials.PassthroughEnabled() || credentials.ImpersonationEnabled() {
+		wrapToolHandlersWithCallerCredentials(mcp, toolProviders)
+	}
+	if limiter != nil {
+		wrapToolHandlersWithResultBudget(mcp, limiter)
+	}
+	if authenticator != nil {
+		wrapToolHandlersWithAuthorization(mcp, authenticator)
+	}
+	wrapToolHandlersWithAudit(mcp, auditor, toolProviders, toolVerb(policyEngine))
 	wrapToolHandlersWithMetrics(mcp, toolProviders)
 
+	if policyEngine != nil {
+		if err := policyEngine.Watch(ctx, func() { notifyToolListChanged(mcp) }); err != nil {
+			logger.Get().Warn("Tool policy will not be reloaded on change", "file", policyPath, "error", err)
+		}
+	}
+
 	// Create wait group for server goroutines
 	var wg sync.WaitGroup
 
@@ -161,6 +436,31 @@ func run(cmd *cobra.Command, args []string) {
 	// HTTP server reference (only used when not in stdio mode)
 	var httpServer *http.Server
 	var metricsServer *http.Server // Separate server for metrics if metricsPort is different from main port
+	var adminServer *http.Server   // Separate server for the admin endpoints if adminPort is different from main port
+
+	// The admin endpoints change server-wide state, so they are only served with a token. In
+	// stdio mode there is no main HTTP server, so they get their own when approvals need them.
+	if adminToken == "" {
+		logger.Get().Info("Admin endpoints are disabled without --admin-token")
+	} else if (stdio && gate != nil) || (!stdio && adminPort != port) {
+		adminServer = &http.Server{
+			Addr:    fmt.Sprintf(":%d", adminPort),
+			Handler: adminHandler(gate, adminToken, readOnly),
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logger.Get().Info("Starting admin endpoints on /admin", "port", strconv.Itoa(adminPort))
+			if err := adminServer.ListenAndServe(); err != nil {
+				if !errors.Is(err, http.ErrServerClosed) {
+					logger.Get().Error("Admin endpoint failed", "error", err)
+				} else {
+					logger.Get().Info("Admin server closed gracefully.")
+				}
+			}
+		}()
+	}
 
 	// Start server based on chosen mode
 	wg.Add(1)
@@ -168,6 +468,13 @@ func run(cmd *cobra.Command, args []string) {
 		go func() {
 			defer wg.Done()
 			runStdioServer(ctx, mcp)
+
+			// The client closing stdin ends the session, so take the admin endpoints down with it
+			if adminServer != nil {
+				shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer shutdownCancel()
+				_ = adminServer.Shutdown(shutdownCtx)
+			}
 		}()
 	} else {
 		sseServer := server.NewStreamableHTTPServer(mcp,
@@ -177,13 +484,22 @@ func run(cmd *cobra.Command, args []string) {
 		// Create a mux to handle different routes
 		mux := http.NewServeMux()
 
+		// With --auth-config every route except the admin endpoints, which have their own
+		// token, and the configured public paths requires an authenticated caller
+		protect := func(handler http.Handler) http.Handler {
+			if authenticator == nil {
+				return handler
+			}
+			return authenticator.Middleware(handler)
+		}
+
 		// Add health endpoint
-		mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		mux.Handle("/health", protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusOK)
 			if err := writeResponse(w, []byte("OK")); err != nil {
 				logger.Get().Error("Failed to write health response", "error", err)
 			}
-		})
+		})))
 
 		// Add metrics endpoint
 		registry := metrics.InitServer() // Initialize Prometheus metrics before starting the server
@@ -192,7 +508,7 @@ func run(cmd *cobra.Command, args []string) {
 			// Create the metrics server outside the goroutine to avoid a race condition
 			// between the goroutine assigning metricsServer and the shutdown handler reading it
 			metricsMux := http.NewServeMux()
-			metricsMux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+			metricsMux.Handle("/metrics", protect(promhttp.HandlerFor(registry, promhttp.HandlerOpts{})))
 			metricsServer = &http.Server{
 				Addr:    fmt.Sprintf(":%d", metricsPort),
 				Handler: metricsMux,
@@ -212,7 +528,7 @@ func run(cmd *cobra.Command, args []string) {
 			}()
 		} else {
 			logger.Get().Info("Starting Prometheus metrics endpoint on /metrics", "port", strconv.Itoa(port))
-			mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+			mux.Handle("/metrics", protect(promhttp.HandlerFor(registry, promhttp.HandlerOpts{})))
 		}
 		serverMode := "read-write"
 		if readOnly {
@@ -220,20 +536,34 @@ func run(cmd *cobra.Command, args []string) {
 		}
 		metrics.KagentToolsMCPServerInfo.WithLabelValues(Name, Version, GitCommit, BuildDate, serverMode).Set(1)
 
+		if adminServer == nil && adminToken != "" {
+			logger.Get().Info("Starting admin endpoints on /admin", "port", strconv.Itoa(port))
+			mux.Handle("/admin/", adminHandler(gate, adminToken, readOnly))
+		}
+
 		// Handle all other routes with the MCP server wrapped in telemetry middleware
-		mux.Handle("/", telemetry.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.Handle("/", protect(telemetry.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			sseServer.ServeHTTP(w, r)
-		})))
+		}))))
 
 		httpServer = &http.Server{
 			Addr:    fmt.Sprintf(":%d", port),
 			Handler: mux,
 		}
+		if authenticator != nil {
+			httpServer.TLSConfig = authenticator.TLSConfig()
+		}
 
 		go func() {
 			defer wg.Done()
-			logger.Get().Info("Running KAgent Tools Server", "port", fmt.Sprintf(":%d", port), "tools", strings.Join(tools, ","))
-			if err := httpServer.ListenAndServe(); err != nil {
+			logger.Get().Info("Running KAgent Tools Server", "port", fmt.Sprintf(":%d", port), "tools", strings.Join(tools, ","), "tls", tlsCertFile != "")
+			var err error
+			if tlsCertFile != "" {
+				err = httpServer.ListenAndServeTLS(tlsCertFile, tlsKeyFile)
+			} else {
+				err = httpServer.ListenAndServe()
+			}
+			if err != nil {
 				if !errors.Is(err, http.ErrServerClosed) {
 					logger.Get().Error("Failed to start HTTP server", "error", err)
 				} else {
@@ -280,6 +610,19 @@ func run(cmd *cobra.Command, args []string) {
 				logger.Get().Info("Metrics server shutdown completed")
 			}
 		}
+
+		// Gracefully shutdown admin server if running separately
+		if adminServer != nil {
+			shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer shutdownCancel()
+
+			if err := adminServer.Shutdown(shutdownCtx); err != nil {
+				logger.Get().Error("Failed to shutdown admin server gracefully", "error", err)
+				rootSpan.RecordError(err)
+			} else {
+				logger.Get().Info("Admin server shutdown completed")
+			}
+		}
 	}()
 
 	// Wait for all server operations to complete
@@ -287,6 +630,19 @@ func run(cmd *cobra.Command, args []string) {
 	logger.Get().Info("Server shutdown complete")
 }
 
+// adminHandler serves the admin endpoints behind token: the cache endpoints always, the
+// approval endpoints when calls require approval
+func adminHandler(gate *approval.Gate, token string, readOnly bool) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/admin/cache", cache.Handler(readOnly))
+	mux.Handle("/admin/cache/", cache.Handler(readOnly))
+	if gate != nil {
+		mux.Handle("/admin/approvals", approval.Handler(gate))
+		mux.Handle("/admin/approvals/", approval.Handler(gate))
+	}
+	return admin.RequireToken(token, mux)
+}
+
 // writeResponse writes data to an HTTP response writer with proper error handling
 func writeResponse(w http.ResponseWriter, data []byte) error {
 	_, err := w.Write(data)
@@ -301,21 +657,47 @@ func runStdioServer(ctx context.Context, mcp *server.MCPServer) {
 	}
 }
 
+// watchCacheInvalidation evicts cached results when the watched resources change in the
+// default cluster. Without the watch results only go stale until they expire, so failures
+// are logged rather than fatal.
+func watchCacheInvalidation(ctx context.Context, kubeconfig string, resources []string) {
+	config, _, err := clusters.LoadRESTConfig(kubeconfig)
+	if err == nil {
+		err = cache.WatchInvalidation(ctx, config, "", resources)
+	}
+	if err != nil {
+		logger.Get().Warn("Failed to watch resources for cache invalidation", "resources", strings.Join(resources, ","), "error", err)
+	}
+}
+
 // registerMCP registers tool providers with the MCP server and returns a mapping
 // of tool_name -> tool_provider. This mapping is built using the ListTools() diff
 // technique: we snapshot the tool list before and after each provider registers,
 // so we know exactly which tools belong to which provider.
 func registerMCP(mcp *server.MCPServer, enabledToolProviders []string, kubeconfig string, readOnly bool) map[string]string {
+	k8sOptions := k8s.Options{
+		Backend:  k8sBackend,
+		ReadOnly: readOnly,
+		InformerCache: k8s.InformerOptions{
+			Enabled:      informerCache,
+			MaxInformers: informerCacheMaxInformers,
+			MaxObjects:   informerCacheMaxObjects,
+		},
+	}
+
 	// A map to hold tool providers and their registration functions
 	toolProviderMap := map[string]func(*server.MCPServer){
-		"argo":       func(s *server.MCPServer) { argo.RegisterTools(s, readOnly) },
-		"cilium":     func(s *server.MCPServer) { cilium.RegisterTools(s, readOnly) },
-		"helm":       func(s *server.MCPServer) { helm.RegisterTools(s, readOnly) },
-		"istio":      func(s *server.MCPServer) { istio.RegisterTools(s, readOnly) },
-		"k8s":        func(s *server.MCPServer) { k8s.RegisterTools(s, nil, kubeconfig, readOnly) },
-		"kubescape":  func(s *server.MCPServer) { kubescape.RegisterTools(s, kubeconfig, readOnly) },
-		"prometheus": func(s *server.MCPServer) { prometheus.RegisterTools(s, readOnly) },
-		"utils":      func(s *server.MCPServer) { utils.RegisterTools(s, readOnly) },
+		"alertmanager": func(s *server.MCPServer) { alertmanager.RegisterToolsWithConfig(s, readOnly, alertmanagerConfig) },
+		"argo":         func(s *server.MCPServer) { argo.RegisterTools(s, readOnly) },
+		"cache":        func(s *server.MCPServer) { cachetools.RegisterTools(s, readOnly) },
+		"cilium":       func(s *server.MCPServer) { cilium.RegisterTools(s, readOnly) },
+		"grafana":      func(s *server.MCPServer) { grafana.RegisterToolsWithConfig(s, readOnly, grafanaConfig) },
+		"helm":         func(s *server.MCPServer) { helm.RegisterTools(s, readOnly) },
+		"istio":        func(s *server.MCPServer) { istio.RegisterTools(s, readOnly) },
+		"k8s":          func(s *server.MCPServer) { k8s.RegisterToolsWithOptions(s, nil, kubeconfig, k8sOptions) },
+		"kubescape":    func(s *server.MCPServer) { kubescape.RegisterTools(s, kubeconfig, readOnly) },
+		"prometheus":   func(s *server.MCPServer) { prometheus.RegisterToolsWithConfig(s, readOnly, prometheusConfig) },
+		"utils":        func(s *server.MCPServer) { utils.RegisterTools(s, readOnly) },
 	}
 
 	// If no specific tools are specified, register all available tools.
@@ -369,7 +751,12 @@ func registerMCP(mcp *server.MCPServer, enabledToolProviders []string, kubeconfi
 //   - Increments kagent_tools_mcp_invocations_failure_total when the handler returns a
 //     non-nil Go error OR when result.IsError is true (the MCP convention for tool-level
 //     failures - handlers return NewToolResultError(...), nil, not a Go error)
-//   - Calls the original handler unchanged - the tool's behaviour is not affected
+//   - Observes the call's duration in kagent_tools_mcp_invocation_duration_seconds and the
+//     size of its content in kagent_tools_mcp_result_bytes
+//   - Increments kagent_tools_mcp_invocation_errors_total for each failure, labeled by the
+//     ErrorCode and Component of structured tool errors
+//   - Calls the original handler with the provider in the context, where the shell executor
+//     picks it up to label the CLI subprocess metrics - the tool's behaviour is not affected
 //
 // This uses the standard middleware/decorator pattern: the original handler and the
 // wrapped handler have the same function signature, so they are interchangeable.
@@ -387,14 +774,23 @@ func wrapToolHandlersWithMetrics(mcpServer *server.MCPServer, toolToProvider map
 			Tool: st.Tool,
 			Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 				metrics.KagentToolsMCPInvocationsTotal.WithLabelValues(toolName, provider).Inc()
+				start := time.Now()
 
-				result, err := originalHandler(ctx, req)
+				// Attribute the CLI subprocesses the tool runs to its provider
+				result, err := originalHandler(metrics.WithToolProvider(ctx, provider), req)
+
+				metrics.KagentToolsMCPInvocationDurationSeconds.WithLabelValues(toolName, provider).Observe(time.Since(start).Seconds())
+				if result != nil {
+					metrics.KagentToolsMCPResultBytes.WithLabelValues(toolName, provider).Observe(float64(resultBytes(result)))
+				}
 
 				// Count as failure if the Go error is non-nil OR if the tool returned
 				// a result with IsError=true (the MCP convention for tool-level failures,
 				// which always return nil for the Go error).
 				if err != nil || (result != nil && result.IsError) {
 					metrics.KagentToolsMCPInvocationsFailureTotal.WithLabelValues(toolName, provider).Inc()
+					code, component := failureLabels(result, err)
+					metrics.KagentToolsMCPInvocationErrorsTotal.WithLabelValues(toolName, provider, code, component).Inc()
 				}
 
 				return result, err
@@ -404,3 +800,515 @@ func wrapToolHandlersWithMetrics(mcpServer *server.MCPServer, toolToProvider map
 
 	mcpServer.SetTools(wrapped...)
 }
+
+// failureLabels returns the error_code and component labels of a failed call: those of the
+// structured tool error it returned, handler_error for a Go error, and unstructured for
+// any other error result
+func failureLabels(result *mcp.CallToolResult, err error) (code, component string) {
+	if err != nil {
+		return "handler_error", "none"
+	}
+	if code, component, ok := toolerrors.CodeAndComponent(result); ok {
+		return code, component
+	}
+	return "unstructured", "none"
+}
+
+// resultBytes returns the size of the text and binary content of a result
+func resultBytes(result *mcp.CallToolResult) int {
+	size := 0
+	for _, content := range result.Content {
+		switch c := content.(type) {
+		case mcp.TextContent:
+			size += len(c.Text)
+		case mcp.ImageContent:
+			size += len(c.Data)
+		case mcp.AudioContent:
+			size += len(c.Data)
+		}
+	}
+	return size
+}
+
+// clusterAwareProviders are the providers whose tools talk to a Kubernetes cluster and
+// therefore accept the optional cluster argument
+var clusterAwareProviders = map[string]bool{
+	"argo":      true,
+	"cilium":    true,
+	"helm":      true,
+	"istio":     true,
+	"k8s":       true,
+	"kubescape": true,
+}
+
+// serverStateTools are the tools of cluster-aware providers that report on the server's own
+// state rather than on a cluster
+var serverStateTools = map[string]bool{
+	"k8s_list_clusters": true,
+	"k8s_cache_health":  true,
+}
+
+// wrapToolHandlersWithClusterSelection adds an optional cluster argument to every tool of a
+// cluster-aware provider. The wrapper resolves the name against the cluster registry and
+// stores the cluster in the request context, where the command builder and the client-go
+// based providers pick it up. Calls without the argument use the default cluster.
+func wrapToolHandlersWithClusterSelection(mcpServer *server.MCPServer, toolToProvider map[string]string) {
+	allTools := mcpServer.ListTools()
+	wrapped := make([]server.ServerTool, 0, len(allTools))
+
+	for name, st := range allTools {
+		if !clusterAwareProviders[toolToProvider[name]] || serverStateTools[name] || st.Tool.RawInputSchema != nil {
+			wrapped = append(wrapped, *st)
+			continue
+		}
+
+		tool := st.Tool
+		properties := make(map[string]any, len(tool.InputSchema.Properties)+1)
+		for k, v := range tool.InputSchema.Properties {
+			properties[k] = v
+		}
+		properties[clusters.ArgumentName] = map[string]any{
+			"type":        "string",
+			"description": "Cluster to run against, as listed by k8s_list_clusters (optional, defaults to the current context)",
+		}
+		tool.InputSchema.Properties = properties
+
+		originalHandler := st.Handler
+		wrapped = append(wrapped, server.ServerTool{
+			Tool: tool,
+			Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				clusterName := req.GetString(clusters.ArgumentName, "")
+				if clusterName == "" {
+					return originalHandler(ctx, req)
+				}
+
+				cluster, err := clusters.GetRegistry().Get(clusterName)
+				if err != nil {
+					return toolerrors.NewValidationError(clusters.ArgumentName, err.Error()).ToMCPResult(), nil
+				}
+				return originalHandler(clusters.WithCluster(ctx, cluster), req)
+			},
+		})
+	}
+
+	mcpServer.SetTools(wrapped...)
+}
+
+// wrapToolHandlersWithCacheFreshness adds the optional bypass_cache and max_age arguments to
+// every read tool of a cluster-aware provider. The wrapper stores the age limit in the
+// request context, where the command builder and the informer cache pick it up, so that an
+// agent can demand fresh data after a change.
+func wrapToolHandlersWithCacheFreshness(mcpServer *server.MCPServer, toolToProvider map[string]string, verb func(string) string) {
+	allTools := mcpServer.ListTools()
+	wrapped := make([]server.ServerTool, 0, len(allTools))
+
+	for name, st := range allTools {
+		if !clusterAwareProviders[toolToProvider[name]] || serverStateTools[name] || verb(name) != policy.VerbRead || st.Tool.RawInputSchema != nil {
+			wrapped = append(wrapped, *st)
+			continue
+		}
+
+		tool := st.Tool
+		properties := make(map[string]any, len(tool.InputSchema.Properties)+2)
+		for k, v := range tool.InputSchema.Properties {
+			properties[k] = v
+		}
+		properties[cache.BypassArgument] = map[string]any{
+			"type":        "boolean",
+			"description": "Skip cached results and read from the cluster (optional, use after a change)",
+		}
+		properties[cache.MaxAgeArgument] = map[string]any{
+			"type":        "string",
+			"description": "Only accept cached results computed at most this long ago, e.g. 10s (optional)",
+		}
+		tool.InputSchema.Properties = properties
+
+		originalHandler := st.Handler
+		wrapped = append(wrapped, server.ServerTool{
+			Tool: tool,
+			Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				if req.GetBool(cache.BypassArgument, false) {
+					return originalHandler(cache.WithMaxAge(ctx, 0), req)
+				}
+
+				maxAge := req.GetString(cache.MaxAgeArgument, "")
+				if maxAge == "" {
+					return originalHandler(ctx, req)
+				}
+				age, err := time.ParseDuration(maxAge)
+				if err != nil || age < 0 {
+					return toolerrors.NewValidationError(cache.MaxAgeArgument, "must be a non-negative duration such as 30s").ToMCPResult(), nil
+				}
+				return originalHandler(cache.WithMaxAge(ctx, age), req)
+			},
+		})
+	}
+
+	mcpServer.SetTools(wrapped...)
+}
+
+// wrapToolHandlersWithCallerCredentials stores the caller's Kubernetes identity in the
+// request context, where the command builder and the client-go based providers pick it up:
+// the bearer token with TOKEN_PASSTHROUGH, the user and groups of the trusted headers with
+// --impersonate. Tools of cluster-aware providers fail without it instead of running with
+// the server's own identity.
+func wrapToolHandlersWithCallerCredentials(mcpServer *server.MCPServer, toolToProvider map[string]string) {
+	allTools := mcpServer.ListTools()
+	wrapped := make([]server.ServerTool, 0, len(allTools))
+
+	for name, st := range allTools {
+		originalHandler := st.Handler
+		toolName := name // capture for closure
+		required := clusterAwareProviders[toolToProvider[toolName]] && !serverStateTools[toolName]
+
+		wrapped = append(wrapped, server.ServerTool{
+			Tool: st.Tool,
+			Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				if credentials.PassthroughEnabled() {
+					token := credentials.BearerToken(req.Header)
+					if token == "" && required {
+						return toolerrors.NewToolError("TokenPassthrough", "call "+toolName, credentials.ErrTokenRequired).
+							WithErrorCode("TOKEN_REQUIRED").
+							WithSuggestions("Send the caller's Kubernetes token in an Authorization: Bearer header").
+							ToMCPResult(), nil
+					}
+					if token != "" {
+						ctx = credentials.WithToken(ctx, token)
+					}
+				}
+
+				if credentials.ImpersonationEnabled() {
+					imp, err := credentials.ImpersonationForRequest(ctx, req.Header)
+					if err != nil && required {
+						code := "IMPERSONATION_REQUIRED"
+						if errors.Is(err, credentials.ErrUntrustedImpersonation) {
+							code = "IMPERSONATION_UNTRUSTED"
+						}
+						return toolerrors.NewToolError("Impersonation", "call "+toolName, err).
+							WithErrorCode(code).
+							WithSuggestions("Call the server through the gateway that sets the impersonation headers").
+							ToMCPResult(), nil
+					}
+					if err == nil {
+						ctx = credentials.WithImpersonation(ctx, imp)
+					}
+				}
+
+				return originalHandler(ctx, req)
+			},
+		})
+	}
+
+	mcpServer.SetTools(wrapped...)
+}
+
+// wrapToolHandlersWithResultBudget keeps every tool result within the limiter's budget and
+// adds the optional cursor argument that reads the pages of a cut result. Pages are served
+// from the stored output to the caller the tool ran for, without running the tool again.
+func wrapToolHandlersWithResultBudget(mcpServer *server.MCPServer, limiter *budget.Limiter) {
+	allTools := mcpServer.ListTools()
+	wrapped := make([]server.ServerTool, 0, len(allTools))
+
+	for name, st := range allTools {
+		if st.Tool.RawInputSchema != nil {
+			wrapped = append(wrapped, *st)
+			continue
+		}
+
+		tool := st.Tool
+		properties := make(map[string]any, len(tool.InputSchema.Properties)+1)
+		for k, v := range tool.InputSchema.Properties {
+			properties[k] = v
+		}
+		properties[budget.CursorArgument] = map[string]any{
+			"type":        "string",
+			"description": "Cursor from a truncated result of this tool, to read its next page instead of running the tool (optional, other arguments are ignored)",
+		}
+		tool.InputSchema.Properties = properties
+
+		// Structured content cut to the budget keeps to the output schema, which declares
+		// the fields marking the cut
+		if tool.OutputSchema.Type != "" && tool.RawOutputSchema == nil {
+			outputProperties := make(map[string]any, len(tool.OutputSchema.Properties)+2)
+			for k, v := range tool.OutputSchema.Properties {
+				outputProperties[k] = v
+			}
+			outputProperties[budget.TruncatedField] = map[string]any{
+				"type":        "boolean",
+				"description": "Set when the largest list of the result was cut to the result budget",
+			}
+			outputProperties[budget.CursorField] = map[string]any{
+				"type":        "string",
+				"description": "Cursor argument that reads the next items of a cut result",
+			}
+			tool.OutputSchema.Properties = outputProperties
+		}
+
+		originalHandler := st.Handler
+		toolName := name // capture for closure
+		wrapped = append(wrapped, server.ServerTool{
+			Tool: tool,
+			Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				owner := identity.Resolve(ctx, req.Header).String()
+				if cursor := req.GetString(budget.CursorArgument, ""); cursor != "" {
+					return limiter.Page(toolName, owner, cursor), nil
+				}
+
+				result, err := originalHandler(ctx, req)
+				if err != nil {
+					return result, err
+				}
+				return limiter.Limit(toolName, owner, result), nil
+			},
+		})
+	}
+
+	mcpServer.SetTools(wrapped...)
+}
+
+// wrapToolHandlersWithApproval parks every call to a tool matched by the gate until it is
+// approved, either by the caller answering an MCP elicitation prompt or by an operator
+// through the admin endpoints. Denied and expired calls never reach the tool.
+func wrapToolHandlersWithApproval(mcpServer *server.MCPServer, gate *approval.Gate) {
+	allTools := mcpServer.ListTools()
+	wrapped := make([]server.ServerTool, 0, len(allTools))
+
+	for name, st := range allTools {
+		if !gate.Requires(name) {
+			wrapped = append(wrapped, *st)
+			continue
+		}
+
+		originalHandler := st.Handler
+		toolName := name // capture for closure
+		wrapped = append(wrapped, server.ServerTool{
+			Tool: st.Tool,
+			Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				decision := gate.Await(ctx, toolName, req, approval.Caller(ctx, req.Header))
+				if decision.Status == approval.StatusApproved {
+					return originalHandler(ctx, req)
+				}
+
+				cause := fmt.Errorf("call was %s", decision.Status)
+				if decision.Reason != "" {
+					cause = fmt.Errorf("call was %s: %s", decision.Status, decision.Reason)
+				}
+				toolErr := toolerrors.NewToolError("Approval", "run "+toolName, cause).
+					WithErrorCode("APPROVAL_"+strings.ToUpper(string(decision.Status))).
+					WithContext("approval_id", decision.ID)
+				if decision.DecidedBy != "" {
+					toolErr = toolErr.WithContext("decided_by", decision.DecidedBy)
+				}
+				if decision.Status == approval.StatusExpired {
+					toolErr = toolErr.WithRetryable(true).WithSuggestions("Ask an operator to approve the call, then retry it")
+				}
+				return toolErr.ToMCPResult(), nil
+			},
+		})
+	}
+
+	mcpServer.SetTools(wrapped...)
+}
+
+// wrapToolHandlersWithPolicy checks every call against the current tool policy before it
+// runs. The engine is consulted on each call, so a reloaded policy applies immediately.
+func wrapToolHandlersWithPolicy(mcpServer *server.MCPServer, engine *policy.Engine) {
+	allTools := mcpServer.ListTools()
+	wrapped := make([]server.ServerTool, 0, len(allTools))
+
+	for name, st := range allTools {
+		originalHandler := st.Handler
+		toolName := name // capture for closure
+
+		wrapped = append(wrapped, server.ServerTool{
+			Tool: st.Tool,
+			Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				decision := engine.Policy().Evaluate(policy.CallFromArguments(toolName, req.GetArguments()))
+				if !decision.Allowed {
+					logger.Get().Info("Tool call denied by policy", "tool", toolName, "rule", decision.Rule, "code", decision.Code, "reason", decision.Reason)
+					return toolerrors.NewPolicyError(toolName, decision.Code, decision.Rule, decision.Reason).ToMCPResult(), nil
+				}
+				return originalHandler(ctx, req)
+			},
+		})
+	}
+
+	mcpServer.SetTools(wrapped...)
+}
+
+// notifyToolListChanged tells connected clients to fetch tools/list again, e.g. after a
+// reloaded policy hid or revealed tools
+func notifyToolListChanged(mcpServer *server.MCPServer) {
+	mcpServer.SendNotificationToAllClients(mcp.MethodNotificationToolsListChanged, nil)
+}
+
+// policyToolFilter leaves tools the current policy denies outright out of tools/list
+func policyToolFilter(engine *policy.Engine) server.ToolFilterFunc {
+	return func(_ context.Context, tools []mcp.Tool) []mcp.Tool {
+		p := engine.Policy()
+		filtered := make([]mcp.Tool, 0, len(tools))
+		for _, tool := range tools {
+			if !p.Hidden(tool.Name) {
+				filtered = append(filtered, tool)
+			}
+		}
+		return filtered
+	}
+}
+
+// authToolFilter leaves the tools none of the caller's roles grant out of tools/list
+func authToolFilter(authenticator *auth.Authenticator) server.ToolFilterFunc {
+	return func(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+		principal, _ := identity.PrincipalFromContext(ctx)
+		filtered := make([]mcp.Tool, 0, len(tools))
+		for _, tool := range tools {
+			if authenticator.Allowed(principal, tool.Name) {
+				filtered = append(filtered, tool)
+			}
+		}
+		return filtered
+	}
+}
+
+// wrapToolHandlersWithAuthorization rejects calls to tools that none of the roles of the
+// authenticated caller grant. Calls without a principal never got past the HTTP
+// middleware and are rejected as well.
+func wrapToolHandlersWithAuthorization(mcpServer *server.MCPServer, authenticator *auth.Authenticator) {
+	allTools := mcpServer.ListTools()
+	wrapped := make([]server.ServerTool, 0, len(allTools))
+
+	for name, st := range allTools {
+		originalHandler := st.Handler
+		toolName := name // capture for closure
+
+		wrapped = append(wrapped, server.ServerTool{
+			Tool: st.Tool,
+			Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				principal, _ := identity.PrincipalFromContext(ctx)
+				if authenticator.Allowed(principal, toolName) {
+					return originalHandler(ctx, req)
+				}
+
+				logger.Get().Info("Tool call forbidden for caller", "tool", toolName, "subject", principal.Subject, "roles", strings.Join(principal.Roles, ","))
+				return toolerrors.NewToolError("Auth", "call "+toolName, fmt.Errorf("%s is not allowed to call %s", identity.Identity{Subject: principal.Subject}, toolName)).
+					WithErrorCode("AUTH_FORBIDDEN").
+					WithContext("subject", principal.Subject).
+					WithContext("roles", strings.Join(principal.Roles, ",")).
+					WithSuggestions("Ask an administrator to bind a role granting this tool to your identity").
+					ToMCPResult(), nil
+			},
+		})
+	}
+
+	mcpServer.SetTools(wrapped...)
+}
+
+// newAuditor creates the auditor for the --audit-sink values. "none" disables auditing.
+func newAuditor(specs []string, reads, stdio bool) (*audit.Auditor, error) {
+	var sinks []audit.Sink
+	for _, spec := range specs {
+		if spec == "none" {
+			continue
+		}
+		sink, err := audit.ParseSink(spec, stdio)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	return audit.NewAuditor(reads, sinks...), nil
+}
+
+// toolVerb classifies tools as read or write with the tool policy when one is loaded
+func toolVerb(engine *policy.Engine) func(string) string {
+	if engine == nil {
+		return policy.DefaultVerb
+	}
+	return func(tool string) string { return engine.Policy().Verb(tool) }
+}
+
+// wrapToolHandlersWithAudit records every tool call with the caller, target, outcome and
+// duration. Mutating calls are always recorded; read-only calls unless --audit-reads=false.
+func wrapToolHandlersWithAudit(mcpServer *server.MCPServer, auditor *audit.Auditor, toolToProvider map[string]string, verb func(string) string) {
+	allTools := mcpServer.ListTools()
+	wrapped := make([]server.ServerTool, 0, len(allTools))
+
+	for name, st := range allTools {
+		originalHandler := st.Handler
+		toolName := name // capture for closure
+		provider := toolToProvider[toolName]
+
+		wrapped = append(wrapped, server.ServerTool{
+			Tool: st.Tool,
+			Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				mutating := verb(toolName) == policy.VerbWrite
+				if !auditor.Records(mutating) {
+					return originalHandler(ctx, req)
+				}
+
+				start := time.Now()
+				result, err := originalHandler(ctx, req)
+
+				event := audit.Event{
+					Time:       start.UTC(),
+					Tool:       toolName,
+					Provider:   provider,
+					Mutating:   mutating,
+					Arguments:  req.GetArguments(),
+					Caller:     identity.Resolve(ctx, req.Header),
+					Cluster:    req.GetString(clusters.ArgumentName, ""),
+					Namespace:  req.GetString("namespace", ""),
+					Status:     audit.StatusSuccess,
+					DurationMS: time.Since(start).Milliseconds(),
+				}
+				event.TraceID, _ = telemetry.ExtractTraceInfo(ctx)
+				if event.Cluster == "" {
+					event.Cluster = clusters.GetRegistry().Default()
+				}
+				switch {
+				case err != nil:
+					event.Status, event.Error = audit.StatusError, err.Error()
+				case result != nil && result.IsError:
+					event.Status, event.Error = audit.StatusError, resultErrorSummary(result)
+				}
+				auditor.Record(ctx, event)
+
+				return result, err
+			},
+		})
+	}
+
+	mcpServer.SetTools(wrapped...)
+}
+
+// resultErrorSummary condenses an error result for the audit record. Structured errors
+// are reduced to their code and cause, anything else to its first line.
+func resultErrorSummary(result *mcp.CallToolResult) string {
+	for _, content := range result.Content {
+		text, ok := content.(mcp.TextContent)
+		if !ok {
+			continue
+		}
+
+		var code, cause string
+		for _, line := range strings.Split(text.Text, "\n") {
+			if value, found := strings.CutPrefix(line, "**Error Code**: "); found {
+				code = value
+			} else if value, found := strings.CutPrefix(line, "**Error**: "); found {
+				cause = value
+			}
+		}
+
+		summary := strings.TrimSpace(text.Text)
+		if code != "" {
+			summary = code + ": " + cause
+		} else if line, _, found := strings.Cut(summary, "\n"); found {
+			summary = line
+		}
+		if len(summary) > 256 {
+			summary = summary[:256] + "..."
+		}
+		return summary
+	}
+	return "tool returned an error"
+}
@@ -3,8 +3,10 @@ package main
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
+	toolerrors "github.com/kagent-dev/tools/internal/errors"
 	"github.com/kagent-dev/tools/internal/metrics"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -16,6 +18,9 @@ import (
 func newTestServer() *server.MCPServer {
 	metrics.KagentToolsMCPInvocationsTotal.Reset()
 	metrics.KagentToolsMCPInvocationsFailureTotal.Reset()
+	metrics.KagentToolsMCPInvocationDurationSeconds.Reset()
+	metrics.KagentToolsMCPInvocationErrorsTotal.Reset()
+	metrics.KagentToolsMCPResultBytes.Reset()
 	return server.NewMCPServer("test-server", "test")
 }
 
@@ -125,3 +130,142 @@ func TestWrapToolHandlersWithMetrics_GoErrorIncrementsFailureCounter(t *testing.
 		t.Errorf("invocations_failure_total: expected 1 for Go error, got %v", failures)
 	}
 }
+
+// TestWrapToolHandlersWithMetrics_ProviderInContext verifies that the tool's provider is
+// passed on to the handler, where the shell executor labels subprocess metrics with it.
+func TestWrapToolHandlersWithMetrics_ProviderInContext(t *testing.T) {
+	s := newTestServer()
+
+	var provider string
+	if _, err := invokeWrapped(t, s, "helm_list_releases", "helm",
+		func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			provider = metrics.ToolProviderFromContext(ctx)
+			return mcp.NewToolResultText("ok"), nil
+		},
+	); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if provider != "helm" {
+		t.Errorf("expected provider helm in the handler context, got %q", provider)
+	}
+}
+
+// TestWrapToolHandlersWithMetrics_DurationAndResultSize verifies that every call is timed
+// and that the size of its content is observed.
+//
+// To replicate manually:
+//
+//	go test -v -run TestWrapToolHandlersWithMetrics_DurationAndResultSize ./cmd/
+func TestWrapToolHandlersWithMetrics_DurationAndResultSize(t *testing.T) {
+	s := newTestServer()
+
+	if _, err := invokeWrapped(t, s, "k8s_get_resources", "k8s",
+		func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText(strings.Repeat("x", 1000)), nil
+		},
+	); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if count := promtest.CollectAndCount(metrics.KagentToolsMCPInvocationDurationSeconds, "kagent_tools_mcp_invocation_duration_seconds"); count != 1 {
+		t.Errorf("invocation_duration_seconds: expected 1 series, got %d", count)
+	}
+
+	expected := `
+# HELP kagent_tools_mcp_result_bytes Size of the content returned by MCP tool invocations
+# TYPE kagent_tools_mcp_result_bytes histogram
+kagent_tools_mcp_result_bytes_bucket{tool_name="k8s_get_resources",tool_provider="k8s",le="256"} 0
+kagent_tools_mcp_result_bytes_bucket{tool_name="k8s_get_resources",tool_provider="k8s",le="1024"} 1
+kagent_tools_mcp_result_bytes_bucket{tool_name="k8s_get_resources",tool_provider="k8s",le="4096"} 1
+kagent_tools_mcp_result_bytes_bucket{tool_name="k8s_get_resources",tool_provider="k8s",le="16384"} 1
+kagent_tools_mcp_result_bytes_bucket{tool_name="k8s_get_resources",tool_provider="k8s",le="65536"} 1
+kagent_tools_mcp_result_bytes_bucket{tool_name="k8s_get_resources",tool_provider="k8s",le="262144"} 1
+kagent_tools_mcp_result_bytes_bucket{tool_name="k8s_get_resources",tool_provider="k8s",le="1.048576e+06"} 1
+kagent_tools_mcp_result_bytes_bucket{tool_name="k8s_get_resources",tool_provider="k8s",le="4.194304e+06"} 1
+kagent_tools_mcp_result_bytes_bucket{tool_name="k8s_get_resources",tool_provider="k8s",le="1.6777216e+07"} 1
+kagent_tools_mcp_result_bytes_bucket{tool_name="k8s_get_resources",tool_provider="k8s",le="+Inf"} 1
+kagent_tools_mcp_result_bytes_sum{tool_name="k8s_get_resources",tool_provider="k8s"} 1000
+kagent_tools_mcp_result_bytes_count{tool_name="k8s_get_resources",tool_provider="k8s"} 1
+`
+	if err := promtest.CollectAndCompare(metrics.KagentToolsMCPResultBytes, strings.NewReader(expected), "kagent_tools_mcp_result_bytes"); err != nil {
+		t.Errorf("result_bytes: %v", err)
+	}
+}
+
+// TestWrapToolHandlersWithMetrics_ErrorCodeBreakdown verifies that failures are counted by
+// the error code and component of structured tool errors, and by the kind of failure
+// otherwise.
+//
+// To replicate manually:
+//
+//	go test -v -run TestWrapToolHandlersWithMetrics_ErrorCodeBreakdown ./cmd/
+func TestWrapToolHandlersWithMetrics_ErrorCodeBreakdown(t *testing.T) {
+	tests := []struct {
+		name          string
+		handler       server.ToolHandlerFunc
+		wantCode      string
+		wantComponent string
+	}{
+		{
+			name: "structured tool error",
+			handler: func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return toolerrors.NewKubernetesError("get pods", fmt.Errorf("pods is forbidden")).
+					WithErrorCode("K8S_PERMISSION_ERROR").
+					ToMCPResult(), nil
+			},
+			wantCode:      "K8S_PERMISSION_ERROR",
+			wantComponent: "Kubernetes",
+		},
+		{
+			name: "plain error result",
+			handler: func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultError("kubectl: resource not found"), nil
+			},
+			wantCode:      "unstructured",
+			wantComponent: "none",
+		},
+		{
+			name: "Go error",
+			handler: func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return nil, fmt.Errorf("connection refused")
+			},
+			wantCode:      "handler_error",
+			wantComponent: "none",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer()
+
+			_, _ = invokeWrapped(t, s, "k8s_get_resources", "k8s", tt.handler)
+
+			failures := promtest.ToFloat64(metrics.KagentToolsMCPInvocationErrorsTotal.WithLabelValues("k8s_get_resources", "k8s", tt.wantCode, tt.wantComponent))
+			if failures != 1 {
+				t.Errorf("invocation_errors_total{error_code=%q,component=%q}: expected 1, got %v", tt.wantCode, tt.wantComponent, failures)
+			}
+			if count := promtest.CollectAndCount(metrics.KagentToolsMCPInvocationErrorsTotal); count != 1 {
+				t.Errorf("invocation_errors_total: expected 1 series, got %d", count)
+			}
+		})
+	}
+}
+
+// TestWrapToolHandlersWithMetrics_SuccessRecordsNoErrorCode verifies that a successful call
+// leaves the error breakdown untouched.
+func TestWrapToolHandlersWithMetrics_SuccessRecordsNoErrorCode(t *testing.T) {
+	s := newTestServer()
+
+	if _, err := invokeWrapped(t, s, "success_tool", "test",
+		func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("all good"), nil
+		},
+	); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if count := promtest.CollectAndCount(metrics.KagentToolsMCPInvocationErrorsTotal); count != 0 {
+		t.Errorf("invocation_errors_total: expected no series for a successful call, got %d", count)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kagent-dev/tools/internal/policy"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func newTestPolicyEngine(t *testing.T, content string) *policy.Engine {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write policy: %v", err)
+	}
+	engine, err := policy.NewEngine(file)
+	if err != nil {
+		t.Fatalf("failed to load policy: %v", err)
+	}
+	return engine
+}
+
+// TestWrapToolHandlersWithPolicy verifies that denied calls return a structured policy
+// error without reaching the tool, and that allowed calls run unchanged.
+func TestWrapToolHandlersWithPolicy(t *testing.T) {
+	engine := newTestPolicyEngine(t, `
+rules:
+- name: no-secrets
+  effect: deny
+  tools: [k8s_get_resources]
+  resourceTypes: [secrets]
+  reason: Agents may not read secrets
+limits:
+- name: replica-cap
+  tools: [k8s_scale]
+  argument: replicas
+  max: 5
+`)
+	s := server.NewMCPServer("test-server", "test")
+
+	calls := 0
+	for _, name := range []string{"k8s_get_resources", "k8s_scale"} {
+		s.AddTool(mcp.NewTool(name), func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			calls++
+			return mcp.NewToolResultText("ok"), nil
+		})
+	}
+	wrapToolHandlersWithPolicy(s, engine)
+	tools := s.ListTools()
+
+	call := func(tool string, args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = args
+		result, err := tools[tool].Handler(context.Background(), req)
+		if err != nil {
+			t.Fatalf("unexpected Go error: %v", err)
+		}
+		return result
+	}
+
+	if result := call("k8s_get_resources", map[string]any{"resource_type": "pods"}); result.IsError {
+		t.Fatalf("expected pods to be allowed, got %v", result)
+	}
+
+	result := call("k8s_get_resources", map[string]any{"resource_type": "secrets"})
+	if !result.IsError {
+		t.Fatal("expected secrets to be denied")
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{"POLICY_DENIED", "Agents may not read secrets", "no-secrets"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in denial, got %q", want, text)
+		}
+	}
+
+	result = call("k8s_scale", map[string]any{"replicas": float64(20)})
+	if !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "POLICY_LIMIT_EXCEEDED") {
+		t.Errorf("expected replica limit to be enforced, got %v", result)
+	}
+
+	if calls != 1 {
+		t.Errorf("expected only the allowed call to run, got %d calls", calls)
+	}
+}
+
+// TestPolicyToolFilter verifies that tools denied outright are left out of tools/list
+func TestPolicyToolFilter(t *testing.T) {
+	engine := newTestPolicyEngine(t, `
+rules:
+- effect: deny
+  tools: [shell]
+- effect: deny
+  tools: [k8s_get_resources]
+  resourceTypes: [secrets]
+`)
+	s := server.NewMCPServer("test-server", "test", server.WithToolFilter(policyToolFilter(engine)))
+	for _, name := range []string{"shell", "k8s_get_resources", "datetime_get_current_time"} {
+		s.AddTool(mcp.NewTool(name), func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("ok"), nil
+		})
+	}
+
+	response := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	data, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("failed to marshal response: %v", err)
+	}
+	var listed struct {
+		Result mcp.ListToolsResult `json:"result"`
+	}
+	if err := json.Unmarshal(data, &listed); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	names := map[string]bool{}
+	for _, tool := range listed.Result.Tools {
+		names[tool.Name] = true
+	}
+	if names["shell"] {
+		t.Error("expected shell to be hidden")
+	}
+	if !names["k8s_get_resources"] || !names["datetime_get_current_time"] {
+		t.Errorf("expected conditionally allowed tools to be listed, got %v", names)
+	}
+}
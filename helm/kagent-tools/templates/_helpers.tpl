@@ -99,3 +99,10 @@ Guards on the rbac block
 {{- end -}}
 {{- end -}}
 {{- end -}}
+
+{{/*
+Name of the Secret the chart renders the credentials of tools.* into
+*/}}
+{{- define "kagent-tools.credentialsSecretName" -}}
+{{ include "kagent-tools.fullname" . }}-credentials
+{{- end }}
@@ -0,0 +1,44 @@
+// Package admin holds what the admin endpoints of the server share: the check of the admin
+// token and their JSON responses.
+package admin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/kagent-dev/tools/internal/logger"
+)
+
+// RequireToken serves next only to calls carrying token as a bearer token. Without a token
+// every call is refused, as the admin endpoints change state shared by every caller.
+func RequireToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token == "" {
+			WriteError(w, http.StatusForbidden, errors.New("admin endpoints are disabled without an admin token"))
+			return
+		}
+		provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			WriteError(w, http.StatusUnauthorized, errors.New("missing or invalid admin token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// WriteJSON writes v as the JSON response of an admin endpoint
+func WriteJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Get().Error("Failed to write admin response", "error", err)
+	}
+}
+
+// WriteError writes err as the {"error": ...} response of an admin endpoint
+func WriteError(w http.ResponseWriter, status int, err error) {
+	WriteJSON(w, status, map[string]string{"error": err.Error()})
+}
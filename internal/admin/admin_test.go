@@ -0,0 +1,47 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireToken(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+
+	tests := []struct {
+		name   string
+		token  string
+		header string
+		want   int
+	}{
+		{name: "valid token", token: "s3cret", header: "Bearer s3cret", want: http.StatusOK},
+		{name: "missing token", token: "s3cret", want: http.StatusUnauthorized},
+		{name: "wrong token", token: "s3cret", header: "Bearer wrong", want: http.StatusUnauthorized},
+		{name: "not a bearer token", token: "s3cret", header: "s3cret", want: http.StatusUnauthorized},
+		{name: "no admin token", header: "Bearer ", want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/admin/cache", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			RequireToken(tt.token, ok).ServeHTTP(rec, req)
+			assert.Equal(t, tt.want, rec.Code)
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+			if tt.want != http.StatusOK {
+				var body map[string]string
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+				assert.NotEmpty(t, body["error"])
+			}
+		})
+	}
+}
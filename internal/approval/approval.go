@@ -0,0 +1,346 @@
+package approval
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kagent-dev/tools/internal/audit"
+	"github.com/kagent-dev/tools/internal/identity"
+	"github.com/kagent-dev/tools/internal/logger"
+)
+
+// Status is the state of an approval request
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusApproved Status = "approved"
+	StatusDenied   Status = "denied"
+	StatusExpired  Status = "expired"
+)
+
+// DefaultTimeout is how long a parked call waits for a decision before it expires
+const DefaultTimeout = 10 * time.Minute
+
+// maxHistory bounds the number of decided requests kept in memory
+const maxHistory = 500
+
+// DefaultTools are the tools that require approval unless --approval-tools overrides them.
+// Entries are path.Match patterns.
+var DefaultTools = []string{
+	"k8s_delete_resource",
+	"helm_uninstall",
+	"cilium_uninstall_cilium",
+	"cilium_delete_*",
+	"cilium_flush_ipsec_state",
+	"istio_delete_waypoint",
+}
+
+var (
+	// ErrNotFound is returned when no pending request has the given ID
+	ErrNotFound = errors.New("approval request not found")
+	// ErrAlreadyDecided is returned when a request has already been approved, denied or expired
+	ErrAlreadyDecided = errors.New("approval request already decided")
+)
+
+// Request is a parked tool call together with the decision taken on it
+type Request struct {
+	ID        string         `json:"id"`
+	Tool      string         `json:"tool"`
+	Arguments map[string]any `json:"arguments,omitempty"`
+	Caller    string         `json:"caller"`
+	Status    Status         `json:"status"`
+	CreatedAt time.Time      `json:"created_at"`
+	DecidedAt *time.Time     `json:"decided_at,omitempty"`
+	DecidedBy string         `json:"decided_by,omitempty"`
+	Reason    string         `json:"reason,omitempty"`
+}
+
+type pendingRequest struct {
+	request Request
+	decided chan struct{}
+}
+
+// Gate parks tool calls that match its patterns until they are approved, denied or expire
+type Gate struct {
+	patterns []string
+	timeout  time.Duration
+
+	mu      sync.Mutex
+	pending map[string]*pendingRequest
+	history []Request
+}
+
+// NewGate creates a gate for the given tool patterns. A zero timeout uses DefaultTimeout.
+func NewGate(patterns []string, timeout time.Duration) (*Gate, error) {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid approval tool pattern %q: %w", pattern, err)
+		}
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Gate{
+		patterns: patterns,
+		timeout:  timeout,
+		pending:  make(map[string]*pendingRequest),
+	}, nil
+}
+
+// Requires reports whether calls to the tool need approval
+func (g *Gate) Requires(tool string) bool {
+	for _, pattern := range g.patterns {
+		if ok, _ := path.Match(pattern, tool); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Submit parks a call and returns the pending request. Its arguments are redacted as in the
+// audit log, since they are listed by the admin endpoints and shown in elicitation prompts.
+func (g *Gate) Submit(tool string, arguments map[string]any, caller string) Request {
+	req := Request{
+		ID:        newID(),
+		Tool:      tool,
+		Arguments: audit.RedactArguments(arguments),
+		Caller:    caller,
+		Status:    StatusPending,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	g.mu.Lock()
+	g.pending[req.ID] = &pendingRequest{request: req, decided: make(chan struct{})}
+	g.mu.Unlock()
+
+	logger.Get().Info("Tool call awaiting approval", "id", req.ID, "tool", tool, "caller", caller)
+	return req
+}
+
+// Decide approves or denies a pending request. The first decision wins.
+func (g *Gate) Decide(id string, approve bool, decider, reason string) (Request, error) {
+	status := StatusDenied
+	if approve {
+		status = StatusApproved
+	}
+	return g.decide(id, status, decider, reason)
+}
+
+func (g *Gate) decide(id string, status Status, decider, reason string) (Request, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	p, ok := g.pending[id]
+	if !ok {
+		for _, req := range g.history {
+			if req.ID == id {
+				return req, ErrAlreadyDecided
+			}
+		}
+		return Request{}, ErrNotFound
+	}
+
+	now := time.Now().UTC()
+	p.request.Status = status
+	p.request.DecidedAt = &now
+	p.request.DecidedBy = decider
+	p.request.Reason = reason
+
+	delete(g.pending, id)
+	g.history = append(g.history, p.request)
+	if len(g.history) > maxHistory {
+		g.history = g.history[len(g.history)-maxHistory:]
+	}
+	close(p.decided)
+
+	logger.Get().Info("Tool call approval decided",
+		"id", id,
+		"tool", p.request.Tool,
+		"caller", p.request.Caller,
+		"status", status,
+		"decided_by", decider,
+		"reason", reason,
+	)
+	return p.request, nil
+}
+
+// Wait blocks until the request is decided, the gate timeout elapses or ctx is done.
+// Requests that time out or whose caller goes away are recorded as expired.
+func (g *Gate) Wait(ctx context.Context, id string) (Request, error) {
+	g.mu.Lock()
+	p, ok := g.pending[id]
+	g.mu.Unlock()
+	if !ok {
+		return g.Get(id)
+	}
+
+	timer := time.NewTimer(g.timeout)
+	defer timer.Stop()
+
+	select {
+	case <-p.decided:
+	case <-timer.C:
+		_, _ = g.decide(id, StatusExpired, "", fmt.Sprintf("no decision within %s", g.timeout))
+	case <-ctx.Done():
+		_, _ = g.decide(id, StatusExpired, "", "caller went away before a decision")
+	}
+	return g.Get(id)
+}
+
+// Get returns a pending or decided request
+func (g *Gate) Get(id string) (Request, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if p, ok := g.pending[id]; ok {
+		return p.request, nil
+	}
+	for i := len(g.history) - 1; i >= 0; i-- {
+		if g.history[i].ID == id {
+			return g.history[i], nil
+		}
+	}
+	return Request{}, ErrNotFound
+}
+
+// Pending returns the requests waiting for a decision, oldest first
+func (g *Gate) Pending() []Request {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	requests := make([]Request, 0, len(g.pending))
+	for _, p := range g.pending {
+		requests = append(requests, p.request)
+	}
+	sort.Slice(requests, func(i, j int) bool { return requests[i].CreatedAt.Before(requests[j].CreatedAt) })
+	return requests
+}
+
+// History returns the most recent decided requests, oldest first
+func (g *Gate) History() []Request {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]Request(nil), g.history...)
+}
+
+// Await parks a call and blocks until it is decided. When the client declared the
+// elicitation capability the user is asked directly; an admin decision made in the
+// meantime still wins, since the first decision is the one recorded.
+func (g *Gate) Await(ctx context.Context, tool string, request mcp.CallToolRequest, caller string) Request {
+	req := g.Submit(tool, request.GetArguments(), caller)
+	notifyPending(ctx, request, req)
+
+	elicitCtx, cancelElicit := context.WithCancel(ctx)
+	defer cancelElicit()
+	if supportsElicitation(ctx) {
+		go g.elicit(elicitCtx, req)
+	}
+
+	decided, err := g.Wait(ctx, req.ID)
+	if err != nil {
+		// Only possible if the request was evicted from the history before we read it back
+		req.Status = StatusExpired
+		return req
+	}
+	return decided
+}
+
+func (g *Gate) elicit(ctx context.Context, req Request) {
+	srv := server.ServerFromContext(ctx)
+	if srv == nil {
+		return
+	}
+
+	result, err := srv.RequestElicitation(ctx, mcp.ElicitationRequest{
+		Params: mcp.ElicitationParams{
+			Message: fmt.Sprintf("Allow %s to run %s with arguments %v? (approval %s)", req.Caller, req.Tool, req.Arguments, req.ID),
+			RequestedSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"approve": map[string]any{
+						"type":        "boolean",
+						"description": "Approve this call",
+					},
+					"reason": map[string]any{
+						"type":        "string",
+						"description": "Optional reason for the decision",
+					},
+				},
+				"required": []string{"approve"},
+			},
+		},
+	})
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Get().Warn("Approval elicitation failed, waiting for an admin decision", "id", req.ID, "error", err)
+		}
+		return
+	}
+
+	decider := req.Caller + " (elicitation)"
+	switch result.Action {
+	case mcp.ElicitationResponseActionAccept:
+		content, _ := result.Content.(map[string]any)
+		approve, _ := content["approve"].(bool)
+		reason, _ := content["reason"].(string)
+		_, _ = g.Decide(req.ID, approve, decider, reason)
+	case mcp.ElicitationResponseActionDecline:
+		_, _ = g.Decide(req.ID, false, decider, "declined")
+	default:
+		// A cancelled prompt leaves the request pending for an admin to decide
+		logger.Get().Info("Approval elicitation cancelled, waiting for an admin decision", "id", req.ID)
+	}
+}
+
+func supportsElicitation(ctx context.Context) bool {
+	session, ok := server.ClientSessionFromContext(ctx).(server.SessionWithClientInfo)
+	if !ok {
+		return false
+	}
+	if _, ok := session.(server.SessionWithElicitation); !ok {
+		return false
+	}
+	return session.GetClientCapabilities().Elicitation != nil
+}
+
+// notifyPending tells a client that asked for progress which approval ID its call is parked under
+func notifyPending(ctx context.Context, request mcp.CallToolRequest, req Request) {
+	if request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil {
+		return
+	}
+	srv := server.ServerFromContext(ctx)
+	if srv == nil {
+		return
+	}
+	err := srv.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+		"progressToken": request.Params.Meta.ProgressToken,
+		"progress":      0,
+		"message":       fmt.Sprintf("Waiting for approval %s of %s", req.ID, req.Tool),
+	})
+	if err != nil {
+		logger.Get().Debug("Failed to send approval progress notification", "id", req.ID, "error", err)
+	}
+}
+
+// Caller identifies who made a tool call for the approval record
+func Caller(ctx context.Context, header http.Header) string {
+	return identity.Resolve(ctx, header).String()
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,196 @@
+package approval
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestGate(t *testing.T, timeout time.Duration) *Gate {
+	t.Helper()
+	gate, err := NewGate(DefaultTools, timeout)
+	require.NoError(t, err)
+	return gate
+}
+
+func TestRequires(t *testing.T) {
+	gate := newTestGate(t, 0)
+
+	assert.True(t, gate.Requires("k8s_delete_resource"))
+	assert.True(t, gate.Requires("helm_uninstall"))
+	assert.True(t, gate.Requires("cilium_delete_service"))
+	assert.True(t, gate.Requires("istio_delete_waypoint"))
+	assert.False(t, gate.Requires("k8s_get_resources"))
+	assert.False(t, gate.Requires("helm_upgrade"))
+
+	_, err := NewGate([]string{"k8s_[delete"}, 0)
+	assert.Error(t, err)
+}
+
+func TestDecide(t *testing.T) {
+	gate := newTestGate(t, time.Second)
+
+	req := gate.Submit("helm_uninstall", map[string]any{"name": "web"}, "alice")
+	assert.Equal(t, StatusPending, req.Status)
+	require.Len(t, gate.Pending(), 1)
+
+	done := make(chan Request)
+	go func() {
+		decided, err := gate.Wait(context.Background(), req.ID)
+		assert.NoError(t, err)
+		done <- decided
+	}()
+
+	approved, err := gate.Decide(req.ID, true, "bob", "planned removal")
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, approved.Status)
+
+	decided := <-done
+	assert.Equal(t, StatusApproved, decided.Status)
+	assert.Equal(t, "alice", decided.Caller)
+	assert.Equal(t, "bob", decided.DecidedBy)
+	assert.Equal(t, "planned removal", decided.Reason)
+	assert.NotNil(t, decided.DecidedAt)
+
+	assert.Empty(t, gate.Pending())
+	require.Len(t, gate.History(), 1)
+
+	_, err = gate.Decide(req.ID, false, "carol", "")
+	assert.ErrorIs(t, err, ErrAlreadyDecided)
+	_, err = gate.Decide("missing", false, "carol", "")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestSubmitRedactsArguments(t *testing.T) {
+	gate := newTestGate(t, time.Second)
+
+	req := gate.Submit("k8s_delete_resource", map[string]any{"resource_type": "secret", "token": "hunter2", "resource_name": "db"}, "alice")
+	assert.Equal(t, "<REDACTED>", req.Arguments["token"])
+	assert.Equal(t, "db", req.Arguments["resource_name"])
+
+	pending := gate.Pending()
+	require.Len(t, pending, 1)
+	assert.Equal(t, "<REDACTED>", pending[0].Arguments["token"])
+}
+
+func TestWaitExpires(t *testing.T) {
+	gate := newTestGate(t, 20*time.Millisecond)
+
+	req := gate.Submit("k8s_delete_resource", nil, "alice")
+	decided, err := gate.Wait(context.Background(), req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusExpired, decided.Status)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	gate = newTestGate(t, time.Minute)
+	req = gate.Submit("k8s_delete_resource", nil, "alice")
+	decided, err = gate.Wait(ctx, req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusExpired, decided.Status)
+}
+
+func TestHistoryIsBounded(t *testing.T) {
+	gate := newTestGate(t, time.Second)
+	for i := 0; i < maxHistory+10; i++ {
+		req := gate.Submit("helm_uninstall", nil, "alice")
+		_, err := gate.Decide(req.ID, false, "bob", "")
+		require.NoError(t, err)
+	}
+	assert.Len(t, gate.History(), maxHistory)
+}
+
+func TestCaller(t *testing.T) {
+	header := http.Header{}
+	assert.Equal(t, "anonymous", Caller(context.Background(), header))
+
+	header.Set("X-Forwarded-User", "alice")
+	assert.Equal(t, "alice", Caller(context.Background(), header))
+}
+
+// elicitingSession is a client session that declares the elicitation capability and
+// answers every prompt with the configured response
+type elicitingSession struct {
+	notifications chan mcp.JSONRPCNotification
+	response      mcp.ElicitationResponse
+	prompts       chan mcp.ElicitationRequest
+}
+
+func (s *elicitingSession) Initialize()       {}
+func (s *elicitingSession) Initialized() bool { return true }
+func (s *elicitingSession) SessionID() string { return "elicit-session" }
+func (s *elicitingSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+func (s *elicitingSession) GetClientInfo() mcp.Implementation            { return mcp.Implementation{} }
+func (s *elicitingSession) SetClientInfo(mcp.Implementation)             {}
+func (s *elicitingSession) SetClientCapabilities(mcp.ClientCapabilities) {}
+func (s *elicitingSession) GetClientCapabilities() mcp.ClientCapabilities {
+	return mcp.ClientCapabilities{Elicitation: &struct{}{}}
+}
+func (s *elicitingSession) RequestElicitation(_ context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+	s.prompts <- request
+	return &mcp.ElicitationResult{ElicitationResponse: s.response}, nil
+}
+
+func TestAwaitElicitation(t *testing.T) {
+	tests := []struct {
+		name     string
+		response mcp.ElicitationResponse
+		want     Status
+	}{
+		{
+			name:     "approved",
+			response: mcp.ElicitationResponse{Action: mcp.ElicitationResponseActionAccept, Content: map[string]any{"approve": true}},
+			want:     StatusApproved,
+		},
+		{
+			name:     "rejected",
+			response: mcp.ElicitationResponse{Action: mcp.ElicitationResponseActionAccept, Content: map[string]any{"approve": false, "reason": "wrong cluster"}},
+			want:     StatusDenied,
+		},
+		{
+			name:     "declined",
+			response: mcp.ElicitationResponse{Action: mcp.ElicitationResponseActionDecline},
+			want:     StatusDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gate := newTestGate(t, 5*time.Second)
+
+			var decided Request
+			s := server.NewMCPServer("test", "v0.0.1", server.WithElicitation())
+			s.AddTool(mcp.NewTool("helm_uninstall"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				decided = gate.Await(ctx, "helm_uninstall", request, Caller(ctx, request.Header))
+				return mcp.NewToolResultText(string(decided.Status)), nil
+			})
+
+			session := &elicitingSession{
+				notifications: make(chan mcp.JSONRPCNotification, 10),
+				response:      tt.response,
+				prompts:       make(chan mcp.ElicitationRequest, 1),
+			}
+			require.NoError(t, s.RegisterSession(context.Background(), session))
+			ctx := s.WithContext(context.Background(), session)
+
+			message := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"helm_uninstall","arguments":{"name":"web","password":"hunter2"}}}`
+			response := s.HandleMessage(ctx, []byte(message))
+			require.IsType(t, mcp.JSONRPCResponse{}, response)
+
+			prompt := <-session.prompts
+			assert.Contains(t, prompt.Params.Message, "helm_uninstall")
+			assert.NotContains(t, prompt.Params.Message, "hunter2")
+			assert.Equal(t, tt.want, decided.Status)
+			assert.Equal(t, "session:elicit-session", decided.Caller)
+			assert.Equal(t, "session:elicit-session (elicitation)", decided.DecidedBy)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package approval
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/kagent-dev/tools/internal/admin"
+)
+
+// adminPrincipal records decisions made with the admin token, which does not name anyone
+const adminPrincipal = "admin"
+
+// decisionBody is the optional JSON body of an approve or deny call
+type decisionBody struct {
+	Reason string `json:"reason"`
+}
+
+// Handler serves the admin endpoints for a gate:
+//
+//	GET  /admin/approvals              pending requests and recent decisions
+//	GET  /admin/approvals/{id}         a single request
+//	POST /admin/approvals/{id}/approve approve a pending request
+//	POST /admin/approvals/{id}/deny    deny a pending request
+//
+// The handler does not authenticate calls; it is served behind admin.RequireToken, as anyone
+// able to reach the endpoints could otherwise approve their own calls. The admin token does
+// not name anyone, so decisions are recorded as made by the admin.
+func Handler(gate *Gate) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /admin/approvals", func(w http.ResponseWriter, r *http.Request) {
+		admin.WriteJSON(w, http.StatusOK, map[string][]Request{
+			"pending": gate.Pending(),
+			"history": gate.History(),
+		})
+	})
+
+	mux.HandleFunc("GET /admin/approvals/{id}", func(w http.ResponseWriter, r *http.Request) {
+		req, err := gate.Get(r.PathValue("id"))
+		if err != nil {
+			admin.WriteError(w, http.StatusNotFound, err)
+			return
+		}
+		admin.WriteJSON(w, http.StatusOK, req)
+	})
+
+	decide := func(approve bool) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			var body decisionBody
+			if err := json.NewDecoder(io.LimitReader(r.Body, 64*1024)).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+				admin.WriteError(w, http.StatusBadRequest, err)
+				return
+			}
+
+			req, err := gate.Decide(r.PathValue("id"), approve, adminPrincipal, body.Reason)
+			switch {
+			case errors.Is(err, ErrNotFound):
+				admin.WriteError(w, http.StatusNotFound, err)
+			case errors.Is(err, ErrAlreadyDecided):
+				admin.WriteJSON(w, http.StatusConflict, map[string]any{"error": err.Error(), "request": req})
+			default:
+				admin.WriteJSON(w, http.StatusOK, req)
+			}
+		}
+	}
+	mux.HandleFunc("POST /admin/approvals/{id}/approve", decide(true))
+	mux.HandleFunc("POST /admin/approvals/{id}/deny", decide(false))
+
+	return mux
+}
@@ -0,0 +1,63 @@
+package approval
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	gate := newTestGate(t, time.Minute)
+	handler := Handler(gate)
+
+	first := gate.Submit("k8s_delete_resource", map[string]any{"name": "web"}, "alice")
+	second := gate.Submit("helm_uninstall", nil, "alice")
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/approvals", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var listed map[string][]Request
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &listed))
+	assert.Len(t, listed["pending"], 2)
+	assert.Empty(t, listed["history"])
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/approvals/"+first.ID+"/approve", strings.NewReader(`{"decided_by":"bob","reason":"ticket 42"}`)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var decided Request
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &decided))
+	assert.Equal(t, StatusApproved, decided.Status)
+	assert.Equal(t, "admin", decided.DecidedBy, "the decider is not taken from the body")
+	assert.Equal(t, "ticket 42", decided.Reason)
+
+	// An empty body is accepted, and the decider is not taken from a header
+	req := httptest.NewRequest(http.MethodPost, "/admin/approvals/"+second.ID+"/deny", nil)
+	req.Header.Set("X-Remote-User", "mallory")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &decided))
+	assert.Equal(t, StatusDenied, decided.Status)
+	assert.Equal(t, "admin", decided.DecidedBy)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/approvals/"+second.ID+"/approve", nil))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/approvals/missing", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/approvals/"+first.ID, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &decided))
+	assert.Equal(t, first.ID, decided.ID)
+	assert.Equal(t, StatusApproved, decided.Status)
+}
@@ -0,0 +1,175 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/kagent-dev/tools/internal/identity"
+	"github.com/kagent-dev/tools/internal/logger"
+	"github.com/kagent-dev/tools/internal/policy"
+)
+
+// Result statuses recorded on events
+const (
+	StatusSuccess = "success"
+	StatusError   = "error"
+)
+
+// redacted replaces sensitive argument values, matching what logger.RedactArgsForLog uses
+const redacted = "<REDACTED>"
+
+// maxArgumentLength truncates long argument values such as manifests and scripts
+const maxArgumentLength = 2048
+
+// sensitiveNames are the parts of argument names, and of the keys of key=value pairs such as
+// helm --set values, whose values are never recorded
+const sensitiveNames = `password|passwd|secret|token|api[_-]?key|credential|authorization|private[_-]?key`
+
+// sensitiveKey matches argument names whose values are never recorded
+var sensitiveKey = regexp.MustCompile(`(?i)(` + sensitiveNames + `)`)
+
+// sensitivePair matches key=value pairs with a sensitive key, such as db.password=hunter2
+var sensitivePair = regexp.MustCompile(`(?i)([\w.\-\[\]]*(?:` + sensitiveNames + `)[\w.\-\[\]]*=)([^,\s]+)`)
+
+// secretKind matches a Secret kind anywhere in text that does not decode as a manifest
+var secretKind = regexp.MustCompile(`\bkind['"]?\s*:\s*['"]?Secret\b`)
+
+// Event is one audited tool invocation
+type Event struct {
+	Time       time.Time         `json:"time"`
+	Tool       string            `json:"tool"`
+	Provider   string            `json:"provider,omitempty"`
+	Mutating   bool              `json:"mutating"`
+	Arguments  map[string]any    `json:"arguments,omitempty"`
+	Caller     identity.Identity `json:"caller"`
+	Cluster    string            `json:"cluster,omitempty"`
+	Namespace  string            `json:"namespace,omitempty"`
+	Status     string            `json:"status"`
+	Error      string            `json:"error,omitempty"`
+	DurationMS int64             `json:"duration_ms"`
+	TraceID    string            `json:"trace_id,omitempty"`
+}
+
+// Sink stores audit events
+type Sink interface {
+	Name() string
+	Write(ctx context.Context, event Event) error
+	Close() error
+}
+
+// Auditor fans events out to its sinks
+type Auditor struct {
+	sinks []Sink
+	reads bool
+}
+
+// NewAuditor creates an auditor. Mutating calls are always recorded; read-only calls only
+// when reads is true.
+func NewAuditor(reads bool, sinks ...Sink) *Auditor {
+	return &Auditor{sinks: sinks, reads: reads}
+}
+
+// Records reports whether an invocation of a tool with the given mutability is recorded
+func (a *Auditor) Records(mutating bool) bool {
+	return mutating || a.reads
+}
+
+// Record writes an event to every sink. Sink failures are logged at error level and do not
+// fail the tool call.
+func (a *Auditor) Record(ctx context.Context, event Event) {
+	if !a.Records(event.Mutating) {
+		return
+	}
+	event.Arguments = RedactArguments(event.Arguments)
+
+	for _, sink := range a.sinks {
+		if err := sink.Write(ctx, event); err != nil {
+			logger.Get().Error("Failed to write audit event", "tool", event.Tool, "sink", sink.Name(), "error", err)
+		}
+	}
+}
+
+// Close closes every sink
+func (a *Auditor) Close() error {
+	var errs []error
+	for _, sink := range a.sinks {
+		errs = append(errs, sink.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// RedactArguments returns a copy of args without sensitive values: arguments with sensitive
+// names, key=value pairs with sensitive keys, manifests containing a Secret, patches of
+// Secrets and values too long to be useful in an audit trail
+func RedactArguments(args map[string]any) map[string]any {
+	if args == nil {
+		return nil
+	}
+
+	resourceType, _ := args["resource_type"].(string)
+	out := make(map[string]any, len(args))
+	for key, value := range args {
+		if key == "patch" && secretType(resourceType) {
+			out[key] = redacted + " (patch of a Secret)"
+			continue
+		}
+		out[key] = redactValue(key, value)
+	}
+	return out
+}
+
+func redactValue(key string, value any) any {
+	if sensitiveKey.MatchString(key) {
+		return redacted
+	}
+
+	switch v := value.(type) {
+	case string:
+		if containsSecret(v) {
+			return redacted + " (manifest contains a Secret)"
+		}
+		v = sensitivePair.ReplaceAllString(v, "${1}"+redacted)
+		if len(v) > maxArgumentLength {
+			return v[:maxArgumentLength] + "...(truncated)"
+		}
+		return v
+	case map[string]any:
+		return RedactArguments(v)
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = redactValue(key, item)
+		}
+		return items
+	}
+	return value
+}
+
+// containsSecret reports whether a manifest holds a Secret. Manifests are decoded as for
+// policy checks; a Secret kind anywhere in the text counts too, in case a document before it
+// does not decode.
+func containsSecret(value string) bool {
+	if !strings.Contains(value, "Secret") {
+		return false
+	}
+	for _, target := range policy.ManifestTargets(value) {
+		if policy.NormalizeResourceType(target.ResourceType) == "secret" {
+			return true
+		}
+	}
+	return secretKind.MatchString(value)
+}
+
+// secretType reports whether a resource type argument, possibly naming several types
+// separated by commas, names Secrets
+func secretType(resourceType string) bool {
+	for _, name := range strings.Split(resourceType, ",") {
+		if policy.NormalizeResourceType(name) == "secret" {
+			return true
+		}
+	}
+	return false
+}
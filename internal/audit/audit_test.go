@@ -0,0 +1,171 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactArguments(t *testing.T) {
+	args := map[string]any{
+		"namespace": "default",
+		"token":     "abc",
+		"api_key":   "xyz",
+		"manifest":  "apiVersion: v1\nkind: Secret\nmetadata:\n  name: db\ndata:\n  password: cGFzcw==\n",
+		"values":    map[string]any{"adminPassword": "hunter2", "replicas": float64(2)},
+		"script":    strings.Repeat("x", maxArgumentLength+10),
+	}
+
+	redactedArgs := RedactArguments(args)
+	assert.Equal(t, "default", redactedArgs["namespace"])
+	assert.Equal(t, redacted, redactedArgs["token"])
+	assert.Equal(t, redacted, redactedArgs["api_key"])
+	assert.Contains(t, redactedArgs["manifest"], redacted)
+	assert.Equal(t, map[string]any{"adminPassword": redacted, "replicas": float64(2)}, redactedArgs["values"])
+	assert.True(t, strings.HasSuffix(redactedArgs["script"].(string), "...(truncated)"))
+
+	// The original arguments are left alone
+	assert.Equal(t, "abc", args["token"])
+	assert.Nil(t, RedactArguments(nil))
+}
+
+func TestRedactSecretManifests(t *testing.T) {
+	for name, manifest := range map[string]string{
+		"single-line JSON":     `{"apiVersion":"v1","kind":"Secret","metadata":{"name":"db"},"data":{"password":"cGFzcw=="}}`,
+		"comment after kind":   "apiVersion: v1\nkind: Secret # database credentials\nmetadata:\n  name: db\n",
+		"quoted kind":          "apiVersion: v1\nkind: 'Secret'\nmetadata:\n  name: db\n",
+		"second document":      "kind: ConfigMap\nmetadata:\n  name: a\n---\n  kind:   Secret\n  metadata:\n    name: b\n",
+		"list item":            `{"kind":"List","items":[{"kind":"Secret","metadata":{"name":"db"}}]}`,
+		"after a bad document": "kind: [ConfigMap\n---\nkind: Secret\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			out := RedactArguments(map[string]any{"manifest": manifest})
+			assert.Equal(t, redacted+" (manifest contains a Secret)", out["manifest"])
+		})
+	}
+
+	configMap := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: SecretStore\n"
+	assert.Equal(t, configMap, RedactArguments(map[string]any{"manifest": configMap})["manifest"])
+}
+
+func TestRedactSensitivePairs(t *testing.T) {
+	out := RedactArguments(map[string]any{
+		"set":    "image.tag=1.2,db.password=hunter2,auth.apiKey=abc",
+		"values": []any{"global.imagePullSecrets[0].token=xyz", "replicas=2"},
+	})
+	assert.Equal(t, "image.tag=1.2,db.password="+redacted+",auth.apiKey="+redacted, out["set"])
+	assert.Equal(t, []any{"global.imagePullSecrets[0].token=" + redacted, "replicas=2"}, out["values"])
+}
+
+func TestRedactSecretPatches(t *testing.T) {
+	patch := `{"data":{"password":"aHVudGVyMg=="}}`
+	for _, resourceType := range []string{"secret", "Secrets", "secrets.v1", "configmaps,secrets"} {
+		out := RedactArguments(map[string]any{"resource_type": resourceType, "resource_name": "db", "patch": patch})
+		assert.Equal(t, redacted+" (patch of a Secret)", out["patch"], resourceType)
+		assert.Equal(t, "db", out["resource_name"])
+	}
+	assert.Equal(t, patch, RedactArguments(map[string]any{"resource_type": "configmap", "patch": patch})["patch"])
+}
+
+type recordingSink struct {
+	mu     sync.Mutex
+	events []Event
+	err    error
+}
+
+func (s *recordingSink) Name() string { return "recording" }
+func (s *recordingSink) Write(_ context.Context, event Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	return s.err
+}
+func (s *recordingSink) Close() error { return nil }
+
+func TestAuditorRecord(t *testing.T) {
+	failing := &recordingSink{err: errors.New("disk full")}
+	sink := &recordingSink{}
+
+	auditor := NewAuditor(false, failing, sink)
+	assert.True(t, auditor.Records(true))
+	assert.False(t, auditor.Records(false))
+
+	auditor.Record(context.Background(), Event{Tool: "k8s_get_resources"})
+	auditor.Record(context.Background(), Event{Tool: "k8s_delete_resource", Mutating: true, Arguments: map[string]any{"password": "x"}})
+
+	// A failing sink does not keep the event from the others
+	require.Len(t, sink.events, 1)
+	assert.Equal(t, "k8s_delete_resource", sink.events[0].Tool)
+	assert.Equal(t, redacted, sink.events[0].Arguments["password"])
+
+	auditor = NewAuditor(true, sink)
+	auditor.Record(context.Background(), Event{Tool: "k8s_get_resources"})
+	assert.Len(t, sink.events, 2)
+}
+
+func TestStreamAndFileSinks(t *testing.T) {
+	var buf bytes.Buffer
+	stream := NewStreamSink("buffer", &buf)
+	require.NoError(t, stream.Write(context.Background(), Event{Tool: "helm_upgrade", Status: StatusSuccess}))
+	require.NoError(t, stream.Write(context.Background(), Event{Tool: "helm_uninstall", Status: StatusError}))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	var event Event
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &event))
+	assert.Equal(t, "helm_uninstall", event.Tool)
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := ParseSink("file:"+path, false)
+	require.NoError(t, err)
+	require.NoError(t, sink.Write(context.Background(), Event{Tool: "k8s_scale"}))
+	require.NoError(t, sink.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"tool":"k8s_scale"`)
+}
+
+func TestWebhookSink(t *testing.T) {
+	received := make(chan Event, 2)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event Event
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received <- event
+	}))
+	defer collector.Close()
+
+	sink, err := ParseSink("webhook:"+collector.URL, false)
+	require.NoError(t, err)
+	require.NoError(t, sink.Write(context.Background(), Event{Tool: "k8s_apply_manifest", Mutating: true}))
+	require.NoError(t, sink.Close())
+
+	require.Len(t, received, 1)
+	assert.Equal(t, "k8s_apply_manifest", (<-received).Tool)
+	assert.Error(t, sink.Write(context.Background(), Event{Tool: "late"}))
+}
+
+func TestParseSink(t *testing.T) {
+	sink, err := ParseSink("stdout", true)
+	require.NoError(t, err)
+	assert.Equal(t, "stderr", sink.Name(), "stdout carries the MCP protocol in stdio mode")
+
+	for _, spec := range []string{"syslog", "file:", "webhook:ftp://collector", "webhook:"} {
+		_, err := ParseSink(spec, false)
+		assert.Error(t, err, spec)
+	}
+}
@@ -0,0 +1,197 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kagent-dev/tools/internal/logger"
+)
+
+const (
+	webhookQueueSize = 1024
+	webhookTimeout   = 5 * time.Second
+)
+
+// ParseSink creates a sink from its flag value:
+//
+//	stdout               JSON lines on stdout (stderr in stdio mode, where stdout carries MCP)
+//	stderr               JSON lines on stderr
+//	file:<path>          JSON lines appended to a file
+//	webhook:<url>        each event POSTed as JSON to a collector
+func ParseSink(spec string, stdio bool) (Sink, error) {
+	kind, target, _ := strings.Cut(spec, ":")
+	switch kind {
+	case "stdout":
+		if stdio {
+			return NewStreamSink("stderr", os.Stderr), nil
+		}
+		return NewStreamSink("stdout", os.Stdout), nil
+	case "stderr":
+		return NewStreamSink("stderr", os.Stderr), nil
+	case "file":
+		if target == "" {
+			return nil, fmt.Errorf("audit sink %q is missing a file path", spec)
+		}
+		return NewFileSink(target)
+	case "webhook":
+		return NewWebhookSink(target)
+	}
+	return nil, fmt.Errorf("unknown audit sink %q, expected stdout, stderr, file:<path> or webhook:<url>", spec)
+}
+
+// StreamSink writes events as JSON lines to a writer
+type StreamSink struct {
+	name string
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewStreamSink creates a sink writing JSON lines to w
+func NewStreamSink(name string, w io.Writer) *StreamSink {
+	return &StreamSink{name: name, w: w}
+}
+
+func (s *StreamSink) Name() string { return s.name }
+
+func (s *StreamSink) Write(_ context.Context, event Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(line)
+	return err
+}
+
+func (s *StreamSink) Close() error { return nil }
+
+// FileSink appends events as JSON lines to a file
+type FileSink struct {
+	*StreamSink
+	file *os.File
+}
+
+// NewFileSink opens (or creates) the file in append mode
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit file: %w", err)
+	}
+	return &FileSink{StreamSink: NewStreamSink("file:"+path, file), file: file}, nil
+}
+
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// WebhookSink POSTs each event as JSON to a collector. Events are queued and sent in the
+// background so a slow collector does not hold up tool calls; when the queue is full the
+// event is dropped and Write reports it.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+	queue  chan Event
+	done   chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewWebhookSink creates a sink for an http or https collector URL
+func NewWebhookSink(rawURL string) (*WebhookSink, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("audit webhook needs an http or https URL, got %q", rawURL)
+	}
+
+	s := &WebhookSink{
+		url:    rawURL,
+		client: &http.Client{Timeout: webhookTimeout},
+		queue:  make(chan Event, webhookQueueSize),
+		done:   make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+func (s *WebhookSink) Name() string { return "webhook:" + s.url }
+
+func (s *WebhookSink) Write(_ context.Context, event Event) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return errors.New("webhook sink is closed")
+	}
+
+	select {
+	case s.queue <- event:
+		return nil
+	default:
+		return errors.New("webhook queue is full, event dropped")
+	}
+}
+
+// Close stops accepting events and waits for the queued ones to be sent
+func (s *WebhookSink) Close() error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+
+	<-s.done
+	return nil
+}
+
+func (s *WebhookSink) run() {
+	defer close(s.done)
+	for event := range s.queue {
+		if err := s.send(event); err != nil {
+			logger.Get().Error("Failed to send audit event", "sink", s.Name(), "tool", event.Tool, "error", err)
+		}
+	}
+}
+
+func (s *WebhookSink) send(event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("collector returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/kagent-dev/tools/internal/identity"
+	"github.com/kagent-dev/tools/internal/logger"
+)
+
+// Authentication methods recorded on principals
+const (
+	MethodAPIKey = "api-key"
+	MethodJWT    = "jwt"
+	MethodMTLS   = "mtls"
+)
+
+// ErrNoCredentials is returned for requests that carry no credentials at all
+var ErrNoCredentials = errors.New("no credentials")
+
+// Authenticator authenticates HTTP requests and authorizes tool calls by role
+type Authenticator struct {
+	config    *Config
+	jwt       *jwtVerifier
+	clientCAs *x509.CertPool
+	verb      func(string) string
+}
+
+// New creates an authenticator for a validated config. verb classifies tools as read or
+// write for roles that are limited to verbs.
+func New(config *Config, verb func(string) string) (*Authenticator, error) {
+	a := &Authenticator{config: config, verb: verb}
+
+	if config.JWT != nil {
+		verifier, err := newJWTVerifier(config.JWT)
+		if err != nil {
+			return nil, err
+		}
+		a.jwt = verifier
+	}
+
+	if config.MTLS != nil {
+		data, err := os.ReadFile(config.MTLS.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA file: %w", err)
+		}
+		a.clientCAs = x509.NewCertPool()
+		if !a.clientCAs.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in client CA file %s", config.MTLS.ClientCAFile)
+		}
+	}
+
+	return a, nil
+}
+
+// TLSConfig returns the server TLS settings that request client certificates, or nil when
+// mTLS is not configured. Clients without a certificate can still use the other methods.
+func (a *Authenticator) TLSConfig() *tls.Config {
+	if a.clientCAs == nil {
+		return nil
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientAuth: tls.VerifyClientCertIfGiven,
+		ClientCAs:  a.clientCAs,
+	}
+}
+
+// Public reports whether a path is served without authentication. Entries ending in a
+// slash match everything below them.
+func (a *Authenticator) Public(urlPath string) bool {
+	for _, public := range a.config.PublicPaths {
+		if urlPath == public || (strings.HasSuffix(public, "/") && strings.HasPrefix(urlPath, public)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticate identifies the caller of a request. Credentials that are present but
+// invalid are an error rather than a reason to try the next method.
+func (a *Authenticator) Authenticate(r *http.Request) (identity.Principal, error) {
+	if a.clientCAs != nil && r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+		return a.principal(certificateSubject(r.TLS.VerifiedChains[0][0]), MethodMTLS, nil), nil
+	}
+
+	credential, isBearer := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !isBearer {
+		credential = r.Header.Get("X-API-Key")
+	}
+	credential = strings.TrimSpace(credential)
+	if credential == "" {
+		return identity.Principal{}, ErrNoCredentials
+	}
+
+	if key := a.apiKey(credential); key != nil {
+		return a.principal(key.Subject, MethodAPIKey, key.Roles), nil
+	}
+	if isBearer && a.jwt != nil && strings.Count(credential, ".") == 2 {
+		subject, roles, err := a.jwt.verify(credential)
+		if err != nil {
+			return identity.Principal{}, err
+		}
+		return a.principal(subject, MethodJWT, roles), nil
+	}
+	return identity.Principal{}, errors.New("unknown credentials")
+}
+
+// apiKey finds the configured key matching a credential. Every key is compared, in
+// constant time over their hashes, so the timing does not reveal which key came close.
+func (a *Authenticator) apiKey(credential string) *APIKey {
+	sum := sha256.Sum256([]byte(credential))
+	var match *APIKey
+	for i := range a.config.APIKeys {
+		keySum := sha256.Sum256([]byte(a.config.APIKeys[i].Key))
+		if subtle.ConstantTimeCompare(sum[:], keySum[:]) == 1 && match == nil {
+			match = &a.config.APIKeys[i]
+		}
+	}
+	return match
+}
+
+// principal grants a subject the defined roles among those of its credential, plus its
+// bindings and the default roles
+func (a *Authenticator) principal(subject, method string, credentialRoles []string) identity.Principal {
+	var roles []string
+	for _, group := range [][]string{credentialRoles, a.config.Bindings[subject], a.config.DefaultRoles} {
+		for _, role := range group {
+			if _, ok := a.config.Roles[role]; ok && !slices.Contains(roles, role) {
+				roles = append(roles, role)
+			}
+		}
+	}
+	return identity.Principal{Subject: subject, Method: method, Roles: roles}
+}
+
+// certificateSubject names the holder of a client certificate by its first URI SAN, such
+// as a SPIFFE ID, falling back to the common name
+func certificateSubject(cert *x509.Certificate) string {
+	if len(cert.URIs) > 0 {
+		return cert.URIs[0].String()
+	}
+	return cert.Subject.CommonName
+}
+
+// Middleware rejects requests to non-public paths that do not authenticate and stores the
+// principal of the others in the request context
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.Public(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		principal, err := a.Authenticate(r)
+		if err != nil {
+			logger.Get().Info("Rejected unauthenticated request", "path", r.URL.Path, "remote_addr", r.RemoteAddr, "error", err)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="kagent-tools"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(identity.WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// Allowed reports whether any role of the principal grants the tool
+func (a *Authenticator) Allowed(principal identity.Principal, tool string) bool {
+	for _, name := range principal.Roles {
+		if a.config.Roles[name].grants(tool, a.verb) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Role) grants(tool string, verb func(string) string) bool {
+	if len(r.Tools) > 0 && !slices.ContainsFunc(r.Tools, func(pattern string) bool {
+		matched, _ := path.Match(pattern, tool)
+		return matched
+	}) {
+		return false
+	}
+	return len(r.Verbs) == 0 || slices.Contains(r.Verbs, verb(tool))
+}
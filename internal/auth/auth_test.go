@@ -0,0 +1,241 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kagent-dev/tools/internal/identity"
+	"github.com/kagent-dev/tools/internal/policy"
+)
+
+const testConfig = `
+apiKeys:
+  - name: ci
+    key: ci-secret
+    subject: ci-bot
+    roles: [viewer]
+  - keyEnv: TEST_AUTH_OPERATOR_KEY
+    subject: oncall
+roles:
+  viewer:
+    verbs: [read]
+  operator:
+    tools: ["k8s_*", "helm_*"]
+bindings:
+  oncall: [operator, viewer]
+`
+
+func newTestAuthenticator(t *testing.T, config string) *Authenticator {
+	t.Helper()
+	c, err := Parse([]byte(config))
+	require.NoError(t, err)
+	a, err := New(c, policy.DefaultVerb)
+	require.NoError(t, err)
+	return a
+}
+
+func TestParse(t *testing.T) {
+	t.Setenv("TEST_AUTH_OPERATOR_KEY", "ops-secret")
+	c, err := Parse([]byte(testConfig))
+	require.NoError(t, err)
+	assert.Equal(t, "ops-secret", c.APIKeys[1].Key)
+	assert.Equal(t, "apiKeys[1]", c.APIKeys[1].Name)
+	assert.Equal(t, DefaultPublicPaths, c.PublicPaths)
+
+	for name, config := range map[string]string{
+		"no method":       "roles: {}",
+		"unknown field":   "apiKeys: [{key: k, subject: s}]\nusers: []",
+		"missing key":     "apiKeys: [{keyEnv: TEST_AUTH_UNSET, subject: s}]",
+		"missing subject": "apiKeys: [{key: k}]",
+		"unknown role":    "apiKeys: [{key: k, subject: s, roles: [admin]}]",
+		"unknown binding": "apiKeys: [{key: k, subject: s}]\nbindings: {alice: [admin]}",
+		"bad verb":        "apiKeys: [{key: k, subject: s}]\nroles: {viewer: {verbs: [list]}}",
+		"bad pattern":     "apiKeys: [{key: k, subject: s}]\nroles: {viewer: {tools: ['[']}}",
+		"jwt without key": "jwt: {issuer: https://issuer}",
+		"mtls without ca": "mtls: {}",
+	} {
+		_, err := Parse([]byte(config))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestAPIKeyAuthentication(t *testing.T) {
+	t.Setenv("TEST_AUTH_OPERATOR_KEY", "ops-secret")
+	a := newTestAuthenticator(t, testConfig)
+
+	r := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	_, err := a.Authenticate(r)
+	assert.ErrorIs(t, err, ErrNoCredentials)
+
+	r.Header.Set("Authorization", "Bearer ci-secret")
+	principal, err := a.Authenticate(r)
+	require.NoError(t, err)
+	assert.Equal(t, identity.Principal{Subject: "ci-bot", Method: MethodAPIKey, Roles: []string{"viewer"}}, principal)
+
+	r = httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	r.Header.Set("X-API-Key", "ops-secret")
+	principal, err = a.Authenticate(r)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"operator", "viewer"}, principal.Roles)
+
+	r.Header.Set("Authorization", "Bearer wrong")
+	_, err = a.Authenticate(r)
+	assert.Error(t, err, "an invalid bearer token is not rescued by the X-API-Key header")
+}
+
+func TestAllowed(t *testing.T) {
+	t.Setenv("TEST_AUTH_OPERATOR_KEY", "ops-secret")
+	a := newTestAuthenticator(t, testConfig)
+
+	viewer := identity.Principal{Subject: "ci-bot", Roles: []string{"viewer"}}
+	assert.True(t, a.Allowed(viewer, "k8s_get_resources"))
+	assert.True(t, a.Allowed(viewer, "prometheus_query_tool"))
+	assert.False(t, a.Allowed(viewer, "k8s_delete_resource"))
+
+	operator := identity.Principal{Subject: "oncall", Roles: []string{"operator"}}
+	assert.True(t, a.Allowed(operator, "k8s_delete_resource"))
+	assert.False(t, a.Allowed(operator, "shell"))
+
+	assert.False(t, a.Allowed(identity.Principal{Subject: "nobody"}, "k8s_get_resources"))
+}
+
+func TestMiddleware(t *testing.T) {
+	a := newTestAuthenticator(t, "apiKeys: [{key: k, subject: s, roles: [all]}]\nroles: {all: {}}\npublicPaths: [/health, /static/]")
+
+	var got identity.Principal
+	handler := a.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = identity.PrincipalFromContext(r.Context())
+	}))
+
+	for path, want := range map[string]int{"/health": http.StatusOK, "/static/app.js": http.StatusOK, "/metrics": http.StatusUnauthorized, "/mcp": http.StatusUnauthorized} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, want, rec.Code, path)
+		if want == http.StatusUnauthorized {
+			assert.NotEmpty(t, rec.Header().Get("WWW-Authenticate"))
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	r.Header.Set("Authorization", "Bearer k")
+	handler.ServeHTTP(rec, r)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "s", got.Subject)
+}
+
+func TestJWTAuthentication(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	jwks, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: &key.PublicKey, KeyID: "k1", Algorithm: string(jose.ES256)}}})
+	require.NoError(t, err)
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(jwksFile, jwks, 0600))
+
+	a := newTestAuthenticator(t, `
+jwt:
+  jwksFile: `+jwksFile+`
+  issuer: https://issuer.example
+  audiences: [kagent-tools]
+  rolesClaim: groups
+roles:
+  viewer: {verbs: [read]}
+`)
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: jose.JSONWebKey{Key: key, KeyID: "k1"}}, nil)
+	require.NoError(t, err)
+	sign := func(claims jwt.Claims, groups any) string {
+		token, err := jwt.Signed(signer).Claims(claims).Claims(map[string]any{"groups": groups}).Serialize()
+		require.NoError(t, err)
+		return token
+	}
+	authenticate := func(token string) (identity.Principal, error) {
+		r := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		return a.Authenticate(r)
+	}
+
+	valid := jwt.Claims{Subject: "alice", Issuer: "https://issuer.example", Audience: jwt.Audience{"kagent-tools"}, Expiry: jwt.NewNumericDate(time.Now().Add(time.Hour))}
+	principal, err := authenticate(sign(valid, []string{"viewer", "unknown"}))
+	require.NoError(t, err)
+	assert.Equal(t, identity.Principal{Subject: "alice", Method: MethodJWT, Roles: []string{"viewer"}}, principal)
+
+	principal, err = authenticate(sign(valid, "viewer other"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"viewer"}, principal.Roles)
+
+	expired := valid
+	expired.Expiry = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+	_, err = authenticate(sign(expired, nil))
+	assert.Error(t, err)
+
+	wrongAudience := valid
+	wrongAudience.Audience = jwt.Audience{"other"}
+	_, err = authenticate(sign(wrongAudience, nil))
+	assert.Error(t, err)
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherSigner, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: otherKey}, nil)
+	require.NoError(t, err)
+	forged, err := jwt.Signed(otherSigner).Claims(valid).Serialize()
+	require.NoError(t, err)
+	_, err = authenticate(forged)
+	assert.Error(t, err)
+}
+
+func TestMTLSAuthentication(t *testing.T) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}), 0600))
+
+	a := newTestAuthenticator(t, "mtls: {clientCAFile: "+caFile+"}\nroles: {all: {}}\nbindings: {agent: [all]}")
+	require.NotNil(t, a.TLSConfig())
+	assert.Equal(t, tls.VerifyClientCertIfGiven, a.TLSConfig().ClientAuth)
+
+	spiffe, _ := url.Parse("spiffe://cluster.local/ns/kagent/sa/agent")
+	r := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: "agent"}}}}}
+	principal, err := a.Authenticate(r)
+	require.NoError(t, err)
+	assert.Equal(t, identity.Principal{Subject: "agent", Method: MethodMTLS, Roles: []string{"all"}}, principal)
+
+	r.TLS.VerifiedChains[0][0].URIs = []*url.URL{spiffe}
+	principal, err = a.Authenticate(r)
+	require.NoError(t, err)
+	assert.Equal(t, spiffe.String(), principal.Subject)
+
+	_, err = New(&Config{MTLS: &MTLSConfig{ClientCAFile: filepath.Join(t.TempDir(), "missing.pem")}}, policy.DefaultVerb)
+	assert.Error(t, err)
+}
@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/tools/internal/policy"
+)
+
+// DefaultPublicPaths are served without authentication unless the config lists its own
+var DefaultPublicPaths = []string{"/health", "/metrics"}
+
+// Config is the parsed --auth-config file.
+//
+// A request is authenticated by the first method that finds credentials on it: a verified
+// client certificate, then the bearer token (an API key, else a JWT) or the X-API-Key
+// header. The principal gets the roles of its credential, the roles bound to its subject
+// and DefaultRoles; roles decide which tools it can list and call.
+type Config struct {
+	APIKeys      []APIKey            `json:"apiKeys,omitempty"`
+	JWT          *JWTConfig          `json:"jwt,omitempty"`
+	MTLS         *MTLSConfig         `json:"mtls,omitempty"`
+	Roles        map[string]Role     `json:"roles"`
+	Bindings     map[string][]string `json:"bindings,omitempty"`
+	DefaultRoles []string            `json:"defaultRoles,omitempty"`
+	PublicPaths  []string            `json:"publicPaths,omitempty"`
+}
+
+// APIKey is a static key. The key itself is read from KeyEnv when Key is empty, so the
+// config file can be committed without it.
+type APIKey struct {
+	Name    string   `json:"name,omitempty"`
+	Key     string   `json:"key,omitempty"`
+	KeyEnv  string   `json:"keyEnv,omitempty"`
+	Subject string   `json:"subject"`
+	Roles   []string `json:"roles,omitempty"`
+}
+
+// JWTConfig validates bearer tokens against the keys of a local JWKS file
+type JWTConfig struct {
+	JWKSFile     string   `json:"jwksFile"`
+	Issuer       string   `json:"issuer,omitempty"`
+	Audiences    []string `json:"audiences,omitempty"`
+	SubjectClaim string   `json:"subjectClaim,omitempty"`
+	RolesClaim   string   `json:"rolesClaim,omitempty"`
+}
+
+// MTLSConfig authenticates clients presenting a certificate signed by ClientCAFile. The
+// subject is the first URI SAN (e.g. a SPIFFE ID), else the common name.
+type MTLSConfig struct {
+	ClientCAFile string `json:"clientCAFile"`
+}
+
+// Role grants access to the tools matching Tools (all tools when empty) and, if set, only
+// those classified as one of Verbs. Tools are path.Match patterns.
+type Role struct {
+	Tools []string `json:"tools,omitempty"`
+	Verbs []string `json:"verbs,omitempty"`
+}
+
+// Load reads and validates an auth config file
+func Load(file string) (*Config, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read auth config: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse parses and validates a YAML or JSON auth config
+func Parse(data []byte) (*Config, error) {
+	var c Config
+	if err := yaml.UnmarshalStrict(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse auth config: %w", err)
+	}
+
+	if len(c.APIKeys) == 0 && c.JWT == nil && c.MTLS == nil {
+		return nil, errors.New("auth config must enable at least one of apiKeys, jwt or mtls")
+	}
+	if c.PublicPaths == nil {
+		c.PublicPaths = DefaultPublicPaths
+	}
+
+	for name, role := range c.Roles {
+		for _, pattern := range role.Tools {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("role %s: invalid tool pattern %q: %w", name, pattern, err)
+			}
+		}
+		for _, verb := range role.Verbs {
+			if verb != policy.VerbRead && verb != policy.VerbWrite {
+				return nil, fmt.Errorf("role %s: verbs must be read or write, got %q", name, verb)
+			}
+		}
+	}
+	if err := c.checkRoles("defaultRoles", c.DefaultRoles); err != nil {
+		return nil, err
+	}
+	for subject, roles := range c.Bindings {
+		if err := c.checkRoles("binding "+subject, roles); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range c.APIKeys {
+		key := &c.APIKeys[i]
+		if key.Name == "" {
+			key.Name = fmt.Sprintf("apiKeys[%d]", i)
+		}
+		if key.Key == "" && key.KeyEnv != "" {
+			key.Key = strings.TrimSpace(os.Getenv(key.KeyEnv))
+		}
+		if key.Key == "" {
+			return nil, fmt.Errorf("api key %s has no key (set key, or keyEnv to a non-empty variable)", key.Name)
+		}
+		if key.Subject == "" {
+			return nil, fmt.Errorf("api key %s: subject is required", key.Name)
+		}
+		if err := c.checkRoles("api key "+key.Name, key.Roles); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.JWT != nil {
+		if c.JWT.JWKSFile == "" {
+			return nil, errors.New("jwt: jwksFile is required")
+		}
+		if c.JWT.SubjectClaim == "" {
+			c.JWT.SubjectClaim = "sub"
+		}
+		if c.JWT.RolesClaim == "" {
+			c.JWT.RolesClaim = "roles"
+		}
+	}
+	if c.MTLS != nil && c.MTLS.ClientCAFile == "" {
+		return nil, errors.New("mtls: clientCAFile is required")
+	}
+
+	return &c, nil
+}
+
+// checkRoles makes sure every role referenced by field is defined
+func (c *Config) checkRoles(field string, roles []string) error {
+	for _, role := range roles {
+		if _, ok := c.Roles[role]; !ok {
+			return fmt.Errorf("%s: unknown role %q", field, role)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+// jwtLeeway tolerates clock skew between the issuer and the server
+const jwtLeeway = time.Minute
+
+// signatureAlgorithms are the algorithms accepted on tokens. HMAC is left out on purpose:
+// a JWKS file holds public keys.
+var signatureAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.EdDSA,
+}
+
+// jwtVerifier validates tokens against a JWKS file. The file is read again when its
+// modification time changes, so keys can be rotated without a restart.
+type jwtVerifier struct {
+	config *JWTConfig
+
+	mu      sync.Mutex
+	keys    *jose.JSONWebKeySet
+	modTime time.Time
+}
+
+func newJWTVerifier(config *JWTConfig) (*jwtVerifier, error) {
+	v := &jwtVerifier{config: config}
+	if _, err := v.keySet(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// keySet returns the current keys, reloading the file if it changed. A file that can no
+// longer be read or parsed keeps the previous keys in use.
+func (v *jwtVerifier) keySet() (*jose.JSONWebKeySet, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	info, err := os.Stat(v.config.JWKSFile)
+	if err != nil {
+		if v.keys != nil {
+			return v.keys, nil
+		}
+		return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+	if v.keys != nil && info.ModTime().Equal(v.modTime) {
+		return v.keys, nil
+	}
+
+	data, err := os.ReadFile(v.config.JWKSFile)
+	if err == nil {
+		var keys jose.JSONWebKeySet
+		if err = json.Unmarshal(data, &keys); err == nil && len(keys.Keys) == 0 {
+			err = errors.New("no keys")
+		}
+		if err == nil {
+			v.keys, v.modTime = &keys, info.ModTime()
+			return v.keys, nil
+		}
+	}
+	if v.keys != nil {
+		return v.keys, nil
+	}
+	return nil, fmt.Errorf("failed to load JWKS file %s: %w", v.config.JWKSFile, err)
+}
+
+// verify checks the signature, expiry, issuer and audience of a token and returns its
+// subject and the roles listed in the roles claim
+func (v *jwtVerifier) verify(raw string) (string, []string, error) {
+	token, err := jwt.ParseSigned(raw, signatureAlgorithms)
+	if err != nil {
+		return "", nil, fmt.Errorf("malformed token: %w", err)
+	}
+	keys, err := v.keySet()
+	if err != nil {
+		return "", nil, err
+	}
+
+	var claims jwt.Claims
+	var all map[string]any
+	if err := token.Claims(keys, &claims, &all); err != nil {
+		return "", nil, fmt.Errorf("invalid token signature: %w", err)
+	}
+	expected := jwt.Expected{Issuer: v.config.Issuer, AnyAudience: v.config.Audiences, Time: time.Now()}
+	if err := claims.ValidateWithLeeway(expected, jwtLeeway); err != nil {
+		return "", nil, fmt.Errorf("invalid token: %w", err)
+	}
+
+	subject, _ := all[v.config.SubjectClaim].(string)
+	if subject == "" {
+		return "", nil, fmt.Errorf("token has no %s claim", v.config.SubjectClaim)
+	}
+	return subject, claimValues(all[v.config.RolesClaim]), nil
+}
+
+// claimValues reads a claim holding a list of strings or a space separated string, the
+// two shapes used for roles, groups and scopes
+func claimValues(claim any) []string {
+	switch v := claim.(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
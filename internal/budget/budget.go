@@ -0,0 +1,313 @@
+// Package budget keeps tool results within the context window of the calling model. A
+// result over the budget is cut to its head and tail around a marker, and its full text is
+// kept for a while so that the caller can read the omitted part page by page with a cursor.
+package budget
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kagent-dev/tools/internal/cache"
+	toolerrors "github.com/kagent-dev/tools/internal/errors"
+)
+
+const (
+	// CursorArgument is the optional tool argument that reads a page of a truncated result
+	CursorArgument = "cursor"
+
+	// DefaultMaxBytes is the default budget of a tool result
+	DefaultMaxBytes = 64 << 10
+
+	// MinMaxBytes is the smallest budget, which still leaves room for the markers
+	MinMaxBytes = 1024
+
+	// BytesPerToken approximates how many bytes of tool output a model token holds
+	BytesPerToken = 4
+
+	// TruncatedField and CursorField are set on structured content whose item list was cut;
+	// the cursor reads the next items. Tools with an output schema declare both.
+	TruncatedField = "truncated"
+	CursorField    = "cursor"
+)
+
+const (
+	// markerReserve is the part of the budget kept for the truncation marker and page footer
+	markerReserve = 512
+
+	// Full outputs are kept for paging for outputTTL, up to maxOutputs outputs and
+	// maxOutputBytes bytes
+	outputTTL      = 10 * time.Minute
+	maxOutputs     = 100
+	maxOutputBytes = 64 << 20
+)
+
+// Limiter cuts tool results to a byte budget and serves the pages of the results it cut
+type Limiter struct {
+	maxBytes int
+	outputs  *cache.Cache[string]
+}
+
+// NewLimiter creates a limiter for a budget of maxBytes bytes and maxTokens tokens, the
+// smaller of which applies. A limit of 0 is unset; with both unset NewLimiter returns nil.
+func NewLimiter(maxBytes, maxTokens int) (*Limiter, error) {
+	if maxBytes < 0 || maxTokens < 0 {
+		return nil, errors.New("result budget must not be negative")
+	}
+
+	budget := maxBytes
+	if maxTokens > 0 && (budget == 0 || maxTokens*BytesPerToken < budget) {
+		budget = maxTokens * BytesPerToken
+	}
+	if budget == 0 {
+		return nil, nil
+	}
+	if budget < MinMaxBytes {
+		return nil, fmt.Errorf("result budget of %d bytes is below the minimum of %d bytes", budget, MinMaxBytes)
+	}
+
+	return &Limiter{
+		maxBytes: budget,
+		outputs:  cache.NewCacheWithLimits[string]("result_pages", outputTTL, maxOutputs, maxOutputBytes, time.Minute),
+	}, nil
+}
+
+// MaxBytes returns the budget in bytes
+func (l *Limiter) MaxBytes() int {
+	return l.maxBytes
+}
+
+// Close stops the expiry of stored outputs
+func (l *Limiter) Close() {
+	l.outputs.Close()
+}
+
+// Limit returns result unchanged when it fits the budget. A larger text result is cut to its
+// head and tail, with a marker carrying the cursor that reads the omitted part. The full
+// text is kept for tool calls by owner. Structured content larger than the budget keeps the
+// form of its output schema: its largest item list is cut instead, with the cursor that
+// reads the next items, and the text repeats it. Results with several or non-text contents
+// are returned as they are.
+func (l *Limiter) Limit(tool, owner string, result *mcp.CallToolResult) *mcp.CallToolResult {
+	if result == nil || len(result.Content) != 1 {
+		return result
+	}
+	content, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		return result
+	}
+	if result.StructuredContent != nil {
+		if limited := l.limitItems(tool, owner, result); limited != nil {
+			return limited
+		}
+	}
+	if len(content.Text) <= l.maxBytes {
+		return result
+	}
+	text := content.Text
+
+	available := l.maxBytes - markerReserve
+	head := prefixEnd(text, available*2/3)
+	tail := suffixStart(text, available-head)
+
+	var marker string
+	if id, err := newID(); err == nil {
+		l.outputs.Set(outputKey(tool, owner, id), text)
+		marker = fmt.Sprintf("\n... [%d of %d bytes omitted. Call %s again with %s=%q to read the output page by page from here] ...\n\n",
+			tail-head, len(text), tool, CursorArgument, cursor(id, head))
+	} else {
+		marker = fmt.Sprintf("\n... [%d of %d bytes omitted] ...\n\n", tail-head, len(text))
+	}
+
+	content.Text = text[:head] + marker + text[tail:]
+	limited := *result
+	limited.Content = []mcp.Content{content}
+	return &limited
+}
+
+// storedItems is the structured content of a result whose item list was cut, kept for paging
+type storedItems struct {
+	Field  string         `json:"field"`
+	Object map[string]any `json:"object"`
+}
+
+// limitItems cuts the largest item list of structured content larger than the budget. It
+// returns nil when the content fits or has no item list at its top level.
+func (l *Limiter) limitItems(tool, owner string, result *mcp.CallToolResult) *mcp.CallToolResult {
+	data, err := json.Marshal(result.StructuredContent)
+	if err != nil || len(data) <= l.maxBytes {
+		return nil
+	}
+	var object map[string]any
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil
+	}
+	field := largestList(object)
+	if field == "" {
+		return nil
+	}
+	id, err := newID()
+	if err != nil {
+		return nil
+	}
+	stored, err := json.Marshal(storedItems{Field: field, Object: object})
+	if err != nil {
+		return nil
+	}
+	l.outputs.Set(itemsKey(tool, owner, id), string(stored))
+
+	page := l.itemsPage(tool, id, storedItems{Field: field, Object: object}, 0)
+	page.IsError = result.IsError
+	return page
+}
+
+// itemsPage returns the items of stored from offset that fit the budget, at least one, with
+// the other fields of its object
+func (l *Limiter) itemsPage(tool, id string, stored storedItems, offset int) *mcp.CallToolResult {
+	items, _ := stored.Object[stored.Field].([]any)
+	build := func(end int) map[string]any {
+		page := maps.Clone(stored.Object)
+		page[stored.Field] = items[offset:end]
+		if end < len(items) {
+			page[TruncatedField] = true
+			page[CursorField] = cursor(id, end)
+		}
+		return page
+	}
+
+	// The most items that fit, found by bisection
+	available := l.maxBytes - markerReserve
+	low, high := offset+1, len(items)
+	for low < high {
+		mid := (low + high + 1) / 2
+		if data, err := json.Marshal(build(mid)); err == nil && len(data) <= available {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+
+	page := build(low)
+	data, _ := json.Marshal(page)
+	footer := fmt.Sprintf("\n... [items %d-%d of %d of %s, end of output] ...\n", offset, low, len(items), stored.Field)
+	if low < len(items) {
+		footer = fmt.Sprintf("\n... [items %d-%d of %d of %s. Call %s again with %s=%q for the next items] ...\n",
+			offset, low, len(items), stored.Field, tool, CursorArgument, cursor(id, low))
+	}
+	return mcp.NewToolResultStructured(page, string(data)+footer)
+}
+
+// largestList returns the top-level field of object holding the largest list, if any
+func largestList(object map[string]any) string {
+	field, largest := "", 0
+	for key, value := range object {
+		items, ok := value.([]any)
+		if !ok || len(items) == 0 {
+			continue
+		}
+		if data, err := json.Marshal(items); err == nil && (len(data) > largest || len(data) == largest && key < field) {
+			field, largest = key, len(data)
+		}
+	}
+	return field
+}
+
+// Page returns the page of a result of tool that starts at cursor. Results are only
+// served to the owner they were stored for.
+func (l *Limiter) Page(tool, owner, value string) *mcp.CallToolResult {
+	id, offsetText, found := strings.Cut(value, ".")
+	offset, err := strconv.Atoi(offsetText)
+	if !found || err != nil {
+		return toolerrors.NewValidationError(CursorArgument, "is not a cursor of a truncated result").ToMCPResult()
+	}
+	unknown := toolerrors.NewValidationError(CursorArgument, "is unknown or expired; call the tool again without it").ToMCPResult()
+
+	if data, ok := l.outputs.Get(itemsKey(tool, owner, id)); ok {
+		var stored storedItems
+		if err := json.Unmarshal([]byte(data), &stored); err != nil {
+			return unknown
+		}
+		if items, _ := stored.Object[stored.Field].([]any); offset <= 0 || offset >= len(items) {
+			return unknown
+		}
+		return l.itemsPage(tool, id, stored, offset)
+	}
+
+	text, ok := l.outputs.Get(outputKey(tool, owner, id))
+	if !ok || offset < 0 || offset >= len(text) {
+		return unknown
+	}
+
+	end := len(text)
+	if end-offset > l.maxBytes-markerReserve {
+		end = offset + prefixEnd(text[offset:], l.maxBytes-markerReserve)
+	}
+
+	footer := fmt.Sprintf("\n... [bytes %d-%d of %d, end of output] ...\n", offset, end, len(text))
+	if end < len(text) {
+		footer = fmt.Sprintf("\n... [bytes %d-%d of %d. Call %s again with %s=%q for the next page] ...\n",
+			offset, end, len(text), tool, CursorArgument, cursor(id, end))
+	}
+	return mcp.NewToolResultText(text[offset:end] + footer)
+}
+
+// prefixEnd returns where to cut text so that at most n bytes are kept, preferring the end
+// of a line in the second half of them
+func prefixEnd(text string, n int) int {
+	if n >= len(text) {
+		return len(text)
+	}
+	end := n
+	for end > 0 && !utf8.RuneStart(text[end]) {
+		end--
+	}
+	if newline := strings.LastIndexByte(text[:end], '\n'); newline >= n/2 {
+		end = newline + 1
+	}
+	return end
+}
+
+// suffixStart returns where the last n bytes of text start, preferring the start of a line
+// in the first half of them
+func suffixStart(text string, n int) int {
+	start := len(text) - n
+	if start <= 0 {
+		return 0
+	}
+	for start < len(text) && !utf8.RuneStart(text[start]) {
+		start++
+	}
+	if newline := strings.IndexByte(text[start:], '\n'); newline >= 0 && newline < n/2 {
+		start += newline + 1
+	}
+	return start
+}
+
+func newID() (string, error) {
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+func cursor(id string, offset int) string {
+	return id + "." + strconv.Itoa(offset)
+}
+
+func outputKey(tool, owner, id string) string {
+	return cache.CacheKey(tool, owner, id)
+}
+
+func itemsKey(tool, owner, id string) string {
+	return cache.CacheKey(tool, owner, id, "items")
+}
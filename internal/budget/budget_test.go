@@ -0,0 +1,202 @@
+package budget
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cursorPattern finds the cursor in a truncation marker or page footer
+var cursorPattern = regexp.MustCompile(`cursor="([^"]+)"`)
+
+func resultText(t *testing.T, result *mcp.CallToolResult) string {
+	t.Helper()
+	require.Len(t, result.Content, 1)
+	text, ok := result.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	return text.Text
+}
+
+// numberedLines returns count lines of about 50 bytes each
+func numberedLines(count int) string {
+	var sb strings.Builder
+	for i := 0; i < count; i++ {
+		fmt.Fprintf(&sb, "line %05d %s\n", i, strings.Repeat("x", 39))
+	}
+	return sb.String()
+}
+
+func TestNewLimiter(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxBytes  int
+		maxTokens int
+		want      int
+		wantErr   bool
+	}{
+		{name: "bytes only", maxBytes: 8192, want: 8192},
+		{name: "tokens only", maxTokens: 1000, want: 4000},
+		{name: "smaller of both", maxBytes: 8192, maxTokens: 1000, want: 4000},
+		{name: "disabled"},
+		{name: "too small", maxBytes: 100, wantErr: true},
+		{name: "negative", maxTokens: -1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter, err := NewLimiter(tt.maxBytes, tt.maxTokens)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.want == 0 {
+				assert.Nil(t, limiter)
+				return
+			}
+			defer limiter.Close()
+			assert.Equal(t, tt.want, limiter.MaxBytes())
+		})
+	}
+}
+
+func TestLimit(t *testing.T) {
+	limiter, err := NewLimiter(4096, 0)
+	require.NoError(t, err)
+	defer limiter.Close()
+
+	t.Run("small results are unchanged", func(t *testing.T) {
+		result := mcp.NewToolResultText("short")
+		assert.Same(t, result, limiter.Limit("k8s_get_resources", "alice", result))
+	})
+
+	t.Run("large results keep head and tail", func(t *testing.T) {
+		text := numberedLines(1000)
+		result := limiter.Limit("k8s_get_resources", "alice", mcp.NewToolResultText(text))
+
+		limited := resultText(t, result)
+		assert.LessOrEqual(t, len(limited), 4096)
+		assert.True(t, strings.HasPrefix(limited, "line 00000 "))
+		assert.True(t, strings.HasSuffix(limited, "line 00999 "+strings.Repeat("x", 39)+"\n"))
+		assert.Contains(t, limited, "bytes omitted. Call k8s_get_resources again with cursor=")
+		for _, line := range strings.Split(strings.TrimSuffix(limited, "\n"), "\n") {
+			assert.True(t, line == "" || strings.HasPrefix(line, "line ") || strings.HasPrefix(line, "... ["), "cut inside a line: %q", line)
+		}
+	})
+
+	t.Run("errors are limited too", func(t *testing.T) {
+		result := limiter.Limit("helm_get_release", "alice", mcp.NewToolResultError(numberedLines(200)))
+		assert.True(t, result.IsError)
+		assert.LessOrEqual(t, len(resultText(t, result)), 4096)
+	})
+
+	t.Run("structured content keeps its form", func(t *testing.T) {
+		text := numberedLines(200)
+		structured := map[string]string{"text": text}
+		result := limiter.Limit("k8s_get_resources", "alice", mcp.NewToolResultStructured(structured, text))
+		assert.Equal(t, structured, result.StructuredContent, "content without an item list is not cut")
+		assert.LessOrEqual(t, len(resultText(t, result)), 4096)
+
+		small := map[string]int{"total_count": 200}
+		result = limiter.Limit("k8s_get_resources", "alice", mcp.NewToolResultStructured(small, text))
+		assert.Equal(t, small, result.StructuredContent)
+	})
+
+	t.Run("multi-byte characters are not split", func(t *testing.T) {
+		text := strings.Repeat("é", 5000)
+		limited := resultText(t, limiter.Limit("k8s_get_resources", "alice", mcp.NewToolResultText(text)))
+		assert.True(t, strings.HasPrefix(limited, "éé"))
+		assert.True(t, strings.HasSuffix(limited, "éé"))
+		assert.NotContains(t, limited, "�")
+	})
+}
+
+func TestPage(t *testing.T) {
+	limiter, err := NewLimiter(4096, 0)
+	require.NoError(t, err)
+	defer limiter.Close()
+
+	text := numberedLines(1000)
+	limited := resultText(t, limiter.Limit("k8s_get_resources", "alice", mcp.NewToolResultText(text)))
+	head := limited[:strings.Index(limited, "\n... [")]
+
+	// Reading every page after the head gives back the full text
+	read := head
+	match := cursorPattern.FindStringSubmatch(limited)
+	for pages := 0; match != nil; pages++ {
+		require.Less(t, pages, 100, "paging does not end")
+		page := resultText(t, limiter.Page("k8s_get_resources", "alice", match[1]))
+		assert.LessOrEqual(t, len(page), 4096)
+		read += page[:strings.LastIndex(page, "\n... [")]
+		match = cursorPattern.FindStringSubmatch(page)
+		if match == nil {
+			assert.Contains(t, page, "end of output")
+		}
+	}
+	assert.Equal(t, text, read)
+
+	cursor := cursorPattern.FindStringSubmatch(limited)[1]
+	for name, page := range map[string]*mcp.CallToolResult{
+		"other owner": limiter.Page("k8s_get_resources", "mallory", cursor),
+		"other tool":  limiter.Page("helm_get_release", "alice", cursor),
+		"malformed":   limiter.Page("k8s_get_resources", "alice", "not-a-cursor"),
+		"past end":    limiter.Page("k8s_get_resources", "alice", strings.Split(cursor, ".")[0]+".999999"),
+	} {
+		assert.True(t, page.IsError, name)
+	}
+}
+
+// itemList is structured content with an item list, as tools with an output schema return
+type itemList struct {
+	Kind  string   `json:"kind"`
+	Items []string `json:"items"`
+}
+
+func TestLimitItems(t *testing.T) {
+	limiter, err := NewLimiter(4096, 0)
+	require.NoError(t, err)
+	defer limiter.Close()
+
+	full := itemList{Kind: "events"}
+	for i := 0; i < 300; i++ {
+		full.Items = append(full.Items, fmt.Sprintf("event %03d %s", i, strings.Repeat("x", 40)))
+	}
+	result := limiter.Limit("k8s_get_events", "alice", mcp.NewToolResultStructured(full, "a table of 300 events"))
+
+	// Every page holds part of the items, keeps the other fields and fits the budget
+	var read []any
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 100, "paging does not end")
+		require.False(t, result.IsError, resultText(t, result))
+		assert.LessOrEqual(t, len(resultText(t, result)), 4096)
+
+		page, ok := result.StructuredContent.(map[string]any)
+		require.True(t, ok)
+		assert.Equal(t, "events", page["kind"])
+		items := page["items"].([]any)
+		require.NotEmpty(t, items)
+		read = append(read, items...)
+
+		cursor, ok := page[CursorField].(string)
+		if !ok {
+			assert.Nil(t, page[TruncatedField])
+			assert.Contains(t, resultText(t, result), "end of output")
+			break
+		}
+		assert.Equal(t, true, page[TruncatedField])
+		assert.Contains(t, resultText(t, result), fmt.Sprintf("cursor=%q", cursor))
+		result = limiter.Page("k8s_get_events", "alice", cursor)
+	}
+	require.Len(t, read, len(full.Items))
+	for i, item := range read {
+		assert.Equal(t, full.Items[i], item)
+	}
+
+	first := limiter.Limit("k8s_get_events", "alice", mcp.NewToolResultStructured(full, ""))
+	cursor := first.StructuredContent.(map[string]any)[CursorField].(string)
+	assert.True(t, limiter.Page("k8s_get_events", "mallory", cursor).IsError)
+}
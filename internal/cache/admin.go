@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/kagent-dev/tools/internal/logger"
+	"github.com/kagent-dev/tools/internal/telemetry"
+)
+
+// cacheTypes lists every cache type, in the order operators see them
+var cacheTypes = []CacheType{CacheTypeKubernetes, CacheTypeHelm, CacheTypeIstio, CacheTypeCommand}
+
+// ParseCacheType returns the cache type named as CacheType.String renders it
+func ParseCacheType(name string) (CacheType, error) {
+	names := make([]string, 0, len(cacheTypes))
+	for _, cacheType := range cacheTypes {
+		if cacheType.String() == name {
+			return cacheType, nil
+		}
+		names = append(names, cacheType.String())
+	}
+	return 0, fmt.Errorf("unknown cache type %q, expected one of %s", name, strings.Join(names, ", "))
+}
+
+// cachesOfType returns the cache of the named type, or every cache for an empty name
+func cachesOfType(typeName string) ([]*Cache[string], error) {
+	InitCaches()
+	if typeName == "" {
+		caches := make([]*Cache[string], 0, len(cacheTypes))
+		for _, cacheType := range cacheTypes {
+			caches = append(caches, cacheRegistry[cacheType])
+		}
+		return caches, nil
+	}
+
+	cacheType, err := ParseCacheType(typeName)
+	if err != nil {
+		return nil, err
+	}
+	return []*Cache[string]{cacheRegistry[cacheType]}, nil
+}
+
+// StatsByType returns the statistics of the cache of the named type, or of every cache for
+// an empty name, by cache name
+func StatsByType(typeName string) (map[string]CacheStats, error) {
+	caches, err := cachesOfType(typeName)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]CacheStats, len(caches))
+	for _, cache := range caches {
+		stats[cache.Name()] = cache.Stats()
+	}
+	return stats, nil
+}
+
+// Selector picks the cached results an operator invalidates. Empty fields match every
+// result; results read without a cluster or namespace match any cluster or namespace.
+type Selector struct {
+	Type      string `json:"type,omitempty"`
+	Cluster   string `json:"cluster,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	KeyPrefix string `json:"key_prefix,omitempty"`
+}
+
+// InvalidateSelected removes the selected results and returns how many each cache removed
+func InvalidateSelected(selector Selector) (map[string]int, error) {
+	ctx := context.Background()
+	_, span := telemetry.StartSpan(ctx, "cache.invalidate",
+		attribute.String("cache.operation", "invalidate_selected"),
+		attribute.String("cache.type", selector.Type),
+		attribute.String("cache.key_prefix", selector.KeyPrefix),
+	)
+	defer span.End()
+
+	caches, err := cachesOfType(selector.Type)
+	if err != nil {
+		telemetry.RecordError(span, err, "Cache type not found")
+		return nil, err
+	}
+
+	tag := Tag{Cluster: selector.Cluster, Namespace: selector.Namespace}
+	match := func(entry *CacheEntry[string]) bool {
+		if !strings.HasPrefix(entry.key, selector.KeyPrefix) {
+			return false
+		}
+		return tag == Tag{} || entryMatches(entry.Tags, []Tag{tag})
+	}
+
+	removed := make(map[string]int, len(caches))
+	total := 0
+	for _, cache := range caches {
+		removed[cache.Name()] = cache.invalidateWhere(match)
+		total += removed[cache.Name()]
+	}
+
+	span.SetAttributes(
+		attribute.String("cache.tag", tag.String()),
+		attribute.Int("cache.items_cleared", total),
+	)
+	telemetry.RecordSuccess(span, "Cache invalidated successfully")
+	logger.Get().Info("Cache invalidated", "type", selector.Type, "tag", tag.String(), "key_prefix", selector.KeyPrefix, "items_cleared", total)
+	return removed, nil
+}
+
+// SetTTLByType overrides the TTL of results stored from now on in the cache of the named
+// type, or in every cache for an empty name. A ttl of 0 restores the TTLs the tools ask for.
+func SetTTLByType(typeName string, ttl time.Duration) error {
+	if ttl < 0 {
+		return fmt.Errorf("ttl must not be negative, got %s", ttl)
+	}
+	caches, err := cachesOfType(typeName)
+	if err != nil {
+		return err
+	}
+
+	for _, cache := range caches {
+		cache.SetTTL(ttl)
+	}
+	logger.Get().Info("Cache TTL set", "type", typeName, "ttl", ttl.String())
+	return nil
+}
+
+// BypassArgument and MaxAgeArgument are the optional tool arguments with which a caller
+// demands fresher results than the cache may hold
+const (
+	BypassArgument = "bypass_cache"
+	MaxAgeArgument = "max_age"
+)
+
+type maxAgeKey struct{}
+
+// WithMaxAge returns a context whose cached commands only accept results computed at most
+// maxAge ago. A maxAge of 0 bypasses the cache.
+func WithMaxAge(ctx context.Context, maxAge time.Duration) context.Context {
+	return context.WithValue(ctx, maxAgeKey{}, maxAge)
+}
+
+// MaxAgeFromContext returns the age limit set for the current call, if any
+func MaxAgeFromContext(ctx context.Context) (time.Duration, bool) {
+	maxAge, ok := ctx.Value(maxAgeKey{}).(time.Duration)
+	return maxAge, ok
+}
@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCacheType(t *testing.T) {
+	cacheType, err := ParseCacheType("helm")
+	require.NoError(t, err)
+	assert.Equal(t, CacheTypeHelm, cacheType)
+
+	_, err = ParseCacheType("redis")
+	assert.ErrorContains(t, err, "expected one of kubernetes, helm, istio, command")
+}
+
+func TestInvalidateSelected(t *testing.T) {
+	kubernetes := GetCacheByType(CacheTypeKubernetes)
+	helm := GetCacheByType(CacheTypeHelm)
+	t.Cleanup(kubernetes.Clear)
+	t.Cleanup(helm.Clear)
+
+	fill := func() {
+		kubernetes.SetWithTags("kubectl:get:pods:-n:web", "web pods", time.Minute, []Tag{{Namespace: "web", Kind: "pod"}})
+		kubernetes.SetWithTags("kubectl:get:pods:-n:db", "db pods", time.Minute, []Tag{{Cluster: "prod", Namespace: "db", Kind: "pod"}})
+		kubernetes.SetWithTags("kubectl:get:nodes", "nodes", time.Minute, []Tag{{Kind: "node"}})
+		helm.SetWithTags("helm:list:-n:web", "releases", time.Minute, []Tag{{Namespace: "web"}})
+	}
+
+	tests := []struct {
+		name     string
+		selector Selector
+		removed  map[string]int
+	}{
+		{"everything", Selector{}, map[string]int{"kubernetes": 3, "helm": 1, "istio": 0, "command": 0}},
+		{"type", Selector{Type: "helm"}, map[string]int{"helm": 1}},
+		{"namespace", Selector{Namespace: "web"}, map[string]int{"kubernetes": 2, "helm": 1, "istio": 0, "command": 0}},
+		{"cluster", Selector{Type: "kubernetes", Cluster: "staging"}, map[string]int{"kubernetes": 2}},
+		{"key prefix", Selector{Type: "kubernetes", KeyPrefix: "kubectl:get:pods"}, map[string]int{"kubernetes": 2}},
+		{"key prefix and namespace", Selector{Type: "kubernetes", KeyPrefix: "kubectl:get:pods", Namespace: "db"}, map[string]int{"kubernetes": 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kubernetes.Clear()
+			helm.Clear()
+			fill()
+
+			removed, err := InvalidateSelected(tt.selector)
+			require.NoError(t, err)
+			assert.Equal(t, tt.removed, removed)
+		})
+	}
+
+	_, err := InvalidateSelected(Selector{Type: "redis"})
+	assert.Error(t, err)
+}
+
+func TestSetTTLByType(t *testing.T) {
+	istio := GetCacheByType(CacheTypeIstio)
+	t.Cleanup(func() {
+		require.NoError(t, SetTTLByType("", 0))
+		istio.Clear()
+	})
+
+	istio.SetWithTTL("istioctl:proxy-status", "stored before", time.Hour)
+	require.NoError(t, SetTTLByType("istio", time.Millisecond))
+	istio.SetWithTTL("istioctl:version", "stored after", time.Hour)
+
+	stats, err := StatsByType("istio")
+	require.NoError(t, err)
+	assert.Equal(t, "1ms", stats["istio"].TTL)
+
+	time.Sleep(5 * time.Millisecond)
+	_, found := istio.Get("istioctl:proxy-status")
+	assert.False(t, found, "stored results are shortened to the new TTL")
+	_, found = istio.Get("istioctl:version")
+	assert.False(t, found, "new results take the new TTL")
+
+	require.NoError(t, SetTTLByType("istio", 0))
+	istio.SetWithTTL("istioctl:version", "stored after reset", time.Hour)
+	_, found = istio.Get("istioctl:version")
+	assert.True(t, found, "resetting restores the TTL callers ask for")
+
+	assert.Error(t, SetTTLByType("istio", -time.Second))
+	assert.Error(t, SetTTLByType("redis", time.Second))
+}
+
+func TestCacheFreshResult(t *testing.T) {
+	cache := NewCache[string]("test", time.Minute, 10, time.Hour)
+	defer cache.Close()
+
+	calls := 0
+	compute := func() (string, error) {
+		calls++
+		return "result", nil
+	}
+
+	_, err := CacheFreshResult(context.Background(), cache, "key", time.Minute, nil, -1, compute)
+	require.NoError(t, err)
+	_, err = CacheFreshResult(context.Background(), cache, "key", time.Minute, nil, time.Hour, compute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls, "a recent result is served from the cache")
+
+	time.Sleep(5 * time.Millisecond)
+	_, err = CacheFreshResult(context.Background(), cache, "key", time.Minute, nil, time.Millisecond, compute)
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls, "an older result is recomputed")
+
+	_, err = CacheFreshResult(context.Background(), cache, "key", time.Minute, nil, 0, compute)
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls, "a max age of 0 bypasses the cache")
+
+	_, found := cache.GetFresh("key", time.Hour)
+	assert.True(t, found, "bypassing callers refresh the cached result")
+}
+
+func TestMaxAgeFromContext(t *testing.T) {
+	_, ok := MaxAgeFromContext(context.Background())
+	assert.False(t, ok)
+
+	maxAge, ok := MaxAgeFromContext(WithMaxAge(context.Background(), 0))
+	assert.True(t, ok)
+	assert.Zero(t, maxAge)
+}
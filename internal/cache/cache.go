@@ -1,16 +1,21 @@
 package cache
 
 import (
+	"container/list"
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
+	"golang.org/x/sync/singleflight"
 
 	"github.com/kagent-dev/tools/internal/logger"
+	"github.com/kagent-dev/tools/internal/metrics"
 	"github.com/kagent-dev/tools/internal/telemetry"
 )
 
@@ -56,6 +61,10 @@ type CacheEntry[T any] struct {
 	ExpiresAt   time.Time
 	AccessedAt  time.Time
 	AccessCount int64
+	Tags        []Tag
+	Bytes       int64 // approximate memory held by the entry
+
+	key string
 }
 
 // IsExpired checks if the cache entry has expired
@@ -63,38 +72,70 @@ func (e *CacheEntry[T]) IsExpired() bool {
 	return time.Now().After(e.ExpiresAt)
 }
 
-// Cache is a thread-safe cache with TTL support
+// entryOverhead approximates the memory an entry takes besides its key, value and tags:
+// the entry itself, its list element and its map slot
+const entryOverhead = 256
+
+// entrySize approximates the memory an entry holds. Only string and byte slice values are
+// measured; other values count as the overhead alone.
+func entrySize[T any](key string, value T, tags []Tag) int64 {
+	size := int64(len(key) + entryOverhead)
+	switch v := any(value).(type) {
+	case string:
+		size += int64(len(v))
+	case []byte:
+		size += int64(len(v))
+	}
+	for _, tag := range tags {
+		size += int64(len(tag.Cluster) + len(tag.Namespace) + len(tag.Kind) + len(tag.Name) + 64)
+	}
+	return size
+}
+
+// Cache is a thread-safe LRU cache with TTL support. It holds at most maxSize entries and,
+// when maxBytes is set, entries of at most maxBytes in total; the least recently used
+// entries are evicted to stay within both.
 type Cache[T any] struct {
-	mu              sync.RWMutex
-	data            map[string]*CacheEntry[T]
+	mu              sync.Mutex
+	entries         map[string]*list.Element // values are *CacheEntry[T]
+	lru             *list.List               // most recently used at the front
+	bytes           int64
 	name            string
 	defaultTTL      time.Duration
+	ttl             time.Duration // set by SetTTL, overrides the TTL of new entries
 	maxSize         int
+	maxBytes        int64
 	cleanupInterval time.Duration
 	stopCleanup     chan struct{}
+	flights         singleflight.Group
+
+	// Counters reported by Stats, guarded by mu
+	hitCount      int64
+	missCount     int64
+	evictionCount int64
 
 	// Metrics
-	hits      metric.Int64Counter
-	misses    metric.Int64Counter
+	lookups   *Lookups
 	evictions metric.Int64Counter
 	size      metric.Int64UpDownCounter
+
+	// Prometheus series of the cache, exposed on /metrics
+	evictionsTotal prometheus.Counter
+	entriesGauge   prometheus.Gauge
+	bytesGauge     prometheus.Gauge
 }
 
 // NewCache creates a new cache with specified configuration and name
 func NewCache[T any](name string, defaultTTL time.Duration, maxSize int, cleanupInterval time.Duration) *Cache[T] {
+	return NewCacheWithLimits[T](name, defaultTTL, maxSize, 0, cleanupInterval)
+}
+
+// NewCacheWithLimits creates a new cache that also bounds the approximate bytes its entries
+// hold. A maxBytes of 0 leaves the bytes unbounded.
+func NewCacheWithLimits[T any](name string, defaultTTL time.Duration, maxSize int, maxBytes int64, cleanupInterval time.Duration) *Cache[T] {
 	meter := otel.Meter(fmt.Sprintf("kagent-tools/cache/%s", name))
 
 	// Create metrics with cache name as a label
-	hits, _ := meter.Int64Counter(
-		"cache_hits_total",
-		metric.WithDescription("Total number of cache hits"),
-	)
-
-	misses, _ := meter.Int64Counter(
-		"cache_misses_total",
-		metric.WithDescription("Total number of cache misses"),
-	)
-
 	evictions, _ := meter.Int64Counter(
 		"cache_evictions_total",
 		metric.WithDescription("Total number of cache evictions"),
@@ -106,16 +147,20 @@ func NewCache[T any](name string, defaultTTL time.Duration, maxSize int, cleanup
 	)
 
 	cache := &Cache[T]{
-		data:            make(map[string]*CacheEntry[T]),
+		entries:         make(map[string]*list.Element),
+		lru:             list.New(),
 		name:            name,
 		defaultTTL:      defaultTTL,
 		maxSize:         maxSize,
+		maxBytes:        maxBytes,
 		cleanupInterval: cleanupInterval,
 		stopCleanup:     make(chan struct{}),
-		hits:            hits,
-		misses:          misses,
+		lookups:         NewLookups(name),
 		evictions:       evictions,
 		size:            size,
+		evictionsTotal:  metrics.KagentToolsCacheEvictionsTotal.WithLabelValues(name),
+		entriesGauge:    metrics.KagentToolsCacheEntries.WithLabelValues(name),
+		bytesGauge:      metrics.KagentToolsCacheBytes.WithLabelValues(name),
 	}
 
 	// Start background cleanup
@@ -124,8 +169,21 @@ func NewCache[T any](name string, defaultTTL time.Duration, maxSize int, cleanup
 	return cache
 }
 
+// anyAge accepts cached values however long ago they were stored
+const anyAge time.Duration = -1
+
 // Get retrieves a value from the cache
 func (c *Cache[T]) Get(key string) (T, bool) {
+	return c.get(key, anyAge)
+}
+
+// GetFresh retrieves a value stored at most maxAge ago. Older values count as misses but
+// stay in the cache until they expire or are replaced.
+func (c *Cache[T]) GetFresh(key string, maxAge time.Duration) (T, bool) {
+	return c.get(key, maxAge)
+}
+
+func (c *Cache[T]) get(key string, maxAge time.Duration) (T, bool) {
 	ctx := context.Background()
 	_, span := telemetry.StartSpan(ctx, "cache.get",
 		attribute.String("cache.name", c.name),
@@ -133,10 +191,11 @@ func (c *Cache[T]) Get(key string) (T, bool) {
 	)
 	defer span.End()
 
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	// Reads update the recency and access count of the entry, so they take the write lock
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	entry, exists := c.data[key]
+	element, exists := c.entries[key]
 	if !exists {
 		var zero T
 		c.recordMiss(key)
@@ -147,8 +206,11 @@ func (c *Cache[T]) Get(key string) (T, bool) {
 		return zero, false
 	}
 
+	entry := element.Value.(*CacheEntry[T])
 	if entry.IsExpired() {
 		var zero T
+		c.removeElement(element)
+		c.recordEviction()
 		c.recordMiss(key)
 		telemetry.AddEvent(span, "cache.miss",
 			attribute.String("cache.result", "miss"),
@@ -161,9 +223,24 @@ func (c *Cache[T]) Get(key string) (T, bool) {
 		return zero, false
 	}
 
+	if maxAge >= 0 && time.Since(entry.CreatedAt) > maxAge {
+		var zero T
+		c.recordMiss(key)
+		telemetry.AddEvent(span, "cache.miss",
+			attribute.String("cache.result", "miss"),
+			attribute.String("cache.miss_reason", "too_old"),
+		)
+		span.SetAttributes(
+			attribute.String("cache.result", "miss"),
+			attribute.String("cache.miss_reason", "too_old"),
+		)
+		return zero, false
+	}
+
 	// Update access time and count
 	entry.AccessedAt = time.Now()
 	entry.AccessCount++
+	c.lru.MoveToFront(element)
 
 	c.recordHit(key)
 	telemetry.AddEvent(span, "cache.hit",
@@ -179,6 +256,22 @@ func (c *Cache[T]) Get(key string) (T, bool) {
 	return entry.Value, true
 }
 
+// peek returns an unexpired value stored at most maxAge ago without counting a lookup or
+// refreshing its recency
+func (c *Cache[T]) peek(key string, maxAge time.Duration) (T, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, exists := c.entries[key]; exists {
+		entry := element.Value.(*CacheEntry[T])
+		if !entry.IsExpired() && (maxAge < 0 || time.Since(entry.CreatedAt) <= maxAge) {
+			return entry.Value, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
 // Set stores a value in the cache with default TTL
 func (c *Cache[T]) Set(key string, value T) {
 	c.SetWithTTL(key, value, c.defaultTTL)
@@ -186,32 +279,62 @@ func (c *Cache[T]) Set(key string, value T) {
 
 // SetWithTTL stores a value in the cache with specified TTL
 func (c *Cache[T]) SetWithTTL(key string, value T, ttl time.Duration) {
+	c.SetWithTags(key, value, ttl, nil)
+}
+
+// SetWithTags stores a value with the specified TTL, tagged with the objects it was computed
+// from. Entries without tags depend on the whole cluster and go with any invalidation.
+func (c *Cache[T]) SetWithTags(key string, value T, ttl time.Duration, tags []Tag) {
+	bytes := entrySize(key, value, tags)
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	now := time.Now()
+	// A value over the whole budget would evict every other entry and then itself
+	if c.maxBytes > 0 && bytes > c.maxBytes {
+		if element, exists := c.entries[key]; exists {
+			c.removeElement(element)
+		}
+		logger.Get().Debug("Cache set skipped, value exceeds the cache size", "key", key, "bytes", bytes, "max_bytes", c.maxBytes)
+		return
+	}
 
-	// Check if we need to evict items to make room
-	if len(c.data) >= c.maxSize {
-		c.evictLRU()
+	if c.ttl > 0 {
+		ttl = c.ttl
 	}
 
+	now := time.Now()
 	entry := &CacheEntry[T]{
 		Value:       value,
 		CreatedAt:   now,
 		ExpiresAt:   now.Add(ttl),
 		AccessedAt:  now,
 		AccessCount: 1,
+		Tags:        tags,
+		Bytes:       bytes,
+		key:         key,
 	}
 
 	// Check if key already exists
-	if _, exists := c.data[key]; !exists {
+	if element, exists := c.entries[key]; exists {
+		c.bytes += bytes - element.Value.(*CacheEntry[T]).Bytes
+		c.bytesGauge.Add(float64(bytes - element.Value.(*CacheEntry[T]).Bytes))
+		element.Value = entry
+		c.lru.MoveToFront(element)
+	} else {
+		c.entries[key] = c.lru.PushFront(entry)
+		c.bytes += bytes
 		c.size.Add(context.Background(), 1)
+		c.entriesGauge.Inc()
+		c.bytesGauge.Add(float64(bytes))
 	}
 
-	c.data[key] = entry
+	// Evict least recently used items until the cache fits its limits again
+	for len(c.entries) > c.maxSize || (c.maxBytes > 0 && c.bytes > c.maxBytes) {
+		c.evictLRU()
+	}
 
-	logger.Get().Debug("Cache set", "key", key, "ttl", ttl)
+	logger.Get().Debug("Cache set", "key", key, "ttl", ttl, "bytes", bytes)
 }
 
 // Delete removes a value from the cache
@@ -219,9 +342,8 @@ func (c *Cache[T]) Delete(key string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if _, exists := c.data[key]; exists {
-		delete(c.data, key)
-		c.size.Add(context.Background(), -1)
+	if element, exists := c.entries[key]; exists {
+		c.removeElement(element)
 		logger.Get().Debug("Cache delete", "key", key)
 	}
 }
@@ -231,8 +353,12 @@ func (c *Cache[T]) Clear() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	count := len(c.data)
-	c.data = make(map[string]*CacheEntry[T])
+	count := len(c.entries)
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+	c.entriesGauge.Sub(float64(count))
+	c.bytesGauge.Sub(float64(c.bytes))
+	c.bytes = 0
 	c.size.Add(context.Background(), -int64(count))
 
 	logger.Get().Info("Cache cleared", "items_removed", count)
@@ -240,9 +366,16 @@ func (c *Cache[T]) Clear() {
 
 // Size returns the current number of items in the cache
 func (c *Cache[T]) Size() int {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	return len(c.data)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// Bytes returns the approximate memory held by the entries of the cache
+func (c *Cache[T]) Bytes() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.bytes
 }
 
 // Name returns the name of the cache
@@ -250,20 +383,47 @@ func (c *Cache[T]) Name() string {
 	return c.name
 }
 
+// SetTTL overrides the TTL of the entries stored from now on, whatever TTL their callers
+// ask for, and shortens the entries already stored to it. A ttl of 0 removes the override.
+func (c *Cache[T]) SetTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ttl = ttl
+	if ttl <= 0 {
+		return
+	}
+	for element := c.lru.Front(); element != nil; element = element.Next() {
+		entry := element.Value.(*CacheEntry[T])
+		if expiresAt := entry.CreatedAt.Add(ttl); expiresAt.Before(entry.ExpiresAt) {
+			entry.ExpiresAt = expiresAt
+		}
+	}
+}
+
 // Stats returns cache statistics
 func (c *Cache[T]) Stats() CacheStats {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
 	stats := CacheStats{
-		Size:    len(c.data),
-		MaxSize: c.maxSize,
-		Expired: 0,
-		Oldest:  time.Now(),
-		Newest:  time.Time{},
+		Size:      len(c.entries),
+		MaxSize:   c.maxSize,
+		Bytes:     c.bytes,
+		MaxBytes:  c.maxBytes,
+		Expired:   0,
+		Hits:      c.hitCount,
+		Misses:    c.missCount,
+		Evictions: c.evictionCount,
+		Oldest:    time.Now(),
+		Newest:    time.Time{},
+	}
+	if c.ttl > 0 {
+		stats.TTL = c.ttl.String()
 	}
 
-	for _, entry := range c.data {
+	for element := c.lru.Front(); element != nil; element = element.Next() {
+		entry := element.Value.(*CacheEntry[T])
 		if entry.IsExpired() {
 			stats.Expired++
 		}
@@ -282,11 +442,17 @@ func (c *Cache[T]) Stats() CacheStats {
 
 // CacheStats represents cache statistics
 type CacheStats struct {
-	Size    int       `json:"size"`
-	MaxSize int       `json:"max_size"`
-	Expired int       `json:"expired"`
-	Oldest  time.Time `json:"oldest"`
-	Newest  time.Time `json:"newest"`
+	Size      int       `json:"size"`
+	MaxSize   int       `json:"max_size"`
+	Bytes     int64     `json:"bytes"`
+	MaxBytes  int64     `json:"max_bytes,omitempty"`
+	Expired   int       `json:"expired"`
+	Hits      int64     `json:"hits"`
+	Misses    int64     `json:"misses"`
+	Evictions int64     `json:"evictions"`
+	TTL       string    `json:"ttl,omitempty"` // the TTL set by SetTTL, if any
+	Oldest    time.Time `json:"oldest"`
+	Newest    time.Time `json:"newest"`
 }
 
 // cleanupExpired removes expired entries from the cache
@@ -309,61 +475,115 @@ func (c *Cache[T]) performCleanup() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	keysToDelete := make([]string, 0)
-
-	for key, entry := range c.data {
-		if entry.IsExpired() {
-			keysToDelete = append(keysToDelete, key)
+	expired := 0
+	for element := c.lru.Front(); element != nil; {
+		next := element.Next()
+		if element.Value.(*CacheEntry[T]).IsExpired() {
+			c.removeElement(element)
+			c.recordEviction()
+			expired++
 		}
+		element = next
 	}
 
-	if len(keysToDelete) > 0 {
-		for _, key := range keysToDelete {
-			delete(c.data, key)
-			c.evictions.Add(context.Background(), 1)
-		}
-
-		c.size.Add(context.Background(), -int64(len(keysToDelete)))
-		logger.Get().Debug("Cache cleanup", "expired_items", len(keysToDelete))
+	if expired > 0 {
+		logger.Get().Debug("Cache cleanup", "expired_items", expired)
 	}
 }
 
-// evictLRU removes the least recently used item
+// evictLRU removes the least recently used item. The caller holds c.mu.
 func (c *Cache[T]) evictLRU() {
-	var oldestKey string
-	var oldestTime time.Time = time.Now()
-
-	for key, entry := range c.data {
-		if entry.AccessedAt.Before(oldestTime) {
-			oldestTime = entry.AccessedAt
-			oldestKey = key
-		}
+	element := c.lru.Back()
+	if element == nil {
+		return
 	}
+	c.removeElement(element)
+	c.recordEviction()
+	logger.Get().Debug("Cache LRU eviction", "key", element.Value.(*CacheEntry[T]).key)
+}
 
-	if oldestKey != "" {
-		delete(c.data, oldestKey)
-		c.evictions.Add(context.Background(), 1)
-		c.size.Add(context.Background(), -1)
-		logger.Get().Debug("Cache LRU eviction", "key", oldestKey)
-	}
+// removeElement removes an entry from the map and the recency list. The caller holds c.mu.
+func (c *Cache[T]) removeElement(element *list.Element) {
+	entry := c.lru.Remove(element).(*CacheEntry[T])
+	delete(c.entries, entry.key)
+	c.bytes -= entry.Bytes
+	c.size.Add(context.Background(), -1)
+	c.entriesGauge.Dec()
+	c.bytesGauge.Sub(float64(entry.Bytes))
 }
 
-// recordHit records a cache hit
+// recordHit records a cache hit. The caller holds c.mu.
 func (c *Cache[T]) recordHit(key string) {
-	c.hits.Add(context.Background(), 1, metric.WithAttributes(
+	c.hitCount++
+	c.lookups.Hit(key)
+}
+
+// recordMiss records a cache miss. The caller holds c.mu.
+func (c *Cache[T]) recordMiss(key string) {
+	c.missCount++
+	c.lookups.Miss(key)
+}
+
+// recordEviction records the eviction of an entry. The caller holds c.mu.
+func (c *Cache[T]) recordEviction() {
+	c.evictionCount++
+	c.evictions.Add(context.Background(), 1)
+	c.evictionsTotal.Inc()
+}
+
+// Lookups records the hits and misses of a cache in the cache metrics, both the OTel ones and
+// those on /metrics. Caches kept outside
+// this package, such as the informers of the k8s tools, use it to report like Cache does.
+type Lookups struct {
+	name   string
+	hits   metric.Int64Counter
+	misses metric.Int64Counter
+
+	hitsTotal   prometheus.Counter
+	missesTotal prometheus.Counter
+}
+
+// NewLookups creates the hit and miss metrics for the named cache
+func NewLookups(name string) *Lookups {
+	meter := otel.Meter(fmt.Sprintf("kagent-tools/cache/%s", name))
+
+	hits, _ := meter.Int64Counter(
+		"cache_hits_total",
+		metric.WithDescription("Total number of cache hits"),
+	)
+
+	misses, _ := meter.Int64Counter(
+		"cache_misses_total",
+		metric.WithDescription("Total number of cache misses"),
+	)
+
+	return &Lookups{
+		name:        name,
+		hits:        hits,
+		misses:      misses,
+		hitsTotal:   metrics.KagentToolsCacheHitsTotal.WithLabelValues(name),
+		missesTotal: metrics.KagentToolsCacheMissesTotal.WithLabelValues(name),
+	}
+}
+
+// Hit records a cache hit
+func (l *Lookups) Hit(key string) {
+	l.hits.Add(context.Background(), 1, metric.WithAttributes(
 		attribute.String("cache.key", key),
 		attribute.String("cache.result", "hit"),
-		attribute.String("cache.name", c.name),
+		attribute.String("cache.name", l.name),
 	))
+	l.hitsTotal.Inc()
 }
 
-// recordMiss records a cache miss
-func (c *Cache[T]) recordMiss(key string) {
-	c.misses.Add(context.Background(), 1, metric.WithAttributes(
+// Miss records a cache miss
+func (l *Lookups) Miss(key string) {
+	l.misses.Add(context.Background(), 1, metric.WithAttributes(
 		attribute.String("cache.key", key),
 		attribute.String("cache.result", "miss"),
-		attribute.String("cache.name", c.name),
+		attribute.String("cache.name", l.name),
 	))
+	l.missesTotal.Inc()
 }
 
 // Close stops the cache cleanup goroutine
@@ -442,17 +662,18 @@ var (
 func InitCaches() {
 	once.Do(func() {
 		// Initialize caches with optimized TTL values based on use case
-		// Kubernetes: 45s - K8s resources change frequently, users expect fresh data
-		cacheRegistry[CacheTypeKubernetes] = NewCache[string](CacheTypeKubernetes.String(), 45*time.Second, 1000, 1*time.Minute)
+		// Kubernetes: 45s - K8s resources change frequently, users expect fresh data.
+		// 64MiB - a few `get -A -o json` outputs are large enough to matter.
+		cacheRegistry[CacheTypeKubernetes] = NewCacheWithLimits[string](CacheTypeKubernetes.String(), 45*time.Second, 1000, 64<<20, 1*time.Minute)
 
 		// Istio: 1m - Service mesh config more stable than pods, but proxy status can change
-		cacheRegistry[CacheTypeIstio] = NewCache[string](CacheTypeIstio.String(), 1*time.Minute, 500, 1*time.Minute)
+		cacheRegistry[CacheTypeIstio] = NewCacheWithLimits[string](CacheTypeIstio.String(), 1*time.Minute, 500, 16<<20, 1*time.Minute)
 
 		// Helm: 2m - Releases change less frequently, chart info is stable
-		cacheRegistry[CacheTypeHelm] = NewCache[string](CacheTypeHelm.String(), 2*time.Minute, 300, 2*time.Minute)
+		cacheRegistry[CacheTypeHelm] = NewCacheWithLimits[string](CacheTypeHelm.String(), 2*time.Minute, 300, 16<<20, 2*time.Minute)
 
 		// Command: 3m - General CLI commands have stable output, status commands don't change rapidly
-		cacheRegistry[CacheTypeCommand] = NewCache[string](CacheTypeCommand.String(), 3*time.Minute, 200, 1*time.Minute)
+		cacheRegistry[CacheTypeCommand] = NewCacheWithLimits[string](CacheTypeCommand.String(), 3*time.Minute, 200, 16<<20, 1*time.Minute)
 
 		logger.Get().Info("Caches initialized")
 	})
@@ -492,7 +713,33 @@ func CacheKey(components ...string) string {
 
 // CacheResult is a helper function to cache the result of a function
 func CacheResult[T any](cache *Cache[T], key string, ttl time.Duration, fn func() (T, error)) (T, error) {
-	ctx := context.Background()
+	return CacheResultWithTags(cache, key, ttl, nil, fn)
+}
+
+// CacheResultWithTags caches the result of a function computed from the tagged objects
+func CacheResultWithTags[T any](cache *Cache[T], key string, ttl time.Duration, tags []Tag, fn func() (T, error)) (T, error) {
+	return cacheResult(context.Background(), cache, key, ttl, tags, anyAge, fn)
+}
+
+// CacheFreshResult is CacheResultWithTags for callers that only accept a result computed at
+// most maxAge ago. With a maxAge of 0 the function always runs, and its result replaces the
+// cached one; a negative maxAge accepts any unexpired result. ctx is the context fn runs
+// under: when it is done before fn fails, callers waiting on the same computation run their
+// own function instead of sharing the failure.
+func CacheFreshResult[T any](ctx context.Context, cache *Cache[T], key string, ttl time.Duration, tags []Tag, maxAge time.Duration, fn func() (T, error)) (T, error) {
+	return cacheResult(ctx, cache, key, ttl, tags, maxAge, fn)
+}
+
+// canceledFlight is the error of a shared computation that failed after the context of the
+// caller it ran for was done
+type canceledFlight struct {
+	err error
+}
+
+func (e canceledFlight) Error() string { return e.err.Error() }
+func (e canceledFlight) Unwrap() error { return e.err }
+
+func cacheResult[T any](ctx context.Context, cache *Cache[T], key string, ttl time.Duration, tags []Tag, maxAge time.Duration, fn func() (T, error)) (T, error) {
 	_, span := telemetry.StartSpan(ctx, "cache.result",
 		attribute.String("cache.name", cache.name),
 		attribute.String("cache.key", key),
@@ -502,8 +749,24 @@ func CacheResult[T any](cache *Cache[T], key string, ttl time.Duration, fn func(
 
 	var zero T
 
+	// A bypassing caller must not share a computation that started before it asked
+	if maxAge == 0 {
+		span.SetAttributes(
+			attribute.String("cache.operation", "compute"),
+			attribute.String("cache.result", "bypass"),
+		)
+		result, err := fn()
+		if err != nil {
+			telemetry.RecordError(span, err, "Function execution failed")
+			return zero, err
+		}
+		cache.SetWithTags(key, result, ttl, tags)
+		telemetry.RecordSuccess(span, "Cache bypassed - result recomputed and cached")
+		return result, nil
+	}
+
 	// Try to get from cache first
-	if cachedResult, found := cache.Get(key); found {
+	if cachedResult, found := cache.get(key, maxAge); found {
 		telemetry.AddEvent(span, "cache.result.hit",
 			attribute.String("cache.operation", "get"),
 			attribute.String("cache.result", "hit"),
@@ -526,20 +789,53 @@ func CacheResult[T any](cache *Cache[T], key string, ttl time.Duration, fn func(
 		attribute.String("cache.result", "miss"),
 	)
 
-	result, err := fn()
+	// Concurrent misses of a key wait for a single computation of the result. It runs under
+	// the context of the caller that started it, so if that caller goes away the others,
+	// while still waiting themselves, compute the result again.
+	var value interface{}
+	var err error
+	for {
+		var shared bool
+		value, err, shared = cache.flights.Do(key, func() (interface{}, error) {
+			// A computation that finished since the lookup above has stored the result
+			if cachedResult, found := cache.peek(key, maxAge); found {
+				return cachedResult, nil
+			}
+
+			result, err := fn()
+			if err != nil {
+				if ctx.Err() != nil {
+					return result, canceledFlight{err: err}
+				}
+				return result, err
+			}
+
+			// Store in cache
+			cache.SetWithTags(key, result, ttl, tags)
+			return result, nil
+		})
+		span.SetAttributes(attribute.Bool("cache.shared", shared))
+
+		var canceled canceledFlight
+		if !errors.As(err, &canceled) {
+			break
+		}
+		if !shared || ctx.Err() != nil {
+			err = canceled.err
+			break
+		}
+		telemetry.AddEvent(span, "cache.result.retry")
+	}
 	if err != nil {
 		telemetry.RecordError(span, err, "Function execution failed")
 		return zero, err
 	}
 
-	// Store in cache
-	cache.SetWithTTL(key, result, ttl)
-
 	telemetry.AddEvent(span, "cache.result.stored",
 		attribute.String("cache.operation", "set"),
 	)
 	span.SetAttributes(attribute.String("cache.operation", "set"))
 	telemetry.RecordSuccess(span, "Function executed and result cached")
 
-	return result, nil
+	return value.(T), nil
 }
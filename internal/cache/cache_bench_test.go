@@ -0,0 +1,200 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/kagent-dev/tools/internal/telemetry"
+)
+
+// scanCache is the previous implementation, kept to benchmark against: a map whose LRU
+// eviction scans every entry, and whose Get updates entries under the read lock.
+type scanCache[T any] struct {
+	mu      sync.RWMutex
+	data    map[string]*CacheEntry[T]
+	name    string
+	maxSize int
+	lookups *Lookups
+}
+
+func newScanCache[T any](name string, maxSize int) *scanCache[T] {
+	return &scanCache[T]{data: make(map[string]*CacheEntry[T]), name: name, maxSize: maxSize, lookups: NewLookups(name)}
+}
+
+func (c *scanCache[T]) Get(key string) (T, bool) {
+	_, span := telemetry.StartSpan(context.Background(), "cache.get",
+		attribute.String("cache.name", c.name),
+		attribute.String("cache.key", key),
+	)
+	defer span.End()
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, exists := c.data[key]
+	if !exists || entry.IsExpired() {
+		var zero T
+		c.lookups.Miss(key)
+		return zero, false
+	}
+	entry.AccessedAt = time.Now()
+	entry.AccessCount++
+	c.lookups.Hit(key)
+	return entry.Value, true
+}
+
+func (c *scanCache[T]) Set(key string, value T, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.data) >= c.maxSize {
+		var oldestKey string
+		oldestTime := time.Now()
+		for key, entry := range c.data {
+			if entry.AccessedAt.Before(oldestTime) {
+				oldestTime = entry.AccessedAt
+				oldestKey = key
+			}
+		}
+		delete(c.data, oldestKey)
+	}
+
+	now := time.Now()
+	c.data[key] = &CacheEntry[T]{Value: value, CreatedAt: now, ExpiresAt: now.Add(ttl), AccessedAt: now, AccessCount: 1}
+}
+
+// benchmarkSizes are the entry counts of the caches benchmarked, around the size of the
+// kubernetes cache
+var benchmarkSizes = []int{100, 1000, 10000}
+
+func benchmarkKeys(n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("kubectl:get:pods:-n:namespace-%d:-o:wide", i)
+	}
+	return keys
+}
+
+// BenchmarkSetFull measures inserts into a full cache, each of which evicts an entry
+func BenchmarkSetFull(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		keys := benchmarkKeys(2 * size)
+
+		b.Run(fmt.Sprintf("lru/%d", size), func(b *testing.B) {
+			cache := NewCache[string]("bench", time.Minute, size, time.Hour)
+			defer cache.Close()
+			for _, key := range keys[:size] {
+				cache.Set(key, "value")
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				cache.Set(keys[i%len(keys)], "value")
+			}
+		})
+
+		b.Run(fmt.Sprintf("scan/%d", size), func(b *testing.B) {
+			cache := newScanCache[string]("bench", size)
+			for _, key := range keys[:size] {
+				cache.Set(key, "value", time.Minute)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				cache.Set(keys[i%len(keys)], "value", time.Minute)
+			}
+		})
+	}
+}
+
+// BenchmarkGet measures hits on a full cache
+func BenchmarkGet(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		keys := benchmarkKeys(size)
+
+		b.Run(fmt.Sprintf("lru/%d", size), func(b *testing.B) {
+			cache := NewCache[string]("bench", time.Minute, size, time.Hour)
+			defer cache.Close()
+			for _, key := range keys {
+				cache.Set(key, "value")
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				cache.Get(keys[i%len(keys)])
+			}
+		})
+
+		b.Run(fmt.Sprintf("scan/%d", size), func(b *testing.B) {
+			cache := newScanCache[string]("bench", size)
+			for _, key := range keys {
+				cache.Set(key, "value", time.Minute)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				cache.Get(keys[i%len(keys)])
+			}
+		})
+	}
+}
+
+// BenchmarkMixedParallel measures concurrent callers reading and, one time in ten,
+// storing a result in a full cache
+func BenchmarkMixedParallel(b *testing.B) {
+	const size = 1000
+	keys := benchmarkKeys(2 * size)
+
+	b.Run("lru", func(b *testing.B) {
+		cache := NewCache[string]("bench", time.Minute, size, time.Hour)
+		defer cache.Close()
+		for _, key := range keys[:size] {
+			cache.Set(key, "value")
+		}
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for i := 0; pb.Next(); i++ {
+				if i%10 == 0 {
+					cache.Set(keys[i%len(keys)], "value")
+				} else {
+					cache.Get(keys[i%len(keys)])
+				}
+			}
+		})
+	})
+
+	b.Run("scan", func(b *testing.B) {
+		cache := newScanCache[string]("bench", size)
+		for _, key := range keys[:size] {
+			cache.Set(key, "value", time.Minute)
+		}
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for i := 0; pb.Next(); i++ {
+				if i%10 == 0 {
+					cache.Set(keys[i%len(keys)], "value", time.Minute)
+				} else {
+					cache.Get(keys[i%len(keys)])
+				}
+			}
+		})
+	})
+}
+
+// BenchmarkCacheResultConcurrentMisses measures callers missing the same key at once, which
+// share one computation of the result
+func BenchmarkCacheResultConcurrentMisses(b *testing.B) {
+	cache := NewCache[string]("bench", time.Minute, 1000, time.Hour)
+	defer cache.Close()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			key := fmt.Sprintf("key%d", i%100)
+			_, _ = CacheResult(cache, key, time.Millisecond, func() (string, error) {
+				time.Sleep(100 * time.Microsecond)
+				return "result", nil
+			})
+		}
+	})
+}
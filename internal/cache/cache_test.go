@@ -1,11 +1,19 @@
 package cache
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	promtest "github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/kagent-dev/tools/internal/metrics"
 )
 
 func TestNewCache(t *testing.T) {
@@ -486,3 +494,196 @@ func TestCacheOTelTracing(t *testing.T) {
 	InvalidateByType(CacheTypeCommand)
 	assert.True(t, oldSize > 0) // Verify we had items to clear
 }
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewCache[string]("test-cache", 1*time.Minute, 2, 10*time.Second)
+	defer cache.Close()
+
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+
+	// Reading key1 makes key2 the least recently used
+	_, found := cache.Get("key1")
+	assert.True(t, found)
+	cache.Set("key3", "value3")
+
+	_, found = cache.Get("key2")
+	assert.False(t, found, "the least recently used key is evicted")
+	_, found = cache.Get("key1")
+	assert.True(t, found)
+
+	// Replacing a key does not grow the cache
+	cache.Set("key1", "updated")
+	assert.Equal(t, 2, cache.Size())
+	value, _ := cache.Get("key1")
+	assert.Equal(t, "updated", value)
+}
+
+func TestCacheByteLimit(t *testing.T) {
+	value := strings.Repeat("x", 1000)
+	limit := 3 * entrySize("key0", value, nil)
+	cache := NewCacheWithLimits[string]("test-cache", 1*time.Minute, 100, limit, 10*time.Second)
+	defer cache.Close()
+
+	for i := 0; i < 5; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), value)
+	}
+	assert.Equal(t, 3, cache.Size(), "entries beyond the byte limit are evicted")
+	assert.LessOrEqual(t, cache.Bytes(), limit)
+	_, found := cache.Get("key1")
+	assert.False(t, found)
+	_, found = cache.Get("key4")
+	assert.True(t, found)
+
+	cache.Set("huge", strings.Repeat("x", int(limit)))
+	_, found = cache.Get("huge")
+	assert.False(t, found, "a value over the whole budget is not cached")
+	assert.Equal(t, 3, cache.Size(), "and evicts nothing")
+
+	cache.Delete("key4")
+	cache.Clear()
+	assert.Zero(t, cache.Bytes())
+}
+
+func TestCacheStatsCounters(t *testing.T) {
+	cache := NewCache[string]("test-cache", 1*time.Minute, 1, 10*time.Second)
+	defer cache.Close()
+
+	cache.Set("key1", "value1")
+	cache.Get("key1")
+	cache.Get("missing")
+	cache.Set("key2", "value2")
+	cache.SetWithTTL("key3", "value3", -time.Second)
+	cache.Get("key3")
+
+	stats := cache.Stats()
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Equal(t, int64(2), stats.Misses)
+	assert.Equal(t, int64(3), stats.Evictions, "two evictions for space and one for expiry")
+	assert.Equal(t, 0, stats.Size)
+	assert.Zero(t, stats.Bytes)
+}
+
+func TestCachePrometheusMetrics(t *testing.T) {
+	cache := NewCache[string]("prometheus-test", 1*time.Minute, 1, 10*time.Second)
+	defer cache.Close()
+
+	cache.Set("key1", "value1")
+	cache.Get("key1")
+	cache.Get("missing")
+	cache.Set("key2", "value2")
+
+	assert.Equal(t, 1.0, promtest.ToFloat64(metrics.KagentToolsCacheHitsTotal.WithLabelValues("prometheus-test")))
+	assert.Equal(t, 1.0, promtest.ToFloat64(metrics.KagentToolsCacheMissesTotal.WithLabelValues("prometheus-test")))
+	assert.Equal(t, 1.0, promtest.ToFloat64(metrics.KagentToolsCacheEvictionsTotal.WithLabelValues("prometheus-test")))
+	assert.Equal(t, 1.0, promtest.ToFloat64(metrics.KagentToolsCacheEntries.WithLabelValues("prometheus-test")))
+	assert.Equal(t, float64(cache.Bytes()), promtest.ToFloat64(metrics.KagentToolsCacheBytes.WithLabelValues("prometheus-test")))
+
+	cache.Clear()
+	assert.Zero(t, promtest.ToFloat64(metrics.KagentToolsCacheEntries.WithLabelValues("prometheus-test")))
+	assert.Zero(t, promtest.ToFloat64(metrics.KagentToolsCacheBytes.WithLabelValues("prometheus-test")))
+}
+
+func TestCacheResultDeduplicatesConcurrentMisses(t *testing.T) {
+	cache := NewCache[string]("test-cache", 1*time.Minute, 100, 10*time.Second)
+	defer cache.Close()
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := CacheResult(cache, "slow-key", 1*time.Minute, func() (string, error) {
+				calls.Add(1)
+				<-release
+				return "result", nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, "result", result)
+		}()
+	}
+
+	// Let every caller miss before the first computation finishes
+	assert.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestCacheResultRetriesAfterCanceledCaller(t *testing.T) {
+	cache := NewCache[string]("test-cache", 1*time.Minute, 100, 10*time.Second)
+	defer cache.Close()
+
+	// The first caller goes away while the second waits on its computation
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	firstDone := make(chan error, 1)
+	go func() {
+		_, err := CacheFreshResult(firstCtx, cache, "key", time.Minute, nil, -1, func() (string, error) {
+			close(started)
+			<-firstCtx.Done()
+			return "", fmt.Errorf("command failed: %w", firstCtx.Err())
+		})
+		firstDone <- err
+	}()
+	<-started
+
+	var calls atomic.Int32
+	secondDone := make(chan struct{})
+	var result string
+	var err error
+	go func() {
+		defer close(secondDone)
+		result, err = CacheFreshResult(context.Background(), cache, "key", time.Minute, nil, -1, func() (string, error) {
+			calls.Add(1)
+			return "result", nil
+		})
+	}()
+
+	// Let the second caller join the running computation before the first goes away
+	time.Sleep(20 * time.Millisecond)
+	cancelFirst()
+
+	assert.ErrorIs(t, <-firstDone, context.Canceled)
+	<-secondDone
+	assert.NoError(t, err)
+	assert.Equal(t, "result", result)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// A failure that is not due to a canceled caller is shared
+	shared := errors.New("boom")
+	_, err = CacheFreshResult(context.Background(), cache, "other", time.Minute, nil, -1, func() (string, error) {
+		return "", shared
+	})
+	assert.Equal(t, shared, err)
+}
+
+func TestCacheConcurrentAccess(t *testing.T) {
+	cache := NewCacheWithLimits[string]("test-cache", 1*time.Minute, 50, 20_000, 10*time.Second)
+	defer cache.Close()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				key := fmt.Sprintf("key%d", (g*31+i)%80)
+				cache.Set(key, strings.Repeat("v", i%300))
+				cache.Get(key)
+				if i%50 == 0 {
+					cache.Stats()
+					cache.InvalidateTags(Tag{Namespace: "web"})
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	stats := cache.Stats()
+	assert.LessOrEqual(t, stats.Size, 50)
+	assert.LessOrEqual(t, stats.Bytes, int64(20_000))
+}
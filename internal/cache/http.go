@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/kagent-dev/tools/internal/admin"
+)
+
+// ttlBody is the JSON body of a TTL change
+type ttlBody struct {
+	Type string `json:"type"`
+	TTL  string `json:"ttl"`
+}
+
+// Handler serves the admin endpoints for the result caches:
+//
+//	GET  /admin/cache            statistics of every cache, or of ?type=<type>
+//	POST /admin/cache/invalidate remove the results picked by a Selector body
+//	POST /admin/cache/ttl        set the TTL of new results from a {"type", "ttl"} body
+//
+// In read-only mode only the statistics are served. The handler does not authenticate
+// calls; it is served behind admin.RequireToken.
+func Handler(readOnly bool) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /admin/cache", func(w http.ResponseWriter, r *http.Request) {
+		stats, err := StatsByType(r.URL.Query().Get("type"))
+		if err != nil {
+			admin.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		admin.WriteJSON(w, http.StatusOK, stats)
+	})
+
+	if !readOnly {
+		mux.HandleFunc("POST /admin/cache/invalidate", func(w http.ResponseWriter, r *http.Request) {
+			var selector Selector
+			if err := json.NewDecoder(io.LimitReader(r.Body, 64*1024)).Decode(&selector); err != nil && !errors.Is(err, io.EOF) {
+				admin.WriteError(w, http.StatusBadRequest, err)
+				return
+			}
+			removed, err := InvalidateSelected(selector)
+			if err != nil {
+				admin.WriteError(w, http.StatusBadRequest, err)
+				return
+			}
+			admin.WriteJSON(w, http.StatusOK, map[string]map[string]int{"removed": removed})
+		})
+
+		mux.HandleFunc("POST /admin/cache/ttl", func(w http.ResponseWriter, r *http.Request) {
+			var body ttlBody
+			if err := json.NewDecoder(io.LimitReader(r.Body, 64*1024)).Decode(&body); err != nil {
+				admin.WriteError(w, http.StatusBadRequest, err)
+				return
+			}
+			ttl, err := time.ParseDuration(body.TTL)
+			if err == nil {
+				err = SetTTLByType(body.Type, ttl)
+			}
+			if err != nil {
+				admin.WriteError(w, http.StatusBadRequest, err)
+				return
+			}
+			stats, _ := StatsByType(body.Type)
+			admin.WriteJSON(w, http.StatusOK, stats)
+		})
+	}
+
+	return mux
+}
@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	command := GetCacheByType(CacheTypeCommand)
+	t.Cleanup(func() {
+		require.NoError(t, SetTTLByType("", 0))
+		command.Clear()
+	})
+	command.SetWithTags("cilium:status", "ok", time.Minute, nil)
+	handler := Handler(false)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/cache?type=command", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var stats map[string]CacheStats
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &stats))
+	assert.Equal(t, 1, stats["command"].Size)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/cache/ttl", strings.NewReader(`{"type":"command","ttl":"30s"}`)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &stats))
+	assert.Equal(t, "30s", stats["command"].TTL)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/cache/invalidate", strings.NewReader(`{"type":"command","key_prefix":"cilium:"}`)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var invalidated map[string]map[string]int
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &invalidated))
+	assert.Equal(t, map[string]int{"command": 1}, invalidated["removed"])
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/cache/ttl", strings.NewReader(`{"ttl":"soon"}`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/cache?type=redis", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestHandlerReadOnly(t *testing.T) {
+	handler := Handler(true)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/cache", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/cache/invalidate", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,212 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/kagent-dev/tools/internal/logger"
+	"github.com/kagent-dev/tools/internal/telemetry"
+)
+
+// Tag describes the Kubernetes objects a cache entry was computed from, or the objects a
+// write changed. Empty fields match anything, so Tag{Namespace: "prod"} stands for every
+// object in the prod namespace and the zero Tag for the whole cluster.
+type Tag struct {
+	Cluster   string `json:"cluster,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Kind      string `json:"kind,omitempty"`
+	Name      string `json:"name,omitempty"`
+}
+
+// ResourceTag returns the tag for objects of a kind, given as kubectl accepts it: singular,
+// plural, short name or with an API group ("deploy", "deployments.apps")
+func ResourceTag(namespace, kind, name string) Tag {
+	return Tag{Namespace: namespace, Kind: NormalizeKind(kind), Name: name}
+}
+
+// String renders the tag for logs and spans
+func (t Tag) String() string {
+	field := func(value string) string {
+		if value == "" {
+			return "*"
+		}
+		return value
+	}
+	return fmt.Sprintf("%s/%s/%s/%s", field(t.Cluster), field(t.Namespace), field(t.Kind), field(t.Name))
+}
+
+// Matches reports whether two tags can describe the same object
+func (t Tag) Matches(other Tag) bool {
+	match := func(a, b string) bool { return a == "" || b == "" || a == b }
+	return match(t.Cluster, other.Cluster) &&
+		match(t.Namespace, other.Namespace) &&
+		match(t.Kind, other.Kind) &&
+		match(t.Name, other.Name)
+}
+
+// kindAliases maps the short names kubectl accepts to normalized kinds
+var kindAliases = map[string]string{
+	"all":    "",
+	"cj":     "cronjob",
+	"cm":     "configmap",
+	"crd":    "customresourcedefinition",
+	"deploy": "deployment",
+	"ds":     "daemonset",
+	"ep":     "endpoint",
+	"ev":     "event",
+	"hpa":    "horizontalpodautoscaler",
+	"ing":    "ingress",
+	"limits": "limitrange",
+	"netpol": "networkpolicy",
+	"no":     "node",
+	"ns":     "namespace",
+	"pdb":    "poddisruptionbudget",
+	"po":     "pod",
+	"pv":     "persistentvolume",
+	"pvc":    "persistentvolumeclaim",
+	"quota":  "resourcequota",
+	"ro":     "rollout",
+	"rs":     "replicaset",
+	"sa":     "serviceaccount",
+	"sc":     "storageclass",
+	"sts":    "statefulset",
+	"svc":    "service",
+}
+
+// NormalizeKind reduces the spellings of a kind kubectl accepts to one lower case singular
+// name without API group. "all" normalizes to the empty kind, which matches every kind.
+func NormalizeKind(kind string) string {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	kind, _, _ = strings.Cut(kind, ".")
+	if alias, ok := kindAliases[kind]; ok {
+		return alias
+	}
+	switch {
+	case strings.HasSuffix(kind, "ies"):
+		return strings.TrimSuffix(kind, "ies") + "y"
+	case strings.HasSuffix(kind, "sses"), strings.HasSuffix(kind, "xes"), strings.HasSuffix(kind, "ches"), strings.HasSuffix(kind, "shes"):
+		return kind[:len(kind)-2]
+	case strings.HasSuffix(kind, "s") && !strings.HasSuffix(kind, "ss"):
+		return kind[:len(kind)-1]
+	}
+	return kind
+}
+
+// relatedKinds groups kinds whose objects change together: scaling a deployment changes its
+// replica sets and pods, deleting a pod changes the status of the workload that owns it
+var relatedKinds = [][]string{
+	{"deployment", "replicaset", "statefulset", "daemonset", "job", "cronjob", "rollout", "pod", "horizontalpodautoscaler"},
+	{"service", "endpoint", "endpointslice"},
+}
+
+// affected expands the tag of a write to everything the write may have changed: related
+// kinds, the events it caused and, for a namespace, the objects inside it
+func (t Tag) affected() []Tag {
+	tags := []Tag{t}
+	if t.Kind == "" {
+		return tags
+	}
+
+	related := func(kind string) Tag {
+		return Tag{Cluster: t.Cluster, Namespace: t.Namespace, Kind: kind}
+	}
+	tags = append(tags, related("event"))
+	for _, group := range relatedKinds {
+		for _, kind := range group {
+			if kind != t.Kind {
+				continue
+			}
+			for _, other := range group {
+				if other != t.Kind {
+					tags = append(tags, related(other))
+				}
+			}
+		}
+	}
+	if t.Kind == "namespace" && t.Name != "" {
+		tags = append(tags, Tag{Cluster: t.Cluster, Namespace: t.Name})
+	}
+	return tags
+}
+
+// InvalidateTags removes the entries with a tag matching any of the given ones and returns
+// how many were removed
+func (c *Cache[T]) InvalidateTags(tags ...Tag) int {
+	if len(tags) == 0 {
+		return 0
+	}
+
+	return c.invalidateWhere(func(entry *CacheEntry[T]) bool {
+		return entryMatches(entry.Tags, tags)
+	})
+}
+
+// invalidateWhere removes the entries that match and returns how many were removed
+func (c *Cache[T]) invalidateWhere(match func(*CacheEntry[T]) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for element := c.lru.Front(); element != nil; {
+		next := element.Next()
+		if match(element.Value.(*CacheEntry[T])) {
+			c.removeElement(element)
+			removed++
+		}
+		element = next
+	}
+	return removed
+}
+
+func entryMatches(entryTags, tags []Tag) bool {
+	if len(entryTags) == 0 {
+		return true
+	}
+	for _, entryTag := range entryTags {
+		for _, tag := range tags {
+			if entryTag.Matches(tag) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Invalidate removes the entries that may be stale after a write to the tagged objects from
+// every cache, and returns how many were removed
+func Invalidate(tags ...Tag) int {
+	ctx := context.Background()
+	_, span := telemetry.StartSpan(ctx, "cache.invalidate",
+		attribute.String("cache.operation", "invalidate_tags"),
+	)
+	defer span.End()
+
+	affected := make([]Tag, 0, len(tags))
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		affected = append(affected, tag.affected()...)
+		names = append(names, tag.String())
+	}
+
+	InitCaches()
+	removed := 0
+	for _, cache := range cacheRegistry {
+		removed += cache.InvalidateTags(affected...)
+	}
+
+	span.SetAttributes(
+		attribute.StringSlice("cache.tags", names),
+		attribute.Int("cache.items_cleared", removed),
+	)
+	telemetry.RecordSuccess(span, "Cache invalidated successfully")
+	// Watches invalidate on every change in the cluster, most of which evict nothing
+	if removed > 0 {
+		logger.Get().Info("Cache invalidated", "tags", names, "items_cleared", removed)
+	} else {
+		logger.Get().Debug("Cache invalidated", "tags", names, "items_cleared", removed)
+	}
+	return removed
+}
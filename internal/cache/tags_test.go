@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeKind(t *testing.T) {
+	for kind, want := range map[string]string{
+		"pods":                     "pod",
+		"Pod":                      "pod",
+		"po":                       "pod",
+		"deploy":                   "deployment",
+		"deployments.apps":         "deployment",
+		"deployments.v1.apps":      "deployment",
+		"networkpolicies":          "networkpolicy",
+		"ingresses":                "ingress",
+		"ingress":                  "ingress",
+		"storageclasses":           "storageclass",
+		"endpoints":                "endpoint",
+		"ep":                       "endpoint",
+		"all":                      "",
+		"virtualservices.istio.io": "virtualservice",
+	} {
+		assert.Equal(t, want, NormalizeKind(kind), kind)
+	}
+}
+
+func TestTagMatches(t *testing.T) {
+	pod := Tag{Cluster: "prod", Namespace: "web", Kind: "pod", Name: "api-1"}
+
+	assert.True(t, pod.Matches(pod))
+	assert.True(t, pod.Matches(Tag{}), "the zero tag stands for the whole cluster")
+	assert.True(t, pod.Matches(Tag{Namespace: "web"}))
+	assert.True(t, pod.Matches(Tag{Cluster: "prod", Kind: "pod"}))
+	assert.False(t, pod.Matches(Tag{Cluster: "staging"}))
+	assert.False(t, pod.Matches(Tag{Namespace: "db"}))
+	assert.False(t, pod.Matches(Tag{Kind: "service"}))
+	assert.False(t, pod.Matches(Tag{Kind: "pod", Name: "api-2"}))
+}
+
+func TestInvalidateTags(t *testing.T) {
+	cache := NewCache[string]("test-tags", time.Minute, 100, time.Minute)
+	defer cache.Close()
+
+	cache.SetWithTags("web-pods", "v", time.Minute, []Tag{ResourceTag("web", "pods", "")})
+	cache.SetWithTags("db-pods", "v", time.Minute, []Tag{ResourceTag("db", "pods", "")})
+	cache.SetWithTags("web-config", "v", time.Minute, []Tag{ResourceTag("web", "configmap", "settings")})
+	cache.SetWithTags("all-pods", "v", time.Minute, []Tag{ResourceTag("", "pods", "")})
+	cache.Set("untagged", "v")
+
+	assert.Equal(t, 0, cache.InvalidateTags())
+	assert.Equal(t, 3, cache.InvalidateTags(ResourceTag("web", "pod", "api-1")))
+
+	_, found := cache.Get("db-pods")
+	assert.True(t, found, "pods in other namespaces stay cached")
+	_, found = cache.Get("web-config")
+	assert.True(t, found, "other kinds stay cached")
+	_, found = cache.Get("untagged")
+	assert.False(t, found, "untagged entries may depend on anything")
+}
+
+func TestInvalidateRelatedKinds(t *testing.T) {
+	InitCaches()
+	k8s := GetCacheByType(CacheTypeKubernetes)
+	k8s.Clear()
+	defer k8s.Clear()
+
+	k8s.SetWithTags("pods", "v", time.Minute, []Tag{ResourceTag("web", "pods", "")})
+	k8s.SetWithTags("events", "v", time.Minute, []Tag{ResourceTag("web", "events", "")})
+	k8s.SetWithTags("services", "v", time.Minute, []Tag{ResourceTag("web", "services", "")})
+	k8s.SetWithTags("other", "v", time.Minute, []Tag{ResourceTag("other", "pods", "")})
+
+	assert.Equal(t, 2, Invalidate(ResourceTag("web", "deployment", "api")), "scaling a deployment changes its pods and causes events")
+	_, found := k8s.Get("services")
+	assert.True(t, found)
+
+	k8s.SetWithTags("configmap", "v", time.Minute, []Tag{ResourceTag("other", "configmap", "settings")})
+	k8s.SetWithTags("namespace", "v", time.Minute, []Tag{ResourceTag("", "namespace", "other")})
+	assert.Equal(t, 3, Invalidate(ResourceTag("", "namespace", "other")), "deleting a namespace removes everything in it")
+	assert.Equal(t, 1, k8s.Size())
+}
@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/metadata/metadatainformer"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	toolscache "k8s.io/client-go/tools/cache"
+
+	"github.com/kagent-dev/tools/internal/logger"
+)
+
+// WatchInvalidation evicts cached results whenever objects of the given resources change in
+// the cluster, including changes made outside the server. Resources are named as kubectl
+// names them ("pods", "deployments.apps"). Only object metadata is watched, so memory grows
+// with the number of objects rather than their size. It returns once the watches are synced;
+// they run until ctx is done.
+func WatchInvalidation(ctx context.Context, config *rest.Config, cluster string, resources []string) error {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create discovery client: %w", err)
+	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient))
+
+	gvrs := make([]schema.GroupVersionResource, 0, len(resources))
+	for _, resource := range resources {
+		gvr, err := mapper.ResourceFor(schema.ParseGroupResource(resource).WithVersion(""))
+		if err != nil {
+			return fmt.Errorf("unknown resource %q: %w", resource, err)
+		}
+		gvrs = append(gvrs, gvr)
+	}
+
+	client, err := metadata.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create metadata client: %w", err)
+	}
+	return watchResources(ctx, client, cluster, gvrs)
+}
+
+// watchResources starts a metadata informer per resource that invalidates the cache on
+// every change after the initial list
+func watchResources(ctx context.Context, client metadata.Interface, cluster string, gvrs []schema.GroupVersionResource) error {
+	factory := metadatainformer.NewSharedInformerFactory(client, 0)
+	for _, gvr := range gvrs {
+		kind := NormalizeKind(gvr.Resource)
+		invalidate := func(obj any) {
+			if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			object, err := meta.Accessor(obj)
+			if err != nil {
+				return
+			}
+			Invalidate(Tag{Cluster: cluster, Namespace: object.GetNamespace(), Kind: kind, Name: object.GetName()})
+		}
+
+		_, err := factory.ForResource(gvr).Informer().AddEventHandler(toolscache.ResourceEventHandlerDetailedFuncs{
+			AddFunc: func(obj any, isInInitialList bool) {
+				if !isInInitialList {
+					invalidate(obj)
+				}
+			},
+			UpdateFunc: func(_, obj any) { invalidate(obj) },
+			DeleteFunc: invalidate,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to watch %s: %w", gvr.String(), err)
+		}
+	}
+
+	factory.Start(ctx.Done())
+	for gvr, synced := range factory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			return fmt.Errorf("failed to sync watch of %s", gvr.String())
+		}
+	}
+	logger.Get().Info("Watching resources for cache invalidation", "cluster", cluster, "resources", len(gvrs))
+	return nil
+}
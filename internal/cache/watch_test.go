@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	metadatafake "k8s.io/client-go/metadata/fake"
+)
+
+func TestWatchResources(t *testing.T) {
+	pods := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	pod := func(name string) *metav1.PartialObjectMetadata {
+		return &metav1.PartialObjectMetadata{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: name},
+		}
+	}
+	scheme := metadatafake.NewTestScheme()
+	require.NoError(t, metav1.AddMetaToScheme(scheme))
+	client := metadatafake.NewSimpleMetadataClient(scheme, pod("api-1"))
+
+	InitCaches()
+	k8s := GetCacheByType(CacheTypeKubernetes)
+	k8s.Clear()
+	defer k8s.Clear()
+	k8s.SetWithTags("api-1", "v", time.Minute, []Tag{{Cluster: "prod", Namespace: "web", Kind: "pod", Name: "api-1"}})
+	k8s.SetWithTags("api-2", "v", time.Minute, []Tag{{Cluster: "prod", Namespace: "web", Kind: "pod", Name: "api-2"}})
+	k8s.SetWithTags("staging", "v", time.Minute, []Tag{{Cluster: "staging", Namespace: "web", Kind: "pod"}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, watchResources(ctx, client, "prod", []schema.GroupVersionResource{pods}))
+	assert.Equal(t, 3, k8s.Size(), "the initial list evicts nothing")
+
+	_, err := client.Resource(pods).Namespace("web").(metadatafake.MetadataClient).CreateFake(pod("api-2"), metav1.CreateOptions{})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		_, found := k8s.Get("api-2")
+		return !found
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, found := k8s.Get("api-1")
+	assert.True(t, found, "other pods stay cached")
+	_, found = k8s.Get("staging")
+	assert.True(t, found, "other clusters stay cached")
+}
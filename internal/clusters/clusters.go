@@ -0,0 +1,292 @@
+package clusters
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/kagent-dev/tools/internal/logger"
+)
+
+// ArgumentName is the optional tool argument used to select a cluster per call
+const ArgumentName = "cluster"
+
+const inClusterNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// Cluster is a kube context the server can target
+type Cluster struct {
+	Name       string `json:"name"`
+	Context    string `json:"context"`
+	Kubeconfig string `json:"kubeconfig"`
+	Server     string `json:"server,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+}
+
+// Registry holds the clusters available for per-call selection
+type Registry struct {
+	mu          sync.RWMutex
+	clusters    map[string]Cluster
+	names       []string
+	defaultName string
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{clusters: make(map[string]Cluster)}
+}
+
+// Load builds a registry from a kubeconfig file (one cluster per context) or a directory
+// of kubeconfig files. An empty path uses the default kubeconfig loading rules.
+func Load(path string) (*Registry, error) {
+	registry := NewRegistry()
+
+	var files []string
+	if path == "" {
+		files = clientcmd.NewDefaultClientConfigLoadingRules().GetLoadingPrecedence()
+	} else {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cluster config %s: %w", path, err)
+		}
+		if !info.IsDir() {
+			files = []string{path}
+		} else {
+			entries, err := os.ReadDir(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read cluster config directory %s: %w", path, err)
+			}
+			for _, entry := range entries {
+				if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+					continue
+				}
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+	}
+
+	for _, file := range files {
+		if _, err := os.Stat(file); err != nil {
+			continue // the default loading precedence lists files that may not exist
+		}
+		if err := registry.addKubeconfig(file); err != nil {
+			if path != "" && len(files) == 1 {
+				return nil, err
+			}
+			logger.Get().Warn("Skipping invalid kubeconfig", "file", file, "error", err)
+		}
+	}
+
+	return registry, nil
+}
+
+// addKubeconfig registers every context in a kubeconfig file
+func (r *Registry) addKubeconfig(file string) error {
+	config, err := clientcmd.LoadFromFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig %s: %w", file, err)
+	}
+
+	contextNames := make([]string, 0, len(config.Contexts))
+	for name := range config.Contexts {
+		contextNames = append(contextNames, name)
+	}
+	sort.Strings(contextNames)
+
+	for _, contextName := range contextNames {
+		kubeContext := config.Contexts[contextName]
+		cluster := Cluster{
+			Name:       contextName,
+			Context:    contextName,
+			Kubeconfig: file,
+			Namespace:  kubeContext.Namespace,
+		}
+		if c, ok := config.Clusters[kubeContext.Cluster]; ok {
+			cluster.Server = c.Server
+		}
+
+		r.mu.Lock()
+		if _, exists := r.clusters[cluster.Name]; exists {
+			// Same context name in two files: qualify with the file name
+			cluster.Name = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)) + "/" + contextName
+		}
+		r.clusters[cluster.Name] = cluster
+		r.names = append(r.names, cluster.Name)
+		if r.defaultName == "" && contextName == config.CurrentContext {
+			r.defaultName = cluster.Name
+		}
+		r.mu.Unlock()
+	}
+	return nil
+}
+
+// Get returns the cluster registered under name
+func (r *Registry) Get(name string) (Cluster, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cluster, ok := r.clusters[name]
+	if !ok {
+		return Cluster{}, fmt.Errorf("unknown cluster %q: available clusters are %s", name, strings.Join(r.names, ", "))
+	}
+	return cluster, nil
+}
+
+// List returns all registered clusters in registration order
+func (r *Registry) List() []Cluster {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make([]Cluster, 0, len(r.names))
+	for _, name := range r.names {
+		result = append(result, r.clusters[name])
+	}
+	return result
+}
+
+// Default returns the name of the cluster used when a call does not select one
+func (r *Registry) Default() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.defaultName
+}
+
+var (
+	globalRegistry   = NewRegistry()
+	globalRegistryMu sync.RWMutex
+)
+
+// SetRegistry sets the registry used to resolve per-call cluster selection
+func SetRegistry(registry *Registry) {
+	globalRegistryMu.Lock()
+	defer globalRegistryMu.Unlock()
+	globalRegistry = registry
+}
+
+// GetRegistry returns the registry used to resolve per-call cluster selection
+func GetRegistry() *Registry {
+	globalRegistryMu.RLock()
+	defer globalRegistryMu.RUnlock()
+	return globalRegistry
+}
+
+type clusterKey struct{}
+
+// WithCluster returns a context that routes commands and clients to the given cluster
+func WithCluster(ctx context.Context, cluster Cluster) context.Context {
+	return context.WithValue(ctx, clusterKey{}, cluster)
+}
+
+// FromContext returns the cluster selected for the current call, if any
+func FromContext(ctx context.Context) (Cluster, bool) {
+	cluster, ok := ctx.Value(clusterKey{}).(Cluster)
+	return cluster, ok
+}
+
+// RESTConfig returns the client config and default namespace for a registered cluster
+func RESTConfig(cluster Cluster) (*rest.Config, string, error) {
+	loadingRules := &clientcmd.ClientConfigLoadingRules{ExplicitPath: cluster.Kubeconfig}
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: cluster.Context}
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides)
+
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, "", err
+	}
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil || namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+	return config, namespace, nil
+}
+
+// LoadRESTConfig resolves the client config and default namespace the same way kubectl does:
+// an explicit kubeconfig wins, then in-cluster config, then the default loading rules
+func LoadRESTConfig(kubeconfig string) (*rest.Config, string, error) {
+	if kubeconfig == "" {
+		if config, err := rest.InClusterConfig(); err == nil {
+			namespace := metav1.NamespaceDefault
+			if data, err := os.ReadFile(inClusterNamespaceFile); err == nil && len(bytes.TrimSpace(data)) > 0 {
+				namespace = string(bytes.TrimSpace(data))
+			}
+			return config, namespace, nil
+		}
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfig
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{})
+
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, "", err
+	}
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil || namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+	return config, namespace, nil
+}
+
+// Status reports whether a cluster's API server answered a version request
+type Status struct {
+	Cluster
+	Default   bool   `json:"default"`
+	Reachable bool   `json:"reachable"`
+	Version   string `json:"version,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Check probes a cluster's API server with the given timeout
+func Check(cluster Cluster, timeout time.Duration) Status {
+	status := Status{Cluster: cluster}
+
+	config, _, err := RESTConfig(cluster)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	config.Timeout = timeout
+
+	client, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	version, err := client.ServerVersion()
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+
+	status.Reachable = true
+	status.Version = version.GitVersion
+	return status
+}
+
+// CheckAll probes every registered cluster concurrently
+func (r *Registry) CheckAll(timeout time.Duration) []Status {
+	clusterList := r.List()
+	defaultName := r.Default()
+	statuses := make([]Status, len(clusterList))
+
+	var wg sync.WaitGroup
+	for i, cluster := range clusterList {
+		wg.Add(1)
+		go func(i int, cluster Cluster) {
+			defer wg.Done()
+			statuses[i] = Check(cluster, timeout)
+			statuses[i].Default = cluster.Name == defaultName
+		}(i, cluster)
+	}
+	wg.Wait()
+	return statuses
+}
@@ -0,0 +1,131 @@
+package clusters
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeKubeconfig(t *testing.T, path, currentContext string, contexts map[string]string) {
+	t.Helper()
+
+	content := "apiVersion: v1\nkind: Config\ncurrent-context: " + currentContext + "\nclusters:\n"
+	for name, server := range contexts {
+		content += fmt.Sprintf("- name: %s\n  cluster:\n    server: %s\n", name, server)
+	}
+	content += "contexts:\n"
+	for name := range contexts {
+		content += fmt.Sprintf("- name: %s\n  context:\n    cluster: %s\n    user: %s\n    namespace: ns-%s\n", name, name, name, name)
+	}
+	content += "users:\n"
+	for name := range contexts {
+		content += fmt.Sprintf("- name: %s\n  user:\n    token: test\n", name)
+	}
+
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+}
+
+func TestLoadKubeconfigWithContexts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	writeKubeconfig(t, path, "staging", map[string]string{
+		"prod":    "https://prod.example.com",
+		"staging": "https://staging.example.com",
+	})
+
+	registry, err := Load(path)
+	require.NoError(t, err)
+
+	list := registry.List()
+	require.Len(t, list, 2)
+	assert.Equal(t, "prod", list[0].Name)
+	assert.Equal(t, "staging", list[1].Name)
+	assert.Equal(t, "staging", registry.Default())
+
+	prod, err := registry.Get("prod")
+	require.NoError(t, err)
+	assert.Equal(t, "prod", prod.Context)
+	assert.Equal(t, path, prod.Kubeconfig)
+	assert.Equal(t, "https://prod.example.com", prod.Server)
+	assert.Equal(t, "ns-prod", prod.Namespace)
+
+	_, err = registry.Get("missing")
+	assert.ErrorContains(t, err, "available clusters are prod, staging")
+}
+
+func TestLoadKubeconfigDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeKubeconfig(t, filepath.Join(dir, "east.yaml"), "admin", map[string]string{"admin": "https://east.example.com"})
+	writeKubeconfig(t, filepath.Join(dir, "west.yaml"), "admin", map[string]string{"admin": "https://west.example.com"})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.yaml"), []byte("not: [valid"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".hidden"), []byte("ignored"), 0600))
+
+	registry, err := Load(dir)
+	require.NoError(t, err)
+
+	list := registry.List()
+	require.Len(t, list, 2)
+	assert.Equal(t, "admin", list[0].Name)
+	assert.Equal(t, "west/admin", list[1].Name)
+	assert.Equal(t, "admin", list[1].Context)
+	assert.Equal(t, "https://west.example.com", list[1].Server)
+}
+
+func TestLoadMissingPath(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
+
+func TestClusterContext(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	assert.False(t, ok)
+
+	ctx := WithCluster(context.Background(), Cluster{Name: "prod", Context: "prod-ctx"})
+	cluster, ok := FromContext(ctx)
+	require.True(t, ok)
+	assert.Equal(t, "prod-ctx", cluster.Context)
+}
+
+func TestGlobalRegistry(t *testing.T) {
+	original := GetRegistry()
+	defer SetRegistry(original)
+
+	registry := NewRegistry()
+	SetRegistry(registry)
+	assert.Same(t, registry, GetRegistry())
+}
+
+func TestCheckAll(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"major":"1","minor":"35","gitVersion":"v1.35.1"}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "config")
+	writeKubeconfig(t, path, "up", map[string]string{
+		"up":   server.URL,
+		"down": "http://127.0.0.1:1",
+	})
+	registry, err := Load(path)
+	require.NoError(t, err)
+
+	statuses := registry.CheckAll(2 * time.Second)
+	require.Len(t, statuses, 2)
+
+	down, up := statuses[0], statuses[1]
+	assert.False(t, down.Reachable)
+	assert.NotEmpty(t, down.Error)
+	assert.False(t, down.Default)
+
+	assert.True(t, up.Reachable)
+	assert.Equal(t, "v1.35.1", up.Version)
+	assert.True(t, up.Default)
+}
@@ -2,10 +2,13 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"os/exec"
+	"strconv"
 	"time"
 
 	"github.com/kagent-dev/tools/internal/logger"
+	"github.com/kagent-dev/tools/internal/metrics"
 )
 
 // ShellExecutor defines the interface for executing shell commands
@@ -27,10 +30,19 @@ func (e *DefaultShellExecutor) Exec(ctx context.Context, command string, args ..
 		"args", redactedArgs,
 	)
 
+	commandLabel := metrics.CommandLabel(command)
+	provider := metrics.ToolProviderFromContext(ctx)
+	inFlight := metrics.KagentToolsCommandsInFlight.WithLabelValues(commandLabel, provider)
+	inFlight.Inc()
+
 	cmd := exec.CommandContext(ctx, command, args...)
 	output, err := cmd.CombinedOutput()
 
 	duration := time.Since(startTime)
+	inFlight.Dec()
+	metrics.KagentToolsCommandDurationSeconds.WithLabelValues(commandLabel, provider).Observe(duration.Seconds())
+	metrics.KagentToolsCommandOutputBytes.WithLabelValues(commandLabel, provider).Observe(float64(len(output)))
+	metrics.KagentToolsCommandExecutionsTotal.WithLabelValues(commandLabel, provider, exitCode(err)).Inc()
 
 	if err != nil {
 		log.Error("command execution failed",
@@ -51,6 +63,22 @@ func (e *DefaultShellExecutor) Exec(ctx context.Context, command string, args ..
 	return output, err
 }
 
+// exitCode returns the exit code label for the result of a command: its exit code, "signal"
+// when it was killed, or "error" when it could not be started
+func exitCode(err error) string {
+	if err == nil {
+		return "0"
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return "error"
+	}
+	if code := exitErr.ExitCode(); code >= 0 {
+		return strconv.Itoa(code)
+	}
+	return "signal"
+}
+
 // Context key for shell executor injection
 type contextKey string
 
@@ -2,9 +2,15 @@ package cmd
 
 import (
 	"context"
+	"errors"
+	"os/exec"
 	"testing"
+	"time"
 
+	promtest "github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/kagent-dev/tools/internal/metrics"
 )
 
 func TestDefaultShellExecutor(t *testing.T) {
@@ -20,6 +26,37 @@ func TestDefaultShellExecutor(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestDefaultShellExecutorMetrics(t *testing.T) {
+	executor := &DefaultShellExecutor{}
+	ctx := metrics.WithToolProvider(context.Background(), "utils")
+
+	executions := func(command, exitCode string) float64 {
+		return promtest.ToFloat64(metrics.KagentToolsCommandExecutionsTotal.WithLabelValues(command, "utils", exitCode))
+	}
+	succeeded, failed, unstarted := executions("other", "0"), executions("other", "3"), executions("other", "error")
+
+	_, _ = executor.Exec(ctx, "sh", "-c", "echo hello")
+	_, _ = executor.Exec(ctx, "sh", "-c", "exit 3")
+	_, _ = executor.Exec(ctx, "nonexistent-command")
+
+	assert.Equal(t, succeeded+1, executions("other", "0"))
+	assert.Equal(t, failed+1, executions("other", "3"))
+	assert.Equal(t, unstarted+1, executions("other", "error"))
+	assert.Zero(t, promtest.ToFloat64(metrics.KagentToolsCommandsInFlight.WithLabelValues("other", "utils")))
+	assert.Positive(t, promtest.CollectAndCount(metrics.KagentToolsCommandDurationSeconds, "kagent_tools_command_duration_seconds"))
+	assert.Positive(t, promtest.CollectAndCount(metrics.KagentToolsCommandOutputBytes, "kagent_tools_command_output_bytes"))
+}
+
+func TestExitCode(t *testing.T) {
+	assert.Equal(t, "0", exitCode(nil))
+	assert.Equal(t, "error", exitCode(errors.New("exec: not found")))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := exec.CommandContext(ctx, "sleep", "10").CombinedOutput()
+	assert.Equal(t, "signal", exitCode(err))
+}
+
 func TestMockShellExecutor(t *testing.T) {
 	mock := NewMockShellExecutor()
 
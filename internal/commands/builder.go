@@ -7,7 +7,9 @@ import (
 	"time"
 
 	"github.com/kagent-dev/tools/internal/cache"
+	"github.com/kagent-dev/tools/internal/clusters"
 	"github.com/kagent-dev/tools/internal/cmd"
+	"github.com/kagent-dev/tools/internal/credentials"
 	"github.com/kagent-dev/tools/internal/errors"
 	"github.com/kagent-dev/tools/internal/logger"
 	"github.com/kagent-dev/tools/internal/security"
@@ -24,24 +26,28 @@ const (
 
 // CommandBuilder provides a fluent interface for building CLI commands
 type CommandBuilder struct {
-	command     string
-	args        []string
-	namespace   string
-	context     string
-	kubeconfig  string
-	token       string
-	output      string
-	labels      map[string]string
-	annotations map[string]string
-	timeout     time.Duration
-	useTimeout  bool
-	dryRun      bool
-	force       bool
-	wait        bool
-	validate    bool
-	cached      bool
-	cacheTTL    time.Duration
-	cacheKey    string
+	command      string
+	args         []string
+	namespace    string
+	context      string
+	kubeconfig   string
+	token        string
+	impersonate  *credentials.Impersonation
+	output       string
+	labels       map[string]string
+	annotations  map[string]string
+	timeout      time.Duration
+	useTimeout   bool
+	dryRun       bool
+	serverDryRun bool
+	force        bool
+	wait         bool
+	validate     bool
+	cached       bool
+	cacheTTL     time.Duration
+	cacheKey     string
+	cacheTags    []cache.Tag
+	cluster      string
 }
 
 // NewCommandBuilder creates a new command builder
@@ -121,7 +127,8 @@ func (cb *CommandBuilder) WithKubeconfig(kubeconfig string) *CommandBuilder {
 	return cb
 }
 
-// WithToken sets the authentication token for kubectl commands
+// WithToken sets the bearer token the command authenticates to the cluster with. kubectl
+// and helm take it as a flag; istioctl and cilium get a temporary kubeconfig holding it.
 func (cb *CommandBuilder) WithToken(token string) *CommandBuilder {
 	if token != "" {
 		cb.token = token
@@ -129,6 +136,16 @@ func (cb *CommandBuilder) WithToken(token string) *CommandBuilder {
 	return cb
 }
 
+// WithImpersonation makes the command act as the given user and groups with the configured
+// credentials. kubectl and helm take them as flags; istioctl and cilium get a temporary
+// kubeconfig holding them.
+func (cb *CommandBuilder) WithImpersonation(imp credentials.Impersonation) *CommandBuilder {
+	if imp.User != "" {
+		cb.impersonate = &imp
+	}
+	return cb
+}
+
 // WithOutput sets the output format
 func (cb *CommandBuilder) WithOutput(output string) *CommandBuilder {
 	validOutputs := []string{"json", "yaml", "wide", "name", "custom-columns", "custom-columns-file", "go-template", "go-template-file", "jsonpath", "jsonpath-file"}
@@ -191,6 +208,13 @@ func (cb *CommandBuilder) WithDryRun(dryRun bool) *CommandBuilder {
 	return cb
 }
 
+// WithServerDryRun enables server-side dry run mode: the API server validates and admits
+// the request but does not persist it. It takes precedence over WithDryRun.
+func (cb *CommandBuilder) WithServerDryRun(dryRun bool) *CommandBuilder {
+	cb.serverDryRun = dryRun
+	return cb
+}
+
 // WithForce enables force mode
 func (cb *CommandBuilder) WithForce(force bool) *CommandBuilder {
 	cb.force = force
@@ -227,6 +251,13 @@ func (cb *CommandBuilder) WithCacheKey(key string) *CommandBuilder {
 	return cb
 }
 
+// WithCacheTags sets the objects the cached result is computed from, in place of those
+// told by the command arguments
+func (cb *CommandBuilder) WithCacheTags(tags ...cache.Tag) *CommandBuilder {
+	cb.cacheTags = tags
+	return cb
+}
+
 // Build constructs the final command arguments
 func (cb *CommandBuilder) Build() (string, []string, error) {
 	args := make([]string, 0, len(cb.args)+20)
@@ -239,9 +270,13 @@ func (cb *CommandBuilder) Build() (string, []string, error) {
 		args = append(args, "--namespace", cb.namespace)
 	}
 
-	// Add context if specified
+	// Add context if specified (helm names the flag --kube-context)
 	if cb.context != "" {
-		args = append(args, "--context", cb.context)
+		if cb.command == "helm" {
+			args = append(args, "--kube-context", cb.context)
+		} else {
+			args = append(args, "--context", cb.context)
+		}
 	}
 
 	// Add kubeconfig if specified
@@ -249,9 +284,28 @@ func (cb *CommandBuilder) Build() (string, []string, error) {
 		args = append(args, "--kubeconfig", cb.kubeconfig)
 	}
 
-	// Add token if specified
+	// Add token if specified (helm names the flag --kube-token)
 	if cb.token != "" {
-		args = append(args, "--token", cb.token)
+		switch cb.command {
+		case "kubectl":
+			args = append(args, "--token", cb.token)
+		case "helm":
+			args = append(args, "--kube-token", cb.token)
+		}
+	}
+
+	// Add impersonation if specified, in --flag=value form so a value is never read as a flag
+	if cb.impersonate != nil {
+		userFlag, groupFlag := "--as", "--as-group"
+		if cb.command == "helm" {
+			userFlag, groupFlag = "--kube-as-user", "--kube-as-group"
+		}
+		if cb.command == "kubectl" || cb.command == "helm" {
+			args = append(args, userFlag+"="+cb.impersonate.User)
+			for _, group := range cb.impersonate.Groups {
+				args = append(args, groupFlag+"="+group)
+			}
+		}
 	}
 
 	// Add output format
@@ -280,7 +334,9 @@ func (cb *CommandBuilder) Build() (string, []string, error) {
 	}
 
 	// Add dry run
-	if cb.dryRun {
+	if cb.serverDryRun {
+		args = append(args, "--dry-run=server")
+	} else if cb.dryRun {
 		args = append(args, "--dry-run=client")
 	}
 
@@ -312,6 +368,33 @@ func (cb *CommandBuilder) Execute(ctx context.Context) (string, error) {
 	)
 	defer span.End()
 
+	// A cluster selected for this call overrides the server-wide kubeconfig
+	if cluster, ok := clusters.FromContext(ctx); ok {
+		cb.kubeconfig = cluster.Kubeconfig
+		cb.context = cluster.Context
+		cb.cluster = cluster.Name
+		span.SetAttributes(attribute.String("cluster", cluster.Name))
+	}
+
+	// With token passthrough or impersonation, commands that reach the cluster run as the
+	// caller or not at all
+	if kubernetesCLIs[cb.command] {
+		cb.applyCaller(ctx)
+		if err := cb.requireCaller(); err != nil {
+			err = errors.NewCommandError(cb.command, err)
+			telemetry.RecordError(span, err, "Missing caller identity")
+			return "", err
+		}
+		if (cb.token != "" || cb.impersonate != nil) && kubeconfigCLIs[cb.command] {
+			cleanup, err := cb.useCallerKubeconfig()
+			if err != nil {
+				telemetry.RecordError(span, err, "Caller kubeconfig failed")
+				return "", err
+			}
+			defer cleanup()
+		}
+	}
+
 	command, args, err := cb.Build()
 	if err != nil {
 		telemetry.RecordError(span, err, "Command build failed")
@@ -366,9 +449,16 @@ func (cb *CommandBuilder) executeWithCache(ctx context.Context, command string,
 	)
 	defer span.End()
 
+	// Results are cached per caller, keyed by a fingerprint rather than the token itself
 	cacheKey := cb.cacheKey
 	if cacheKey == "" {
-		cacheKey = cache.CacheKey(append([]string{command}, args...)...)
+		cacheKey = cache.CacheKey(append([]string{command}, redactedArgs...)...)
+	}
+	if cb.token != "" {
+		cacheKey = cache.CacheKey(cacheKey, "token="+credentials.Fingerprint(cb.token))
+	}
+	if cb.impersonate != nil {
+		cacheKey = cache.CacheKey(cacheKey, "as="+cb.impersonate.User, "as-groups="+strings.Join(cb.impersonate.Groups, ","))
 	}
 
 	log.Info("executing cached command",
@@ -386,7 +476,26 @@ func (cb *CommandBuilder) executeWithCache(ctx context.Context, command string,
 		attribute.String("cache_ttl", cb.cacheTTL.String()),
 	)
 
-	result, err := cache.CacheResult(cacheInstance, cacheKey, cb.cacheTTL, func() (string, error) {
+	// Writes evict only the results computed from the objects they changed
+	tags := cb.cacheTags
+	if len(tags) == 0 {
+		tags = CacheTags(command, args)
+	}
+	for i := range tags {
+		if tags[i].Cluster == "" {
+			tags[i].Cluster = cb.cluster
+		}
+	}
+
+	// Callers that asked for fresh data get a result no older than they accept
+	maxAge, limited := cache.MaxAgeFromContext(ctx)
+	if limited {
+		span.SetAttributes(attribute.String("cache_max_age", maxAge.String()))
+	} else {
+		maxAge = -1
+	}
+
+	result, err := cache.CacheFreshResult(ctx, cacheInstance, cacheKey, cb.cacheTTL, tags, maxAge, func() (string, error) {
 		telemetry.AddEvent(span, "cache.miss.executing_command")
 		log.Debug("cache miss, executing command",
 			"command", command,
@@ -429,6 +538,7 @@ func (cb *CommandBuilder) executeCommand(ctx context.Context, command string, ar
 	if err != nil {
 		// Create appropriate error based on command type
 		var toolError *errors.ToolError
+		args = logger.RedactArgsForLog(args) // the operation ends up in the error text
 		switch command {
 		case "kubectl":
 			toolError = errors.NewKubernetesError(strings.Join(args, " "), err)
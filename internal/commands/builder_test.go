@@ -1,11 +1,19 @@
 package commands
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/kagent-dev/tools/internal/cache"
+	"github.com/kagent-dev/tools/internal/clusters"
+	"github.com/kagent-dev/tools/internal/cmd"
+	"github.com/kagent-dev/tools/internal/credentials"
 )
 
 func TestNewCommandBuilder(t *testing.T) {
@@ -203,6 +211,149 @@ func TestCommandBuilderBuild(t *testing.T) {
 	assert.Contains(t, args, "--validate=false")
 }
 
+func TestCommandBuilderBuildServerDryRun(t *testing.T) {
+	_, args, err := NewCommandBuilder("kubectl").
+		WithArgs("apply", "-f", "manifest.yaml").
+		WithDryRun(true).
+		WithServerDryRun(true).
+		Build()
+	require.NoError(t, err)
+
+	assert.Contains(t, args, "--dry-run=server")
+	assert.NotContains(t, args, "--dry-run=client")
+}
+
+func TestCommandBuilderBuildHelmContext(t *testing.T) {
+	_, args, err := HelmBuilder().WithArgs("list").WithContext("prod").Build()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"list", "--kube-context", "prod"}, args)
+}
+
+func TestCommandBuilderExecuteWithClusterFromContext(t *testing.T) {
+	mock := cmd.NewMockShellExecutor()
+	mock.AddCommandString("kubectl", []string{"get", "pods", "--context", "prod-ctx", "--kubeconfig", "/etc/clusters/prod"}, "pod-1", nil)
+	ctx := cmd.WithShellExecutor(context.Background(), mock)
+	ctx = clusters.WithCluster(ctx, clusters.Cluster{Name: "prod", Context: "prod-ctx", Kubeconfig: "/etc/clusters/prod"})
+
+	output, err := KubectlBuilder().
+		WithArgs("get", "pods").
+		WithKubeconfig("/default/kubeconfig").
+		Execute(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "pod-1", output)
+}
+
+func TestCommandBuilderBuildTokenFlags(t *testing.T) {
+	_, args, err := KubectlBuilder().WithArgs("get", "pods").WithToken("t1").Build()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"get", "pods", "--token", "t1"}, args)
+
+	_, args, err = HelmBuilder().WithArgs("list").WithToken("t2").Build()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"list", "--kube-token", "t2"}, args)
+
+	// istioctl has no token flag; Execute hands it a kubeconfig instead
+	_, args, err = IstioCtlBuilder().WithArgs("proxy-status").WithToken("t3").Build()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"proxy-status"}, args)
+}
+
+func TestCommandBuilderBuildImpersonationFlags(t *testing.T) {
+	imp := credentials.Impersonation{User: "alice", Groups: []string{"sre", "dev"}}
+
+	_, args, err := KubectlBuilder().WithArgs("get", "pods").WithImpersonation(imp).Build()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"get", "pods", "--as=alice", "--as-group=sre", "--as-group=dev"}, args)
+
+	_, args, err = HelmBuilder().WithArgs("list").WithImpersonation(imp).Build()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"list", "--kube-as-user=alice", "--kube-as-group=sre", "--kube-as-group=dev"}, args)
+}
+
+func TestCommandBuilderExecuteWithImpersonationFromContext(t *testing.T) {
+	mock := cmd.NewMockShellExecutor()
+	mock.AddCommandString("kubectl", []string{"get", "pods", "--as=alice"}, "pod-1", nil)
+	ctx := cmd.WithShellExecutor(context.Background(), mock)
+
+	credentials.EnableImpersonation(credentials.ImpersonationHeaders{User: "X-Remote-User"})
+	defer credentials.DisableImpersonation()
+
+	output, err := KubectlBuilder().WithArgs("get", "pods").Execute(credentials.WithImpersonation(ctx, credentials.Impersonation{User: "alice"}))
+	require.NoError(t, err)
+	assert.Equal(t, "pod-1", output)
+
+	_, err = KubectlBuilder().WithArgs("get", "pods").Execute(ctx)
+	assert.ErrorContains(t, err, "impersonated user required")
+	assert.Len(t, mock.GetCallLog(), 1)
+}
+
+func TestCommandBuilderExecuteWithTokenFromContext(t *testing.T) {
+	mock := cmd.NewMockShellExecutor()
+	mock.AddCommandString("kubectl", []string{"get", "pods", "--token", "caller-token"}, "pod-1", nil)
+	ctx := cmd.WithShellExecutor(context.Background(), mock)
+
+	output, err := KubectlBuilder().WithArgs("get", "pods").Execute(credentials.WithToken(ctx, "caller-token"))
+	require.NoError(t, err)
+	assert.Equal(t, "pod-1", output)
+
+	// With passthrough on, a call without a token never reaches the cluster
+	t.Setenv(credentials.EnvTokenPassthrough, "true")
+	_, err = HelmBuilder().WithArgs("list").Execute(ctx)
+	assert.ErrorContains(t, err, "Bearer token required")
+	assert.Len(t, mock.GetCallLog(), 1)
+}
+
+// kubeconfigRecorder captures the kubeconfig a command was given while it runs
+type kubeconfigRecorder struct {
+	path, content string
+}
+
+func (r *kubeconfigRecorder) Exec(_ context.Context, _ string, args ...string) ([]byte, error) {
+	for i, arg := range args {
+		if arg == "--kubeconfig" && i+1 < len(args) {
+			r.path = args[i+1]
+			data, err := os.ReadFile(r.path)
+			if err != nil {
+				return nil, err
+			}
+			r.content = string(data)
+		}
+	}
+	return []byte("ok"), nil
+}
+
+func TestCommandBuilderExecuteTokenKubeconfig(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(kubeconfig, []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: prod
+  cluster:
+    server: https://prod.example.com:6443
+users:
+- name: server-sa
+  user:
+    token: server-token
+contexts:
+- name: prod
+  context: {cluster: prod, user: server-sa}
+current-context: prod
+`), 0600))
+
+	recorder := &kubeconfigRecorder{}
+	ctx := credentials.WithToken(cmd.WithShellExecutor(context.Background(), recorder), "caller-token")
+	_, err := CiliumBuilder().WithArgs("status").WithKubeconfig(kubeconfig).Execute(ctx)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, kubeconfig, recorder.path)
+	assert.Contains(t, recorder.content, "https://prod.example.com:6443")
+	assert.Contains(t, recorder.content, "caller-token")
+	assert.NotContains(t, recorder.content, "server-token")
+	_, err = os.Stat(recorder.path)
+	assert.True(t, os.IsNotExist(err), "the temporary kubeconfig is removed after the command")
+}
+
 func TestCommandBuilderBuildWithTimeout(t *testing.T) {
 	cb := NewCommandBuilder("kubectl").
 		WithArgs("delete", "pod", "test-pod").
@@ -579,3 +730,25 @@ func TestCommandBuilderExecuteWithCache(t *testing.T) {
 	assert.Contains(t, args, "world")
 	assert.True(t, cb.cached)
 }
+
+func TestCommandBuilderExecuteWithMaxAge(t *testing.T) {
+	mock := cmd.NewMockShellExecutor()
+	mock.AddCommandString("kubectl", []string{"get", "pods", "--namespace", "max-age"}, "pods", nil)
+	ctx := cmd.WithShellExecutor(context.Background(), mock)
+
+	get := func(ctx context.Context) {
+		t.Helper()
+		_, err := KubectlBuilder().WithArgs("get", "pods").WithNamespace("max-age").WithCache(true).Execute(ctx)
+		require.NoError(t, err)
+	}
+	get(ctx)
+	get(ctx)
+	assert.Len(t, mock.GetCallLog(), 1)
+
+	get(cache.WithMaxAge(ctx, time.Hour))
+	assert.Len(t, mock.GetCallLog(), 1, "the cached result is recent enough")
+	get(cache.WithMaxAge(ctx, 0))
+	assert.Len(t, mock.GetCallLog(), 2, "bypassing callers run the command")
+	get(ctx)
+	assert.Len(t, mock.GetCallLog(), 2, "the bypassing call refreshed the cached result")
+}
@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/kagent-dev/tools/internal/cache"
+	"github.com/kagent-dev/tools/internal/clusters"
+	"github.com/kagent-dev/tools/internal/credentials"
+	"github.com/kagent-dev/tools/internal/logger"
+)
+
+// kubernetesCLIs are the commands that talk to the cluster and therefore honour token
+// passthrough and impersonation
+var kubernetesCLIs = map[string]bool{
+	"kubectl":  true,
+	"helm":     true,
+	"istioctl": true,
+	"cilium":   true,
+}
+
+// kubeconfigCLIs have no flags for a bearer token or impersonation and get a kubeconfig
+// holding them instead
+var kubeconfigCLIs = map[string]bool{
+	"istioctl": true,
+	"cilium":   true,
+}
+
+// applyCaller takes the caller's token and impersonated identity from the call context
+// unless the builder was given its own
+func (cb *CommandBuilder) applyCaller(ctx context.Context) {
+	if cb.token == "" {
+		cb.token = credentials.TokenFromContext(ctx)
+	}
+	if imp, ok := credentials.ImpersonationFromContext(ctx); ok && cb.impersonate == nil {
+		cb.impersonate = &imp
+	}
+}
+
+// requireCaller fails commands that would otherwise fall back to the server's own identity
+func (cb *CommandBuilder) requireCaller() error {
+	if cb.token == "" && credentials.PassthroughEnabled() {
+		return credentials.ErrTokenRequired
+	}
+	if cb.impersonate == nil && credentials.ImpersonationEnabled() {
+		return credentials.ErrImpersonationRequired
+	}
+	return nil
+}
+
+// useCallerKubeconfig points the command at a temporary kubeconfig for the target cluster
+// that authenticates with the builder's token, or impersonates its user with the server's
+// credentials. The returned function removes the file.
+func (cb *CommandBuilder) useCallerKubeconfig() (func(), error) {
+	config, _, err := clusters.LoadRESTConfig(cb.kubeconfig)
+	if cb.context != "" {
+		config, _, err = clusters.RESTConfig(clusters.Cluster{Kubeconfig: cb.kubeconfig, Context: cb.context})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig for the caller: %w", err)
+	}
+	if cb.token != "" {
+		config = credentials.RESTConfig(config, cb.token)
+	}
+	if cb.impersonate != nil {
+		config = credentials.ImpersonatingConfig(config, *cb.impersonate)
+	}
+	data, err := credentials.Kubeconfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render kubeconfig for the caller: %w", err)
+	}
+
+	file, err := os.CreateTemp("", "kagent-tools-*.kubeconfig")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubeconfig for the caller: %w", err)
+	}
+	cleanup := func() { _ = os.Remove(file.Name()) }
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cleanup()
+		return nil, fmt.Errorf("failed to write kubeconfig for the caller: %w", err)
+	}
+
+	// Keep the cache key independent of the temporary file name
+	if cb.cached && cb.cacheKey == "" {
+		command, args, _ := cb.Build()
+		cb.cacheKey = cache.CacheKey(append([]string{command}, logger.RedactArgsForLog(args)...)...)
+	}
+	cb.kubeconfig, cb.context = file.Name(), ""
+	return cleanup, nil
+}
@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/kagent-dev/tools/internal/cache"
+	"github.com/kagent-dev/tools/internal/clusters"
+)
+
+// valueFlags are the flags of the Kubernetes CLIs that take the next argument as their value
+var valueFlags = []string{
+	"-n", "--namespace", "-l", "--selector", "-o", "--output", "-f", "--filename",
+	"-k", "--kustomize", "-c", "--container", "-p", "--patch",
+	"--context", "--kube-context", "--kubeconfig", "--token", "--kube-token",
+	"--field-selector", "--sort-by", "--type", "--replicas", "--timeout", "--tail", "--since",
+	"--subresource", "--version", "--set", "--values", "--chunk-size",
+}
+
+// parsedArgs splits CLI arguments into positionals and the namespace they apply to. The
+// namespace is empty when none is given or all namespaces are requested.
+func parsedArgs(args []string) (positionals []string, namespace string) {
+	allNamespaces := false
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			i = len(args)
+		case arg == "-A" || arg == "--all-namespaces":
+			allNamespaces = true
+		case strings.HasPrefix(arg, "--namespace="):
+			namespace = strings.TrimPrefix(arg, "--namespace=")
+		case arg == "-n" || arg == "--namespace":
+			if i+1 < len(args) {
+				namespace = args[i+1]
+			}
+			i++
+		case slices.Contains(valueFlags, arg):
+			i++
+		case strings.HasPrefix(arg, "-"):
+		default:
+			positionals = append(positionals, arg)
+		}
+	}
+	if allNamespaces {
+		namespace = ""
+	}
+	return positionals, namespace
+}
+
+// kubectlObjectVerbs take the kind and names of their objects as their first arguments
+var kubectlObjectVerbs = map[string]bool{
+	"get": true, "describe": true, "delete": true, "scale": true, "patch": true,
+	"label": true, "annotate": true, "edit": true, "autoscale": true, "expose": true,
+}
+
+// CacheTags describes the objects a command reads or changes, as precisely as its arguments
+// tell. Commands whose objects cannot be told apart are tagged with their namespace, or
+// with the whole cluster.
+func CacheTags(command string, args []string) []cache.Tag {
+	positionals, namespace := parsedArgs(args)
+	if command != "kubectl" || len(positionals) == 0 {
+		return []cache.Tag{{Namespace: namespace}}
+	}
+
+	verb, objects := positionals[0], positionals[1:]
+	switch {
+	case verb == "argo" && len(objects) >= 1 && objects[0] == "rollouts":
+		// The plugin's subcommands place the rollout name differently
+		return []cache.Tag{cache.ResourceTag(namespace, "rollout", "")}
+	case verb == "rollout" && len(objects) >= 2:
+		objects = objects[1:]
+	case (verb == "logs" || verb == "run") && len(objects) >= 1:
+		objects = objects[:1]
+		if !strings.Contains(objects[0], "/") {
+			objects[0] = "pod/" + objects[0]
+		}
+	case !kubectlObjectVerbs[verb] || len(objects) == 0:
+		return []cache.Tag{{Namespace: namespace}}
+	}
+	return objectTags(namespace, objects)
+}
+
+// objectTags tags objects given as "kind name..." or "kind/name...", where the kind may list
+// several kinds separated by commas
+func objectTags(namespace string, objects []string) []cache.Tag {
+	var tags []cache.Tag
+	if strings.Contains(objects[0], "/") {
+		for _, object := range objects {
+			kind, name, _ := strings.Cut(object, "/")
+			tags = append(tags, cache.ResourceTag(namespace, kind, name))
+		}
+		return tags
+	}
+
+	kinds, names := strings.Split(objects[0], ","), objects[1:]
+	for _, kind := range kinds {
+		if len(names) == 0 {
+			tags = append(tags, cache.ResourceTag(namespace, kind, ""))
+		}
+		for _, name := range names {
+			tags = append(tags, cache.ResourceTag(namespace, kind, name))
+		}
+	}
+	return tags
+}
+
+// InvalidateCache evicts the cached results a write to the tagged objects may have made
+// stale, in the cluster selected for the call
+func InvalidateCache(ctx context.Context, tags ...cache.Tag) {
+	cluster, _ := clusters.FromContext(ctx)
+	for i := range tags {
+		if tags[i].Cluster == "" {
+			tags[i].Cluster = cluster.Name
+		}
+	}
+	cache.Invalidate(tags...)
+}
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kagent-dev/tools/internal/cache"
+	"github.com/kagent-dev/tools/internal/clusters"
+	"github.com/kagent-dev/tools/internal/cmd"
+)
+
+func TestCacheTags(t *testing.T) {
+	tests := []struct {
+		command string
+		args    []string
+		want    []cache.Tag
+	}{
+		{"kubectl", []string{"get", "pods", "-n", "web", "-o", "wide"}, []cache.Tag{{Namespace: "web", Kind: "pod"}}},
+		{"kubectl", []string{"get", "pods", "-A"}, []cache.Tag{{Kind: "pod"}}},
+		{"kubectl", []string{"get", "deploy,svc", "api", "--namespace=web"}, []cache.Tag{{Namespace: "web", Kind: "deployment", Name: "api"}, {Namespace: "web", Kind: "service", Name: "api"}}},
+		{"kubectl", []string{"describe", "deployment/api", "-n", "web"}, []cache.Tag{{Namespace: "web", Kind: "deployment", Name: "api"}}},
+		{"kubectl", []string{"delete", "pod", "api-1", "-n", "web", "--token", "secret"}, []cache.Tag{{Namespace: "web", Kind: "pod", Name: "api-1"}}},
+		{"kubectl", []string{"rollout", "restart", "deployment/api", "-n", "web"}, []cache.Tag{{Namespace: "web", Kind: "deployment", Name: "api"}}},
+		{"kubectl", []string{"logs", "api-1", "-n", "web", "--tail", "50"}, []cache.Tag{{Namespace: "web", Kind: "pod", Name: "api-1"}}},
+		{"kubectl", []string{"argo", "rollouts", "get", "rollout", "api", "-n", "web"}, []cache.Tag{{Namespace: "web", Kind: "rollout"}}},
+		{"kubectl", []string{"apply", "-f", "manifest.yaml", "-n", "web"}, []cache.Tag{{Namespace: "web"}}},
+		{"kubectl", []string{"config", "view"}, []cache.Tag{{}}},
+		{"helm", []string{"list", "-n", "web"}, []cache.Tag{{Namespace: "web"}}},
+		{"cilium", []string{"status"}, []cache.Tag{{}}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, CacheTags(tt.command, tt.args), "%s %v", tt.command, tt.args)
+	}
+}
+
+func TestInvalidateCacheEvictsOnlyAffectedResults(t *testing.T) {
+	mock := cmd.NewMockShellExecutor()
+	mock.AddCommandString("kubectl", []string{"get", "pods", "--namespace", "web"}, "web pods", nil)
+	mock.AddCommandString("kubectl", []string{"get", "pods", "--namespace", "db"}, "db pods", nil)
+	mock.AddCommandString("kubectl", []string{"get", "pods", "--namespace", "web", "--kubeconfig", "/tmp/prod.kubeconfig"}, "prod web pods", nil)
+	ctx := cmd.WithShellExecutor(context.Background(), mock)
+	prod := clusters.WithCluster(ctx, clusters.Cluster{Name: "prod", Kubeconfig: "/tmp/prod.kubeconfig"})
+
+	get := func(ctx context.Context, namespace string) {
+		t.Helper()
+		_, err := KubectlBuilder().WithArgs("get", "pods").WithNamespace(namespace).WithCache(true).Execute(ctx)
+		require.NoError(t, err)
+	}
+	get(ctx, "web")
+	get(ctx, "db")
+	get(prod, "web")
+	assert.Len(t, mock.GetCallLog(), 3)
+
+	InvalidateCache(prod, cache.ResourceTag("web", "pod", "api-1"))
+
+	get(ctx, "db")
+	assert.Len(t, mock.GetCallLog(), 3, "results for other namespaces stay cached")
+	get(prod, "web")
+	assert.Len(t, mock.GetCallLog(), 4, "the changed namespace is read again")
+	get(ctx, "web")
+	assert.Len(t, mock.GetCallLog(), 5, "results without a selected cluster may be for the changed one")
+}
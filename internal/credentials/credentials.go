@@ -0,0 +1,252 @@
+// Package credentials carries the caller's Kubernetes identity through a tool call, so that
+// commands and clients run as the caller rather than as the server's own ServiceAccount.
+// The identity is either the caller's bearer token (token passthrough) or a user and groups
+// the server impersonates with its own credentials (impersonation).
+package credentials
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync/atomic"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/kagent-dev/tools/internal/identity"
+)
+
+// EnvTokenPassthrough enables token passthrough when set to "true"
+const EnvTokenPassthrough = "TOKEN_PASSTHROUGH"
+
+// ErrTokenRequired is returned for calls without a bearer token while passthrough is on.
+// Such calls fail rather than fall back to the server's credentials.
+var ErrTokenRequired = errors.New("Bearer token required when TOKEN_PASSTHROUGH is true")
+
+// ErrImpersonationRequired is returned for calls without an impersonated user while
+// impersonation is on
+var ErrImpersonationRequired = errors.New("impersonated user required when impersonation is enabled")
+
+// ErrUntrustedImpersonation is returned for calls that did not authenticate as one of the
+// trusted proxies allowed to name the impersonated identity
+var ErrUntrustedImpersonation = errors.New("impersonation headers are only accepted from a trusted proxy")
+
+type tokenKey struct{}
+
+type impersonationKey struct{}
+
+// Impersonation is the user, and optionally the groups, a call acts as
+type Impersonation struct {
+	User   string
+	Groups []string
+}
+
+// ImpersonationHeaders names the trusted headers the impersonated identity is read from and
+// the proxies trusted to set them: path.Match patterns of the subjects they authenticate as
+type ImpersonationHeaders struct {
+	User           string
+	Group          string
+	TrustedProxies []string
+}
+
+// impersonation holds the headers while impersonation is enabled
+var impersonation atomic.Pointer[ImpersonationHeaders]
+
+// EnableImpersonation makes every call that reaches a cluster impersonate the user named in
+// the given headers. Calls without the user header fail.
+func EnableImpersonation(headers ImpersonationHeaders) {
+	impersonation.Store(&headers)
+}
+
+// DisableImpersonation turns impersonation off again
+func DisableImpersonation() {
+	impersonation.Store(nil)
+}
+
+// ImpersonationEnabled reports whether impersonation is on
+func ImpersonationEnabled() bool {
+	return impersonation.Load() != nil
+}
+
+// ImpersonationForRequest reads the impersonated identity from the trusted headers. The
+// headers are only read when the request authenticated as a trusted proxy; anyone else could
+// otherwise name any user and group. The group header may be repeated and each value may
+// list several comma separated groups.
+func ImpersonationForRequest(ctx context.Context, header http.Header) (Impersonation, error) {
+	headers := impersonation.Load()
+	if headers == nil {
+		return Impersonation{}, nil
+	}
+
+	principal, ok := identity.PrincipalFromContext(ctx)
+	if !ok || !trusted(headers.TrustedProxies, principal.Subject) {
+		return Impersonation{}, ErrUntrustedImpersonation
+	}
+
+	imp := Impersonation{User: strings.TrimSpace(header.Get(headers.User))}
+	if imp.User == "" {
+		return Impersonation{}, ErrImpersonationRequired
+	}
+	if headers.Group != "" {
+		for _, value := range header.Values(headers.Group) {
+			for _, group := range strings.Split(value, ",") {
+				if group = strings.TrimSpace(group); group != "" {
+					imp.Groups = append(imp.Groups, group)
+				}
+			}
+		}
+	}
+	return imp, nil
+}
+
+func trusted(proxies []string, subject string) bool {
+	if subject == "" {
+		return false
+	}
+	for _, pattern := range proxies {
+		if ok, _ := path.Match(pattern, subject); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// WithImpersonation returns a context whose commands and clients impersonate imp
+func WithImpersonation(ctx context.Context, imp Impersonation) context.Context {
+	return context.WithValue(ctx, impersonationKey{}, imp)
+}
+
+// ImpersonationFromContext returns the identity the current call impersonates, if any
+func ImpersonationFromContext(ctx context.Context) (Impersonation, bool) {
+	imp, ok := ctx.Value(impersonationKey{}).(Impersonation)
+	return imp, ok && imp.User != ""
+}
+
+// PassthroughEnabled reports whether TOKEN_PASSTHROUGH is on
+func PassthroughEnabled() bool {
+	return os.Getenv(EnvTokenPassthrough) == "true"
+}
+
+// BearerToken extracts the bearer token from the Authorization header
+func BearerToken(header http.Header) string {
+	if token, ok := strings.CutPrefix(header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+// ForRequest returns the token a call must use: the caller's bearer token when passthrough
+// is on, or an empty token when it is off
+func ForRequest(header http.Header) (string, error) {
+	if !PassthroughEnabled() {
+		return "", nil
+	}
+	token := BearerToken(header)
+	if token == "" {
+		return "", ErrTokenRequired
+	}
+	return token, nil
+}
+
+// WithToken returns a context whose commands and clients authenticate with token
+func WithToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, tokenKey{}, token)
+}
+
+// TokenFromContext returns the token stored for the current call, if any
+func TokenFromContext(ctx context.Context) string {
+	token, _ := ctx.Value(tokenKey{}).(string)
+	return token
+}
+
+// Required returns an error when passthrough or impersonation is on and the call does not
+// carry the caller's identity
+func Required(ctx context.Context) error {
+	if PassthroughEnabled() && TokenFromContext(ctx) == "" {
+		return ErrTokenRequired
+	}
+	if _, ok := ImpersonationFromContext(ctx); ImpersonationEnabled() && !ok {
+		return ErrImpersonationRequired
+	}
+	return nil
+}
+
+// RESTConfig returns a copy of config that authenticates with token only. The server's own
+// credentials (client certificates, exec plugins, its token) are dropped so the call runs
+// purely as the caller.
+func RESTConfig(config *rest.Config, token string) *rest.Config {
+	tokenConfig := rest.AnonymousClientConfig(config)
+	tokenConfig.BearerToken = token
+	return tokenConfig
+}
+
+// ImpersonatingConfig returns a copy of config that keeps the server's credentials and
+// impersonates imp
+func ImpersonatingConfig(config *rest.Config, imp Impersonation) *rest.Config {
+	impersonating := rest.CopyConfig(config)
+	impersonating.Impersonate = rest.ImpersonationConfig{UserName: imp.User, Groups: imp.Groups}
+	return impersonating
+}
+
+// Kubeconfig renders config as a kubeconfig, including its credentials and impersonation,
+// for CLIs that have no flags for them
+func Kubeconfig(config *rest.Config) ([]byte, error) {
+	cluster := clientcmdapi.NewCluster()
+	cluster.Server = config.Host
+	cluster.CertificateAuthority = config.CAFile
+	cluster.CertificateAuthorityData = config.CAData
+	cluster.InsecureSkipTLSVerify = config.Insecure
+	cluster.TLSServerName = config.ServerName
+	cluster.ProxyURL = proxyURL(config)
+
+	user := clientcmdapi.NewAuthInfo()
+	user.Token = config.BearerToken
+	user.TokenFile = config.BearerTokenFile
+	user.ClientCertificate = config.CertFile
+	user.ClientCertificateData = config.CertData
+	user.ClientKey = config.KeyFile
+	user.ClientKeyData = config.KeyData
+	user.Username = config.Username
+	user.Password = config.Password
+	user.Exec = config.ExecProvider
+	user.AuthProvider = config.AuthProvider
+	user.Impersonate = config.Impersonate.UserName
+	user.ImpersonateUID = config.Impersonate.UID
+	user.ImpersonateGroups = config.Impersonate.Groups
+	user.ImpersonateUserExtra = config.Impersonate.Extra
+
+	kubeconfig := clientcmdapi.NewConfig()
+	kubeconfig.Clusters["cluster"] = cluster
+	kubeconfig.AuthInfos["caller"] = user
+	kubeconfig.Contexts["caller"] = &clientcmdapi.Context{Cluster: "cluster", AuthInfo: "caller"}
+	kubeconfig.CurrentContext = "caller"
+	return clientcmd.Write(*kubeconfig)
+}
+
+func proxyURL(config *rest.Config) string {
+	if config.Proxy == nil {
+		return ""
+	}
+	req, err := http.NewRequest(http.MethodGet, config.Host, nil)
+	if err != nil {
+		return ""
+	}
+	proxy, err := config.Proxy(req)
+	if err != nil || proxy == nil {
+		return ""
+	}
+	return proxy.String()
+}
+
+// Fingerprint identifies a token without revealing it, e.g. to keep cached results of
+// different callers apart
+func Fingerprint(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:8])
+}
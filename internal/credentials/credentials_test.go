@@ -0,0 +1,128 @@
+package credentials
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/kagent-dev/tools/internal/identity"
+)
+
+func TestForRequest(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "Basic dXNlcjpwYXNz")
+
+	token, err := ForRequest(header)
+	require.NoError(t, err)
+	assert.Empty(t, token, "no token is used while passthrough is off")
+
+	t.Setenv(EnvTokenPassthrough, "true")
+	_, err = ForRequest(header)
+	assert.ErrorIs(t, err, ErrTokenRequired)
+
+	header.Set("Authorization", "Bearer caller-token")
+	token, err = ForRequest(header)
+	require.NoError(t, err)
+	assert.Equal(t, "caller-token", token)
+}
+
+func TestRequired(t *testing.T) {
+	assert.NoError(t, Required(context.Background()))
+
+	t.Setenv(EnvTokenPassthrough, "true")
+	assert.ErrorIs(t, Required(context.Background()), ErrTokenRequired)
+	assert.NoError(t, Required(WithToken(context.Background(), "caller-token")))
+}
+
+func TestRESTConfigAndKubeconfig(t *testing.T) {
+	server := &rest.Config{
+		Host:            "https://prod.example.com:6443",
+		BearerToken:     "server-token",
+		TLSClientConfig: rest.TLSClientConfig{CAData: []byte("ca"), CertData: []byte("cert"), KeyData: []byte("key")},
+	}
+
+	config := RESTConfig(server, "caller-token")
+	assert.Equal(t, "caller-token", config.BearerToken)
+	assert.Empty(t, config.CertData)
+	assert.Equal(t, []byte("ca"), config.CAData)
+	assert.Equal(t, "server-token", server.BearerToken, "the server config is left alone")
+
+	data, err := Kubeconfig(config)
+	require.NoError(t, err)
+	kubeconfig, err := clientcmd.Load(data)
+	require.NoError(t, err)
+	context := kubeconfig.Contexts[kubeconfig.CurrentContext]
+	assert.Equal(t, "https://prod.example.com:6443", kubeconfig.Clusters[context.Cluster].Server)
+	assert.Equal(t, "caller-token", kubeconfig.AuthInfos[context.AuthInfo].Token)
+	assert.Empty(t, kubeconfig.AuthInfos[context.AuthInfo].ClientCertificateData)
+
+	impersonating := ImpersonatingConfig(server, Impersonation{User: "alice", Groups: []string{"sre"}})
+	assert.Equal(t, "server-token", impersonating.BearerToken, "impersonation keeps the server's credentials")
+	assert.Empty(t, server.Impersonate.UserName)
+
+	data, err = Kubeconfig(impersonating)
+	require.NoError(t, err)
+	kubeconfig, err = clientcmd.Load(data)
+	require.NoError(t, err)
+	user := kubeconfig.AuthInfos[kubeconfig.Contexts[kubeconfig.CurrentContext].AuthInfo]
+	assert.Equal(t, "alice", user.Impersonate)
+	assert.Equal(t, []string{"sre"}, user.ImpersonateGroups)
+	assert.Equal(t, []byte("cert"), user.ClientCertificateData)
+}
+
+func TestImpersonationForRequest(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-Remote-User", "alice")
+	header.Add("X-Remote-Group", "sre, oncall")
+	header.Add("X-Remote-Group", "dev")
+	gateway := identity.WithPrincipal(context.Background(), identity.Principal{Subject: "spiffe://cluster.local/ns/kagent/sa/gateway", Method: "mtls"})
+
+	imp, err := ImpersonationForRequest(gateway, header)
+	require.NoError(t, err)
+	assert.Empty(t, imp.User, "nothing is impersonated while impersonation is off")
+
+	EnableImpersonation(ImpersonationHeaders{User: "X-Remote-User", Group: "X-Remote-Group", TrustedProxies: []string{"spiffe://cluster.local/ns/kagent/sa/*"}})
+	defer DisableImpersonation()
+
+	imp, err = ImpersonationForRequest(gateway, header)
+	require.NoError(t, err)
+	assert.Equal(t, Impersonation{User: "alice", Groups: []string{"sre", "oncall", "dev"}}, imp)
+
+	_, err = ImpersonationForRequest(gateway, http.Header{})
+	assert.ErrorIs(t, err, ErrImpersonationRequired)
+
+	assert.ErrorIs(t, Required(context.Background()), ErrImpersonationRequired)
+	assert.NoError(t, Required(WithImpersonation(context.Background(), imp)))
+}
+
+func TestImpersonationForRequestRejectsUntrustedCallers(t *testing.T) {
+	EnableImpersonation(ImpersonationHeaders{User: "X-Remote-User", Group: "X-Remote-Group", TrustedProxies: []string{"gateway"}})
+	defer DisableImpersonation()
+
+	header := http.Header{}
+	header.Set("X-Remote-User", "admin")
+	header.Set("X-Remote-Group", "system:masters")
+
+	for name, ctx := range map[string]context.Context{
+		"unauthenticated":       context.Background(),
+		"another principal":     identity.WithPrincipal(context.Background(), identity.Principal{Subject: "agent", Method: "api-key"}),
+		"principal without one": identity.WithPrincipal(context.Background(), identity.Principal{}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			imp, err := ImpersonationForRequest(ctx, header)
+			assert.ErrorIs(t, err, ErrUntrustedImpersonation)
+			assert.Empty(t, imp.User)
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	assert.Equal(t, Fingerprint("a"), Fingerprint("a"))
+	assert.NotEqual(t, Fingerprint("a"), Fingerprint("b"))
+	assert.NotContains(t, Fingerprint("secret-token"), "secret")
+}
@@ -70,6 +70,31 @@ func (e *ToolError) ToMCPResult() *mcp.CallToolResult {
 	return mcp.NewToolResultError(message.String())
 }
 
+// CodeAndComponent returns the error code and component of a result built by ToMCPResult.
+// ok is false for results that carry no structured error.
+func CodeAndComponent(result *mcp.CallToolResult) (code, component string, ok bool) {
+	if result == nil || !result.IsError {
+		return "", "", false
+	}
+	for _, content := range result.Content {
+		text, isText := content.(mcp.TextContent)
+		if !isText {
+			continue
+		}
+		for _, line := range strings.Split(text.Text, "\n") {
+			if value, found := strings.CutPrefix(line, "**Error Code**: "); found {
+				code = value
+			} else if value, found := strings.CutPrefix(line, "❌ **"); found {
+				component = strings.TrimSuffix(value, " Error**")
+			}
+		}
+		if code != "" {
+			return code, component, true
+		}
+	}
+	return "", "", false
+}
+
 // NewToolError creates a new structured tool error
 func NewToolError(component, operation string, cause error) *ToolError {
 	return &ToolError{
@@ -244,6 +269,63 @@ func NewPrometheusError(operation string, cause error) *ToolError {
 	return err
 }
 
+// NewAlertmanagerError creates an Alertmanager-specific error
+func NewAlertmanagerError(operation string, cause error) *ToolError {
+	err := NewToolError("Alertmanager", operation, cause)
+
+	causeStr := cause.Error()
+	if strings.Contains(causeStr, "connection refused") {
+		err = err.WithSuggestions(
+			"Check if Alertmanager is running",
+			"Verify the Alertmanager URL",
+			"Check network connectivity",
+		).WithRetryable(true).WithErrorCode("ALERTMANAGER_CONNECTION_ERROR")
+	} else if strings.Contains(causeStr, "(400)") || strings.Contains(causeStr, "(404)") {
+		err = err.WithSuggestions(
+			"Check the matchers, e.g. alertname=\"HighLatency\" or namespace=~\"prod-.*\"",
+			"Verify the silence ID with alertmanager_list_silences",
+		).WithRetryable(false).WithErrorCode("ALERTMANAGER_REQUEST_ERROR")
+	} else {
+		err = err.WithSuggestions(
+			"Check Alertmanager status",
+			"Check authentication if required",
+		).WithRetryable(true).WithErrorCode("ALERTMANAGER_GENERIC_ERROR")
+	}
+
+	return err
+}
+
+// NewGrafanaError creates a Grafana-specific error
+func NewGrafanaError(operation string, cause error) *ToolError {
+	err := NewToolError("Grafana", operation, cause)
+
+	causeStr := cause.Error()
+	if strings.Contains(causeStr, "connection refused") {
+		err = err.WithSuggestions(
+			"Check if Grafana is running",
+			"Verify the Grafana URL",
+			"Check network connectivity",
+		).WithRetryable(true).WithErrorCode("GRAFANA_CONNECTION_ERROR")
+	} else if strings.Contains(causeStr, "(401)") || strings.Contains(causeStr, "(403)") {
+		err = err.WithSuggestions(
+			"Check the Grafana API key or service account token",
+			"Verify the token's role allows the operation, e.g. Editor to create annotations",
+		).WithRetryable(false).WithErrorCode("GRAFANA_AUTH_ERROR")
+	} else if strings.Contains(causeStr, "(404)") {
+		err = err.WithSuggestions(
+			"Verify the dashboard UID with grafana_search_dashboards",
+			"Verify the panel ID with grafana_get_dashboard",
+		).WithRetryable(false).WithErrorCode("GRAFANA_NOT_FOUND")
+	} else {
+		err = err.WithSuggestions(
+			"Check Grafana status",
+			"Check authentication if required",
+		).WithRetryable(true).WithErrorCode("GRAFANA_GENERIC_ERROR")
+	}
+
+	return err
+}
+
 // NewArgoError creates an Argo-specific error
 func NewArgoError(operation string, cause error) *ToolError {
 	err := NewToolError("Argo Rollouts", operation, cause)
@@ -421,3 +503,16 @@ func NewCommandError(command string, cause error) *ToolError {
 
 	return err
 }
+
+// NewPolicyError creates an error for a tool call rejected by the tool policy. The code is
+// POLICY_DENIED or POLICY_LIMIT_EXCEEDED and rule names the policy entry that matched.
+func NewPolicyError(tool, code, rule, reason string) *ToolError {
+	err := NewToolError("Policy", fmt.Sprintf("call %s", tool), fmt.Errorf("%s", reason))
+
+	err = err.WithSuggestions(
+		"Do not retry the same call, the policy will reject it again",
+		"Ask the platform team to change the tool policy if the call is required",
+	).WithRetryable(false).WithErrorCode(code).WithContext("rule", rule)
+
+	return err
+}
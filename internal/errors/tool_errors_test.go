@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -107,6 +108,26 @@ func TestToolErrorToMCPResult(t *testing.T) {
 	}
 }
 
+func TestCodeAndComponent(t *testing.T) {
+	result := NewToolError("Istio", "proxy status", errors.New("timed out")).
+		WithErrorCode("ISTIO_CONNECTION_ERROR").
+		ToMCPResult()
+
+	code, component, ok := CodeAndComponent(result)
+	assert.True(t, ok)
+	assert.Equal(t, "ISTIO_CONNECTION_ERROR", code)
+	assert.Equal(t, "Istio", component)
+
+	_, _, ok = CodeAndComponent(mcp.NewToolResultError("kubectl: resource not found"))
+	assert.False(t, ok)
+
+	_, _, ok = CodeAndComponent(mcp.NewToolResultText("**Error Code**: NOT_AN_ERROR"))
+	assert.False(t, ok)
+
+	_, _, ok = CodeAndComponent(nil)
+	assert.False(t, ok)
+}
+
 func TestNewKubernetesError(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -234,6 +255,22 @@ func TestNewPrometheusError(t *testing.T) {
 	assert.Equal(t, cause, err.Cause)
 }
 
+func TestNewAlertmanagerError(t *testing.T) {
+	err := NewAlertmanagerError("create_silence", errors.New("Alertmanager API error (400): bad matcher"))
+
+	assert.Equal(t, "Alertmanager", err.Component)
+	assert.Equal(t, "ALERTMANAGER_REQUEST_ERROR", err.ErrorCode)
+	assert.False(t, err.IsRetryable)
+}
+
+func TestNewGrafanaError(t *testing.T) {
+	err := NewGrafanaError("create_annotation", errors.New("Grafana API error (403): permission denied"))
+
+	assert.Equal(t, "Grafana", err.Component)
+	assert.Equal(t, "GRAFANA_AUTH_ERROR", err.ErrorCode)
+	assert.False(t, err.IsRetryable)
+}
+
 func TestNewArgoError(t *testing.T) {
 	cause := errors.New("test error")
 	err := NewArgoError("test operation", cause)
@@ -262,6 +299,17 @@ func TestNewValidationError(t *testing.T) {
 	assert.Contains(t, err.Cause.Error(), "validation failed")
 }
 
+func TestNewPolicyError(t *testing.T) {
+	err := NewPolicyError("k8s_scale", "POLICY_LIMIT_EXCEEDED", "replica-cap", "replicas 50 exceeds the limit of 10")
+
+	assert.Equal(t, "Policy", err.Component)
+	assert.Equal(t, "call k8s_scale", err.Operation)
+	assert.Equal(t, "POLICY_LIMIT_EXCEEDED", err.ErrorCode)
+	assert.Equal(t, "replica-cap", err.Context["rule"])
+	assert.False(t, err.IsRetryable)
+	assert.Contains(t, err.Cause.Error(), "exceeds the limit")
+}
+
 func TestNewSecurityError(t *testing.T) {
 	cause := errors.New("security violation")
 	err := NewSecurityError("test operation", cause)
@@ -0,0 +1,57 @@
+package httpclient
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kagent-dev/tools/internal/security"
+)
+
+// ApplyEnv sets the fields left empty from the environment variables they map to
+func ApplyEnv(fields map[*string]string) {
+	for field, env := range fields {
+		if *field == "" {
+			*field = strings.TrimSpace(os.Getenv(env))
+		}
+	}
+}
+
+// NormalizeURL returns the base URL of an API: defaultURL when rawURL is empty, with http://
+// added to a bare host:port and without a trailing slash. The URL is validated.
+func NormalizeURL(rawURL, defaultURL string) (string, error) {
+	if rawURL == "" {
+		rawURL = defaultURL
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+	rawURL = strings.TrimSuffix(rawURL, "/")
+	if err := security.ValidateURL(rawURL); err != nil {
+		return "", err
+	}
+	return rawURL, nil
+}
+
+// configKey is the context key for a config of type T
+type configKey[T any] struct{}
+
+// ContextWithConfig returns a copy of ctx holding config
+func ContextWithConfig[T any](ctx context.Context, config *T) context.Context {
+	return context.WithValue(ctx, configKey[T]{}, config)
+}
+
+// ConfigFromContext returns the config of type T held by ctx, nil when there is none
+func ConfigFromContext[T any](ctx context.Context) *T {
+	config, _ := ctx.Value(configKey[T]{}).(*T)
+	return config
+}
+
+// WithConfig makes config available to handler through ConfigFromContext
+func WithConfig[T any](config *T, handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error)) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handler(ContextWithConfig(ctx, config), request)
+	}
+}
@@ -0,0 +1,51 @@
+package httpclient
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyEnv(t *testing.T) {
+	t.Setenv("TEST_HTTPCLIENT_URL", " http://from-env:9093 ")
+	t.Setenv("TEST_HTTPCLIENT_USERNAME", "env-user")
+
+	url, username := "", "flag-user"
+	ApplyEnv(map[*string]string{&url: "TEST_HTTPCLIENT_URL", &username: "TEST_HTTPCLIENT_USERNAME"})
+	assert.Equal(t, "http://from-env:9093", url)
+	assert.Equal(t, "flag-user", username, "set fields are kept")
+}
+
+func TestNormalizeURL(t *testing.T) {
+	for rawURL, want := range map[string]string{
+		"":                           "http://localhost:9093",
+		"alertmanager:9093":          "http://alertmanager:9093",
+		"https://grafana.example/":   "https://grafana.example",
+		"http://mimir/alertmanager/": "http://mimir/alertmanager",
+	} {
+		got, err := NormalizeURL(rawURL, "http://localhost:9093")
+		require.NoError(t, err, rawURL)
+		assert.Equal(t, want, got, rawURL)
+	}
+
+	_, err := NormalizeURL("ftp://example.com", "")
+	assert.Error(t, err)
+}
+
+func TestWithConfig(t *testing.T) {
+	type config struct{ URL string }
+	type other struct{ URL string }
+
+	assert.Nil(t, ConfigFromContext[config](context.Background()))
+
+	want := &config{URL: "http://alertmanager:9093"}
+	_, err := WithConfig(want, func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		assert.Same(t, want, ConfigFromContext[config](ctx))
+		assert.Nil(t, ConfigFromContext[other](ctx), "configs of other types are kept apart")
+		return nil, nil
+	})(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+}
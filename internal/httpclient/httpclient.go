@@ -0,0 +1,98 @@
+// Package httpclient builds the HTTP clients the tools use to reach monitoring APIs such as
+// Prometheus and Alertmanager with basic auth, bearer tokens, custom CAs and extra headers.
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultTenantHeader carries the tenant of Mimir, Cortex and Loki
+const DefaultTenantHeader = "X-Scope-OrgID"
+
+// DefaultTimeout bounds the requests of clients whose options set no timeout, so that a
+// hung server does not block a tool call
+const DefaultTimeout = 30 * time.Second
+
+// Default is the client of URLs passed to a tool call, which carries no credentials
+var Default = &http.Client{Timeout: DefaultTimeout}
+
+// Options configures the credentials, TLS and headers of a client. The bearer token is read
+// from BearerTokenFile on every request so that rotated tokens are picked up.
+type Options struct {
+	Username           string
+	Password           string
+	BearerTokenFile    string
+	CAFile             string
+	InsecureSkipVerify bool
+	Headers            map[string]string
+	TenantID           string
+	// TenantHeader carries TenantID, DefaultTenantHeader when empty
+	TenantHeader string
+	// Timeout bounds every request, DefaultTimeout when zero
+	Timeout time.Duration
+}
+
+// New returns a client that adds the credentials and headers of options to every request
+func New(options Options) (*http.Client, error) {
+	if options.Username != "" && options.BearerTokenFile != "" {
+		return nil, errors.New("set either a username or a bearer token file, not both")
+	}
+	if options.TenantID != "" && options.TenantHeader == "" {
+		options.TenantHeader = DefaultTenantHeader
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = DefaultTimeout
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if options.CAFile != "" || options.InsecureSkipVerify {
+		tlsConfig := &tls.Config{InsecureSkipVerify: options.InsecureSkipVerify} //nolint:gosec // opted into per endpoint
+		if options.CAFile != "" {
+			ca, err := os.ReadFile(options.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA file: %w", err)
+			}
+			tlsConfig.RootCAs = x509.NewCertPool()
+			if !tlsConfig.RootCAs.AppendCertsFromPEM(ca) {
+				return nil, fmt.Errorf("CA file %s holds no PEM certificates", options.CAFile)
+			}
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	return &http.Client{Transport: &authTransport{options: options, base: transport}, Timeout: options.Timeout}, nil
+}
+
+// authTransport adds the credentials and headers of options to requests
+type authTransport struct {
+	options Options
+	base    http.RoundTripper
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	options := t.options
+	req = req.Clone(req.Context())
+	for name, value := range options.Headers {
+		req.Header.Set(name, value)
+	}
+	if options.TenantID != "" {
+		req.Header.Set(options.TenantHeader, options.TenantID)
+	}
+	switch {
+	case options.Username != "":
+		req.SetBasicAuth(options.Username, options.Password)
+	case options.BearerTokenFile != "":
+		token, err := os.ReadFile(options.BearerTokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bearer token file: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+	return t.base.RoundTrip(req)
+}
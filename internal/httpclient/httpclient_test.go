@@ -0,0 +1,78 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+	}))
+	defer server.Close()
+
+	get := func(t *testing.T, options Options) {
+		t.Helper()
+		client, err := New(options)
+		require.NoError(t, err)
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	t.Run("basic auth, headers and tenant", func(t *testing.T) {
+		get(t, Options{Username: "kagent", Password: "secret", Headers: map[string]string{"X-Org": "ops"}, TenantID: "team-a"})
+		user, password, ok := got.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "kagent", user)
+		assert.Equal(t, "secret", password)
+		assert.Equal(t, "ops", got.Header.Get("X-Org"))
+		assert.Equal(t, "team-a", got.Header.Get(DefaultTenantHeader))
+	})
+
+	t.Run("bearer token is read on every request", func(t *testing.T) {
+		token := filepath.Join(t.TempDir(), "token")
+		require.NoError(t, os.WriteFile(token, []byte("first\n"), 0o600))
+		client, err := New(Options{BearerTokenFile: token})
+		require.NoError(t, err)
+
+		for _, want := range []string{"first", "second"} {
+			require.NoError(t, os.WriteFile(token, []byte(want), 0o600))
+			resp, err := client.Get(server.URL)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, "Bearer "+want, got.Header.Get("Authorization"))
+		}
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		notPEM := filepath.Join(t.TempDir(), "ca.pem")
+		require.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0o600))
+		for name, options := range map[string]Options{
+			"basic and bearer": {Username: "kagent", BearerTokenFile: "/token"},
+			"missing CA file":  {CAFile: "/does/not/exist"},
+			"CA file no PEM":   {CAFile: notPEM},
+		} {
+			_, err := New(options)
+			assert.Error(t, err, name)
+		}
+	})
+
+	t.Run("requests time out", func(t *testing.T) {
+		client, err := New(Options{})
+		require.NoError(t, err)
+		assert.Equal(t, DefaultTimeout, client.Timeout)
+
+		client, err = New(Options{Timeout: time.Minute})
+		require.NoError(t, err)
+		assert.Equal(t, time.Minute, client.Timeout)
+	})
+}
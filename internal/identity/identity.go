@@ -0,0 +1,141 @@
+package identity
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kagent-dev/tools/internal/telemetry"
+)
+
+// Sources an identity can come from
+const (
+	SourceAuthenticated = "authenticated"
+	SourceToken         = "token"
+	SourceProxy         = "proxy"
+	SourceSession       = "session"
+	SourceAnonymous     = "anonymous"
+)
+
+// proxyHeaders are set by authenticating proxies in front of the server
+var proxyHeaders = []string{"X-Remote-User", "X-Forwarded-User", "X-Forwarded-Email"}
+
+// Identity describes who made a tool call, as far as the server can tell
+type Identity struct {
+	Subject       string   `json:"subject,omitempty"`
+	Source        string   `json:"source"`
+	ClientName    string   `json:"client_name,omitempty"`
+	ClientVersion string   `json:"client_version,omitempty"`
+	SessionID     string   `json:"session_id,omitempty"`
+	Method        string   `json:"method,omitempty"`
+	Roles         []string `json:"roles,omitempty"`
+}
+
+// Principal is a caller the HTTP transport authenticated, with the roles it was granted
+type Principal struct {
+	Subject string
+	Method  string
+	Roles   []string
+}
+
+type principalKey struct{}
+
+// WithPrincipal stores the authenticated principal of a request in its context
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the authenticated principal, if the request was authenticated
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
+
+// String returns the subject, falling back to the session ID
+func (i Identity) String() string {
+	switch {
+	case i.Subject != "":
+		return i.Subject
+	case i.SessionID != "":
+		return "session:" + i.SessionID
+	}
+	return SourceAnonymous
+}
+
+// Resolve identifies the caller of a tool call. A principal authenticated by the HTTP
+// transport wins; otherwise the bearer token subject, the headers of an authenticating
+// proxy and the MCP session are used, in that order. The token signature is not checked
+// in that case, so the subject is only good for attribution.
+func Resolve(ctx context.Context, header http.Header) Identity {
+	id := Identity{Source: SourceAnonymous}
+
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		id.SessionID = session.SessionID()
+		if withInfo, ok := session.(server.SessionWithClientInfo); ok {
+			info := withInfo.GetClientInfo()
+			id.ClientName, id.ClientVersion = info.Name, info.Version
+		}
+		if id.SessionID != "" {
+			id.Source = SourceSession
+		}
+	}
+
+	if principal, ok := PrincipalFromContext(ctx); ok {
+		id.Subject, id.Source = principal.Subject, SourceAuthenticated
+		id.Method, id.Roles = principal.Method, principal.Roles
+		return id
+	}
+
+	authorization := header.Get("Authorization")
+	if authorization == "" {
+		authorization = telemetry.ExtractHTTPHeaders(ctx)["Authorization"]
+	}
+	if subject := tokenSubject(authorization); subject != "" {
+		id.Subject, id.Source = subject, SourceToken
+		return id
+	}
+
+	for _, name := range proxyHeaders {
+		if value := header.Get(name); value != "" {
+			id.Subject, id.Source = value, SourceProxy
+			return id
+		}
+	}
+	return id
+}
+
+// tokenSubject returns the subject of a JWT bearer token, or its client ID for tokens
+// issued to a client rather than a user
+func tokenSubject(authorization string) string {
+	token, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok {
+		return ""
+	}
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ""
+	}
+
+	var claims struct {
+		Subject  string `json:"sub"`
+		ClientID string `json:"client_id"`
+		Azp      string `json:"azp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return ""
+	}
+	for _, subject := range []string{claims.Subject, claims.ClientID, claims.Azp} {
+		if subject != "" {
+			return subject
+		}
+	}
+	return ""
+}
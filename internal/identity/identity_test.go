@@ -0,0 +1,85 @@
+package identity
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kagent-dev/tools/internal/telemetry"
+)
+
+func bearer(claims string) string {
+	return "Bearer " + base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".sig"
+}
+
+type testSession struct{}
+
+func (testSession) Initialize()                                         {}
+func (testSession) Initialized() bool                                   { return true }
+func (testSession) SessionID() string                                   { return "abc" }
+func (testSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return nil }
+func (testSession) GetClientInfo() mcp.Implementation {
+	return mcp.Implementation{Name: "kagent", Version: "0.7.0"}
+}
+func (testSession) SetClientInfo(mcp.Implementation)              {}
+func (testSession) GetClientCapabilities() mcp.ClientCapabilities { return mcp.ClientCapabilities{} }
+func (testSession) SetClientCapabilities(mcp.ClientCapabilities)  {}
+
+func TestResolve(t *testing.T) {
+	id := Resolve(context.Background(), http.Header{})
+	assert.Equal(t, SourceAnonymous, id.Source)
+	assert.Equal(t, "anonymous", id.String())
+
+	s := server.NewMCPServer("test", "v0.0.1")
+	ctx := s.WithContext(context.Background(), testSession{})
+	id = Resolve(ctx, http.Header{})
+	assert.Equal(t, SourceSession, id.Source)
+	assert.Equal(t, "session:abc", id.String())
+	assert.Equal(t, "kagent", id.ClientName)
+	assert.Equal(t, "0.7.0", id.ClientVersion)
+
+	header := http.Header{}
+	header.Set("X-Forwarded-User", "alice")
+	id = Resolve(ctx, header)
+	assert.Equal(t, SourceProxy, id.Source)
+	assert.Equal(t, "alice", id.String())
+	assert.Equal(t, "abc", id.SessionID)
+
+	header.Set("Authorization", bearer(`{"sub":"system:serviceaccount:kagent:agent"}`))
+	id = Resolve(ctx, header)
+	assert.Equal(t, SourceToken, id.Source)
+	assert.Equal(t, "system:serviceaccount:kagent:agent", id.Subject)
+}
+
+func TestResolveFromTelemetryHeaders(t *testing.T) {
+	ctx := context.WithValue(context.Background(), telemetry.HTTPHeadersKey, map[string]string{
+		"Authorization": bearer(`{"client_id":"ci-bot"}`),
+	})
+	assert.Equal(t, "ci-bot", Resolve(ctx, nil).Subject)
+}
+
+func TestTokenSubject(t *testing.T) {
+	assert.Equal(t, "bob", tokenSubject(bearer(`{"sub":"bob","azp":"cli"}`)))
+	assert.Equal(t, "cli", tokenSubject(bearer(`{"azp":"cli"}`)))
+	assert.Empty(t, tokenSubject("Bearer opaque-token"))
+	assert.Empty(t, tokenSubject("Basic Ym9iOnB3"))
+	assert.Empty(t, tokenSubject("Bearer a.!!!.c"))
+}
+
+func TestResolvePrincipal(t *testing.T) {
+	ctx := WithPrincipal(context.Background(), Principal{Subject: "ci-bot", Method: "api-key", Roles: []string{"viewer"}})
+	header := http.Header{}
+	header.Set("X-Remote-User", "mallory")
+
+	id := Resolve(ctx, header)
+	assert.Equal(t, SourceAuthenticated, id.Source)
+	assert.Equal(t, "ci-bot", id.String())
+	assert.Equal(t, "api-key", id.Method)
+	assert.Equal(t, []string{"viewer"}, id.Roles)
+}
@@ -60,7 +60,8 @@ func WithContext(ctx context.Context) *slog.Logger {
 }
 
 // RedactArgsForLog returns a copy of args with sensitive values redacted for logging.
-// Any value immediately following "--token" is replaced with "<REDACTED>" so tokens are not logged.
+// Any value immediately following "--token" or "--kube-token" is replaced with "<REDACTED>"
+// so tokens are not logged.
 func RedactArgsForLog(args []string) []string {
 	if len(args) == 0 {
 		return nil
@@ -68,7 +69,7 @@ func RedactArgsForLog(args []string) []string {
 	out := make([]string, len(args))
 	copy(out, args)
 	for i := 0; i < len(out)-1; i++ {
-		if out[i] == "--token" {
+		if out[i] == "--token" || out[i] == "--kube-token" {
 			out[i+1] = "<REDACTED>"
 			i++ // skip the redacted value
 		}
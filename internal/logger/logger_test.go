@@ -24,6 +24,10 @@ func TestRedactArgsForLog(t *testing.T) {
 		assert.Equal(t, "-n", redacted[4])
 		assert.Equal(t, "default", redacted[5])
 	})
+	t.Run("redacts helm kube-token value", func(t *testing.T) {
+		redacted := RedactArgsForLog([]string{"list", "--kube-token", "secret-token-123"})
+		assert.Equal(t, []string{"list", "--kube-token", "<REDACTED>"}, redacted)
+	})
 	t.Run("empty args returns nil", func(t *testing.T) {
 		assert.Nil(t, RedactArgsForLog(nil))
 		assert.Nil(t, RedactArgsForLog([]string{}))
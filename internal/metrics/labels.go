@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"context"
+	"path/filepath"
+)
+
+// knownCommands are the CLIs reported under their own name. Every other command, such as
+// those run by the shell tool, is reported as "other" to keep the label bounded.
+var knownCommands = map[string]bool{
+	"kubectl":  true,
+	"helm":     true,
+	"istioctl": true,
+	"cilium":   true,
+}
+
+// CommandLabel returns the command label value for a CLI
+func CommandLabel(command string) string {
+	if name := filepath.Base(command); knownCommands[name] {
+		return name
+	}
+	return "other"
+}
+
+type toolProviderKey struct{}
+
+// WithToolProvider returns a context that attributes the commands run during a tool call to
+// the tool's provider
+func WithToolProvider(ctx context.Context, provider string) context.Context {
+	return context.WithValue(ctx, toolProviderKey{}, provider)
+}
+
+// ToolProviderFromContext returns the provider of the tool being called, or "none" outside a
+// tool call
+func ToolProviderFromContext(ctx context.Context) string {
+	if provider, ok := ctx.Value(toolProviderKey{}).(string); ok && provider != "" {
+		return provider
+	}
+	return "none"
+}
@@ -47,6 +47,108 @@ var (
 		},
 		[]string{"tool_name", "tool_provider"},
 	)
+
+	KagentToolsMCPInvocationDurationSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "kagent_tools_mcp_invocation_duration_seconds",
+			Help:    "Duration of MCP tool invocations",
+			Buckets: []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+		},
+		[]string{"tool_name", "tool_provider"},
+	)
+
+	KagentToolsMCPInvocationErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kagent_tools_mcp_invocation_errors_total",
+			Help: "Total number of failed MCP tool invocations by error code and component (unstructured for plain error results, handler_error for Go errors)",
+		},
+		[]string{"tool_name", "tool_provider", "error_code", "component"},
+	)
+
+	KagentToolsMCPResultBytes = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "kagent_tools_mcp_result_bytes",
+			Help:    "Size of the content returned by MCP tool invocations",
+			Buckets: prometheus.ExponentialBuckets(256, 4, 9), // 256B to 16MiB
+		},
+		[]string{"tool_name", "tool_provider"},
+	)
+
+	// Result cache metrics, labeled by cache name (kubernetes, helm, istio, command, informer)
+	KagentToolsCacheHitsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kagent_tools_cache_hits_total",
+			Help: "Total number of cache lookups that found a result",
+		},
+		[]string{"cache"},
+	)
+
+	KagentToolsCacheMissesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kagent_tools_cache_misses_total",
+			Help: "Total number of cache lookups that found no usable result",
+		},
+		[]string{"cache"},
+	)
+
+	KagentToolsCacheEvictionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kagent_tools_cache_evictions_total",
+			Help: "Total number of results evicted from a cache because they expired or it was full",
+		},
+		[]string{"cache"},
+	)
+
+	KagentToolsCacheEntries = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kagent_tools_cache_entries",
+			Help: "Current number of results held by a cache",
+		},
+		[]string{"cache"},
+	)
+
+	KagentToolsCacheBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kagent_tools_cache_bytes",
+			Help: "Approximate memory held by the results of a cache",
+		},
+		[]string{"cache"},
+	)
+
+	// CLI subprocess metrics, labeled by command (see CommandLabel) and tool provider
+	KagentToolsCommandDurationSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "kagent_tools_command_duration_seconds",
+			Help:    "Duration of CLI subprocess executions",
+			Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+		},
+		[]string{"command", "tool_provider"},
+	)
+
+	KagentToolsCommandExecutionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kagent_tools_command_executions_total",
+			Help: "Total number of CLI subprocess executions by exit code (signal when killed, error when it could not start)",
+		},
+		[]string{"command", "tool_provider", "exit_code"},
+	)
+
+	KagentToolsCommandOutputBytes = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "kagent_tools_command_output_bytes",
+			Help:    "Size of the combined output of CLI subprocess executions",
+			Buckets: prometheus.ExponentialBuckets(256, 4, 9), // 256B to 16MiB
+		},
+		[]string{"command", "tool_provider"},
+	)
+
+	KagentToolsCommandsInFlight = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kagent_tools_commands_in_flight",
+			Help: "Current number of running CLI subprocesses",
+		},
+		[]string{"command", "tool_provider"},
+	)
 )
 
 func InitServer() *prometheus.Registry {
@@ -64,6 +166,20 @@ func InitServer() *prometheus.Registry {
 	registry.MustRegister(KagentToolsMCPRegisteredTools)
 	registry.MustRegister(KagentToolsMCPInvocationsTotal)
 	registry.MustRegister(KagentToolsMCPInvocationsFailureTotal)
+	registry.MustRegister(KagentToolsMCPInvocationDurationSeconds)
+	registry.MustRegister(KagentToolsMCPInvocationErrorsTotal)
+	registry.MustRegister(KagentToolsMCPResultBytes)
+
+	// Register cache and CLI subprocess metrics
+	registry.MustRegister(KagentToolsCacheHitsTotal)
+	registry.MustRegister(KagentToolsCacheMissesTotal)
+	registry.MustRegister(KagentToolsCacheEvictionsTotal)
+	registry.MustRegister(KagentToolsCacheEntries)
+	registry.MustRegister(KagentToolsCacheBytes)
+	registry.MustRegister(KagentToolsCommandDurationSeconds)
+	registry.MustRegister(KagentToolsCommandExecutionsTotal)
+	registry.MustRegister(KagentToolsCommandOutputBytes)
+	registry.MustRegister(KagentToolsCommandsInFlight)
 
 	return registry
 }
@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"context"
 	"testing"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -266,3 +267,85 @@ func TestMain(m *testing.M) {
 	resetMetrics()
 	m.Run()
 }
+
+func TestInitServer_RegistersCacheAndCommandMetrics(t *testing.T) {
+	registry := InitServer()
+
+	KagentToolsCacheHitsTotal.WithLabelValues("kubernetes").Inc()
+	KagentToolsCacheMissesTotal.WithLabelValues("kubernetes").Inc()
+	KagentToolsCacheEvictionsTotal.WithLabelValues("kubernetes").Inc()
+	KagentToolsCacheEntries.WithLabelValues("kubernetes").Set(1)
+	KagentToolsCacheBytes.WithLabelValues("kubernetes").Set(1024)
+	KagentToolsCommandDurationSeconds.WithLabelValues("kubectl", "k8s").Observe(0.2)
+	KagentToolsCommandExecutionsTotal.WithLabelValues("kubectl", "k8s", "0").Inc()
+	KagentToolsCommandOutputBytes.WithLabelValues("kubectl", "k8s").Observe(512)
+	KagentToolsCommandsInFlight.WithLabelValues("kubectl", "k8s").Set(0)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %v", err)
+	}
+
+	for _, name := range []string{
+		"kagent_tools_cache_hits_total",
+		"kagent_tools_cache_misses_total",
+		"kagent_tools_cache_evictions_total",
+		"kagent_tools_cache_entries",
+		"kagent_tools_cache_bytes",
+		"kagent_tools_command_duration_seconds",
+		"kagent_tools_command_executions_total",
+		"kagent_tools_command_output_bytes",
+		"kagent_tools_commands_in_flight",
+	} {
+		if findMetricFamily(families, name) == nil {
+			t.Errorf("Expected %s metric to be present", name)
+		}
+	}
+}
+
+func TestInitServer_RegistersInvocationMetrics(t *testing.T) {
+	registry := InitServer()
+
+	KagentToolsMCPInvocationDurationSeconds.WithLabelValues("istio_proxy_status", "istio").Observe(3)
+	KagentToolsMCPInvocationErrorsTotal.WithLabelValues("istio_proxy_status", "istio", "ISTIO_CONNECTION_ERROR", "Istio").Inc()
+	KagentToolsMCPResultBytes.WithLabelValues("istio_proxy_status", "istio").Observe(2048)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %v", err)
+	}
+
+	for _, name := range []string{
+		"kagent_tools_mcp_invocation_duration_seconds",
+		"kagent_tools_mcp_invocation_errors_total",
+		"kagent_tools_mcp_result_bytes",
+	} {
+		if findMetricFamily(families, name) == nil {
+			t.Errorf("Expected %s metric to be present", name)
+		}
+	}
+}
+
+func TestCommandLabel(t *testing.T) {
+	tests := map[string]string{
+		"kubectl":             "kubectl",
+		"/usr/local/bin/helm": "helm",
+		"istioctl":            "istioctl",
+		"cilium":              "cilium",
+		"rm":                  "other",
+	}
+	for command, expected := range tests {
+		if label := CommandLabel(command); label != expected {
+			t.Errorf("CommandLabel(%q): expected %q, got %q", command, expected, label)
+		}
+	}
+}
+
+func TestToolProviderFromContext(t *testing.T) {
+	if provider := ToolProviderFromContext(context.Background()); provider != "none" {
+		t.Errorf("Expected provider none outside a tool call, got %q", provider)
+	}
+	if provider := ToolProviderFromContext(WithToolProvider(context.Background(), "k8s")); provider != "k8s" {
+		t.Errorf("Expected provider k8s, got %q", provider)
+	}
+}
@@ -0,0 +1,520 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// Effect is what happens to a call matched by a rule
+type Effect string
+
+const (
+	EffectAllow Effect = "allow"
+	EffectDeny  Effect = "deny"
+)
+
+// Verbs a tool can be classified as
+const (
+	VerbRead  = "read"
+	VerbWrite = "write"
+)
+
+// Error codes returned for rejected calls
+const (
+	CodeDenied        = "POLICY_DENIED"
+	CodeLimitExceeded = "POLICY_LIMIT_EXCEEDED"
+)
+
+// DefaultWriteTools are the tools classified as the write verb unless the policy file
+// lists its own writeTools. Entries are path.Match patterns.
+var DefaultWriteTools = []string{
+	"k8s_scale",
+	"k8s_patch_*",
+	"k8s_apply_manifest",
+	"k8s_delete_resource",
+	"k8s_check_service_connectivity",
+	"k8s_execute_command",
+	"k8s_rollout",
+	"k8s_label_resource",
+	"k8s_annotate_resource",
+	"k8s_remove_*",
+	"k8s_create_*",
+	"helm_upgrade",
+	"helm_uninstall",
+	"helm_repo_*",
+	"istio_install_istio",
+	"istio_apply_waypoint",
+	"istio_delete_waypoint",
+	"argo_promote_rollout",
+	"argo_pause_rollout",
+	"argo_set_rollout_image",
+	"alertmanager_create_silence",
+	"alertmanager_expire_silence",
+	"grafana_create_annotation",
+	"cilium_install_cilium",
+	"cilium_upgrade_cilium",
+	"cilium_uninstall_cilium",
+	"cilium_connect_*",
+	"cilium_disconnect_*",
+	"cilium_toggle_*",
+	"cilium_update_*",
+	"cilium_delete_*",
+	"cilium_set_*",
+	"cilium_manage_*",
+	"cilium_flush_*",
+	"cache_invalidate",
+	"cache_set_ttl",
+	"shell",
+}
+
+// Policy is the parsed tool policy file.
+//
+// Rules are evaluated in order and the first rule whose conditions all match a call decides
+// its effect; calls that no rule matches get DefaultEffect. Limits are checked afterwards
+// for calls the rules allow.
+type Policy struct {
+	DefaultEffect Effect   `json:"defaultEffect,omitempty"`
+	WriteTools    []string `json:"writeTools,omitempty"`
+	Rules         []Rule   `json:"rules,omitempty"`
+	Limits        []Limit  `json:"limits,omitempty"`
+}
+
+// Rule allows or denies the calls that match all of its conditions. Conditions left empty
+// match every call. All values are path.Match patterns.
+type Rule struct {
+	Name          string   `json:"name,omitempty"`
+	Effect        Effect   `json:"effect"`
+	Tools         []string `json:"tools,omitempty"`
+	Verbs         []string `json:"verbs,omitempty"`
+	Clusters      []string `json:"clusters,omitempty"`
+	Namespaces    []string `json:"namespaces,omitempty"`
+	ResourceTypes []string `json:"resourceTypes,omitempty"`
+	Reason        string   `json:"reason,omitempty"`
+}
+
+// Limit caps a numeric argument of the matching tools, e.g. replicas of k8s_scale
+type Limit struct {
+	Name     string   `json:"name,omitempty"`
+	Tools    []string `json:"tools"`
+	Argument string   `json:"argument"`
+	Max      float64  `json:"max"`
+	Reason   string   `json:"reason,omitempty"`
+}
+
+// Target is one object a call touches. Calls taking a manifest have a target per object.
+type Target struct {
+	Namespace     string
+	AllNamespaces bool
+	ResourceType  string
+}
+
+// DefaultNamespace is the namespace of calls that name none, as the tools fall back to it
+const DefaultNamespace = "default"
+
+// allNamespacesTools span every namespace when a call names none
+var allNamespacesTools = []string{"k8s_get_events", "k8s_watch_events"}
+
+// Call is the part of a tool call the policy looks at
+type Call struct {
+	Tool      string
+	Cluster   string
+	Targets   []Target
+	Arguments map[string]any
+}
+
+// Decision is the outcome of evaluating a call
+type Decision struct {
+	Allowed bool
+	Code    string
+	Rule    string
+	Reason  string
+}
+
+// Load reads and validates a policy file
+func Load(file string) (*Policy, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse parses and validates a YAML or JSON policy
+func Parse(data []byte) (*Policy, error) {
+	var p Policy
+	if err := yaml.UnmarshalStrict(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+
+	if p.DefaultEffect == "" {
+		p.DefaultEffect = EffectAllow
+	}
+	if p.DefaultEffect != EffectAllow && p.DefaultEffect != EffectDeny {
+		return nil, fmt.Errorf("defaultEffect must be allow or deny, got %q", p.DefaultEffect)
+	}
+	if len(p.WriteTools) == 0 {
+		p.WriteTools = DefaultWriteTools
+	}
+	if err := validatePatterns("writeTools", p.WriteTools); err != nil {
+		return nil, err
+	}
+
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rules[%d]", i)
+		}
+		if rule.Effect != EffectAllow && rule.Effect != EffectDeny {
+			return nil, fmt.Errorf("rule %s: effect must be allow or deny, got %q", rule.Name, rule.Effect)
+		}
+		for _, verb := range rule.Verbs {
+			if verb != VerbRead && verb != VerbWrite {
+				return nil, fmt.Errorf("rule %s: verbs must be read or write, got %q", rule.Name, verb)
+			}
+		}
+		for field, patterns := range map[string][]string{
+			"tools":         rule.Tools,
+			"clusters":      rule.Clusters,
+			"namespaces":    rule.Namespaces,
+			"resourceTypes": rule.ResourceTypes,
+		} {
+			if err := validatePatterns("rule "+rule.Name+" "+field, patterns); err != nil {
+				return nil, err
+			}
+		}
+		for j, resourceType := range rule.ResourceTypes {
+			rule.ResourceTypes[j] = NormalizeResourceType(resourceType)
+		}
+	}
+
+	for i := range p.Limits {
+		limit := &p.Limits[i]
+		if limit.Name == "" {
+			limit.Name = fmt.Sprintf("limits[%d]", i)
+		}
+		if limit.Argument == "" {
+			return nil, fmt.Errorf("limit %s: argument is required", limit.Name)
+		}
+		if err := validatePatterns("limit "+limit.Name+" tools", limit.Tools); err != nil {
+			return nil, err
+		}
+	}
+
+	return &p, nil
+}
+
+func validatePatterns(field string, patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("%s: invalid pattern %q: %w", field, pattern, err)
+		}
+	}
+	return nil
+}
+
+// Verb classifies a tool as read or write
+func (p *Policy) Verb(tool string) string {
+	if matchAny(p.WriteTools, tool) {
+		return VerbWrite
+	}
+	return VerbRead
+}
+
+// DefaultVerb classifies a tool using DefaultWriteTools, for callers without a policy file
+func DefaultVerb(tool string) string {
+	if matchAny(DefaultWriteTools, tool) {
+		return VerbWrite
+	}
+	return VerbRead
+}
+
+// Evaluate decides whether a call may run. A call with several targets runs only if every
+// target is allowed.
+func (p *Policy) Evaluate(call Call) Decision {
+	targets := call.Targets
+	if len(targets) == 0 {
+		targets = []Target{{}}
+	}
+
+	for _, target := range targets {
+		if decision := p.evaluateTarget(call, target); !decision.Allowed {
+			return decision
+		}
+	}
+
+	for _, limit := range p.Limits {
+		if !matchAny(limit.Tools, call.Tool) {
+			continue
+		}
+		value, ok := number(call.Arguments[limit.Argument])
+		if !ok || value <= limit.Max {
+			continue
+		}
+		reason := limit.Reason
+		if reason == "" {
+			reason = fmt.Sprintf("%s %s exceeds the limit of %s", limit.Argument, formatNumber(value), formatNumber(limit.Max))
+		}
+		return Decision{Code: CodeLimitExceeded, Rule: limit.Name, Reason: reason}
+	}
+
+	return Decision{Allowed: true}
+}
+
+func (p *Policy) evaluateTarget(call Call, target Target) Decision {
+	verb := p.Verb(call.Tool)
+	for _, rule := range p.Rules {
+		if !rule.matches(call, verb, target) {
+			continue
+		}
+		if rule.Effect == EffectAllow {
+			return Decision{Allowed: true, Rule: rule.Name}
+		}
+		return Decision{Code: CodeDenied, Rule: rule.Name, Reason: denyReason(rule.Reason, call.Tool, target)}
+	}
+
+	if p.DefaultEffect == EffectDeny {
+		return Decision{Code: CodeDenied, Rule: "defaultEffect", Reason: fmt.Sprintf("%s is not allowed by any policy rule", call.Tool)}
+	}
+	return Decision{Allowed: true}
+}
+
+func denyReason(reason, tool string, target Target) string {
+	if reason != "" {
+		return reason
+	}
+	reason = tool + " is denied by policy"
+	if target.ResourceType != "" {
+		reason += " for resource type " + target.ResourceType
+	}
+	if target.Namespace != "" {
+		reason += " in namespace " + target.Namespace
+	} else if target.AllNamespaces {
+		reason += " across all namespaces"
+	}
+	return reason
+}
+
+// Hidden reports whether every call to the tool is denied regardless of its arguments, in
+// which case the tool is left out of tools/list
+func (p *Policy) Hidden(tool string) bool {
+	verb := p.Verb(tool)
+	for _, rule := range p.Rules {
+		if len(rule.Tools) > 0 && !matchAny(rule.Tools, tool) {
+			continue
+		}
+		if len(rule.Verbs) > 0 && !contains(rule.Verbs, verb) {
+			continue
+		}
+		if len(rule.Clusters) > 0 || len(rule.Namespaces) > 0 || len(rule.ResourceTypes) > 0 {
+			// Whether this rule applies depends on the arguments
+			return false
+		}
+		return rule.Effect == EffectDeny
+	}
+	return p.DefaultEffect == EffectDeny
+}
+
+func (r Rule) matches(call Call, verb string, target Target) bool {
+	if len(r.Tools) > 0 && !matchAny(r.Tools, call.Tool) {
+		return false
+	}
+	if len(r.Verbs) > 0 && !contains(r.Verbs, verb) {
+		return false
+	}
+	if len(r.Clusters) > 0 && !matchAny(r.Clusters, call.Cluster) {
+		return false
+	}
+	// A call across all namespaces touches every namespace a rule can name
+	if len(r.Namespaces) > 0 && !target.AllNamespaces && (target.Namespace == "" || !matchAny(r.Namespaces, target.Namespace)) {
+		return false
+	}
+	if len(r.ResourceTypes) > 0 && (target.ResourceType == "" || !matchAny(r.ResourceTypes, NormalizeResourceType(target.ResourceType))) {
+		return false
+	}
+	return true
+}
+
+// CallFromArguments extracts the policy relevant parts of a tool call from its arguments
+func CallFromArguments(tool string, args map[string]any) Call {
+	call := Call{Tool: tool, Arguments: args}
+	call.Cluster, _ = args["cluster"].(string)
+
+	base := Target{}
+	base.Namespace, _ = args["namespace"].(string)
+	base.ResourceType, _ = args["resource_type"].(string)
+	switch v := args["all_namespaces"].(type) {
+	case bool:
+		base.AllNamespaces = v
+	case string:
+		base.AllNamespaces, _ = strconv.ParseBool(v)
+	}
+	// A call without a namespace runs in one all the same, which rules on namespaces must see
+	if base.Namespace == "" && !base.AllNamespaces {
+		if contains(allNamespacesTools, tool) {
+			base.AllNamespaces = true
+		} else {
+			base.Namespace = DefaultNamespace
+		}
+	}
+
+	for _, key := range []string{"manifest", "yaml_content"} {
+		manifest, _ := args[key].(string)
+		if manifest == "" {
+			continue
+		}
+		for _, object := range ManifestTargets(manifest) {
+			if object.Namespace == "" {
+				object.Namespace = base.Namespace
+			}
+			call.Targets = append(call.Targets, object)
+		}
+	}
+	if len(call.Targets) == 0 {
+		// kubectl takes several resource types separated by commas, such as pods,secrets
+		for _, resourceType := range strings.Split(base.ResourceType, ",") {
+			target := base
+			target.ResourceType = strings.TrimSpace(resourceType)
+			call.Targets = append(call.Targets, target)
+		}
+	}
+	return call
+}
+
+// ManifestTargets lists the kind and namespace of every object in a manifest. Documents
+// that do not parse are skipped; the tool itself reports them.
+func ManifestTargets(manifest string) []Target {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(strings.NewReader(manifest), 4096)
+
+	var targets []Target
+	for {
+		var object struct {
+			Kind     string `json:"kind"`
+			Metadata struct {
+				Namespace string `json:"namespace"`
+			} `json:"metadata"`
+			Items []struct {
+				Kind     string `json:"kind"`
+				Metadata struct {
+					Namespace string `json:"namespace"`
+				} `json:"metadata"`
+			} `json:"items"`
+		}
+		if err := decoder.Decode(&object); err != nil {
+			if !errors.Is(err, io.EOF) {
+				return targets
+			}
+			break
+		}
+		if object.Kind == "" {
+			continue
+		}
+		if strings.HasSuffix(object.Kind, "List") && len(object.Items) > 0 {
+			for _, item := range object.Items {
+				targets = append(targets, Target{Namespace: item.Metadata.Namespace, ResourceType: item.Kind})
+			}
+			continue
+		}
+		targets = append(targets, Target{Namespace: object.Metadata.Namespace, ResourceType: object.Kind})
+	}
+	return targets
+}
+
+// shortNames maps the short names kubectl accepts for built-in resource types to their
+// singular names
+var shortNames = map[string]string{
+	"cm":     "configmap",
+	"crd":    "customresourcedefinition",
+	"crds":   "customresourcedefinition",
+	"cj":     "cronjob",
+	"cs":     "componentstatus",
+	"csr":    "certificatesigningrequest",
+	"deploy": "deployment",
+	"ds":     "daemonset",
+	"ep":     "endpoint",
+	"ev":     "event",
+	"hpa":    "horizontalpodautoscaler",
+	"ing":    "ingress",
+	"limits": "limitrange",
+	"netpol": "networkpolicy",
+	"no":     "node",
+	"ns":     "namespace",
+	"pc":     "priorityclass",
+	"pdb":    "poddisruptionbudget",
+	"po":     "pod",
+	"pv":     "persistentvolume",
+	"pvc":    "persistentvolumeclaim",
+	"quota":  "resourcequota",
+	"rc":     "replicationcontroller",
+	"rs":     "replicaset",
+	"sa":     "serviceaccount",
+	"sc":     "storageclass",
+	"sts":    "statefulset",
+	"svc":    "service",
+}
+
+// NormalizeResourceType reduces the ways a resource type can be written (Secret, secrets,
+// secrets.v1, secret/name, and short names such as cm) to a lower-case singular name
+func NormalizeResourceType(resourceType string) string {
+	resourceType = strings.ToLower(strings.TrimSpace(resourceType))
+	resourceType, _, _ = strings.Cut(resourceType, "/")
+	resourceType, _, _ = strings.Cut(resourceType, ".")
+	if name, ok := shortNames[resourceType]; ok {
+		return name
+	}
+
+	switch {
+	case strings.HasSuffix(resourceType, "ies"):
+		return strings.TrimSuffix(resourceType, "ies") + "y"
+	case strings.HasSuffix(resourceType, "sses"), strings.HasSuffix(resourceType, "xes"),
+		strings.HasSuffix(resourceType, "ches"), strings.HasSuffix(resourceType, "shes"):
+		return strings.TrimSuffix(resourceType, "es")
+	case strings.HasSuffix(resourceType, "s") && !strings.HasSuffix(resourceType, "ss"):
+		return strings.TrimSuffix(resourceType, "s")
+	}
+	return resourceType
+}
+
+func matchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func number(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
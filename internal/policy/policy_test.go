@@ -0,0 +1,244 @@
+package policy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicy = `
+defaultEffect: allow
+rules:
+- name: no-shell
+  effect: deny
+  tools: [shell]
+- name: protect-system-namespaces
+  effect: deny
+  verbs: [write]
+  namespaces: [kube-system, kube-*]
+  reason: kube-system is managed by the platform team
+- name: no-secrets
+  effect: deny
+  tools: [k8s_get_resources, k8s_describe_resource, k8s_apply_manifest]
+  resourceTypes: [secrets]
+- name: no-prod
+  effect: deny
+  verbs: [write]
+  clusters: [prod]
+limits:
+- name: replica-cap
+  tools: [k8s_scale]
+  argument: replicas
+  max: 10
+`
+
+func mustParse(t *testing.T, data string) *Policy {
+	t.Helper()
+	p, err := Parse([]byte(data))
+	require.NoError(t, err)
+	return p
+}
+
+func TestEvaluate(t *testing.T) {
+	p := mustParse(t, testPolicy)
+
+	tests := []struct {
+		name string
+		tool string
+		args map[string]any
+		code string
+		rule string
+	}{
+		{name: "unmatched call is allowed", tool: "k8s_get_resources", args: map[string]any{"resource_type": "pods"}},
+		{name: "denied tool", tool: "shell", args: map[string]any{"command": "ls"}, code: CodeDenied, rule: "no-shell"},
+		{name: "write in kube-system", tool: "k8s_delete_resource", args: map[string]any{"namespace": "kube-system"}, code: CodeDenied, rule: "protect-system-namespaces"},
+		{name: "write in kube-public", tool: "k8s_label_resource", args: map[string]any{"namespace": "kube-public"}, code: CodeDenied, rule: "protect-system-namespaces"},
+		{name: "read in kube-system", tool: "k8s_get_resources", args: map[string]any{"resource_type": "pods", "namespace": "kube-system"}},
+		{name: "secrets", tool: "k8s_get_resources", args: map[string]any{"resource_type": "secrets"}, code: CodeDenied, rule: "no-secrets"},
+		{name: "secret by kind", tool: "k8s_describe_resource", args: map[string]any{"resource_type": "Secret"}, code: CodeDenied, rule: "no-secrets"},
+		{name: "secret by group", tool: "k8s_get_resources", args: map[string]any{"resource_type": "secrets.v1"}, code: CodeDenied, rule: "no-secrets"},
+		{name: "secrets among several types", tool: "k8s_get_resources", args: map[string]any{"resource_type": "pods,secrets"}, code: CodeDenied, rule: "no-secrets"},
+		{name: "secrets first among several types", tool: "k8s_get_resources", args: map[string]any{"resource_type": "secrets, pods"}, code: CodeDenied, rule: "no-secrets"},
+		{name: "several allowed types", tool: "k8s_get_resources", args: map[string]any{"resource_type": "pods,cm"}},
+		{name: "secret in a manifest", tool: "k8s_apply_manifest", args: map[string]any{
+			"manifest": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n---\napiVersion: v1\nkind: Secret\nmetadata:\n  name: b\n",
+		}, code: CodeDenied, rule: "no-secrets"},
+		{name: "manifest namespace", tool: "k8s_apply_manifest", args: map[string]any{
+			"manifest": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n  namespace: kube-system\n",
+		}, code: CodeDenied, rule: "protect-system-namespaces"},
+		{name: "all namespaces", tool: "k8s_delete_resource", args: map[string]any{"all_namespaces": "true"}, code: CodeDenied, rule: "protect-system-namespaces"},
+		{name: "write to prod cluster", tool: "helm_upgrade", args: map[string]any{"cluster": "prod"}, code: CodeDenied, rule: "no-prod"},
+		{name: "read from prod cluster", tool: "helm_list_releases", args: map[string]any{"cluster": "prod"}},
+		{name: "replicas within limit", tool: "k8s_scale", args: map[string]any{"replicas": float64(10)}},
+		{name: "replicas over limit", tool: "k8s_scale", args: map[string]any{"replicas": float64(50)}, code: CodeLimitExceeded, rule: "replica-cap"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := p.Evaluate(CallFromArguments(tt.tool, tt.args))
+			if tt.code == "" {
+				assert.True(t, decision.Allowed, "%+v", decision)
+				return
+			}
+			assert.False(t, decision.Allowed)
+			assert.Equal(t, tt.code, decision.Code)
+			assert.Equal(t, tt.rule, decision.Rule)
+			assert.NotEmpty(t, decision.Reason)
+		})
+	}
+
+	decision := p.Evaluate(CallFromArguments("k8s_scale", map[string]any{"replicas": float64(50)}))
+	assert.Equal(t, "replicas 50 exceeds the limit of 10", decision.Reason)
+	decision = p.Evaluate(CallFromArguments("k8s_delete_resource", map[string]any{"namespace": "kube-system"}))
+	assert.Equal(t, "kube-system is managed by the platform team", decision.Reason)
+}
+
+func TestEvaluateWithoutNamespace(t *testing.T) {
+	p := mustParse(t, `
+rules:
+- name: protect-default
+  effect: deny
+  verbs: [write]
+  namespaces: [default]
+- name: no-system-events
+  effect: deny
+  tools: [k8s_get_events]
+  namespaces: [kube-system]
+`)
+
+	// A call without a namespace runs in the default namespace
+	decision := p.Evaluate(CallFromArguments("k8s_delete_resource", map[string]any{"resource_type": "pod", "resource_name": "web"}))
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, "protect-default", decision.Rule)
+	assert.Equal(t, "k8s_delete_resource is denied by policy for resource type pod in namespace default", decision.Reason)
+
+	decision = p.Evaluate(CallFromArguments("k8s_apply_manifest", map[string]any{"manifest": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n"}))
+	assert.Equal(t, "protect-default", decision.Rule)
+
+	assert.True(t, p.Evaluate(CallFromArguments("k8s_delete_resource", map[string]any{"resource_type": "pod", "namespace": "apps"})).Allowed)
+
+	// Events without a namespace are listed across all namespaces
+	decision = p.Evaluate(CallFromArguments("k8s_get_events", nil))
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, "no-system-events", decision.Rule)
+	assert.True(t, p.Evaluate(CallFromArguments("k8s_get_events", map[string]any{"namespace": "apps"})).Allowed)
+}
+
+func TestEvaluateDefaultDeny(t *testing.T) {
+	p := mustParse(t, `
+defaultEffect: deny
+rules:
+- effect: allow
+  verbs: [read]
+- effect: allow
+  tools: [k8s_scale]
+`)
+
+	assert.True(t, p.Evaluate(CallFromArguments("k8s_get_resources", nil)).Allowed)
+	assert.True(t, p.Evaluate(CallFromArguments("k8s_scale", nil)).Allowed)
+
+	decision := p.Evaluate(CallFromArguments("k8s_delete_resource", nil))
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, "defaultEffect", decision.Rule)
+
+	assert.False(t, p.Hidden("k8s_get_resources"))
+	assert.False(t, p.Hidden("k8s_scale"))
+	assert.True(t, p.Hidden("k8s_delete_resource"))
+}
+
+func TestHidden(t *testing.T) {
+	p := mustParse(t, testPolicy)
+
+	assert.True(t, p.Hidden("shell"))
+	// Only denied for some arguments
+	assert.False(t, p.Hidden("k8s_get_resources"))
+	assert.False(t, p.Hidden("k8s_delete_resource"))
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"unknown field":  "rules:\n- effect: deny\n  tool: [shell]\n",
+		"bad effect":     "rules:\n- effect: block\n",
+		"bad verb":       "rules:\n- effect: deny\n  verbs: [delete]\n",
+		"bad pattern":    "rules:\n- effect: deny\n  tools: ['k8s_[']\n",
+		"bad default":    "defaultEffect: maybe\n",
+		"limit argument": "limits:\n- tools: [k8s_scale]\n  max: 3\n",
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse([]byte(data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestEvaluateShortNames(t *testing.T) {
+	p := mustParse(t, `
+rules:
+- name: no-configmaps
+  effect: deny
+  resourceTypes: [configmaps, deployments.apps]
+`)
+
+	for _, resourceType := range []string{"cm", "CM", "deploy", "pods,deploy"} {
+		decision := p.Evaluate(CallFromArguments("k8s_get_resources", map[string]any{"resource_type": resourceType}))
+		assert.False(t, decision.Allowed, resourceType)
+		assert.Equal(t, "no-configmaps", decision.Rule, resourceType)
+	}
+	assert.True(t, p.Evaluate(CallFromArguments("k8s_get_resources", map[string]any{"resource_type": "po,svc"})).Allowed)
+}
+
+func TestNormalizeResourceType(t *testing.T) {
+	for input, want := range map[string]string{
+		"Secret":                   "secret",
+		"secrets":                  "secret",
+		"secrets.v1":               "secret",
+		"secret/db-password":       "secret",
+		"ingresses":                "ingress",
+		"NetworkPolicies":          "networkpolicy",
+		"deployments.apps":         "deployment",
+		"customresourcedefinition": "customresourcedefinition",
+		"cm":                       "configmap",
+		"po":                       "pod",
+		"deploy":                   "deployment",
+		"svc":                      "service",
+		"deploy.apps":              "deployment",
+		"endpoints":                "endpoint",
+		"ep":                       "endpoint",
+	} {
+		assert.Equal(t, want, NormalizeResourceType(input), input)
+	}
+}
+
+func TestEngineReload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("rules:\n- effect: deny\n  tools: [shell]\n"), 0600))
+
+	engine, err := NewEngine(file)
+	require.NoError(t, err)
+	assert.True(t, engine.Policy().Hidden("shell"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloaded := make(chan struct{}, 10)
+	require.NoError(t, engine.Watch(ctx, func() { reloaded <- struct{}{} }))
+
+	require.NoError(t, os.WriteFile(file, []byte("rules:\n- effect: deny\n  tools: [helm_*]\n"), 0600))
+	assert.Eventually(t, func() bool {
+		return engine.Policy().Hidden("helm_uninstall") && !engine.Policy().Hidden("shell")
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.NotEmpty(t, reloaded)
+
+	// An invalid file keeps the previous policy
+	require.NoError(t, os.WriteFile(file, []byte("rules: [oops"), 0600))
+	assert.Error(t, engine.Reload())
+	assert.True(t, engine.Policy().Hidden("helm_uninstall"))
+
+	_, err = NewEngine(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,114 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/kagent-dev/tools/internal/logger"
+)
+
+// Engine holds the current policy of a file and swaps it when the file changes
+type Engine struct {
+	file    string
+	current atomic.Pointer[Policy]
+
+	mu   sync.Mutex
+	data []byte
+}
+
+// NewEngine loads the policy file. The file must be valid at startup; later changes that do
+// not parse are logged and the previous policy stays in effect.
+func NewEngine(file string) (*Engine, error) {
+	e := &Engine{file: file}
+	if err := e.Reload(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Policy returns the policy currently in effect
+func (e *Engine) Policy() *Policy {
+	return e.current.Load()
+}
+
+// Reload re-reads the policy file, keeping the current policy if the file did not change or
+// does not parse
+func (e *Engine) Reload() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	data, err := os.ReadFile(e.file)
+	if err != nil {
+		return fmt.Errorf("failed to read policy file: %w", err)
+	}
+	if e.current.Load() != nil && bytes.Equal(data, e.data) {
+		return nil
+	}
+	// Writers that truncate before writing briefly leave an empty file behind, which must
+	// not turn into an allow-everything policy
+	if len(bytes.TrimSpace(data)) == 0 {
+		return fmt.Errorf("policy file %s is empty", e.file)
+	}
+
+	p, err := Parse(data)
+	if err != nil {
+		return err
+	}
+	e.data = data
+	e.current.Store(p)
+	logger.Get().Info("Loaded tool policy", "file", e.file, "rules", len(p.Rules), "limits", len(p.Limits), "default_effect", p.DefaultEffect)
+	return nil
+}
+
+// Watch reloads the policy whenever the file changes until ctx is done, calling onReload
+// (if set) after a new policy took effect. The directory is watched rather than the file so
+// that editors replacing the file and ConfigMap volumes swapping their symlinks are both
+// picked up.
+func (e *Engine) Watch(ctx context.Context, onReload func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to watch policy file: %w", err)
+	}
+	if err := watcher.Add(filepath.Dir(e.file)); err != nil {
+		_ = watcher.Close()
+		return fmt.Errorf("failed to watch policy file: %w", err)
+	}
+
+	go func() {
+		defer func() { _ = watcher.Close() }()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Has(fsnotify.Chmod) {
+					continue
+				}
+				previous := e.Policy()
+				if err := e.Reload(); err != nil {
+					logger.Get().Error("Failed to reload tool policy, keeping the previous policy", "file", e.file, "error", err)
+					continue
+				}
+				if onReload != nil && e.Policy() != previous {
+					onReload()
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logger.Get().Error("Tool policy watcher failed", "file", e.file, "error", err)
+			}
+		}
+	}()
+	return nil
+}
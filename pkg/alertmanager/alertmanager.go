@@ -0,0 +1,441 @@
+package alertmanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kagent-dev/tools/internal/errors"
+	"github.com/kagent-dev/tools/internal/httpclient"
+	"github.com/kagent-dev/tools/internal/identity"
+	"github.com/kagent-dev/tools/internal/telemetry"
+)
+
+// Alertmanager tools using the v2 HTTP API
+
+const (
+	// defaultSilenceDuration is how long a silence lasts without duration or ends_at
+	defaultSilenceDuration = time.Hour
+
+	// defaultCreatedBy is the author of silences created without created_by by an
+	// unauthenticated caller
+	defaultCreatedBy = "kagent"
+)
+
+// matcherPattern matches a label matcher such as alertname="HighLatency" or
+// namespace=~prod-.*
+var matcherPattern = regexp.MustCompile(`^\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*(=~|!~|!=|=)\s*(.*?)\s*$`)
+
+// silenceIDPattern matches the UUID of a silence
+var silenceIDPattern = regexp.MustCompile(`^[0-9a-fA-F-]{1,64}$`)
+
+// callAPI sends a request for path, e.g. /api/v2/alerts, with params and a JSON body to the
+// Alertmanager of the call and decodes the JSON response into result when it is not nil.
+// Failures are returned as a tool error result.
+func callAPI(ctx context.Context, request mcp.CallToolRequest, operation, method, path string, params url.Values, body, result any) *mcp.CallToolResult {
+	alertmanagerURL, client, errResult := resolveTarget(ctx, request)
+	if errResult != nil {
+		return errResult
+	}
+
+	apiURL := alertmanagerURL + path
+	fullURL := apiURL
+	if len(params) > 0 {
+		fullURL = fmt.Sprintf("%s?%s", apiURL, params.Encode())
+	}
+
+	newError := func(cause error) *errors.ToolError {
+		toolErr := errors.NewAlertmanagerError(operation, cause).
+			WithContext("alertmanager_url", alertmanagerURL).
+			WithContext("api_url", apiURL)
+		for name, values := range params {
+			toolErr = toolErr.WithContext(name, strings.Join(values, ", "))
+		}
+		return toolErr
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return newError(err).ToMCPResult()
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, fullURL, reader)
+	if err != nil {
+		return newError(err).ToMCPResult()
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return newError(err).ToMCPResult()
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return newError(err).WithContext("status_code", resp.StatusCode).ToMCPResult()
+	}
+	if resp.StatusCode != http.StatusOK {
+		cause := fmt.Errorf("Alertmanager API error (%d): %s", resp.StatusCode, strings.TrimSpace(string(data)))
+		return newError(cause).WithContext("status_code", resp.StatusCode).ToMCPResult()
+	}
+
+	if result != nil {
+		if err := json.Unmarshal(data, result); err != nil {
+			return newError(fmt.Errorf("failed to decode response: %w", err)).ToMCPResult()
+		}
+	}
+	return nil
+}
+
+// parseMatcher parses a label matcher such as alertname="HighLatency", severity!=info or
+// namespace=~"prod-.*". The value may be quoted.
+func parseMatcher(value string) (apiMatcher, error) {
+	match := matcherPattern.FindStringSubmatch(value)
+	if match == nil {
+		return apiMatcher{}, fmt.Errorf("invalid matcher %q: expected <label><op><value> with op one of =, !=, =~, !~", value)
+	}
+	matcher := apiMatcher{
+		Name:    match[1],
+		Value:   match[3],
+		IsRegex: strings.Contains(match[2], "~"),
+		IsEqual: !strings.HasPrefix(match[2], "!"),
+	}
+	if strings.HasPrefix(matcher.Value, `"`) {
+		unquoted, err := strconv.Unquote(matcher.Value)
+		if err != nil {
+			return apiMatcher{}, fmt.Errorf("invalid matcher %q: %w", value, err)
+		}
+		matcher.Value = unquoted
+	}
+	if matcher.IsRegex {
+		if _, err := regexp.Compile("^(?:" + matcher.Value + ")$"); err != nil {
+			return apiMatcher{}, fmt.Errorf("invalid matcher %q: %w", value, err)
+		}
+	}
+	return matcher, nil
+}
+
+// matchersArg parses the matchers of argument name, a string or an array of strings
+func matchersArg(request mcp.CallToolRequest, name string) ([]apiMatcher, *mcp.CallToolResult) {
+	values := request.GetStringSlice(name, nil)
+	if value := mcp.ParseString(request, name, ""); value != "" {
+		values = []string{value}
+	}
+	matchers := make([]apiMatcher, 0, len(values))
+	for _, value := range values {
+		matcher, err := parseMatcher(value)
+		if err != nil {
+			return nil, mcp.NewToolResultError(err.Error())
+		}
+		matchers = append(matchers, matcher)
+	}
+	return matchers, nil
+}
+
+// alertParams returns the query parameters of the alert filters of a call: the filter
+// matchers, the receiver regular expression and which alert states to include
+func alertParams(request mcp.CallToolRequest) (url.Values, *mcp.CallToolResult) {
+	matchers, errResult := matchersArg(request, "filter")
+	if errResult != nil {
+		return nil, errResult
+	}
+
+	params := url.Values{}
+	for _, matcher := range matchers {
+		params.Add("filter", matcher.String())
+	}
+	if receiver := mcp.ParseString(request, "receiver", ""); receiver != "" {
+		if _, err := regexp.Compile(receiver); err != nil {
+			return nil, mcp.NewToolResultError(fmt.Sprintf("Invalid receiver regular expression: %v", err))
+		}
+		params.Set("receiver", receiver)
+	}
+	for name, include := range map[string]bool{
+		"active":      mcp.ParseBoolean(request, "active", true),
+		"silenced":    mcp.ParseBoolean(request, "silenced", false),
+		"inhibited":   mcp.ParseBoolean(request, "inhibited", false),
+		"unprocessed": mcp.ParseBoolean(request, "unprocessed", true),
+	} {
+		params.Set(name, strconv.FormatBool(include))
+	}
+	return params, nil
+}
+
+// structuredResult returns result as pretty-printed JSON text and structured content
+func structuredResult(result any) *mcp.CallToolResult {
+	content, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError("failed to marshal result: " + err.Error())
+	}
+	return mcp.NewToolResultStructured(result, string(content))
+}
+
+func handleListAlerts(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	params, errResult := alertParams(request)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	var alerts []apiAlert
+	if errResult := callAPI(ctx, request, "list_alerts", http.MethodGet, "/api/v2/alerts", params, nil, &alerts); errResult != nil {
+		return errResult, nil
+	}
+	return structuredResult(AlertList{Total: len(alerts), Alerts: newAlerts(alerts)}), nil
+}
+
+func handleListAlertGroups(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	params, errResult := alertParams(request)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	var groups []apiAlertGroup
+	if errResult := callAPI(ctx, request, "list_alert_groups", http.MethodGet, "/api/v2/alerts/groups", params, nil, &groups); errResult != nil {
+		return errResult, nil
+	}
+
+	result := AlertGroupList{Total: len(groups), Groups: make([]AlertGroup, 0, len(groups))}
+	for _, group := range groups {
+		result.Groups = append(result.Groups, AlertGroup{
+			Labels:   group.Labels,
+			Receiver: group.Receiver.Name,
+			Alerts:   newAlerts(group.Alerts),
+		})
+	}
+	return structuredResult(result), nil
+}
+
+func handleListSilences(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	state := mcp.ParseString(request, "state", "")
+	if _, ok := silenceStateOrder[state]; state != "" && !ok {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid state %q: expected active, pending or expired", state)), nil
+	}
+	matchers, errResult := matchersArg(request, "filter")
+	if errResult != nil {
+		return errResult, nil
+	}
+	params := url.Values{}
+	for _, matcher := range matchers {
+		params.Add("filter", matcher.String())
+	}
+
+	var silences []apiSilence
+	if errResult := callAPI(ctx, request, "list_silences", http.MethodGet, "/api/v2/silences", params, nil, &silences); errResult != nil {
+		return errResult, nil
+	}
+
+	result := SilenceList{Silences: []Silence{}}
+	for _, silence := range silences {
+		if converted := newSilence(silence); state == "" || converted.State == state {
+			result.Silences = append(result.Silences, converted)
+		}
+	}
+	sortSilences(result.Silences)
+	result.Total = len(result.Silences)
+	return structuredResult(result), nil
+}
+
+func handleCreateSilence(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	matchers, errResult := matchersArg(request, "matchers")
+	if errResult != nil {
+		return errResult, nil
+	}
+	if len(matchers) == 0 {
+		return mcp.NewToolResultError("matchers parameter is required"), nil
+	}
+	comment := strings.TrimSpace(mcp.ParseString(request, "comment", ""))
+	if comment == "" {
+		return mcp.NewToolResultError("comment parameter is required: say why the alerts are silenced"), nil
+	}
+
+	createdBy := mcp.ParseString(request, "created_by", "")
+	if createdBy == "" {
+		createdBy = defaultCreatedBy
+		if principal, ok := identity.PrincipalFromContext(ctx); ok && principal.Subject != "" {
+			createdBy = principal.Subject
+		}
+	}
+
+	// The silence starts now unless starts_at is given and lasts duration or until ends_at
+	startsAt := time.Now().UTC()
+	if value := mcp.ParseString(request, "starts_at", ""); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid starts_at: %v", err)), nil
+		}
+		startsAt = t
+	}
+	endsAt := startsAt.Add(defaultSilenceDuration)
+	duration, until := mcp.ParseString(request, "duration", ""), mcp.ParseString(request, "ends_at", "")
+	switch {
+	case duration != "" && until != "":
+		return mcp.NewToolResultError("set either duration or ends_at, not both"), nil
+	case duration != "":
+		d, err := parseDuration(duration)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid duration: %v", err)), nil
+		}
+		endsAt = startsAt.Add(d)
+	case until != "":
+		t, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid ends_at: %v", err)), nil
+		}
+		endsAt = t
+	}
+	if !endsAt.After(startsAt) || !endsAt.After(time.Now()) {
+		return mcp.NewToolResultError("the silence must end after it starts and in the future"), nil
+	}
+
+	silence := apiSilence{Matchers: matchers, StartsAt: startsAt, EndsAt: endsAt, CreatedBy: createdBy, Comment: comment}
+	result := SilenceResult{DryRun: mcp.ParseBoolean(request, "dry_run", false), Silence: newSilence(silence)}
+
+	// Show which alerts the silence mutes, so that a too broad silence is noticed
+	params := url.Values{"silenced": {"true"}, "inhibited": {"true"}}
+	for _, matcher := range matchers {
+		params.Add("filter", matcher.String())
+	}
+	var alerts []apiAlert
+	if errResult := callAPI(ctx, request, "list_alerts", http.MethodGet, "/api/v2/alerts", params, nil, &alerts); errResult != nil {
+		return errResult, nil
+	}
+	result.Matching = newAlerts(alerts)
+
+	if !result.DryRun {
+		var created struct {
+			SilenceID string `json:"silenceID"`
+		}
+		if errResult := callAPI(ctx, request, "create_silence", http.MethodPost, "/api/v2/silences", nil, silence, &created); errResult != nil {
+			return errResult, nil
+		}
+		result.SilenceID = created.SilenceID
+		result.Silence.ID = created.SilenceID
+	}
+	return structuredResult(result), nil
+}
+
+func handleExpireSilence(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	id := mcp.ParseString(request, "silence_id", "")
+	if id == "" {
+		return mcp.NewToolResultError("silence_id parameter is required"), nil
+	}
+	if !silenceIDPattern.MatchString(id) {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid silence ID %q", id)), nil
+	}
+
+	if errResult := callAPI(ctx, request, "expire_silence", http.MethodDelete, "/api/v2/silence/"+id, nil, nil, nil); errResult != nil {
+		return errResult, nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Silence %s expired", id)), nil
+}
+
+func handleListReceivers(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var receivers []struct {
+		Name string `json:"name"`
+	}
+	if errResult := callAPI(ctx, request, "list_receivers", http.MethodGet, "/api/v2/receivers", nil, nil, &receivers); errResult != nil {
+		return errResult, nil
+	}
+
+	result := ReceiverList{Receivers: make([]string, 0, len(receivers))}
+	for _, receiver := range receivers {
+		result.Receivers = append(result.Receivers, receiver.Name)
+	}
+	return structuredResult(result), nil
+}
+
+// parseDuration parses a Go duration such as 2h30m, or a number of days such as 3d
+func parseDuration(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		count, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		return time.Duration(count) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// RegisterTools registers the Alertmanager tools against the default Alertmanager
+func RegisterTools(s *server.MCPServer, readOnly bool) {
+	RegisterToolsWithConfig(s, readOnly, nil)
+}
+
+// RegisterToolsWithConfig registers the Alertmanager tools against the Alertmanager of
+// config, which may be nil
+func RegisterToolsWithConfig(s *server.MCPServer, readOnly bool, config *Config) {
+	alertFilters := []mcp.ToolOption{
+		mcp.WithArray("filter", mcp.WithStringItems(), mcp.Description(`Label matchers all alerts must match, e.g. alertname="HighLatency" or namespace=~"prod-.*"`)),
+		mcp.WithString("receiver", mcp.Description("Regular expression the receiver of the alerts must match")),
+		mcp.WithBoolean("active", mcp.Description("Include active alerts (default: true)")),
+		mcp.WithBoolean("silenced", mcp.Description("Include silenced alerts (default: false)")),
+		mcp.WithBoolean("inhibited", mcp.Description("Include inhibited alerts (default: false)")),
+		mcp.WithBoolean("unprocessed", mcp.Description("Include alerts not yet processed (default: true)")),
+		mcp.WithString("alertmanager_url", mcp.Description(alertmanagerURLDescription)),
+	}
+
+	s.AddTool(mcp.NewTool("alertmanager_list_alerts", append([]mcp.ToolOption{
+		mcp.WithDescription("List the alerts Alertmanager holds, by default those firing and neither silenced nor inhibited"),
+		mcp.WithOutputSchema[AlertList](),
+	}, alertFilters...)...), telemetry.AdaptToolHandler(telemetry.WithTracing("alertmanager_list_alerts", httpclient.WithConfig(config, handleListAlerts))))
+
+	s.AddTool(mcp.NewTool("alertmanager_list_alert_groups", append([]mcp.ToolOption{
+		mcp.WithDescription("List the alerts grouped as Alertmanager notifies them, with the receiver of each group"),
+		mcp.WithOutputSchema[AlertGroupList](),
+	}, alertFilters...)...), telemetry.AdaptToolHandler(telemetry.WithTracing("alertmanager_list_alert_groups", httpclient.WithConfig(config, handleListAlertGroups))))
+
+	s.AddTool(mcp.NewTool("alertmanager_list_silences",
+		mcp.WithDescription("List silences, active ones first"),
+		mcp.WithString("state", mcp.Description("Only list silences in this state (default: all)"), mcp.Enum("active", "pending", "expired")),
+		mcp.WithArray("filter", mcp.WithStringItems(), mcp.Description(`Label matchers the silences must match, e.g. alertname="HighLatency"`)),
+		mcp.WithString("alertmanager_url", mcp.Description(alertmanagerURLDescription)),
+		mcp.WithOutputSchema[SilenceList](),
+	), telemetry.AdaptToolHandler(telemetry.WithTracing("alertmanager_list_silences", httpclient.WithConfig(config, handleListSilences))))
+
+	s.AddTool(mcp.NewTool("alertmanager_list_receivers",
+		mcp.WithDescription("List the receivers alerts can be routed to"),
+		mcp.WithString("alertmanager_url", mcp.Description(alertmanagerURLDescription)),
+		mcp.WithOutputSchema[ReceiverList](),
+	), telemetry.AdaptToolHandler(telemetry.WithTracing("alertmanager_list_receivers", httpclient.WithConfig(config, handleListReceivers))))
+
+	// Write tools - only registered when not in read-only mode
+	if !readOnly {
+		s.AddTool(mcp.NewTool("alertmanager_create_silence",
+			mcp.WithDescription("Silence the alerts matching all matchers for a while, e.g. during remediation. Returns the silence ID and the alerts it mutes"),
+			mcp.WithArray("matchers", mcp.WithStringItems(), mcp.Description(`Label matchers the silenced alerts must all match, e.g. alertname="HighLatency" and namespace="prod"`), mcp.Required()),
+			mcp.WithString("comment", mcp.Description("Why the alerts are silenced"), mcp.Required()),
+			mcp.WithString("duration", mcp.Description("How long the silence lasts, e.g. 30m, 2h or 1d (default: 1h)")),
+			mcp.WithString("starts_at", mcp.Description("RFC3339 start of the silence (default: now)")),
+			mcp.WithString("ends_at", mcp.Description("RFC3339 end of the silence, instead of duration")),
+			mcp.WithString("created_by", mcp.Description("Author of the silence (default: the authenticated caller, else kagent)")),
+			mcp.WithBoolean("dry_run", mcp.Description("Return the silence and the alerts it would mute without creating it")),
+			mcp.WithString("alertmanager_url", mcp.Description(alertmanagerURLDescription)),
+			mcp.WithOutputSchema[SilenceResult](),
+		), telemetry.AdaptToolHandler(telemetry.WithTracing("alertmanager_create_silence", httpclient.WithConfig(config, handleCreateSilence))))
+
+		s.AddTool(mcp.NewTool("alertmanager_expire_silence",
+			mcp.WithDescription("Expire a silence so that the alerts it muted notify again"),
+			mcp.WithString("silence_id", mcp.Description("ID of the silence, see alertmanager_list_silences"), mcp.Required()),
+			mcp.WithString("alertmanager_url", mcp.Description(alertmanagerURLDescription)),
+		), telemetry.AdaptToolHandler(telemetry.WithTracing("alertmanager_expire_silence", httpclient.WithConfig(config, handleExpireSilence))))
+	}
+}
+
+const alertmanagerURLDescription = "Alertmanager URL, queried without the configured credentials (default: the configured Alertmanager, else http://localhost:9093)"
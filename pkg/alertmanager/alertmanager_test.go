@@ -0,0 +1,332 @@
+package alertmanager
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kagent-dev/tools/internal/httpclient"
+	"github.com/kagent-dev/tools/internal/identity"
+)
+
+// fakeAlertmanager stands in for the Alertmanager v2 API and records the requests it gets
+type fakeAlertmanager struct {
+	*httptest.Server
+	requests []*http.Request
+	bodies   []string
+}
+
+func newFakeAlertmanager(t *testing.T) *fakeAlertmanager {
+	fake := &fakeAlertmanager{}
+	responses := map[string]string{
+		"GET /api/v2/alerts": `[
+			{"labels":{"alertname":"KubePodCrashLooping","severity":"warning","namespace":"prod"},"annotations":{"summary":"Pod is crash looping"},
+			 "startsAt":"2026-10-16T09:00:00Z","endsAt":"2026-10-16T11:00:00Z","fingerprint":"b2","receivers":[{"name":"slack"}],
+			 "status":{"state":"active","silencedBy":[],"inhibitedBy":[]}},
+			{"labels":{"alertname":"HighLatency","severity":"critical","namespace":"prod"},
+			 "startsAt":"2026-10-16T10:00:00Z","endsAt":"2026-10-16T11:00:00Z","fingerprint":"a1","receivers":[{"name":"pagerduty"}],
+			 "status":{"state":"active","silencedBy":[],"inhibitedBy":[]}}
+		]`,
+		"GET /api/v2/alerts/groups": `[
+			{"labels":{"namespace":"prod"},"receiver":{"name":"slack"},"alerts":[
+				{"labels":{"alertname":"KubePodCrashLooping"},"startsAt":"2026-10-16T09:00:00Z","fingerprint":"b2","status":{"state":"active"}}
+			]}
+		]`,
+		"GET /api/v2/silences": `[
+			{"id":"e1","status":{"state":"expired"},"matchers":[{"name":"alertname","value":"Watchdog","isRegex":false,"isEqual":true}],
+			 "startsAt":"2026-10-15T00:00:00Z","endsAt":"2026-10-15T01:00:00Z","createdBy":"ops","comment":"maintenance"},
+			{"id":"a1","status":{"state":"active"},"matchers":[{"name":"namespace","value":"prod-.*","isRegex":true,"isEqual":true}],
+			 "startsAt":"2026-10-16T00:00:00Z","endsAt":"2026-10-17T00:00:00Z","createdBy":"ops","comment":"rollout"}
+		]`,
+		"GET /api/v2/receivers":           `[{"name":"pagerduty"},{"name":"slack"}]`,
+		"POST /api/v2/silences":           `{"silenceID":"4c1b2a3d-0000-4000-8000-000000000001"}`,
+		"DELETE /api/v2/silence/a1":       ``,
+		"DELETE /api/v2/silence/0000dead": ``,
+	}
+	fake.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		fake.requests = append(fake.requests, r)
+		fake.bodies = append(fake.bodies, string(body))
+
+		key := r.Method + " " + r.URL.Path
+		if key == "DELETE /api/v2/silence/0000dead" {
+			http.Error(w, "silence not found", http.StatusNotFound)
+			return
+		}
+		response, ok := responses[key]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(fake.Close)
+	return fake
+}
+
+// last returns the last request the fake got
+func (f *fakeAlertmanager) last() *http.Request {
+	return f.requests[len(f.requests)-1]
+}
+
+// call calls handler against the fake through the configured Alertmanager
+func (f *fakeAlertmanager) call(t *testing.T, ctx context.Context, handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	config, err := LoadConfig(Config{URL: f.URL})
+	require.NoError(t, err)
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = args
+	result, err := httpclient.WithConfig(config, handler)(ctx, req)
+	require.NoError(t, err)
+	return result
+}
+
+func getResultText(result *mcp.CallToolResult) string {
+	if result == nil || len(result.Content) == 0 {
+		return ""
+	}
+	if textContent, ok := result.Content[0].(mcp.TextContent); ok {
+		return textContent.Text
+	}
+	return ""
+}
+
+// decode unmarshals the JSON text of result into v
+func decode(t *testing.T, result *mcp.CallToolResult, v any) {
+	t.Helper()
+	require.False(t, result.IsError, getResultText(result))
+	require.NoError(t, json.Unmarshal([]byte(getResultText(result)), v))
+}
+
+func TestRegisterTools(t *testing.T) {
+	writeTools := []string{"alertmanager_create_silence", "alertmanager_expire_silence"}
+
+	s := server.NewMCPServer("test", "v0.0.1")
+	RegisterTools(s, false)
+	for _, name := range append([]string{"alertmanager_list_alerts", "alertmanager_list_alert_groups", "alertmanager_list_silences", "alertmanager_list_receivers"}, writeTools...) {
+		assert.Contains(t, s.ListTools(), name)
+	}
+
+	s = server.NewMCPServer("test", "v0.0.1")
+	RegisterTools(s, true)
+	for _, name := range writeTools {
+		assert.NotContains(t, s.ListTools(), name)
+	}
+}
+
+func TestHandleListAlerts(t *testing.T) {
+	fake := newFakeAlertmanager(t)
+
+	t.Run("defaults", func(t *testing.T) {
+		var result AlertList
+		decode(t, fake.call(t, context.Background(), handleListAlerts, nil), &result)
+
+		assert.Equal(t, url.Values{"active": {"true"}, "silenced": {"false"}, "inhibited": {"false"}, "unprocessed": {"true"}}, fake.last().URL.Query())
+		require.Equal(t, 2, result.Total)
+		assert.Equal(t, "HighLatency", result.Alerts[0].Name)
+		assert.Equal(t, "critical", result.Alerts[0].Severity)
+		assert.Equal(t, []string{"pagerduty"}, result.Alerts[0].Receivers)
+		assert.Equal(t, "KubePodCrashLooping", result.Alerts[1].Name)
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		fake.call(t, context.Background(), handleListAlerts, map[string]any{
+			"filter":   []any{"severity=critical", `namespace=~"prod-.*"`},
+			"receiver": "pager.*",
+			"silenced": true,
+		})
+
+		query := fake.last().URL.Query()
+		assert.Equal(t, []string{`severity="critical"`, `namespace=~"prod-.*"`}, query["filter"])
+		assert.Equal(t, "pager.*", query.Get("receiver"))
+		assert.Equal(t, "true", query.Get("silenced"))
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		result := fake.call(t, context.Background(), handleListAlerts, map[string]any{"filter": []any{"severity"}})
+		assert.True(t, result.IsError)
+		assert.Contains(t, getResultText(result), "invalid matcher")
+	})
+}
+
+func TestHandleListAlertGroups(t *testing.T) {
+	fake := newFakeAlertmanager(t)
+
+	var result AlertGroupList
+	decode(t, fake.call(t, context.Background(), handleListAlertGroups, nil), &result)
+	assert.Equal(t, "/api/v2/alerts/groups", fake.last().URL.Path)
+	require.Equal(t, 1, result.Total)
+	assert.Equal(t, "slack", result.Groups[0].Receiver)
+	assert.Equal(t, map[string]string{"namespace": "prod"}, result.Groups[0].Labels)
+	assert.Equal(t, "KubePodCrashLooping", result.Groups[0].Alerts[0].Name)
+}
+
+func TestHandleListSilences(t *testing.T) {
+	fake := newFakeAlertmanager(t)
+
+	var all SilenceList
+	decode(t, fake.call(t, context.Background(), handleListSilences, nil), &all)
+	require.Equal(t, 2, all.Total)
+	assert.Equal(t, "a1", all.Silences[0].ID, "active silences come first")
+	assert.Equal(t, []string{`namespace=~"prod-.*"`}, all.Silences[0].Matchers)
+
+	var expired SilenceList
+	decode(t, fake.call(t, context.Background(), handleListSilences, map[string]any{"state": "expired"}), &expired)
+	require.Equal(t, 1, expired.Total)
+	assert.Equal(t, "e1", expired.Silences[0].ID)
+
+	result := fake.call(t, context.Background(), handleListSilences, map[string]any{"state": "gone"})
+	assert.True(t, result.IsError)
+}
+
+func TestHandleCreateSilence(t *testing.T) {
+	args := func(extra map[string]any) map[string]any {
+		args := map[string]any{"matchers": []any{`alertname="HighLatency"`, "namespace=prod"}, "comment": "rolling back the release"}
+		for name, value := range extra {
+			args[name] = value
+		}
+		return args
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		fake := newFakeAlertmanager(t)
+		var result SilenceResult
+		decode(t, fake.call(t, context.Background(), handleCreateSilence, args(map[string]any{"dry_run": true})), &result)
+
+		for _, req := range fake.requests {
+			assert.Equal(t, http.MethodGet, req.Method, "a dry run does not create the silence")
+		}
+		assert.True(t, result.DryRun)
+		assert.Empty(t, result.SilenceID)
+		assert.Equal(t, []string{`alertname="HighLatency"`, `namespace="prod"`}, result.Silence.Matchers)
+		assert.Equal(t, "kagent", result.Silence.CreatedBy)
+		assert.Equal(t, time.Hour, result.Silence.EndsAt.Sub(result.Silence.StartsAt))
+		assert.Len(t, result.Matching, 2)
+	})
+
+	t.Run("create", func(t *testing.T) {
+		fake := newFakeAlertmanager(t)
+		ctx := identity.WithPrincipal(context.Background(), identity.Principal{Subject: "oncall@example.com"})
+		var result SilenceResult
+		decode(t, fake.call(t, ctx, handleCreateSilence, args(map[string]any{"duration": "2d"})), &result)
+
+		assert.Equal(t, "4c1b2a3d-0000-4000-8000-000000000001", result.SilenceID)
+		assert.Equal(t, http.MethodPost, fake.last().Method)
+
+		var sent apiSilence
+		require.NoError(t, json.Unmarshal([]byte(fake.bodies[len(fake.bodies)-1]), &sent))
+		assert.Equal(t, "oncall@example.com", sent.CreatedBy)
+		assert.Equal(t, "rolling back the release", sent.Comment)
+		assert.Equal(t, 48*time.Hour, sent.EndsAt.Sub(sent.StartsAt))
+		assert.Equal(t, []apiMatcher{{Name: "alertname", Value: "HighLatency", IsEqual: true}, {Name: "namespace", Value: "prod", IsEqual: true}}, sent.Matchers)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		fake := newFakeAlertmanager(t)
+		for name, tt := range map[string]struct {
+			args map[string]any
+			want string
+		}{
+			"no matchers":           {args: map[string]any{"comment": "x"}, want: "matchers parameter is required"},
+			"no comment":            {args: map[string]any{"matchers": []any{"alertname=A"}}, want: "comment parameter is required"},
+			"duration and ends_at":  {args: args(map[string]any{"duration": "1h", "ends_at": "2030-01-01T00:00:00Z"}), want: "not both"},
+			"invalid duration":      {args: args(map[string]any{"duration": "soon"}), want: "Invalid duration"},
+			"ends in the past":      {args: args(map[string]any{"ends_at": "2020-01-01T00:00:00Z"}), want: "must end after"},
+			"invalid regex matcher": {args: args(map[string]any{"matchers": []any{"alertname=~("}}), want: "invalid matcher"},
+		} {
+			result := fake.call(t, context.Background(), handleCreateSilence, tt.args)
+			assert.True(t, result.IsError, name)
+			assert.Contains(t, getResultText(result), tt.want, name)
+		}
+		assert.Empty(t, fake.requests)
+	})
+}
+
+func TestHandleExpireSilence(t *testing.T) {
+	fake := newFakeAlertmanager(t)
+
+	result := fake.call(t, context.Background(), handleExpireSilence, map[string]any{"silence_id": "a1"})
+	require.False(t, result.IsError, getResultText(result))
+	assert.Equal(t, http.MethodDelete, fake.last().Method)
+	assert.Equal(t, "/api/v2/silence/a1", fake.last().URL.Path)
+
+	result = fake.call(t, context.Background(), handleExpireSilence, map[string]any{"silence_id": "0000dead"})
+	assert.True(t, result.IsError)
+	assert.Contains(t, getResultText(result), "Alertmanager API error (404)")
+
+	result = fake.call(t, context.Background(), handleExpireSilence, map[string]any{"silence_id": "../alerts"})
+	assert.True(t, result.IsError)
+	assert.Contains(t, getResultText(result), "Invalid silence ID")
+}
+
+func TestHandleListReceivers(t *testing.T) {
+	fake := newFakeAlertmanager(t)
+
+	var result ReceiverList
+	decode(t, fake.call(t, context.Background(), handleListReceivers, nil), &result)
+	assert.Equal(t, []string{"pagerduty", "slack"}, result.Receivers)
+}
+
+func TestResolveTarget(t *testing.T) {
+	fake := newFakeAlertmanager(t)
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"alertmanager_url": fake.URL + "/"}
+	result, err := handleListReceivers(context.Background(), req)
+	require.NoError(t, err)
+	require.False(t, result.IsError, getResultText(result))
+	assert.Equal(t, "/api/v2/receivers", fake.last().URL.Path)
+
+	_, err = LoadConfig(Config{URL: "ftp://alertmanager:9093"})
+	assert.Error(t, err)
+
+	t.Setenv(EnvURL, "alertmanager.monitoring:9093")
+	config, err := LoadConfig(Config{})
+	require.NoError(t, err)
+	assert.Equal(t, "http://alertmanager.monitoring:9093", config.URL)
+	assert.Equal(t, httpclient.DefaultTimeout, config.client.Timeout)
+
+	// Neither an alertmanager_url nor the default Alertmanager can hang a call
+	_, client, _ := resolveTarget(context.Background(), req)
+	assert.Equal(t, httpclient.DefaultTimeout, client.Timeout)
+	_, client, _ = resolveTarget(context.Background(), mcp.CallToolRequest{})
+	assert.Equal(t, httpclient.DefaultTimeout, client.Timeout)
+}
+
+func TestParseMatcher(t *testing.T) {
+	tests := map[string]apiMatcher{
+		`alertname="HighLatency"`:  {Name: "alertname", Value: "HighLatency", IsEqual: true},
+		"severity != info":         {Name: "severity", Value: "info"},
+		`namespace=~"prod-.*"`:     {Name: "namespace", Value: "prod-.*", IsRegex: true, IsEqual: true},
+		"pod!~web-.*":              {Name: "pod", Value: "web-.*", IsRegex: true},
+		`summary="say \"hi\""`:     {Name: "summary", Value: `say "hi"`, IsEqual: true},
+		"instance=10.0.0.1:9100":   {Name: "instance", Value: "10.0.0.1:9100", IsEqual: true},
+		`job=""`:                   {Name: "job", IsEqual: true},
+		"__name__=up":              {Name: "__name__", Value: "up", IsEqual: true},
+		"service=api=v2":           {Name: "service", Value: "api=v2", IsEqual: true},
+		"alertname = Watchdog":     {Name: "alertname", Value: "Watchdog", IsEqual: true},
+		`path="/api/v1/query?x=1"`: {Name: "path", Value: "/api/v1/query?x=1", IsEqual: true},
+	}
+	for value, want := range tests {
+		got, err := parseMatcher(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, want, got, value)
+	}
+
+	for _, value := range []string{"", "severity", "1abc=x", `a="unterminated`, "a=~["} {
+		_, err := parseMatcher(value)
+		assert.Error(t, err, value)
+	}
+}
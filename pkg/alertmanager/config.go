@@ -0,0 +1,76 @@
+package alertmanager
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kagent-dev/tools/internal/httpclient"
+	"github.com/kagent-dev/tools/internal/security"
+)
+
+// DefaultURL is the Alertmanager queried when none is configured
+const DefaultURL = "http://localhost:9093"
+
+// Environment variables read for the fields of the config left empty
+const (
+	EnvURL             = "ALERTMANAGER_URL"
+	EnvUsername        = "ALERTMANAGER_USERNAME"
+	EnvPassword        = "ALERTMANAGER_PASSWORD"
+	EnvBearerTokenFile = "ALERTMANAGER_BEARER_TOKEN_FILE"
+	EnvCAFile          = "ALERTMANAGER_CA_FILE"
+	EnvTenantID        = "ALERTMANAGER_TENANT_ID"
+)
+
+// Config is the Alertmanager the tools talk to and how to reach it. Mimir and Cortex serve
+// the Alertmanager API of a tenant named by TenantID.
+type Config struct {
+	URL string
+	httpclient.Options
+
+	client *http.Client
+}
+
+// LoadConfig reads the fields of config left empty from the environment, validates the URL
+// and creates the client
+func LoadConfig(config Config) (*Config, error) {
+	httpclient.ApplyEnv(map[*string]string{
+		&config.URL:             EnvURL,
+		&config.Username:        EnvUsername,
+		&config.Password:        EnvPassword,
+		&config.BearerTokenFile: EnvBearerTokenFile,
+		&config.CAFile:          EnvCAFile,
+		&config.TenantID:        EnvTenantID,
+	})
+
+	var err error
+	if config.URL, err = httpclient.NormalizeURL(config.URL, DefaultURL); err != nil {
+		return nil, fmt.Errorf("invalid Alertmanager URL: %w", err)
+	}
+
+	client, err := httpclient.New(config.Options)
+	if err != nil {
+		return nil, err
+	}
+	config.client = client
+	return &config, nil
+}
+
+// resolveTarget returns the Alertmanager URL and client of a tool call: the alertmanager_url
+// argument with a client without credentials, else the configured Alertmanager, else
+// DefaultURL
+func resolveTarget(ctx context.Context, request mcp.CallToolRequest) (string, *http.Client, *mcp.CallToolResult) {
+	if alertmanagerURL := mcp.ParseString(request, "alertmanager_url", ""); alertmanagerURL != "" {
+		if err := security.ValidateURL(alertmanagerURL); err != nil {
+			return "", nil, mcp.NewToolResultError(fmt.Sprintf("Invalid Alertmanager URL: %v", err))
+		}
+		return strings.TrimSuffix(alertmanagerURL, "/"), httpclient.Default, nil
+	}
+	if config := httpclient.ConfigFromContext[Config](ctx); config != nil {
+		return config.URL, config.client, nil
+	}
+	return DefaultURL, httpclient.Default, nil
+}
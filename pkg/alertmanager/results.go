@@ -0,0 +1,200 @@
+package alertmanager
+
+import (
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Alert is an alert as Alertmanager tracks it
+type Alert struct {
+	Name         string            `json:"alertname"`
+	Severity     string            `json:"severity,omitempty"`
+	State        string            `json:"state" jsonschema_description:"active, suppressed (silenced or inhibited) or unprocessed"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	StartsAt     time.Time         `json:"starts_at"`
+	EndsAt       time.Time         `json:"ends_at"`
+	Receivers    []string          `json:"receivers,omitempty"`
+	SilencedBy   []string          `json:"silenced_by,omitempty" jsonschema_description:"IDs of the silences muting the alert"`
+	InhibitedBy  []string          `json:"inhibited_by,omitempty" jsonschema_description:"Fingerprints of the alerts inhibiting the alert"`
+	Fingerprint  string            `json:"fingerprint"`
+	GeneratorURL string            `json:"generator_url,omitempty"`
+}
+
+// AlertList is the result of alertmanager_list_alerts
+type AlertList struct {
+	Total  int     `json:"total"`
+	Alerts []Alert `json:"alerts"`
+}
+
+// AlertGroup is a group of alerts notified together
+type AlertGroup struct {
+	Labels   map[string]string `json:"labels" jsonschema_description:"Labels the alerts are grouped by"`
+	Receiver string            `json:"receiver"`
+	Alerts   []Alert           `json:"alerts"`
+}
+
+// AlertGroupList is the result of alertmanager_list_alert_groups
+type AlertGroupList struct {
+	Total  int          `json:"total"`
+	Groups []AlertGroup `json:"groups"`
+}
+
+// Silence mutes the alerts matching all its matchers between its start and end
+type Silence struct {
+	ID        string    `json:"id"`
+	State     string    `json:"state" jsonschema_description:"active, pending or expired"`
+	Matchers  []string  `json:"matchers" jsonschema_description:"Matchers such as alertname=\"HighLatency\""`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	CreatedBy string    `json:"created_by"`
+	Comment   string    `json:"comment"`
+}
+
+// SilenceList is the result of alertmanager_list_silences
+type SilenceList struct {
+	Total    int       `json:"total"`
+	Silences []Silence `json:"silences"`
+}
+
+// SilenceResult is the result of alertmanager_create_silence
+type SilenceResult struct {
+	SilenceID string  `json:"silence_id,omitempty" jsonschema_description:"ID of the created silence, empty on a dry run"`
+	DryRun    bool    `json:"dry_run"`
+	Silence   Silence `json:"silence"`
+	Matching  []Alert `json:"matching_alerts" jsonschema_description:"Alerts the silence mutes now"`
+}
+
+// ReceiverList is the result of alertmanager_list_receivers
+type ReceiverList struct {
+	Receivers []string `json:"receivers"`
+}
+
+// apiAlert is an alert of the Alertmanager v2 API
+type apiAlert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	Fingerprint  string            `json:"fingerprint"`
+	GeneratorURL string            `json:"generatorURL"`
+	Receivers    []struct {
+		Name string `json:"name"`
+	} `json:"receivers"`
+	Status struct {
+		State       string   `json:"state"`
+		SilencedBy  []string `json:"silencedBy"`
+		InhibitedBy []string `json:"inhibitedBy"`
+	} `json:"status"`
+}
+
+// apiAlertGroup is an alert group of the Alertmanager v2 API
+type apiAlertGroup struct {
+	Labels   map[string]string `json:"labels"`
+	Receiver struct {
+		Name string `json:"name"`
+	} `json:"receiver"`
+	Alerts []apiAlert `json:"alerts"`
+}
+
+// apiMatcher is a silence matcher of the Alertmanager v2 API
+type apiMatcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+	IsEqual bool   `json:"isEqual"`
+}
+
+// apiSilence is a silence of the Alertmanager v2 API
+type apiSilence struct {
+	ID     string `json:"id,omitempty"`
+	Status *struct {
+		State string `json:"state"`
+	} `json:"status,omitempty"`
+	Matchers  []apiMatcher `json:"matchers"`
+	StartsAt  time.Time    `json:"startsAt"`
+	EndsAt    time.Time    `json:"endsAt"`
+	CreatedBy string       `json:"createdBy"`
+	Comment   string       `json:"comment"`
+}
+
+// String renders the matcher as Alertmanager parses it, e.g. namespace=~"prod-.*"
+func (m apiMatcher) String() string {
+	operator := "="
+	switch {
+	case m.IsRegex && m.IsEqual:
+		operator = "=~"
+	case m.IsRegex:
+		operator = "!~"
+	case !m.IsEqual:
+		operator = "!="
+	}
+	return m.Name + operator + strconv.Quote(m.Value)
+}
+
+func newAlert(alert apiAlert) Alert {
+	result := Alert{
+		Name:         alert.Labels["alertname"],
+		Severity:     alert.Labels["severity"],
+		State:        alert.Status.State,
+		Labels:       alert.Labels,
+		Annotations:  alert.Annotations,
+		StartsAt:     alert.StartsAt,
+		EndsAt:       alert.EndsAt,
+		SilencedBy:   alert.Status.SilencedBy,
+		InhibitedBy:  alert.Status.InhibitedBy,
+		Fingerprint:  alert.Fingerprint,
+		GeneratorURL: alert.GeneratorURL,
+	}
+	for _, receiver := range alert.Receivers {
+		result.Receivers = append(result.Receivers, receiver.Name)
+	}
+	return result
+}
+
+// newAlerts converts alerts, ordered by name and then oldest first
+func newAlerts(alerts []apiAlert) []Alert {
+	result := make([]Alert, 0, len(alerts))
+	for _, alert := range alerts {
+		result = append(result, newAlert(alert))
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].StartsAt.Before(result[j].StartsAt)
+	})
+	return result
+}
+
+func newSilence(silence apiSilence) Silence {
+	result := Silence{
+		ID:        silence.ID,
+		Matchers:  make([]string, 0, len(silence.Matchers)),
+		StartsAt:  silence.StartsAt,
+		EndsAt:    silence.EndsAt,
+		CreatedBy: silence.CreatedBy,
+		Comment:   silence.Comment,
+	}
+	if silence.Status != nil {
+		result.State = silence.Status.State
+	}
+	for _, matcher := range silence.Matchers {
+		result.Matchers = append(result.Matchers, matcher.String())
+	}
+	return result
+}
+
+// silenceStateOrder lists active silences first, then pending and expired ones
+var silenceStateOrder = map[string]int{"active": 0, "pending": 1, "expired": 2}
+
+// sortSilences orders silences by state and then by end, latest first
+func sortSilences(silences []Silence) {
+	sort.SliceStable(silences, func(i, j int) bool {
+		if silenceStateOrder[silences[i].State] != silenceStateOrder[silences[j].State] {
+			return silenceStateOrder[silences[i].State] < silenceStateOrder[silences[j].State]
+		}
+		return silences[i].EndsAt.After(silences[j].EndsAt)
+	})
+}
@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kagent-dev/tools/internal/cache"
+	"github.com/kagent-dev/tools/internal/telemetry"
+)
+
+// cacheTypeDescription documents the type argument of every cache tool
+const cacheTypeDescription = "Cache to act on: kubernetes, helm, istio or command (optional, defaults to every cache)"
+
+// Report the size, limits and hit rate of the result caches
+func handleCacheStats(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	stats, err := cache.StatsByType(mcp.ParseString(request, "type", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal cache stats: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// Remove cached results by cache type, cluster, namespace or key prefix
+func handleCacheInvalidate(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	removed, err := cache.InvalidateSelected(cache.Selector{
+		Type:      mcp.ParseString(request, "type", ""),
+		Cluster:   mcp.ParseString(request, "cluster", ""),
+		Namespace: mcp.ParseString(request, "namespace", ""),
+		KeyPrefix: mcp.ParseString(request, "key_prefix", ""),
+	})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	data, err := json.MarshalIndent(map[string]map[string]int{"removed": removed}, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal invalidated results: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// Override the TTL of the results the caches store from now on
+func handleCacheSetTTL(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	typeName := mcp.ParseString(request, "type", "")
+	ttl, err := time.ParseDuration(mcp.ParseString(request, "ttl", ""))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid ttl: %v", err)), nil
+	}
+	if err := cache.SetTTLByType(typeName, ttl); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return handleCacheStats(ctx, request)
+}
+
+// RegisterTools registers the cache management tools with the MCP server
+func RegisterTools(s *server.MCPServer, readOnly bool) {
+	s.AddTool(mcp.NewTool("cache_stats",
+		mcp.WithDescription("Show the size, byte usage, limits, TTL override, hits, misses and evictions of the tool result caches"),
+		mcp.WithString("type", mcp.Description(cacheTypeDescription)),
+	), telemetry.AdaptToolHandler(telemetry.WithTracing("cache_stats", handleCacheStats)))
+
+	// Write tools - only registered when not in read-only mode
+	if !readOnly {
+		s.AddTool(mcp.NewTool("cache_invalidate",
+			mcp.WithDescription("Remove cached tool results so that the next calls read fresh data. Without filters every result of the selected caches is removed."),
+			mcp.WithString("type", mcp.Description(cacheTypeDescription)),
+			mcp.WithString("cluster", mcp.Description("Only remove results for this cluster, as listed by k8s_list_clusters (optional)")),
+			mcp.WithString("namespace", mcp.Description("Only remove results that may include objects of this namespace (optional)")),
+			mcp.WithString("key_prefix", mcp.Description("Only remove results whose cache key starts with this prefix, e.g. kubectl:get:pods (optional)")),
+		), telemetry.AdaptToolHandler(telemetry.WithTracing("cache_invalidate", handleCacheInvalidate)))
+
+		s.AddTool(mcp.NewTool("cache_set_ttl",
+			mcp.WithDescription("Set how long the caches keep the results they store from now on, overriding the tools' defaults, and shorten the results already stored to it"),
+			mcp.WithString("ttl", mcp.Description("TTL as a duration, e.g. 30s or 5m; 0 restores the tools' defaults"), mcp.Required()),
+			mcp.WithString("type", mcp.Description(cacheTypeDescription)),
+		), telemetry.AdaptToolHandler(telemetry.WithTracing("cache_set_ttl", handleCacheSetTTL)))
+	}
+}
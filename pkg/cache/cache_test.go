@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kagent-dev/tools/internal/cache"
+)
+
+func callTool(t *testing.T, handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = args
+	result, err := handler(context.Background(), req)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	return result
+}
+
+func resultText(result *mcp.CallToolResult) string {
+	return result.Content[0].(mcp.TextContent).Text
+}
+
+func TestRegisterTools(t *testing.T) {
+	s := server.NewMCPServer("test-server", "v0.0.1")
+	RegisterTools(s, false)
+	assert.Len(t, s.ListTools(), 3)
+
+	s = server.NewMCPServer("test-server", "v0.0.1")
+	RegisterTools(s, true)
+	tools := s.ListTools()
+	assert.Len(t, tools, 1)
+	assert.Contains(t, tools, "cache_stats")
+}
+
+func TestCacheTools(t *testing.T) {
+	kubernetes := cache.GetCacheByType(cache.CacheTypeKubernetes)
+	t.Cleanup(func() {
+		require.NoError(t, cache.SetTTLByType("", 0))
+		kubernetes.Clear()
+	})
+	kubernetes.SetWithTags("kubectl:get:pods:-n:web", "web pods", time.Minute, []cache.Tag{{Namespace: "web", Kind: "pod"}})
+	kubernetes.SetWithTags("kubectl:get:pods:-n:db", "db pods", time.Minute, []cache.Tag{{Namespace: "db", Kind: "pod"}})
+
+	result := callTool(t, handleCacheStats, map[string]any{"type": "kubernetes"})
+	require.False(t, result.IsError, resultText(result))
+	var stats map[string]cache.CacheStats
+	require.NoError(t, json.Unmarshal([]byte(resultText(result)), &stats))
+	assert.Equal(t, 2, stats["kubernetes"].Size)
+	assert.Equal(t, int64(64<<20), stats["kubernetes"].MaxBytes)
+
+	result = callTool(t, handleCacheInvalidate, map[string]any{"type": "kubernetes", "namespace": "web"})
+	require.False(t, result.IsError, resultText(result))
+	assert.JSONEq(t, `{"removed": {"kubernetes": 1}}`, resultText(result))
+	_, found := kubernetes.Get("kubectl:get:pods:-n:db")
+	assert.True(t, found, "results for other namespaces stay cached")
+
+	result = callTool(t, handleCacheSetTTL, map[string]any{"type": "kubernetes", "ttl": "10s"})
+	require.False(t, result.IsError, resultText(result))
+	require.NoError(t, json.Unmarshal([]byte(resultText(result)), &stats))
+	assert.Equal(t, "10s", stats["kubernetes"].TTL)
+
+	for name, args := range map[string]map[string]any{
+		"unknown type": {"type": "redis"},
+		"missing ttl":  {"type": "kubernetes"},
+		"negative ttl": {"ttl": "-1s"},
+	} {
+		assert.True(t, callTool(t, handleCacheSetTTL, args).IsError, name)
+	}
+	assert.True(t, callTool(t, handleCacheInvalidate, map[string]any{"type": "redis"}).IsError)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/kagent-dev/tools/internal/cache"
 	"github.com/kagent-dev/tools/internal/commands"
 	"github.com/kagent-dev/tools/internal/telemetry"
 	"github.com/kagent-dev/tools/pkg/utils"
@@ -21,6 +22,16 @@ func runCiliumCliWithContext(ctx context.Context, args ...string) (string, error
 		Execute(ctx)
 }
 
+// runCiliumCliWrite runs a cilium command that changes the cluster. Installs, upgrades and
+// mesh changes touch objects across the cluster, so every cached result for it is evicted.
+func runCiliumCliWrite(ctx context.Context, args ...string) (string, error) {
+	output, err := runCiliumCliWithContext(ctx, args...)
+	if err == nil {
+		commands.InvalidateCache(ctx, cache.Tag{})
+	}
+	return output, err
+}
+
 func handleCiliumStatusAndVersion(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	status, err := runCiliumCliWithContext(ctx, "status")
 	if err != nil {
@@ -48,7 +59,7 @@ func handleUpgradeCilium(ctx context.Context, request mcp.CallToolRequest) (*mcp
 		args = append(args, "--datapath-mode", datapathMode)
 	}
 
-	output, err := runCiliumCliWithContext(ctx, args...)
+	output, err := runCiliumCliWrite(ctx, args...)
 	if err != nil {
 		return mcp.NewToolResultError("Error upgrading Cilium: " + err.Error()), nil
 	}
@@ -72,7 +83,7 @@ func handleInstallCilium(ctx context.Context, request mcp.CallToolRequest) (*mcp
 		args = append(args, "--datapath-mode", datapathMode)
 	}
 
-	output, err := runCiliumCliWithContext(ctx, args...)
+	output, err := runCiliumCliWrite(ctx, args...)
 	if err != nil {
 		return mcp.NewToolResultError("Error installing Cilium: " + err.Error()), nil
 	}
@@ -81,7 +92,7 @@ func handleInstallCilium(ctx context.Context, request mcp.CallToolRequest) (*mcp
 }
 
 func handleUninstallCilium(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	output, err := runCiliumCliWithContext(ctx, "uninstall")
+	output, err := runCiliumCliWrite(ctx, "uninstall")
 	if err != nil {
 		return mcp.NewToolResultError("Error uninstalling Cilium: " + err.Error()), nil
 	}
@@ -102,7 +113,7 @@ func handleConnectToRemoteCluster(ctx context.Context, request mcp.CallToolReque
 		args = append(args, "--destination-context", context)
 	}
 
-	output, err := runCiliumCliWithContext(ctx, args...)
+	output, err := runCiliumCliWrite(ctx, args...)
 	if err != nil {
 		return mcp.NewToolResultError("Error connecting to remote cluster: " + err.Error()), nil
 	}
@@ -119,7 +130,7 @@ func handleDisconnectRemoteCluster(ctx context.Context, request mcp.CallToolRequ
 
 	args := []string{"clustermesh", "disconnect", "--destination-cluster", clusterName}
 
-	output, err := runCiliumCliWithContext(ctx, args...)
+	output, err := runCiliumCliWrite(ctx, args...)
 	if err != nil {
 		return mcp.NewToolResultError("Error disconnecting from remote cluster: " + err.Error()), nil
 	}
@@ -174,7 +185,7 @@ func handleToggleHubble(ctx context.Context, request mcp.CallToolRequest) (*mcp.
 		action = "disable"
 	}
 
-	output, err := runCiliumCliWithContext(ctx, "hubble", action)
+	output, err := runCiliumCliWrite(ctx, "hubble", action)
 	if err != nil {
 		return mcp.NewToolResultError("Error toggling Hubble: " + err.Error()), nil
 	}
@@ -193,7 +204,7 @@ func handleToggleClusterMesh(ctx context.Context, request mcp.CallToolRequest) (
 		action = "disable"
 	}
 
-	output, err := runCiliumCliWithContext(ctx, "clustermesh", action)
+	output, err := runCiliumCliWrite(ctx, "clustermesh", action)
 	if err != nil {
 		return mcp.NewToolResultError("Error toggling cluster mesh: " + err.Error()), nil
 	}
@@ -280,6 +291,7 @@ func RegisterTools(s *server.MCPServer, readOnly bool) {
 	s.AddTool(mcp.NewTool("cilium_get_endpoints_list",
 		mcp.WithDescription("Get the list of all endpoints in the cluster"),
 		mcp.WithString("node_name", mcp.Description("The name of the node to get the endpoints list for")),
+		mcp.WithOutputSchema[EndpointList](),
 	), telemetry.AdaptToolHandler(telemetry.WithTracing("cilium_get_endpoints_list", handleGetEndpointsList)))
 
 	s.AddTool(mcp.NewTool("cilium_get_endpoint_details",
@@ -738,6 +750,21 @@ func handleDisconnectEndpoint(ctx context.Context, request mcp.CallToolRequest)
 	return mcp.NewToolResultText(output), nil
 }
 
+// EndpointList is the structured content of cilium_get_endpoints_list
+type EndpointList struct {
+	Columns   []string            `json:"columns" jsonschema_description:"Row keys in the order cilium-dbg prints the columns, e.g. endpoint, identity, labels_source_key_value, ipv4, status"`
+	Endpoints []map[string]string `json:"endpoints" jsonschema_description:"Each endpoint by column key; the labels of an endpoint are joined with commas"`
+}
+
+// endpointList parses the table cilium-dbg endpoint list prints
+func endpointList(text string) EndpointList {
+	list := EndpointList{Columns: []string{}, Endpoints: []map[string]string{}}
+	if table, ok := utils.ParseTable(text); ok {
+		list.Columns, list.Endpoints = table.Columns, table.Rows
+	}
+	return list
+}
+
 func handleGetEndpointsList(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	nodeName := mcp.ParseString(request, "node_name", "")
 
@@ -745,7 +772,7 @@ func handleGetEndpointsList(ctx context.Context, request mcp.CallToolRequest) (*
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to get endpoints list: %v", err)), nil
 	}
-	return mcp.NewToolResultText(output), nil
+	return mcp.NewToolResultStructured(endpointList(output), output), nil
 }
 
 func handleListIdentities(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
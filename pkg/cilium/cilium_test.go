@@ -296,6 +296,31 @@ func TestHandleGetEndpointsList(t *testing.T) {
 	assert.Contains(t, getResultText(result), "ENDPOINT")
 }
 
+func TestHandleGetEndpointsListStructured(t *testing.T) {
+	output := `ENDPOINT   POLICY (ingress)   POLICY (egress)   IDENTITY   LABELS (source:key[=value])               IPv6   IPv4        STATUS
+           ENFORCEMENT        ENFORCEMENT
+1204       Disabled           Disabled          4          reserved:health                                  10.0.0.94   ready
+2471       Enabled            Disabled          8201       k8s:app=web                                      10.0.0.12   ready
+                                                           k8s:io.kubernetes.pod.namespace=default
+`
+	mock := cmd.NewMockShellExecutor()
+	mockCiliumDbgCommand(mock, []string{"endpoint", "list"}, output, nil)
+	ctx := cmd.WithShellExecutor(context.Background(), mock)
+
+	result, err := handleGetEndpointsList(ctx, newRequestWithArgs(map[string]any{"node_name": "test-node"}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	list, ok := result.StructuredContent.(EndpointList)
+	require.True(t, ok)
+	require.Len(t, list.Endpoints, 2)
+	assert.Equal(t, "2471", list.Endpoints[1]["endpoint"])
+	assert.Equal(t, "Enabled", list.Endpoints[1]["policy_ingress_enforcement"])
+	assert.Equal(t, "8201", list.Endpoints[1]["identity"])
+	assert.Equal(t, "k8s:app=web, k8s:io.kubernetes.pod.namespace=default", list.Endpoints[1]["labels_source_key_value"])
+	assert.Equal(t, "ready", list.Endpoints[1]["status"])
+}
+
 func TestHandleGetEndpointDetails(t *testing.T) {
 	ctx := context.Background()
 	mock := cmd.NewMockShellExecutor()
@@ -0,0 +1,90 @@
+package grafana
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+
+	"github.com/kagent-dev/tools/internal/httpclient"
+	"github.com/kagent-dev/tools/pkg/prometheus"
+)
+
+// DefaultURL is the Grafana queried when none is configured
+const DefaultURL = "http://localhost:3000"
+
+// orgHeader selects the organization of a Grafana request
+const orgHeader = "X-Grafana-Org-Id"
+
+// Environment variables read for the fields of the config left empty
+const (
+	EnvURL      = "GRAFANA_URL"
+	EnvAPIKey   = "GRAFANA_API_KEY"
+	EnvUsername = "GRAFANA_USERNAME"
+	EnvPassword = "GRAFANA_PASSWORD"
+	EnvCAFile   = "GRAFANA_CA_FILE"
+	EnvOrgID    = "GRAFANA_ORG_ID"
+)
+
+// Config is the Grafana the tools talk to and how to reach it. APIKey is a service account
+// token or legacy API key sent as a bearer token; Username and Password are used instead for
+// basic auth.
+type Config struct {
+	URL    string
+	APIKey string
+	OrgID  string
+	httpclient.Options
+
+	// Prometheus holds the data sources grafana_query_panel runs panel queries against
+	Prometheus *prometheus.Config
+
+	client *http.Client
+}
+
+// LoadConfig reads the fields of config left empty from the environment, validates the URL
+// and creates the client
+func LoadConfig(config Config) (*Config, error) {
+	httpclient.ApplyEnv(map[*string]string{
+		&config.URL:      EnvURL,
+		&config.APIKey:   EnvAPIKey,
+		&config.Username: EnvUsername,
+		&config.Password: EnvPassword,
+		&config.CAFile:   EnvCAFile,
+		&config.OrgID:    EnvOrgID,
+	})
+
+	var err error
+	if config.URL, err = httpclient.NormalizeURL(config.URL, DefaultURL); err != nil {
+		return nil, fmt.Errorf("invalid Grafana URL: %w", err)
+	}
+	if config.APIKey != "" && config.Username != "" {
+		return nil, errors.New("set either a Grafana API key or a username, not both")
+	}
+
+	options := config.Options
+	options.Headers = maps.Clone(options.Headers)
+	if options.Headers == nil {
+		options.Headers = map[string]string{}
+	}
+	if config.APIKey != "" {
+		options.Headers["Authorization"] = "Bearer " + config.APIKey
+	}
+	if config.OrgID != "" {
+		options.Headers[orgHeader] = config.OrgID
+	}
+	client, err := httpclient.New(options)
+	if err != nil {
+		return nil, err
+	}
+	config.client = client
+	return &config, nil
+}
+
+// configFromContext returns the config of a tool call, the default Grafana when none is set
+func configFromContext(ctx context.Context) *Config {
+	if config := httpclient.ConfigFromContext[Config](ctx); config != nil {
+		return config
+	}
+	return &Config{URL: DefaultURL, client: httpclient.Default}
+}
@@ -0,0 +1,408 @@
+package grafana
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/kagent-dev/tools/internal/errors"
+	"github.com/kagent-dev/tools/internal/httpclient"
+	"github.com/kagent-dev/tools/internal/identity"
+	"github.com/kagent-dev/tools/internal/security"
+	"github.com/kagent-dev/tools/internal/telemetry"
+	"github.com/kagent-dev/tools/pkg/prometheus"
+)
+
+// Grafana tools using the Grafana HTTP API
+
+const (
+	// defaultSearchLimit is the number of dashboards a search returns without limit
+	defaultSearchLimit = 50
+
+	// defaultMaxPoints is the number of points per series the automatic step of a panel
+	// query aims for, as for prometheus_query_range_tool
+	defaultMaxPoints = 250
+
+	// annotationTag marks the annotations the tools create
+	annotationTag = "kagent"
+)
+
+// uidPattern matches the UID of a dashboard or data source
+var uidPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,40}$`)
+
+// callAPI sends a request for path, e.g. /api/search, with params and a JSON body to the
+// Grafana of the call and decodes the JSON response into result when it is not nil.
+// Failures are returned as a tool error result.
+func callAPI(ctx context.Context, operation, method, path string, params url.Values, body, result any) *mcp.CallToolResult {
+	config := configFromContext(ctx)
+	apiURL := config.URL + path
+	fullURL := apiURL
+	if len(params) > 0 {
+		fullURL = fmt.Sprintf("%s?%s", apiURL, params.Encode())
+	}
+
+	newError := func(cause error) *errors.ToolError {
+		toolErr := errors.NewGrafanaError(operation, cause).
+			WithContext("grafana_url", config.URL).
+			WithContext("api_url", apiURL)
+		for name, values := range params {
+			toolErr = toolErr.WithContext(name, strings.Join(values, ", "))
+		}
+		return toolErr
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return newError(err).ToMCPResult()
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, fullURL, reader)
+	if err != nil {
+		return newError(err).ToMCPResult()
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := config.client.Do(req)
+	if err != nil {
+		return newError(err).ToMCPResult()
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return newError(err).WithContext("status_code", resp.StatusCode).ToMCPResult()
+	}
+	if resp.StatusCode != http.StatusOK {
+		cause := fmt.Errorf("Grafana API error (%d): %s", resp.StatusCode, apiErrorMessage(data))
+		return newError(cause).WithContext("status_code", resp.StatusCode).ToMCPResult()
+	}
+
+	if result != nil {
+		if err := json.Unmarshal(data, result); err != nil {
+			return newError(fmt.Errorf("failed to decode response: %w", err)).ToMCPResult()
+		}
+	}
+	return nil
+}
+
+// apiErrorMessage returns the message of a Grafana error response, else the body
+func apiErrorMessage(body []byte) string {
+	var response struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &response); err == nil && response.Message != "" {
+		return response.Message
+	}
+	return strings.TrimSpace(string(body))
+}
+
+// structuredResult returns result as pretty-printed JSON text and structured content
+func structuredResult(result any) *mcp.CallToolResult {
+	content, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError("failed to marshal result: " + err.Error())
+	}
+	return mcp.NewToolResultStructured(result, string(content))
+}
+
+// getDashboard fetches the dashboard of the dashboard_uid or uid argument
+func getDashboard(ctx context.Context, request mcp.CallToolRequest, name string) (apiDashboard, *mcp.CallToolResult) {
+	uid := mcp.ParseString(request, name, "")
+	if uid == "" {
+		return apiDashboard{}, mcp.NewToolResultError(name + " parameter is required")
+	}
+	if !uidPattern.MatchString(uid) {
+		return apiDashboard{}, mcp.NewToolResultError(fmt.Sprintf("Invalid dashboard UID %q", uid))
+	}
+
+	var dashboard apiDashboard
+	if errResult := callAPI(ctx, "get_dashboard", http.MethodGet, "/api/dashboards/uid/"+uid, nil, nil, &dashboard); errResult != nil {
+		return apiDashboard{}, errResult
+	}
+	return dashboard, nil
+}
+
+func handleSearchDashboards(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	params := url.Values{"type": {"dash-db"}}
+	if query := mcp.ParseString(request, "query", ""); query != "" {
+		params.Set("query", query)
+	}
+	for _, tag := range request.GetStringSlice("tags", nil) {
+		params.Add("tag", tag)
+	}
+	params.Set("limit", strconv.Itoa(max(mcp.ParseInt(request, "limit", defaultSearchLimit), 1)))
+
+	var hits []apiSearchHit
+	if errResult := callAPI(ctx, "search_dashboards", http.MethodGet, "/api/search", params, nil, &hits); errResult != nil {
+		return errResult, nil
+	}
+
+	baseURL := configFromContext(ctx).URL
+	result := DashboardList{Total: len(hits), Dashboards: make([]DashboardSummary, 0, len(hits))}
+	for _, hit := range hits {
+		result.Dashboards = append(result.Dashboards, DashboardSummary{
+			UID:    hit.UID,
+			Title:  hit.Title,
+			URL:    baseURL + hit.URL,
+			Folder: hit.FolderTitle,
+			Tags:   hit.Tags,
+		})
+	}
+	return structuredResult(result), nil
+}
+
+func handleGetDashboard(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	dashboard, errResult := getDashboard(ctx, request, "uid")
+	if errResult != nil {
+		return errResult, nil
+	}
+	return structuredResult(newDashboard(dashboard, configFromContext(ctx).URL)), nil
+}
+
+func handleListDataSources(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var dataSources []apiDataSource
+	if errResult := callAPI(ctx, "list_datasources", http.MethodGet, "/api/datasources", nil, nil, &dataSources); errResult != nil {
+		return errResult, nil
+	}
+
+	result := DataSourceList{DataSources: make([]DataSource, 0, len(dataSources))}
+	for _, ds := range dataSources {
+		result.DataSources = append(result.DataSources, DataSource{UID: ds.UID, Name: ds.Name, Type: ds.Type, URL: ds.URL, IsDefault: ds.IsDefault})
+	}
+	sort.Slice(result.DataSources, func(i, j int) bool {
+		return result.DataSources[i].Name < result.DataSources[j].Name
+	})
+	return structuredResult(result), nil
+}
+
+func handleQueryPanel(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	panelID := mcp.ParseInt(request, "panel_id", 0)
+	if panelID == 0 {
+		return mcp.NewToolResultError("panel_id parameter is required"), nil
+	}
+	dashboard, errResult := getDashboard(ctx, request, "dashboard_uid")
+	if errResult != nil {
+		return errResult, nil
+	}
+	converted := newDashboard(dashboard, "")
+
+	// Pick the query of the panel: ref_id, else the first visible query with an expression
+	var panel *Panel
+	for i := range converted.Panels {
+		if converted.Panels[i].ID == panelID {
+			panel = &converted.Panels[i]
+		}
+	}
+	if panel == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Panel %d not found in dashboard %s; grafana_get_dashboard lists its panels", panelID, converted.UID)), nil
+	}
+	refID := mcp.ParseString(request, "ref_id", "")
+	var query *Query
+	for i, q := range panel.Queries {
+		if (refID != "" && q.RefID == refID) || (refID == "" && !q.Hidden && q.Expr != "") {
+			query = &panel.Queries[i]
+			break
+		}
+	}
+	switch {
+	case query == nil && refID != "":
+		return mcp.NewToolResultError(fmt.Sprintf("Panel %q has no query %s", panel.Title, refID)), nil
+	case query == nil:
+		return mcp.NewToolResultError(fmt.Sprintf("Panel %q has no query with an expression", panel.Title)), nil
+	case query.Expr == "":
+		return mcp.NewToolResultError(fmt.Sprintf("Query %s of panel %q has no expression", query.RefID, panel.Title)), nil
+	case query.DatasourceType != "" && query.DatasourceType != "prometheus":
+		return mcp.NewToolResultError(fmt.Sprintf("Query %s of panel %q uses a %s data source; only Prometheus queries can be run", query.RefID, panel.Title, query.DatasourceType)), nil
+	}
+
+	// Render the query for the time range as Grafana would
+	r, err := prometheus.ResolveRange(mcp.ParseString(request, "start", ""), mcp.ParseString(request, "end", ""), mcp.ParseString(request, "step", ""),
+		mcp.ParseInt(request, "max_points", defaultMaxPoints), time.Now())
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	values := variableValues(dashboard, mcp.ParseStringMap(request, "variables", nil))
+	expr, missing := renderQuery(query.Expr, values, r)
+	if len(missing) > 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("Query %s of panel %q uses variables without a value: %s; set them with the variables argument", query.RefID, panel.Title, strings.Join(missing, ", "))), nil
+	}
+	if err := security.ValidatePromQLQuery(expr); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Query %s of panel %q renders to %s, which the Prometheus tools reject: %v", query.RefID, panel.Title, expr, err)), nil
+	}
+
+	config := configFromContext(ctx)
+	datasource := mcp.ParseString(request, "datasource", "")
+	if datasource == "" {
+		uid, _ := renderQuery(query.Datasource, values, r)
+		datasource = matchDataSource(ctx, config.Prometheus, uid)
+	}
+
+	arguments := map[string]any{
+		"query": expr,
+		"start": strconv.FormatInt(r.Start.Unix(), 10),
+		"end":   strconv.FormatInt(r.End.Unix(), 10),
+		"step":  strconv.FormatFloat(r.Step.Seconds(), 'f', -1, 64),
+	}
+	for _, name := range []string{"output", "top_k", "sort_by"} {
+		if value, ok := request.GetArguments()[name]; ok {
+			arguments[name] = value
+		}
+	}
+	if datasource != "" {
+		arguments["datasource"] = datasource
+	}
+	result, err := prometheus.QueryRange(ctx, config.Prometheus, arguments)
+	if err != nil || result.IsError {
+		return result, err
+	}
+
+	if datasource == "" {
+		datasource = "default"
+	}
+	result.Content = append(result.Content, mcp.NewTextContent(fmt.Sprintf("Query %s of panel %q of dashboard %q run against Prometheus data source %s: %s",
+		query.RefID, panel.Title, converted.Title, datasource, expr)))
+	return result, nil
+}
+
+// matchDataSource returns the name of the Prometheus data source with the URL of the Grafana
+// data source uid, a UID or name, and an empty string, the default data source, when none
+// matches
+func matchDataSource(ctx context.Context, config *prometheus.Config, uid string) string {
+	if config == nil || uid == "" || !uidPattern.MatchString(uid) {
+		return ""
+	}
+	var ds apiDataSource
+	if callAPI(ctx, "get_datasource", http.MethodGet, "/api/datasources/uid/"+uid, nil, nil, &ds) != nil &&
+		callAPI(ctx, "get_datasource", http.MethodGet, "/api/datasources/name/"+uid, nil, nil, &ds) != nil {
+		return ""
+	}
+	for _, name := range config.Names() {
+		source, err := config.Lookup(name)
+		if err == nil && strings.TrimSuffix(ds.URL, "/") == source.URL {
+			return name
+		}
+	}
+	return ""
+}
+
+func handleCreateAnnotation(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	text := strings.TrimSpace(mcp.ParseString(request, "text", ""))
+	if text == "" {
+		return mcp.NewToolResultError("text parameter is required"), nil
+	}
+
+	// Tag the annotation so that dashboards can show the actions of the tools and of whom
+	tags := []string{annotationTag}
+	if principal, ok := identity.PrincipalFromContext(ctx); ok && principal.Subject != "" {
+		tags = append(tags, "principal:"+principal.Subject)
+	}
+	tags = append(tags, request.GetStringSlice("tags", nil)...)
+	annotation := map[string]any{"text": text, "tags": tags}
+
+	if uid := mcp.ParseString(request, "dashboard_uid", ""); uid != "" {
+		if !uidPattern.MatchString(uid) {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid dashboard UID %q", uid)), nil
+		}
+		annotation["dashboardUID"] = uid
+		if panelID := mcp.ParseInt(request, "panel_id", 0); panelID != 0 {
+			annotation["panelId"] = panelID
+		}
+	}
+	for name, field := range map[string]string{"time": "time", "time_end": "timeEnd"} {
+		if value := mcp.ParseString(request, name, ""); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid %s: %v", name, err)), nil
+			}
+			annotation[field] = t.UnixMilli()
+		}
+	}
+	if _, ok := annotation["time"]; !ok {
+		annotation["time"] = time.Now().UnixMilli()
+	}
+
+	var created struct {
+		ID      int64  `json:"id"`
+		Message string `json:"message"`
+	}
+	if errResult := callAPI(ctx, "create_annotation", http.MethodPost, "/api/annotations", nil, annotation, &created); errResult != nil {
+		return errResult, nil
+	}
+	return structuredResult(AnnotationResult{ID: created.ID, Message: created.Message, Tags: tags}), nil
+}
+
+// RegisterTools registers the Grafana tools against the default Grafana
+func RegisterTools(s *server.MCPServer, readOnly bool) {
+	RegisterToolsWithConfig(s, readOnly, nil)
+}
+
+// RegisterToolsWithConfig registers the Grafana tools against the Grafana of config, which may
+// be nil
+func RegisterToolsWithConfig(s *server.MCPServer, readOnly bool, config *Config) {
+	s.AddTool(mcp.NewTool("grafana_search_dashboards",
+		mcp.WithDescription("Search dashboards by title and tags"),
+		mcp.WithString("query", mcp.Description("Text the dashboard titles must contain (default: all dashboards)")),
+		mcp.WithArray("tags", mcp.WithStringItems(), mcp.Description("Tags the dashboards must all have")),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Maximum number of dashboards to return (default: %d)", defaultSearchLimit))),
+		mcp.WithOutputSchema[DashboardList](),
+	), telemetry.AdaptToolHandler(telemetry.WithTracing("grafana_search_dashboards", httpclient.WithConfig(config, handleSearchDashboards))))
+
+	s.AddTool(mcp.NewTool("grafana_get_dashboard",
+		mcp.WithDescription("Get the variables and panels of a dashboard with the PromQL or LogQL queries of each panel"),
+		mcp.WithString("uid", mcp.Description("UID of the dashboard, see grafana_search_dashboards"), mcp.Required()),
+		mcp.WithOutputSchema[Dashboard](),
+	), telemetry.AdaptToolHandler(telemetry.WithTracing("grafana_get_dashboard", httpclient.WithConfig(config, handleGetDashboard))))
+
+	s.AddTool(mcp.NewTool("grafana_query_panel",
+		mcp.WithDescription("Run the Prometheus query of a dashboard panel over a time range, with its variables rendered as Grafana would, through the Prometheus tools"),
+		mcp.WithString("dashboard_uid", mcp.Description("UID of the dashboard"), mcp.Required()),
+		mcp.WithNumber("panel_id", mcp.Description("ID of the panel, see grafana_get_dashboard"), mcp.Required()),
+		mcp.WithString("ref_id", mcp.Description("Query of the panel to run, e.g. A (default: the first visible query)")),
+		mcp.WithObject("variables", mcp.Description(`Values of dashboard variables, e.g. {"namespace": "prod"} (default: the values saved with the dashboard)`)),
+		mcp.WithString("start", mcp.Description("Start time: now-<duration> such as now-6h, a duration such as 30m, RFC3339 or Unix timestamp (default: an hour before end)")),
+		mcp.WithString("end", mcp.Description("End time in the formats of start (default: now)")),
+		mcp.WithString("step", mcp.Description("Query resolution step, which also sets $__interval (default: chosen to keep series within max_points)")),
+		mcp.WithNumber("max_points", mcp.Description(fmt.Sprintf("Points per series the automatic step aims for (default: %d)", defaultMaxPoints))),
+		mcp.WithString("output", mcp.Description("raw returns every sample; summary returns statistics and a trend per series (default: raw)"), mcp.Enum("raw", "summary")),
+		mcp.WithNumber("top_k", mcp.Description("Series to summarize with output=summary (default: 10)")),
+		mcp.WithString("sort_by", mcp.Description("Statistic ordering the series of a summary (default: avg)"), mcp.Enum("avg", "max", "last", "change")),
+		mcp.WithString("datasource", mcp.Description("Prometheus data source to query, see prometheus_list_datasources (default: the one with the URL of the panel's Grafana data source, else the default)")),
+	), telemetry.AdaptToolHandler(telemetry.WithTracing("grafana_query_panel", httpclient.WithConfig(config, handleQueryPanel))))
+
+	s.AddTool(mcp.NewTool("grafana_list_datasources",
+		mcp.WithDescription("List the data sources of Grafana with their type and URL"),
+		mcp.WithOutputSchema[DataSourceList](),
+	), telemetry.AdaptToolHandler(telemetry.WithTracing("grafana_list_datasources", httpclient.WithConfig(config, handleListDataSources))))
+
+	// Write tools - only registered when not in read-only mode
+	if !readOnly {
+		s.AddTool(mcp.NewTool("grafana_create_annotation",
+			mcp.WithDescription("Annotate dashboards with an action, e.g. a remediation, so that it shows on the graphs humans look at. Annotations are tagged kagent"),
+			mcp.WithString("text", mcp.Description("What was done and why"), mcp.Required()),
+			mcp.WithArray("tags", mcp.WithStringItems(), mcp.Description("Extra tags, e.g. the affected service; dashboards can show annotations by tag")),
+			mcp.WithString("dashboard_uid", mcp.Description("Dashboard to annotate (default: an organization-wide annotation)")),
+			mcp.WithNumber("panel_id", mcp.Description("Panel of dashboard_uid to annotate (default: the whole dashboard)")),
+			mcp.WithString("time", mcp.Description("RFC3339 time of the action (default: now)")),
+			mcp.WithString("time_end", mcp.Description("RFC3339 end of the action, making the annotation a region")),
+			mcp.WithOutputSchema[AnnotationResult](),
+		), telemetry.AdaptToolHandler(telemetry.WithTracing("grafana_create_annotation", httpclient.WithConfig(config, handleCreateAnnotation))))
+	}
+}
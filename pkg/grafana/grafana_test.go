@@ -0,0 +1,368 @@
+package grafana
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kagent-dev/tools/internal/httpclient"
+	"github.com/kagent-dev/tools/internal/identity"
+	"github.com/kagent-dev/tools/pkg/prometheus"
+)
+
+const testDashboard = `{
+	"meta": {"url": "/d/k8s-pods/kubernetes-pods", "folderTitle": "Kubernetes"},
+	"dashboard": {
+		"uid": "k8s-pods",
+		"title": "Kubernetes / Pods",
+		"tags": ["kubernetes"],
+		"templating": {"list": [
+			{"name": "datasource", "type": "datasource", "query": "prometheus", "current": {"value": "thanos"}},
+			{"name": "namespace", "type": "query", "query": {"query": "label_values(kube_pod_info, namespace)"}, "current": {"value": "prod"}},
+			{"name": "pod", "type": "query", "query": "label_values(kube_pod_info{namespace=\"$namespace\"}, pod)", "includeAll": true, "current": {"value": ["$__all"]}},
+			{"name": "container", "type": "custom", "current": {"value": []}}
+		]},
+		"panels": [
+			{"id": 1, "title": "CPU", "type": "timeseries", "datasource": {"type": "prometheus", "uid": "${datasource}"},
+			 "targets": [
+				{"refId": "A", "expr": "sum by (pod) (rate(container_cpu_usage_seconds_total{namespace=\"$namespace\", pod=~\"$pod\"}[$__rate_interval]))"},
+				{"refId": "B", "expr": "kube_pod_container_resource_limits{container=\"$container\"}", "hide": true}
+			 ]},
+			{"id": 2, "title": "Logs", "type": "row", "collapsed": true, "panels": [
+				{"id": 3, "title": "Errors", "type": "logs", "datasource": {"type": "loki", "uid": "loki"},
+				 "targets": [{"refId": "A", "expr": "{namespace=\"$namespace\"} |= \"error\""}]}
+			]}
+		]
+	}
+}`
+
+// fakeGrafana stands in for the Grafana HTTP API and records the requests it gets
+type fakeGrafana struct {
+	*httptest.Server
+	requests []*http.Request
+	bodies   []string
+}
+
+func newFakeGrafana(t *testing.T, prometheusURL string) *fakeGrafana {
+	fake := &fakeGrafana{}
+	responses := map[string]string{
+		"GET /api/search":                  `[{"uid":"k8s-pods","title":"Kubernetes / Pods","url":"/d/k8s-pods/kubernetes-pods","folderTitle":"Kubernetes","tags":["kubernetes"]}]`,
+		"GET /api/dashboards/uid/k8s-pods": testDashboard,
+		"GET /api/datasources": `[{"uid":"thanos","name":"Thanos","type":"prometheus","url":"` + prometheusURL + `"},
+			{"uid":"loki","name":"Loki","type":"loki","url":"http://loki:3100","isDefault":true}]`,
+		"GET /api/datasources/uid/thanos": `{"uid":"thanos","name":"Thanos","type":"prometheus","url":"` + prometheusURL + `/"}`,
+		"POST /api/annotations":           `{"id":42,"message":"Annotation added"}`,
+	}
+	fake.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		fake.requests = append(fake.requests, r)
+		fake.bodies = append(fake.bodies, string(body))
+
+		response, ok := responses[r.Method+" "+r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Dashboard not found"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(fake.Close)
+	return fake
+}
+
+// last returns the last request the fake got
+func (f *fakeGrafana) last() *http.Request {
+	return f.requests[len(f.requests)-1]
+}
+
+// call calls handler against the fake with the Prometheus data sources of prometheusConfig
+func (f *fakeGrafana) call(t *testing.T, ctx context.Context, prometheusConfig *prometheus.Config, handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	config, err := LoadConfig(Config{URL: f.URL, APIKey: "glsa_token", Prometheus: prometheusConfig})
+	require.NoError(t, err)
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = args
+	result, err := httpclient.WithConfig(config, handler)(ctx, req)
+	require.NoError(t, err)
+	return result
+}
+
+func getResultText(result *mcp.CallToolResult) string {
+	if result == nil || len(result.Content) == 0 {
+		return ""
+	}
+	if textContent, ok := result.Content[0].(mcp.TextContent); ok {
+		return textContent.Text
+	}
+	return ""
+}
+
+// decode unmarshals the JSON text of result into v
+func decode(t *testing.T, result *mcp.CallToolResult, v any) {
+	t.Helper()
+	require.False(t, result.IsError, getResultText(result))
+	require.NoError(t, json.Unmarshal([]byte(getResultText(result)), v))
+}
+
+func TestRegisterTools(t *testing.T) {
+	s := server.NewMCPServer("test", "v0.0.1")
+	RegisterTools(s, false)
+	for _, name := range []string{"grafana_search_dashboards", "grafana_get_dashboard", "grafana_query_panel", "grafana_list_datasources", "grafana_create_annotation"} {
+		assert.Contains(t, s.ListTools(), name)
+	}
+
+	s = server.NewMCPServer("test", "v0.0.1")
+	RegisterTools(s, true)
+	assert.NotContains(t, s.ListTools(), "grafana_create_annotation")
+}
+
+func TestLoadConfig(t *testing.T) {
+	fake := newFakeGrafana(t, "http://prometheus:9090")
+
+	t.Setenv(EnvURL, fake.URL)
+	t.Setenv(EnvAPIKey, "glsa_token")
+	t.Setenv(EnvOrgID, "2")
+	config, err := LoadConfig(Config{})
+	require.NoError(t, err)
+	resp, err := config.client.Get(config.URL + "/api/datasources")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "Bearer glsa_token", fake.last().Header.Get("Authorization"))
+	assert.Equal(t, "2", fake.last().Header.Get(orgHeader))
+	assert.Equal(t, httpclient.DefaultTimeout, config.client.Timeout)
+	assert.Equal(t, httpclient.DefaultTimeout, configFromContext(context.Background()).client.Timeout)
+
+	_, err = LoadConfig(Config{Options: httpclient.Options{Username: "admin"}})
+	assert.ErrorContains(t, err, "not both")
+
+	t.Setenv(EnvAPIKey, "")
+	_, err = LoadConfig(Config{URL: "ftp://grafana:3000"})
+	assert.Error(t, err)
+}
+
+func TestHandleSearchDashboards(t *testing.T) {
+	fake := newFakeGrafana(t, "http://prometheus:9090")
+
+	var result DashboardList
+	decode(t, fake.call(t, context.Background(), nil, handleSearchDashboards, map[string]any{"query": "pods", "tags": []any{"kubernetes", "prod"}}), &result)
+
+	query := fake.last().URL.Query()
+	assert.Equal(t, "dash-db", query.Get("type"))
+	assert.Equal(t, "pods", query.Get("query"))
+	assert.Equal(t, []string{"kubernetes", "prod"}, query["tag"])
+	assert.Equal(t, "50", query.Get("limit"))
+	require.Equal(t, 1, result.Total)
+	assert.Equal(t, fake.URL+"/d/k8s-pods/kubernetes-pods", result.Dashboards[0].URL)
+	assert.Equal(t, "Kubernetes", result.Dashboards[0].Folder)
+}
+
+func TestHandleGetDashboard(t *testing.T) {
+	fake := newFakeGrafana(t, "http://prometheus:9090")
+
+	var dashboard Dashboard
+	decode(t, fake.call(t, context.Background(), nil, handleGetDashboard, map[string]any{"uid": "k8s-pods"}), &dashboard)
+
+	assert.Equal(t, "Kubernetes / Pods", dashboard.Title)
+	require.Len(t, dashboard.Variables, 4)
+	assert.Equal(t, "label_values(kube_pod_info, namespace)", dashboard.Variables[1].Query)
+	assert.Equal(t, []string{"$__all"}, dashboard.Variables[2].Current)
+
+	require.Len(t, dashboard.Panels, 2, "rows are flattened")
+	cpu := dashboard.Panels[0]
+	assert.Equal(t, "${datasource}", cpu.Datasource)
+	require.Len(t, cpu.Queries, 2)
+	assert.Equal(t, "prometheus", cpu.Queries[0].DatasourceType, "queries inherit the data source of the panel")
+	assert.Contains(t, cpu.Queries[0].Expr, "container_cpu_usage_seconds_total")
+	assert.True(t, cpu.Queries[1].Hidden)
+
+	logs := dashboard.Panels[1]
+	assert.Equal(t, 3, logs.ID)
+	assert.Equal(t, "Logs", logs.Row)
+	assert.Equal(t, "loki", logs.Queries[0].DatasourceType)
+
+	result := fake.call(t, context.Background(), nil, handleGetDashboard, map[string]any{"uid": "missing"})
+	assert.True(t, result.IsError)
+	assert.Contains(t, getResultText(result), "Grafana API error (404): Dashboard not found")
+
+	result = fake.call(t, context.Background(), nil, handleGetDashboard, map[string]any{"uid": "../admin"})
+	assert.True(t, result.IsError)
+	assert.Contains(t, getResultText(result), "Invalid dashboard UID")
+}
+
+func TestHandleListDataSources(t *testing.T) {
+	fake := newFakeGrafana(t, "http://prometheus:9090")
+
+	var result DataSourceList
+	decode(t, fake.call(t, context.Background(), nil, handleListDataSources, nil), &result)
+	require.Len(t, result.DataSources, 2)
+	assert.Equal(t, "Loki", result.DataSources[0].Name)
+	assert.True(t, result.DataSources[0].IsDefault)
+	assert.Equal(t, "prometheus", result.DataSources[1].Type)
+}
+
+func TestHandleQueryPanel(t *testing.T) {
+	var queries []*http.Request
+	newPrometheus := func() *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			queries = append(queries, r)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[]}}`))
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+	local, thanos := newPrometheus(), newPrometheus()
+
+	// The Grafana data source thanos has the URL of the Prometheus data source thanos
+	file := filepath.Join(t.TempDir(), "prometheus.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("datasources:\n- name: local\n  url: "+local.URL+"\n- name: thanos\n  url: "+thanos.URL+"\ndefault: local\n"), 0o600))
+	prometheusConfig, err := prometheus.LoadConfig(file, prometheus.DataSource{})
+	require.NoError(t, err)
+	fake := newFakeGrafana(t, thanos.URL)
+
+	t.Run("renders variables and matches the data source", func(t *testing.T) {
+		result := fake.call(t, context.Background(), prometheusConfig, handleQueryPanel, map[string]any{
+			"dashboard_uid": "k8s-pods", "panel_id": float64(1), "start": "1700000000", "end": "1700003600", "step": "60s",
+		})
+		require.False(t, result.IsError, getResultText(result))
+
+		query := queries[len(queries)-1]
+		assert.Equal(t, thanos.URL, "http://"+query.Host)
+		assert.Equal(t, "/api/v1/query_range", query.URL.Path)
+		assert.Equal(t, `sum by (pod) (rate(container_cpu_usage_seconds_total{namespace="prod", pod=~".*"}[1m15s]))`, query.URL.Query().Get("query"))
+		assert.Equal(t, "60", query.URL.Query().Get("step"))
+		require.Len(t, result.Content, 2)
+		assert.Contains(t, result.Content[1].(mcp.TextContent).Text, "Prometheus data source thanos")
+	})
+
+	t.Run("variables argument and explicit data source", func(t *testing.T) {
+		result := fake.call(t, context.Background(), prometheusConfig, handleQueryPanel, map[string]any{
+			"dashboard_uid": "k8s-pods", "panel_id": float64(1), "start": "now-6h", "datasource": "local",
+			"variables": map[string]any{"namespace": "staging", "pod": "web-1.prod"},
+		})
+		require.False(t, result.IsError, getResultText(result))
+
+		query := queries[len(queries)-1]
+		assert.Equal(t, local.URL, "http://"+query.Host)
+		assert.Contains(t, query.URL.Query().Get("query"), `{namespace="staging", pod=~"web-1.prod"}[2m15s]`)
+	})
+
+	for name, tt := range map[string]struct {
+		args map[string]any
+		want string
+	}{
+		"missing panel":      {args: map[string]any{"panel_id": float64(9)}, want: "Panel 9 not found"},
+		"loki query":         {args: map[string]any{"panel_id": float64(3)}, want: "uses a loki data source"},
+		"unknown ref_id":     {args: map[string]any{"panel_id": float64(1), "ref_id": "C"}, want: "has no query C"},
+		"variable unset":     {args: map[string]any{"panel_id": float64(1), "ref_id": "B"}, want: "variables without a value: container"},
+		"rejected query":     {args: map[string]any{"panel_id": float64(1), "variables": map[string]any{"pod": []any{"a", "b"}}}, want: `renders to sum by (pod) (rate(container_cpu_usage_seconds_total{namespace="prod", pod=~"(a|b)"}`},
+		"invalid time range": {args: map[string]any{"panel_id": float64(1), "start": "now", "end": "now-1h"}, want: "Invalid time range"},
+	} {
+		tt.args["dashboard_uid"] = "k8s-pods"
+		result := fake.call(t, context.Background(), prometheusConfig, handleQueryPanel, tt.args)
+		assert.True(t, result.IsError, name)
+		assert.Contains(t, getResultText(result), tt.want, name)
+	}
+}
+
+func TestHandleCreateAnnotation(t *testing.T) {
+	fake := newFakeGrafana(t, "http://prometheus:9090")
+	ctx := identity.WithPrincipal(context.Background(), identity.Principal{Subject: "oncall@example.com"})
+
+	var result AnnotationResult
+	decode(t, fake.call(t, ctx, nil, handleCreateAnnotation, map[string]any{
+		"text":          "Rolled back checkout to revision 41",
+		"tags":          []any{"checkout"},
+		"dashboard_uid": "k8s-pods",
+		"panel_id":      float64(1),
+		"time":          "2026-10-16T10:00:00Z",
+	}), &result)
+	assert.Equal(t, int64(42), result.ID)
+	assert.Equal(t, []string{"kagent", "principal:oncall@example.com", "checkout"}, result.Tags)
+
+	var sent map[string]any
+	require.NoError(t, json.Unmarshal([]byte(fake.bodies[len(fake.bodies)-1]), &sent))
+	assert.Equal(t, "Rolled back checkout to revision 41", sent["text"])
+	assert.Equal(t, "k8s-pods", sent["dashboardUID"])
+	assert.Equal(t, float64(1), sent["panelId"])
+	assert.Equal(t, float64(time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC).UnixMilli()), sent["time"])
+	assert.Equal(t, "Bearer glsa_token", fake.last().Header.Get("Authorization"))
+
+	for name, args := range map[string]map[string]any{
+		"no text":      {"tags": []any{"x"}},
+		"invalid time": {"text": "x", "time": "yesterday"},
+	} {
+		result := fake.call(t, ctx, nil, handleCreateAnnotation, args)
+		assert.True(t, result.IsError, name)
+	}
+}
+
+func TestRenderQuery(t *testing.T) {
+	r := prometheus.TimeRange{Start: time.Unix(0, 0), End: time.Unix(7200, 0), Step: 30 * time.Second}
+	values := map[string]variableValue{
+		"job":       {values: []string{"api"}},
+		"instance":  {values: []string{"a:9100", "b:9100"}},
+		"host":      {values: []string{"web.prod"}},
+		"path":      {values: []string{"/a+b", `/c"d\`}},
+		"threshold": {values: []string{"0.5"}},
+		"quoted":    {values: []string{`a"b\c`}},
+		"pod":       {all: ".*"},
+		"unset":     {},
+	}
+
+	tests := map[string]string{
+		`up{job="$job"}`:                            `up{job="api"}`,
+		`up{job="${job}"}`:                          `up{job="api"}`,
+		`up{job="${job:raw}"}`:                      `up{job="api"}`,
+		`up{job="[[job]]"}`:                         `up{job="api"}`,
+		`up{instance=~"$instance"}`:                 `up{instance=~"(a:9100|b:9100)"}`,
+		`up{host="$host"}`:                          `up{host="web.prod"}`,
+		`up{host=~"$host"}`:                         `up{host=~"web.prod"}`,
+		`up{host!="$host"}`:                         `up{host!="web.prod"}`,
+		`x > $threshold`:                            `x > 0.5`,
+		`x * ${threshold}`:                          `x * 0.5`,
+		`up{x="$quoted"}`:                           `up{x="a\"b\\c"}`,
+		`up{path=~"$path"}`:                         `up{path=~"(/a\\+b|/c\"d\\\\)"}`,
+		`up{path!~ "$path", job="$job"}`:            `up{path!~ "(/a\\+b|/c\"d\\\\)", job="api"}`,
+		`up{path="$path"}`:                          `up{path="(/a+b|/c\"d\\)"}`,
+		`up{pod=~"$pod"}`:                           `up{pod=~".*"}`,
+		`rate(x[$__rate_interval])`:                 `rate(x[1m])`,
+		`rate(x[$__interval])`:                      `rate(x[30s])`,
+		`increase(x[$__range])`:                     `increase(x[2h])`,
+		`x / $__range_s`:                            `x / 7200`,
+		`label_replace(up, "a", "$1", "b", "(.*)")`: `label_replace(up, "a", "$1", "b", "(.*)")`,
+	}
+	for expr, want := range tests {
+		got, missing := renderQuery(expr, values, r)
+		assert.Equal(t, want, got, expr)
+		assert.Empty(t, missing, expr)
+	}
+
+	_, missing := renderQuery(`up{x="$unset"}`, values, r)
+	assert.Equal(t, []string{"unset"}, missing)
+}
+
+func TestPromDuration(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		0:                              "0s",
+		500 * time.Millisecond:         "500ms",
+		75 * time.Second:               "1m15s",
+		90 * time.Minute:               "1h30m",
+		(24*time.Hour + 2*time.Minute): "1d2m",
+	} {
+		assert.Equal(t, want, promDuration(d))
+	}
+}
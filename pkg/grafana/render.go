@@ -0,0 +1,157 @@
+package grafana
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kagent-dev/tools/pkg/prometheus"
+)
+
+// scrapeInterval is the scrape interval $__rate_interval assumes, the default of Grafana
+const scrapeInterval = 15 * time.Second
+
+// allValue selects every value of a variable set to All without a custom all value
+const allValue = ".*"
+
+// variableValue is the value of a template variable: the values selected, or the regular
+// expression matching every value when all is set
+type variableValue struct {
+	values []string
+	all    string
+}
+
+// variablePattern matches the variable syntaxes of Grafana: $name, ${name}, ${name:format}
+// and [[name]]
+var variablePattern = regexp.MustCompile(`\$(\w+)|\$\{(\w+)(?::\w+)?\}|\[\[(\w+)(?::\w+)?\]\]`)
+
+// regexMatcherPattern matches the double-quoted regular expression of a =~ or !~ matcher
+var regexMatcherPattern = regexp.MustCompile(`[=!]~\s*"(?:[^"\\]|\\.)*"`)
+
+// stringEscaper escapes a value for the double-quoted PromQL string it is substituted into
+var stringEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// variableValues returns the values of the template variables of a dashboard: the current
+// values of the dashboard unless overrides, from the variables argument, sets them
+func variableValues(dashboard apiDashboard, overrides map[string]any) map[string]variableValue {
+	values := map[string]variableValue{}
+	for _, variable := range dashboard.Dashboard.Templating.List {
+		var current variableValue
+		for _, value := range variable.Current.Value {
+			if value == "$__all" {
+				current.all = allValue
+				if variable.AllValue != "" {
+					current.all = variable.AllValue
+				}
+				continue
+			}
+			current.values = append(current.values, value)
+		}
+		values[variable.Name] = current
+	}
+	for name, value := range overrides {
+		switch value := value.(type) {
+		case []any:
+			var current variableValue
+			for _, item := range value {
+				current.values = append(current.values, fmt.Sprint(item))
+			}
+			values[name] = current
+		default:
+			values[name] = variableValue{values: []string{fmt.Sprint(value)}}
+		}
+	}
+	return values
+}
+
+// renderQuery substitutes the template variables of expr with values and the interval and
+// range variables with those of r. As in Grafana, a variable with several values becomes a
+// regular expression matching any of them, its values quoted as regular expressions within
+// =~ and !~ matchers, and values are escaped for the PromQL strings they end up in. Formats
+// such as ${name:csv} are ignored. Variables in values without a value are returned as
+// missing; other $ sequences, such as the $1 of label_replace, are left alone.
+func renderQuery(expr string, values map[string]variableValue, r prometheus.TimeRange) (string, []string) {
+	span := r.End.Sub(r.Start)
+	builtins := map[string]string{
+		"__interval":      promDuration(r.Step),
+		"__interval_ms":   strconv.FormatInt(r.Step.Milliseconds(), 10),
+		"__rate_interval": promDuration(max(r.Step+scrapeInterval, 4*scrapeInterval)),
+		"__range":         promDuration(span.Truncate(time.Second)),
+		"__range_s":       strconv.FormatInt(int64(span.Seconds()), 10),
+		"__range_ms":      strconv.FormatInt(span.Milliseconds(), 10),
+	}
+	regexMatchers := regexMatcherPattern.FindAllStringIndex(expr, -1)
+	inRegexMatcher := func(offset int) bool {
+		for _, matcher := range regexMatchers {
+			if offset >= matcher[0] && offset < matcher[1] {
+				return true
+			}
+		}
+		return false
+	}
+
+	var missing []string
+	var rendered strings.Builder
+	last := 0
+	for _, loc := range variablePattern.FindAllStringSubmatchIndex(expr, -1) {
+		rendered.WriteString(expr[last:loc[0]])
+		last = loc[1]
+		match, name := expr[loc[0]:loc[1]], ""
+		for i := 2; i < len(loc); i += 2 {
+			if loc[i] >= 0 {
+				name = expr[loc[i]:loc[i+1]]
+			}
+		}
+
+		if value, ok := builtins[name]; ok {
+			rendered.WriteString(value)
+			continue
+		}
+		current, ok := values[name]
+		switch {
+		case !ok:
+			rendered.WriteString(match)
+		case current.all != "":
+			rendered.WriteString(current.all)
+		case len(current.values) == 0:
+			missing = append(missing, name)
+			rendered.WriteString(match)
+		case len(current.values) == 1:
+			rendered.WriteString(stringEscaper.Replace(current.values[0]))
+		default:
+			quoted := make([]string, 0, len(current.values))
+			for _, value := range current.values {
+				if inRegexMatcher(loc[0]) {
+					value = regexp.QuoteMeta(value)
+				}
+				quoted = append(quoted, stringEscaper.Replace(value))
+			}
+			rendered.WriteString("(" + strings.Join(quoted, "|") + ")")
+		}
+	}
+	rendered.WriteString(expr[last:])
+	return rendered.String(), missing
+}
+
+// promDuration renders d as a PromQL duration such as 1h30m or 500ms
+func promDuration(d time.Duration) string {
+	if d%time.Second != 0 {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	var b strings.Builder
+	for _, unit := range []struct {
+		suffix string
+		length time.Duration
+	}{{"d", 24 * time.Hour}, {"h", time.Hour}, {"m", time.Minute}, {"s", time.Second}} {
+		if n := d / unit.length; n > 0 {
+			fmt.Fprintf(&b, "%d%s", n, unit.suffix)
+			d -= n * unit.length
+		}
+	}
+	if b.Len() == 0 {
+		return "0s"
+	}
+	return b.String()
+}
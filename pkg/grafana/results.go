@@ -0,0 +1,247 @@
+package grafana
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// DashboardSummary is a dashboard found by grafana_search_dashboards
+type DashboardSummary struct {
+	UID    string   `json:"uid"`
+	Title  string   `json:"title"`
+	URL    string   `json:"url"`
+	Folder string   `json:"folder,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+}
+
+// DashboardList is the result of grafana_search_dashboards
+type DashboardList struct {
+	Total      int                `json:"total"`
+	Dashboards []DashboardSummary `json:"dashboards"`
+}
+
+// Dashboard is the result of grafana_get_dashboard
+type Dashboard struct {
+	UID       string     `json:"uid"`
+	Title     string     `json:"title"`
+	URL       string     `json:"url"`
+	Folder    string     `json:"folder,omitempty"`
+	Tags      []string   `json:"tags,omitempty"`
+	Variables []Variable `json:"variables,omitempty"`
+	Panels    []Panel    `json:"panels"`
+}
+
+// Variable is a template variable of a dashboard with its current value
+type Variable struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Query   string   `json:"query,omitempty" jsonschema_description:"Query listing the options of a query variable"`
+	Current []string `json:"current,omitempty" jsonschema_description:"Selected values; $__all selects all options"`
+}
+
+// Panel is a panel of a dashboard with its queries
+type Panel struct {
+	ID         int     `json:"id"`
+	Title      string  `json:"title"`
+	Type       string  `json:"type"`
+	Row        string  `json:"row,omitempty" jsonschema_description:"Title of the row holding the panel"`
+	Datasource string  `json:"datasource,omitempty" jsonschema_description:"UID, or variable, of the data source of the panel"`
+	Queries    []Query `json:"queries,omitempty"`
+}
+
+// Query is a query of a panel. Expr holds the PromQL of Prometheus and the LogQL of Loki
+// queries.
+type Query struct {
+	RefID          string `json:"ref_id"`
+	DatasourceType string `json:"datasource_type,omitempty"`
+	Datasource     string `json:"datasource,omitempty"`
+	Expr           string `json:"expr,omitempty"`
+	Hidden         bool   `json:"hidden,omitempty"`
+}
+
+// DataSource is a data source of Grafana
+type DataSource struct {
+	UID       string `json:"uid"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	URL       string `json:"url,omitempty"`
+	IsDefault bool   `json:"is_default,omitempty"`
+}
+
+// DataSourceList is the result of grafana_list_datasources
+type DataSourceList struct {
+	DataSources []DataSource `json:"datasources"`
+}
+
+// AnnotationResult is the result of grafana_create_annotation
+type AnnotationResult struct {
+	ID      int64    `json:"id"`
+	Message string   `json:"message"`
+	Tags    []string `json:"tags"`
+}
+
+// apiSearchHit is a result of the Grafana search API
+type apiSearchHit struct {
+	UID         string   `json:"uid"`
+	Title       string   `json:"title"`
+	URL         string   `json:"url"`
+	FolderTitle string   `json:"folderTitle"`
+	Tags        []string `json:"tags"`
+}
+
+// apiDashboard is a dashboard of the Grafana dashboard API
+type apiDashboard struct {
+	Meta struct {
+		URL         string `json:"url"`
+		FolderTitle string `json:"folderTitle"`
+	} `json:"meta"`
+	Dashboard struct {
+		UID        string     `json:"uid"`
+		Title      string     `json:"title"`
+		Tags       []string   `json:"tags"`
+		Panels     []apiPanel `json:"panels"`
+		Templating struct {
+			List []apiVariable `json:"list"`
+		} `json:"templating"`
+	} `json:"dashboard"`
+}
+
+// apiPanel is a panel of a dashboard model; rows hold their collapsed panels
+type apiPanel struct {
+	ID         int           `json:"id"`
+	Title      string        `json:"title"`
+	Type       string        `json:"type"`
+	Datasource apiDatasource `json:"datasource"`
+	Targets    []apiTarget   `json:"targets"`
+	Panels     []apiPanel    `json:"panels"`
+}
+
+// apiTarget is a query of a panel
+type apiTarget struct {
+	RefID      string        `json:"refId"`
+	Datasource apiDatasource `json:"datasource"`
+	Expr       string        `json:"expr"`
+	Hide       bool          `json:"hide"`
+}
+
+// apiDatasource references a data source by UID and type, or by name or variable in
+// dashboards older than Grafana 8
+type apiDatasource struct {
+	UID  string `json:"uid"`
+	Type string `json:"type"`
+}
+
+func (d *apiDatasource) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		d.UID = name
+		return nil
+	}
+	type plain apiDatasource
+	return json.Unmarshal(data, (*plain)(d))
+}
+
+// apiVariable is a template variable of a dashboard model
+type apiVariable struct {
+	Name       string          `json:"name"`
+	Type       string          `json:"type"`
+	Query      json.RawMessage `json:"query"`
+	IncludeAll bool            `json:"includeAll"`
+	AllValue   string          `json:"allValue"`
+	Current    struct {
+		Value stringList `json:"value"`
+	} `json:"current"`
+}
+
+// stringList is a JSON string or array of strings
+type stringList []string
+
+func (l *stringList) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err == nil {
+		*l = stringList{value}
+		return nil
+	}
+	var values []string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil // Values of other types are not substituted
+	}
+	*l = values
+	return nil
+}
+
+// queryString returns the query of a variable, a string or an object with a query field
+func (v apiVariable) queryString() string {
+	var query string
+	if err := json.Unmarshal(v.Query, &query); err == nil {
+		return query
+	}
+	var object struct {
+		Query string `json:"query"`
+	}
+	_ = json.Unmarshal(v.Query, &object)
+	return object.Query
+}
+
+// apiDataSource is a data source of the Grafana data source API
+type apiDataSource struct {
+	UID       string `json:"uid"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	URL       string `json:"url"`
+	IsDefault bool   `json:"isDefault"`
+}
+
+// newDashboard converts a dashboard, listing the panels of rows after their row
+func newDashboard(dashboard apiDashboard, baseURL string) Dashboard {
+	result := Dashboard{
+		UID:    dashboard.Dashboard.UID,
+		Title:  dashboard.Dashboard.Title,
+		URL:    baseURL + dashboard.Meta.URL,
+		Folder: dashboard.Meta.FolderTitle,
+		Tags:   dashboard.Dashboard.Tags,
+		Panels: []Panel{},
+	}
+	for _, variable := range dashboard.Dashboard.Templating.List {
+		result.Variables = append(result.Variables, Variable{
+			Name:    variable.Name,
+			Type:    variable.Type,
+			Query:   variable.queryString(),
+			Current: variable.Current.Value,
+		})
+	}
+
+	row := ""
+	var add func(panels []apiPanel)
+	add = func(panels []apiPanel) {
+		for _, panel := range panels {
+			if panel.Type == "row" {
+				row = panel.Title
+				add(panel.Panels)
+				continue
+			}
+			result.Panels = append(result.Panels, newPanel(panel, row))
+		}
+	}
+	add(dashboard.Dashboard.Panels)
+	return result
+}
+
+func newPanel(panel apiPanel, row string) Panel {
+	result := Panel{ID: panel.ID, Title: panel.Title, Type: panel.Type, Row: row, Datasource: panel.Datasource.UID}
+	for _, target := range panel.Targets {
+		// Queries use the data source of the panel unless they name their own
+		datasource := target.Datasource
+		if datasource.UID == "" || datasource.UID == "-- Mixed --" {
+			datasource = panel.Datasource
+		}
+		result.Queries = append(result.Queries, Query{
+			RefID:          target.RefID,
+			DatasourceType: datasource.Type,
+			Datasource:     datasource.UID,
+			Expr:           strings.TrimSpace(target.Expr),
+			Hidden:         target.Hide,
+		})
+	}
+	return result
+}
@@ -2,10 +2,12 @@ package helm
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/kagent-dev/tools/internal/cache"
 	"github.com/kagent-dev/tools/internal/commands"
 	"github.com/kagent-dev/tools/internal/errors"
 	"github.com/kagent-dev/tools/internal/security"
@@ -13,8 +15,53 @@ import (
 	"github.com/kagent-dev/tools/pkg/utils"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"sigs.k8s.io/yaml"
 )
 
+// Release is a Helm release as helm list reports it
+type Release struct {
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace"`
+	Revision   string `json:"revision"`
+	Updated    string `json:"updated"`
+	Status     string `json:"status"`
+	Chart      string `json:"chart"`
+	AppVersion string `json:"app_version"`
+}
+
+// ReleaseList is the structured content of helm_list_releases
+type ReleaseList struct {
+	Releases []Release `json:"releases"`
+}
+
+// releaseList parses helm list output in the table, json or yaml format
+func releaseList(output, text string) ReleaseList {
+	list := ReleaseList{Releases: []Release{}}
+	switch output {
+	case "json":
+		_ = json.Unmarshal([]byte(text), &list.Releases)
+	case "yaml":
+		_ = yaml.Unmarshal([]byte(text), &list.Releases)
+	default:
+		table, _ := utils.ParseTable(text)
+		for _, row := range table.Rows {
+			list.Releases = append(list.Releases, Release{
+				Name:       row["name"],
+				Namespace:  row["namespace"],
+				Revision:   row["revision"],
+				Updated:    row["updated"],
+				Status:     row["status"],
+				Chart:      row["chart"],
+				AppVersion: row["app_version"],
+			})
+		}
+	}
+	if list.Releases == nil {
+		list.Releases = []Release{}
+	}
+	return list
+}
+
 // Helm list releases
 func handleHelmListReleases(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	namespace := mcp.ParseString(request, "namespace", "")
@@ -84,7 +131,7 @@ func handleHelmListReleases(ctx context.Context, request mcp.CallToolRequest) (*
 		return mcp.NewToolResultError(fmt.Sprintf("Helm list command failed: %v", err)), nil
 	}
 
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultStructured(releaseList(output, result), result), nil
 }
 
 func runHelmCommand(ctx context.Context, args []string) (string, error) {
@@ -213,6 +260,9 @@ func handleHelmUpgradeRelease(ctx context.Context, request mcp.CallToolRequest)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Helm upgrade command failed: %v", err)), nil
 	}
+	if !dryRun {
+		commands.InvalidateCache(ctx, cache.Tag{Namespace: namespace})
+	}
 
 	return mcp.NewToolResultText(result), nil
 }
@@ -242,6 +292,9 @@ func handleHelmUninstall(ctx context.Context, request mcp.CallToolRequest) (*mcp
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Helm uninstall command failed: %v", err)), nil
 	}
+	if !dryRun {
+		commands.InvalidateCache(ctx, cache.Tag{Namespace: namespace})
+	}
 
 	return mcp.NewToolResultText(result), nil
 }
@@ -302,6 +355,7 @@ func RegisterTools(s *server.MCPServer, readOnly bool) {
 		mcp.WithString("pending", mcp.Description("List pending releases")),
 		mcp.WithString("filter", mcp.Description("A regular expression to filter releases by")),
 		mcp.WithString("output", mcp.Description("The output format (e.g., 'json', 'yaml', 'table')")),
+		mcp.WithOutputSchema[ReleaseList](),
 	), telemetry.AdaptToolHandler(telemetry.WithTracing("helm_list_releases", handleHelmListReleases)))
 
 	s.AddTool(mcp.NewTool("helm_get_release",
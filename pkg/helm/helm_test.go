@@ -128,6 +128,67 @@ prod-app    production      1               deployed        my-chart-1.0.0`,
 	})
 }
 
+func TestHandleHelmListReleasesStructured(t *testing.T) {
+	t.Run("table output", func(t *testing.T) {
+		output := "NAME \tNAMESPACE \tREVISION\tUPDATED                                \tSTATUS  \tCHART       \tAPP VERSION\n" +
+			"nginx\tweb       \t3       \t2024-05-01 10:00:00.123456 +0000 UTC\tdeployed\tnginx-15.0.0\t1.25.0     \n"
+		mock := cmd.NewMockShellExecutor()
+		mock.AddCommandString("helm", []string{"list", "-A"}, output, nil)
+		ctx := cmd.WithShellExecutor(context.Background(), mock)
+
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]interface{}{"all_namespaces": "true"}
+		result, err := handleHelmListReleases(ctx, request)
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+
+		assert.Equal(t, output, getResultText(result))
+		list, ok := result.StructuredContent.(ReleaseList)
+		require.True(t, ok)
+		assert.Equal(t, []Release{{
+			Name:       "nginx",
+			Namespace:  "web",
+			Revision:   "3",
+			Updated:    "2024-05-01 10:00:00.123456 +0000 UTC",
+			Status:     "deployed",
+			Chart:      "nginx-15.0.0",
+			AppVersion: "1.25.0",
+		}}, list.Releases)
+	})
+
+	t.Run("json output", func(t *testing.T) {
+		output := `[{"name":"app1","namespace":"default","revision":"1","updated":"2024-05-01","status":"deployed","chart":"app-1.0.0","app_version":"1.0"}]`
+		mock := cmd.NewMockShellExecutor()
+		mock.AddCommandString("helm", []string{"list", "-o", "json"}, output, nil)
+		ctx := cmd.WithShellExecutor(context.Background(), mock)
+
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]interface{}{"output": "json"}
+		result, err := handleHelmListReleases(ctx, request)
+		require.NoError(t, err)
+
+		list, ok := result.StructuredContent.(ReleaseList)
+		require.True(t, ok)
+		require.Len(t, list.Releases, 1)
+		assert.Equal(t, "app-1.0.0", list.Releases[0].Chart)
+		assert.Equal(t, "1.0", list.Releases[0].AppVersion)
+	})
+
+	t.Run("no releases", func(t *testing.T) {
+		list := releaseList("", "NAME\tNAMESPACE\tREVISION\tUPDATED\tSTATUS\tCHART\tAPP VERSION\n")
+		assert.NotNil(t, list.Releases)
+		assert.Empty(t, list.Releases)
+	})
+
+	t.Run("output schema", func(t *testing.T) {
+		s := server.NewMCPServer("test-server", "v0.0.1")
+		RegisterTools(s, true)
+		tool := s.ListTools()["helm_list_releases"].Tool
+		assert.Equal(t, "object", tool.OutputSchema.Type)
+		assert.Contains(t, tool.OutputSchema.Properties, "releases")
+	})
+}
+
 // Test Helm Get Release
 func TestHandleHelmGetRelease(t *testing.T) {
 	t.Run("get release all resources", func(t *testing.T) {
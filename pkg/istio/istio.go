@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/kagent-dev/tools/internal/cache"
 	"github.com/kagent-dev/tools/internal/commands"
 	"github.com/kagent-dev/tools/internal/telemetry"
 	"github.com/kagent-dev/tools/pkg/utils"
@@ -12,6 +13,23 @@ import (
 	"github.com/mark3labs/mcp-go/server"
 )
 
+// ProxyStatus is the structured content of istio_proxy_status. Listing the proxies of the
+// mesh fills one row per proxy; the config comparison printed for a single pod is only
+// returned as text.
+type ProxyStatus struct {
+	Columns []string            `json:"columns" jsonschema_description:"Row keys in the order istioctl prints the columns, e.g. name, cluster, cds, lds, eds, rds, istiod, version"`
+	Proxies []map[string]string `json:"proxies" jsonschema_description:"Sync state of each proxy by column key"`
+}
+
+// proxyStatus parses the table istioctl proxy-status prints
+func proxyStatus(text string) ProxyStatus {
+	status := ProxyStatus{Columns: []string{}, Proxies: []map[string]string{}}
+	if table, ok := utils.ParseTable(text); ok {
+		status.Columns, status.Proxies = table.Columns, table.Rows
+	}
+	return status
+}
+
 // Istio proxy status
 func handleIstioProxyStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	podName := mcp.ParseString(request, "pod_name", "")
@@ -32,7 +50,7 @@ func handleIstioProxyStatus(ctx context.Context, request mcp.CallToolRequest) (*
 		return mcp.NewToolResultError(fmt.Sprintf("istioctl proxy-status failed: %v", err)), nil
 	}
 
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultStructured(proxyStatus(result), result), nil
 }
 
 func runIstioCtl(ctx context.Context, args []string) (string, error) {
@@ -79,6 +97,7 @@ func handleIstioInstall(ctx context.Context, request mcp.CallToolRequest) (*mcp.
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("istioctl install failed: %v", err)), nil
 	}
+	commands.InvalidateCache(ctx, cache.Tag{})
 
 	return mcp.NewToolResultText(result), nil
 }
@@ -218,6 +237,7 @@ func handleWaypointApply(ctx context.Context, request mcp.CallToolRequest) (*mcp
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("istioctl waypoint apply failed: %v", err)), nil
 	}
+	commands.InvalidateCache(ctx, cache.Tag{Namespace: namespace})
 
 	return mcp.NewToolResultText(result), nil
 }
@@ -249,6 +269,7 @@ func handleWaypointDelete(ctx context.Context, request mcp.CallToolRequest) (*mc
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("istioctl waypoint delete failed: %v", err)), nil
 	}
+	commands.InvalidateCache(ctx, cache.Tag{Namespace: namespace})
 
 	return mcp.NewToolResultText(result), nil
 }
@@ -306,6 +327,7 @@ func RegisterTools(s *server.MCPServer, readOnly bool) {
 		mcp.WithDescription("Get Envoy proxy status for pods, retrieves last sent and acknowledged xDS sync from Istiod to each Envoy in the mesh"),
 		mcp.WithString("pod_name", mcp.Description("Name of the pod to get proxy status for")),
 		mcp.WithString("namespace", mcp.Description("Namespace of the pod")),
+		mcp.WithOutputSchema[ProxyStatus](),
 	), telemetry.AdaptToolHandler(telemetry.WithTracing("istio_proxy_status", handleIstioProxyStatus)))
 
 	// Istio proxy config
@@ -70,6 +70,32 @@ func TestHandleIstioProxyStatus(t *testing.T) {
 	})
 }
 
+func TestHandleIstioProxyStatusStructured(t *testing.T) {
+	output := `NAME                                CLUSTER      CDS        LDS        EDS        RDS        ECDS         ISTIOD                      VERSION
+details-v1-7d4d9d5fcb-abcde.bookinfo   Kubernetes   SYNCED     SYNCED     SYNCED     SYNCED     NOT SENT     istiod-5c4f4498d4-xyz12     1.22.0
+ratings-v1-85c74b6cb4-fghij.bookinfo   Kubernetes   STALE      SYNCED     SYNCED     SYNCED     NOT SENT     istiod-5c4f4498d4-xyz12     1.22.0
+`
+	mock := cmd.NewMockShellExecutor()
+	mock.AddCommandString("istioctl", []string{"proxy-status", "-n", "bookinfo"}, output, nil)
+	ctx := cmd.WithShellExecutor(context.Background(), mock)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"namespace": "bookinfo"}
+	result, err := handleIstioProxyStatus(ctx, request)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	status, ok := result.StructuredContent.(ProxyStatus)
+	require.True(t, ok)
+	assert.Equal(t, []string{"name", "cluster", "cds", "lds", "eds", "rds", "ecds", "istiod", "version"}, status.Columns)
+	require.Len(t, status.Proxies, 2)
+	assert.Equal(t, "STALE", status.Proxies[1]["cds"])
+	assert.Equal(t, "NOT SENT", status.Proxies[1]["ecds"])
+
+	// The config comparison printed for one pod is not a table
+	assert.Empty(t, proxyStatus("Clusters Match\nListeners Match\nRoutes Match (RDS last loaded at Mon, 01 Jan 2024 10:00:00 UTC)\n").Proxies)
+}
+
 func TestHandleIstioProxyConfig(t *testing.T) {
 	ctx := context.Background()
 
@@ -0,0 +1,200 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kagent-dev/tools/internal/commands"
+	"github.com/kagent-dev/tools/internal/credentials"
+)
+
+// Backend names accepted by NewBackend
+const (
+	BackendKubectl  = "kubectl"
+	BackendClientGo = "client-go"
+)
+
+// ErrUnsupported is returned by a backend that cannot serve a request (for example
+// an output format it does not render). K8sTool retries such calls with kubectl.
+var ErrUnsupported = errors.New("operation not supported by backend")
+
+// CallOptions carries per-call settings that apply to every backend operation
+type CallOptions struct {
+	// Token is the bearer token to authenticate with; empty means use the configured credentials
+	Token string
+	// Impersonation is the user the configured credentials act as; empty means nobody
+	Impersonation credentials.Impersonation
+}
+
+// GetOptions describes a resource read
+type GetOptions struct {
+	ResourceType  string
+	Name          string
+	Namespace     string
+	AllNamespaces bool
+	Output        string
+	Limit         int64  // list at most this many objects; 0 lists them all
+	Continue      string // continue token of the previous page
+}
+
+// LogsOptions describes a pod log read
+type LogsOptions struct {
+	PodName   string
+	Namespace string
+	Container string
+	TailLines int
+}
+
+// ScaleOptions describes a scale operation
+type ScaleOptions struct {
+	ResourceType string
+	Name         string
+	Namespace    string
+	Replicas     int
+	DryRun       bool // server-side dry run: validate and admit without persisting
+}
+
+// PatchOptions describes a patch operation
+type PatchOptions struct {
+	ResourceType string
+	Name         string
+	Namespace    string
+	Patch        string
+	PatchType    string // strategic, merge or json
+	Subresource  string // optional, e.g. status
+	DryRun       bool
+}
+
+// DeleteOptions describes a delete operation
+type DeleteOptions struct {
+	ResourceType string
+	Name         string
+	Namespace    string
+	DryRun       bool
+}
+
+// Backend executes Kubernetes operations for K8sTool. Implementations return the
+// same text shapes kubectl prints so tool output does not depend on the backend.
+// Operations not covered here (describe, exec, rollout, apply, ...) always use kubectl.
+type Backend interface {
+	Name() string
+	Get(ctx context.Context, call CallOptions, opts GetOptions) (string, error)
+	Events(ctx context.Context, call CallOptions, namespace string) (string, error)
+	Logs(ctx context.Context, call CallOptions, opts LogsOptions) (string, error)
+	APIResources(ctx context.Context, call CallOptions) (string, error)
+	Scale(ctx context.Context, call CallOptions, opts ScaleOptions) (string, error)
+	Patch(ctx context.Context, call CallOptions, opts PatchOptions) (string, error)
+	Delete(ctx context.Context, call CallOptions, opts DeleteOptions) (string, error)
+}
+
+// NewBackend creates the backend with the given name for the given kubeconfig
+func NewBackend(name, kubeconfig string) (Backend, error) {
+	switch strings.ToLower(name) {
+	case "", BackendKubectl:
+		return newKubectlBackend(kubeconfig), nil
+	case BackendClientGo:
+		return newClientGoBackend(kubeconfig)
+	default:
+		return nil, fmt.Errorf("unknown k8s backend %q: must be one of %s, %s", name, BackendKubectl, BackendClientGo)
+	}
+}
+
+// kubectlBackend shells out to kubectl through the command builder
+type kubectlBackend struct {
+	kubeconfig string
+}
+
+func newKubectlBackend(kubeconfig string) *kubectlBackend {
+	return &kubectlBackend{kubeconfig: kubeconfig}
+}
+
+func (b *kubectlBackend) Name() string {
+	return BackendKubectl
+}
+
+func (b *kubectlBackend) run(ctx context.Context, call CallOptions, args ...string) (string, error) {
+	return b.runWrite(ctx, call, false, args...)
+}
+
+// runWrite runs a mutating command, optionally as a server-side dry run
+func (b *kubectlBackend) runWrite(ctx context.Context, call CallOptions, dryRun bool, args ...string) (string, error) {
+	builder := commands.NewCommandBuilder("kubectl").
+		WithArgs(args...).
+		WithKubeconfig(b.kubeconfig).
+		WithServerDryRun(dryRun)
+	if call.Token != "" {
+		builder = builder.WithToken(call.Token)
+	}
+	return builder.WithImpersonation(call.Impersonation).Execute(ctx)
+}
+
+func (b *kubectlBackend) Get(ctx context.Context, call CallOptions, opts GetOptions) (string, error) {
+	args := []string{"get", opts.ResourceType}
+
+	if opts.Name != "" {
+		args = append(args, opts.Name)
+	}
+
+	if opts.AllNamespaces {
+		args = append(args, "--all-namespaces")
+	} else if opts.Namespace != "" {
+		args = append(args, "-n", opts.Namespace)
+	}
+
+	if opts.Output != "" {
+		args = append(args, "-o", opts.Output)
+	} else {
+		args = append(args, "-o", "json")
+	}
+
+	return b.run(ctx, call, args...)
+}
+
+func (b *kubectlBackend) Events(ctx context.Context, call CallOptions, namespace string) (string, error) {
+	args := []string{"get", "events", "-o", "json"}
+	if namespace != "" {
+		args = append(args, "-n", namespace)
+	} else {
+		args = append(args, "--all-namespaces")
+	}
+	return b.run(ctx, call, args...)
+}
+
+func (b *kubectlBackend) Logs(ctx context.Context, call CallOptions, opts LogsOptions) (string, error) {
+	args := []string{"logs", opts.PodName, "-n", opts.Namespace}
+
+	if opts.Container != "" {
+		args = append(args, "-c", opts.Container)
+	}
+
+	if opts.TailLines > 0 {
+		args = append(args, "--tail", fmt.Sprintf("%d", opts.TailLines))
+	}
+
+	return b.run(ctx, call, args...)
+}
+
+func (b *kubectlBackend) APIResources(ctx context.Context, call CallOptions) (string, error) {
+	return b.run(ctx, call, "api-resources")
+}
+
+func (b *kubectlBackend) Scale(ctx context.Context, call CallOptions, opts ScaleOptions) (string, error) {
+	args := []string{"scale", opts.ResourceType, opts.Name, "--replicas", fmt.Sprintf("%d", opts.Replicas), "-n", opts.Namespace}
+	return b.runWrite(ctx, call, opts.DryRun, args...)
+}
+
+func (b *kubectlBackend) Patch(ctx context.Context, call CallOptions, opts PatchOptions) (string, error) {
+	args := []string{"patch", opts.ResourceType, opts.Name}
+	if opts.Subresource != "" {
+		args = append(args, "--subresource="+opts.Subresource)
+	}
+	args = append(args, "--type="+opts.PatchType, "-p", opts.Patch, "-n", opts.Namespace)
+	return b.runWrite(ctx, call, opts.DryRun, args...)
+}
+
+func (b *kubectlBackend) Delete(ctx context.Context, call CallOptions, opts DeleteOptions) (string, error) {
+	args := []string{"delete", opts.ResourceType, opts.Name, "-n", opts.Namespace}
+	return b.runWrite(ctx, call, opts.DryRun, args...)
+}
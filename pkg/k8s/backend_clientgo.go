@@ -0,0 +1,662 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/tools/internal/clusters"
+	"github.com/kagent-dev/tools/internal/credentials"
+	toolerrors "github.com/kagent-dev/tools/internal/errors"
+)
+
+// tableAcceptHeader asks the API server to render a resource as a meta.k8s.io Table,
+// which is what kubectl uses for its default and wide output
+const tableAcceptHeader = "application/json;as=Table;v=v1;g=meta.k8s.io,application/json"
+
+// clientGoClients bundles the clients used for a single identity
+type clientGoClients struct {
+	dynamic dynamic.Interface
+	typed   kubernetes.Interface
+	rest    rest.Interface // used for server-side table printing
+}
+
+// clientGoTarget holds the discovery state and clients for one cluster
+type clientGoTarget struct {
+	mapper           meta.RESTMapper
+	discovery        discovery.DiscoveryInterface
+	defaultNamespace string
+	clientsFor       func(call CallOptions) (*clientGoClients, error)
+}
+
+// clientGoBackend talks to the API server directly through client-go
+type clientGoBackend struct {
+	defaultTarget *clientGoTarget
+
+	mu      sync.Mutex
+	targets map[string]*clientGoTarget // per-call cluster selections, keyed by cluster name
+}
+
+func newClientGoBackend(kubeconfig string) (*clientGoBackend, error) {
+	config, namespace, err := clusters.LoadRESTConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes config: %w", err)
+	}
+
+	target, err := newClientGoTarget(config, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return &clientGoBackend{defaultTarget: target, targets: make(map[string]*clientGoTarget)}, nil
+}
+
+func newClientGoTarget(config *rest.Config, namespace string) (*clientGoTarget, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery client: %w", err)
+	}
+	cachedDiscovery := memory.NewMemCacheClient(discoveryClient)
+
+	base, err := newClientGoClients(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &clientGoTarget{
+		mapper:           restmapper.NewShortcutExpander(restmapper.NewDeferredDiscoveryRESTMapper(cachedDiscovery), cachedDiscovery, nil),
+		discovery:        cachedDiscovery,
+		defaultNamespace: namespace,
+		clientsFor: func(call CallOptions) (*clientGoClients, error) {
+			switch {
+			case call.Token != "":
+				return newClientGoClients(credentials.RESTConfig(config, call.Token))
+			case call.Impersonation.User != "":
+				return newClientGoClients(credentials.ImpersonatingConfig(config, call.Impersonation))
+			}
+			return base, nil
+		},
+	}, nil
+}
+
+// targetFor returns the target for the cluster selected on the call, creating it on first use
+func (b *clientGoBackend) targetFor(ctx context.Context) (*clientGoTarget, error) {
+	cluster, ok := clusters.FromContext(ctx)
+	if !ok {
+		return b.defaultTarget, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if target, ok := b.targets[cluster.Name]; ok {
+		return target, nil
+	}
+
+	config, namespace, err := clusters.RESTConfig(cluster)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes config for cluster %s: %w", cluster.Name, err)
+	}
+	target, err := newClientGoTarget(config, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if b.targets == nil {
+		b.targets = make(map[string]*clientGoTarget)
+	}
+	b.targets[cluster.Name] = target
+	return target, nil
+}
+
+// connect resolves the target cluster and the clients for the caller's identity
+func (b *clientGoBackend) connect(ctx context.Context, call CallOptions) (*clientGoTarget, *clientGoClients, error) {
+	target, err := b.targetFor(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	clients, err := target.clientsFor(call)
+	if err != nil {
+		return nil, nil, err
+	}
+	return target, clients, nil
+}
+
+func newClientGoClients(config *rest.Config) (*clientGoClients, error) {
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	typedClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	tableConfig := rest.CopyConfig(config)
+	tableConfig.GroupVersion = &metav1.SchemeGroupVersion
+	tableConfig.NegotiatedSerializer = scheme.Codecs.WithoutConversion()
+	restClient, err := rest.RESTClientFor(tableConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rest client: %w", err)
+	}
+
+	return &clientGoClients{dynamic: dynamicClient, typed: typedClient, rest: restClient}, nil
+}
+
+func (b *clientGoBackend) Name() string {
+	return BackendClientGo
+}
+
+// resolve maps a kubectl-style resource argument (pod, deploy, deployments.apps, ...) to a REST mapping
+func (t *clientGoTarget) resolve(resourceType string) (*meta.RESTMapping, error) {
+	if resourceType == "" || resourceType == "all" || strings.ContainsAny(resourceType, ",/") {
+		// Multi-type and type/name arguments are a kubectl CLI convention
+		return nil, ErrUnsupported
+	}
+
+	fullySpecified, groupResource := schema.ParseResourceArg(strings.ToLower(resourceType))
+	var gvr schema.GroupVersionResource
+	var err error
+	if fullySpecified != nil {
+		gvr, err = t.mapper.ResourceFor(*fullySpecified)
+	}
+	if gvr.Empty() {
+		gvr, err = t.mapper.ResourceFor(groupResource.WithVersion(""))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	gvk, err := t.mapper.KindFor(gvr)
+	if err != nil {
+		return nil, err
+	}
+	return t.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+}
+
+// namespaceFor returns the namespace to use for a mapping, or "" for cluster-scoped resources
+func (t *clientGoTarget) namespaceFor(mapping *meta.RESTMapping, namespace string) string {
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return ""
+	}
+	if namespace == "" {
+		return t.defaultNamespace
+	}
+	return namespace
+}
+
+func resourceClient(clients *clientGoClients, mapping *meta.RESTMapping, namespace string) dynamic.ResourceInterface {
+	if namespace == "" {
+		return clients.dynamic.Resource(mapping.Resource)
+	}
+	return clients.dynamic.Resource(mapping.Resource).Namespace(namespace)
+}
+
+func (b *clientGoBackend) Get(ctx context.Context, call CallOptions, opts GetOptions) (string, error) {
+	result, _, err := b.get(ctx, call, opts)
+	return result, err
+}
+
+// GetPage lists at most opts.Limit objects, starting at the page opts.Continue names, and
+// returns the list metadata carrying the continue token of the next page
+func (b *clientGoBackend) GetPage(ctx context.Context, call CallOptions, opts GetOptions) (string, metav1.ListMeta, error) {
+	if opts.Name != "" {
+		return "", metav1.ListMeta{}, newClientGoError("get "+opts.ResourceType, errors.New("a single resource cannot be paged"), opts.ResourceType, opts.Name)
+	}
+	return b.get(ctx, call, opts)
+}
+
+func (b *clientGoBackend) get(ctx context.Context, call CallOptions, opts GetOptions) (string, metav1.ListMeta, error) {
+	output := opts.Output
+	if output == "" {
+		output = "json"
+	}
+	switch output {
+	case "json", "yaml", "wide", "name":
+	default:
+		return "", metav1.ListMeta{}, ErrUnsupported
+	}
+
+	target, clients, err := b.connect(ctx, call)
+	if err != nil {
+		return "", metav1.ListMeta{}, newClientGoError("get "+opts.ResourceType, err, opts.ResourceType, opts.Name)
+	}
+	mapping, err := target.resolve(opts.ResourceType)
+	if err != nil {
+		return "", metav1.ListMeta{}, newClientGoError("get "+opts.ResourceType, err, opts.ResourceType, opts.Name)
+	}
+
+	namespace := target.namespaceFor(mapping, opts.Namespace)
+	if opts.AllNamespaces {
+		if opts.Name != "" {
+			return "", metav1.ListMeta{}, newClientGoError("get "+opts.ResourceType, errors.New("a resource cannot be retrieved by name across all namespaces"), opts.ResourceType, opts.Name)
+		}
+		namespace = metav1.NamespaceAll
+	}
+
+	var result string
+	var listMeta metav1.ListMeta
+	if output == "wide" {
+		result, listMeta, err = getTable(ctx, clients, mapping, namespace, opts)
+	} else {
+		result, listMeta, err = getObjects(ctx, clients, mapping, namespace, opts, output)
+	}
+	if err != nil {
+		return "", metav1.ListMeta{}, newClientGoError("get "+opts.ResourceType, err, opts.ResourceType, opts.Name)
+	}
+	return result, listMeta, nil
+}
+
+func getObjects(ctx context.Context, clients *clientGoClients, mapping *meta.RESTMapping, namespace string, opts GetOptions, output string) (string, metav1.ListMeta, error) {
+	client := resourceClient(clients, mapping, namespace)
+
+	if opts.Name != "" {
+		obj, err := client.Get(ctx, opts.Name, metav1.GetOptions{})
+		if err != nil {
+			return "", metav1.ListMeta{}, err
+		}
+		result, err := printObjects(mapping, []unstructured.Unstructured{*obj}, true, output)
+		return result, metav1.ListMeta{}, err
+	}
+	list, err := client.List(ctx, metav1.ListOptions{Limit: opts.Limit, Continue: opts.Continue})
+	if err != nil {
+		return "", metav1.ListMeta{}, err
+	}
+	listMeta := metav1.ListMeta{
+		ResourceVersion:    list.GetResourceVersion(),
+		Continue:           list.GetContinue(),
+		RemainingItemCount: list.GetRemainingItemCount(),
+	}
+	result, err := printObjects(mapping, list.Items, false, output)
+	return result, listMeta, err
+}
+
+// printObjects prints objects in the json, yaml or name output of kubectl. A single object
+// is printed on its own rather than in a List.
+func printObjects(mapping *meta.RESTMapping, items []unstructured.Unstructured, single bool, output string) (string, error) {
+	var printable interface{}
+	if single && len(items) == 1 {
+		printable = items[0].Object
+	} else {
+		objects := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			objects = append(objects, item.Object)
+		}
+		// Same envelope kubectl prints for multiple objects
+		printable = map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "List",
+			"items":      objects,
+			"metadata":   map[string]interface{}{"resourceVersion": ""},
+		}
+	}
+
+	switch output {
+	case "name":
+		var sb strings.Builder
+		for _, item := range items {
+			sb.WriteString(qualifiedKind(mapping) + "/" + item.GetName() + "\n")
+		}
+		return sb.String(), nil
+	case "yaml":
+		data, err := yaml.Marshal(printable)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	default:
+		data, err := json.MarshalIndent(printable, "", "    ")
+		if err != nil {
+			return "", err
+		}
+		return string(data) + "\n", nil
+	}
+}
+
+// getTable asks the server to render the resource as a Table and prints it the way kubectl does
+func getTable(ctx context.Context, clients *clientGoClients, mapping *meta.RESTMapping, namespace string, opts GetOptions) (string, metav1.ListMeta, error) {
+	request := clients.rest.Get().
+		AbsPath(resourcePath(mapping.Resource, namespace, opts.Name)...).
+		Param("includeObject", string(metav1.IncludeMetadata)).
+		SetHeader("Accept", tableAcceptHeader)
+	if opts.Limit > 0 {
+		request = request.Param("limit", strconv.FormatInt(opts.Limit, 10))
+	}
+	if opts.Continue != "" {
+		request = request.Param("continue", opts.Continue)
+	}
+	raw, err := request.DoRaw(ctx)
+	if err != nil {
+		return "", metav1.ListMeta{}, err
+	}
+
+	var table metav1.Table
+	if err := json.Unmarshal(raw, &table); err != nil {
+		return "", metav1.ListMeta{}, fmt.Errorf("failed to decode table response: %w", err)
+	}
+
+	return printWide(&table, mapping, namespace, opts.AllNamespaces), table.ListMeta, nil
+}
+
+// printWide prints a table in kubectl's wide output, or the message kubectl prints when
+// nothing was found
+func printWide(table *metav1.Table, mapping *meta.RESTMapping, namespace string, allNamespaces bool) string {
+	if len(table.Rows) == 0 {
+		if namespace == "" {
+			return "No resources found\n"
+		}
+		return fmt.Sprintf("No resources found in %s namespace.\n", namespace)
+	}
+
+	withNamespace := allNamespaces && mapping.Scope.Name() == meta.RESTScopeNameNamespace
+	return printTable(table, withNamespace)
+}
+
+// printTable renders a server-side Table in wide form, optionally prefixed with a NAMESPACE column
+func printTable(table *metav1.Table, withNamespace bool) string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 10, 4, 3, ' ', 0)
+
+	headers := make([]string, 0, len(table.ColumnDefinitions)+1)
+	if withNamespace {
+		headers = append(headers, "NAMESPACE")
+	}
+	for _, column := range table.ColumnDefinitions {
+		headers = append(headers, strings.ToUpper(column.Name))
+	}
+	fmt.Fprintln(w, strings.Join(headers, "\t"))
+
+	for _, row := range table.Rows {
+		cells := make([]string, 0, len(row.Cells)+1)
+		if withNamespace {
+			cells = append(cells, rowNamespace(row))
+		}
+		for _, cell := range row.Cells {
+			cells = append(cells, formatCell(cell))
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+
+	_ = w.Flush()
+	return buf.String()
+}
+
+// rowNamespace returns the namespace of the object a table row was printed from
+func rowNamespace(row metav1.TableRow) string {
+	if object, err := meta.Accessor(row.Object.Object); err == nil {
+		return object.GetNamespace()
+	}
+	var partial metav1.PartialObjectMetadata
+	if len(row.Object.Raw) > 0 {
+		_ = json.Unmarshal(row.Object.Raw, &partial)
+	}
+	return partial.Namespace
+}
+
+func formatCell(cell interface{}) string {
+	switch v := cell.(type) {
+	case nil:
+		return "<none>"
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// resourcePath builds the REST path for a resource collection or a named object
+func resourcePath(gvr schema.GroupVersionResource, namespace, name string) []string {
+	var segments []string
+	if gvr.Group == "" {
+		segments = []string{"api", gvr.Version}
+	} else {
+		segments = []string{"apis", gvr.Group, gvr.Version}
+	}
+	if namespace != "" {
+		segments = append(segments, "namespaces", namespace)
+	}
+	segments = append(segments, gvr.Resource)
+	if name != "" {
+		segments = append(segments, name)
+	}
+	return segments
+}
+
+// qualifiedKind returns the lowercase kind with its group, e.g. deployment.apps, as kubectl prints it
+func qualifiedKind(mapping *meta.RESTMapping) string {
+	kind := strings.ToLower(mapping.GroupVersionKind.Kind)
+	if mapping.GroupVersionKind.Group == "" {
+		return kind
+	}
+	return kind + "." + mapping.GroupVersionKind.Group
+}
+
+func (b *clientGoBackend) Events(ctx context.Context, call CallOptions, namespace string) (string, error) {
+	return b.Get(ctx, call, GetOptions{
+		ResourceType:  "events",
+		Namespace:     namespace,
+		AllNamespaces: namespace == "",
+		Output:        "json",
+	})
+}
+
+func (b *clientGoBackend) Logs(ctx context.Context, call CallOptions, opts LogsOptions) (string, error) {
+	_, clients, err := b.connect(ctx, call)
+	if err != nil {
+		return "", newClientGoError("logs "+opts.PodName, err, "pod", opts.PodName)
+	}
+
+	logOptions := &corev1.PodLogOptions{Container: opts.Container}
+	if opts.TailLines > 0 {
+		tailLines := int64(opts.TailLines)
+		logOptions.TailLines = &tailLines
+	}
+
+	data, err := clients.typed.CoreV1().Pods(opts.Namespace).GetLogs(opts.PodName, logOptions).DoRaw(ctx)
+	if err != nil {
+		return "", newClientGoError("logs "+opts.PodName, err, "pod", opts.PodName)
+	}
+	return string(data), nil
+}
+
+func (b *clientGoBackend) APIResources(ctx context.Context, call CallOptions) (string, error) {
+	target, err := b.targetFor(ctx)
+	if err != nil {
+		return "", newClientGoError("api-resources", err, "", "")
+	}
+
+	groups, resourceLists, err := target.discovery.ServerGroupsAndResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return "", newClientGoError("api-resources", err, "", "")
+	}
+
+	preferred := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		preferred[group.PreferredVersion.GroupVersion] = true
+	}
+
+	type apiResource struct {
+		group string
+		metav1.APIResource
+		groupVersion string
+	}
+	var resources []apiResource
+	for _, list := range resourceLists {
+		if len(preferred) > 0 && !preferred[list.GroupVersion] {
+			continue
+		}
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, resource := range list.APIResources {
+			if strings.Contains(resource.Name, "/") {
+				continue // subresource
+			}
+			resources = append(resources, apiResource{group: gv.Group, APIResource: resource, groupVersion: list.GroupVersion})
+		}
+	}
+	sort.SliceStable(resources, func(i, j int) bool {
+		if resources[i].group != resources[j].group {
+			return resources[i].group < resources[j].group
+		}
+		return resources[i].Name < resources[j].Name
+	})
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 10, 4, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSHORTNAMES\tAPIVERSION\tNAMESPACED\tKIND")
+	for _, r := range resources {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%s\n", r.Name, strings.Join(r.ShortNames, ","), r.groupVersion, r.Namespaced, r.Kind)
+	}
+	_ = w.Flush()
+	return buf.String(), nil
+}
+
+func (b *clientGoBackend) Scale(ctx context.Context, call CallOptions, opts ScaleOptions) (string, error) {
+	operation := "scale " + opts.ResourceType
+	target, clients, err := b.connect(ctx, call)
+	if err != nil {
+		return "", newClientGoError(operation, err, opts.ResourceType, opts.Name)
+	}
+	mapping, err := target.resolve(opts.ResourceType)
+	if err != nil {
+		return "", newClientGoError(operation, err, opts.ResourceType, opts.Name)
+	}
+
+	patch := fmt.Sprintf(`{"spec":{"replicas":%d}}`, opts.Replicas)
+	client := resourceClient(clients, mapping, target.namespaceFor(mapping, opts.Namespace))
+	if _, err := client.Patch(ctx, opts.Name, types.MergePatchType, []byte(patch), metav1.PatchOptions{DryRun: dryRunAll(opts.DryRun)}, "scale"); err != nil {
+		return "", newClientGoError(operation, err, opts.ResourceType, opts.Name)
+	}
+	return fmt.Sprintf("%s/%s scaled%s\n", qualifiedKind(mapping), opts.Name, dryRunSuffix(opts.DryRun)), nil
+}
+
+func (b *clientGoBackend) Patch(ctx context.Context, call CallOptions, opts PatchOptions) (string, error) {
+	operation := "patch " + opts.ResourceType
+	var patchType types.PatchType
+	switch opts.PatchType {
+	case "strategic":
+		patchType = types.StrategicMergePatchType
+	case "merge":
+		patchType = types.MergePatchType
+	case "json":
+		patchType = types.JSONPatchType
+	default:
+		return "", newClientGoError(operation, fmt.Errorf("invalid patch type %q", opts.PatchType), opts.ResourceType, opts.Name)
+	}
+
+	// kubectl accepts patches as JSON or YAML; the API server only takes JSON
+	patch, err := yaml.YAMLToJSON([]byte(opts.Patch))
+	if err != nil {
+		return "", newClientGoError(operation, fmt.Errorf("invalid patch: %w", err), opts.ResourceType, opts.Name)
+	}
+
+	target, clients, err := b.connect(ctx, call)
+	if err != nil {
+		return "", newClientGoError(operation, err, opts.ResourceType, opts.Name)
+	}
+	mapping, err := target.resolve(opts.ResourceType)
+	if err != nil {
+		return "", newClientGoError(operation, err, opts.ResourceType, opts.Name)
+	}
+
+	var subresources []string
+	if opts.Subresource != "" {
+		subresources = append(subresources, opts.Subresource)
+	}
+	client := resourceClient(clients, mapping, target.namespaceFor(mapping, opts.Namespace))
+	if _, err := client.Patch(ctx, opts.Name, patchType, patch, metav1.PatchOptions{DryRun: dryRunAll(opts.DryRun)}, subresources...); err != nil {
+		return "", newClientGoError(operation, err, opts.ResourceType, opts.Name)
+	}
+	return fmt.Sprintf("%s/%s patched%s\n", qualifiedKind(mapping), opts.Name, dryRunSuffix(opts.DryRun)), nil
+}
+
+func (b *clientGoBackend) Delete(ctx context.Context, call CallOptions, opts DeleteOptions) (string, error) {
+	operation := "delete " + opts.ResourceType
+	target, clients, err := b.connect(ctx, call)
+	if err != nil {
+		return "", newClientGoError(operation, err, opts.ResourceType, opts.Name)
+	}
+	mapping, err := target.resolve(opts.ResourceType)
+	if err != nil {
+		return "", newClientGoError(operation, err, opts.ResourceType, opts.Name)
+	}
+
+	// kubectl deletes dependents in the background by default
+	propagation := metav1.DeletePropagationBackground
+	client := resourceClient(clients, mapping, target.namespaceFor(mapping, opts.Namespace))
+	if err := client.Delete(ctx, opts.Name, metav1.DeleteOptions{PropagationPolicy: &propagation, DryRun: dryRunAll(opts.DryRun)}); err != nil {
+		return "", newClientGoError(operation, err, opts.ResourceType, opts.Name)
+	}
+	return fmt.Sprintf("%s %q deleted%s\n", qualifiedKind(mapping), opts.Name, dryRunSuffix(opts.DryRun)), nil
+}
+
+// dryRunAll returns the API dry-run value for a server-side dry run
+func dryRunAll(dryRun bool) []string {
+	if dryRun {
+		return []string{metav1.DryRunAll}
+	}
+	return nil
+}
+
+// dryRunSuffix matches the marker kubectl appends to server dry-run output
+func dryRunSuffix(dryRun bool) string {
+	if dryRun {
+		return " (server dry run)"
+	}
+	return ""
+}
+
+// newClientGoError converts a client-go error into a structured tool error using the
+// API status reason rather than matching on the message text
+func newClientGoError(operation string, err error, resourceType, resourceName string) error {
+	if errors.Is(err, ErrUnsupported) {
+		return err
+	}
+
+	toolErr := toolerrors.NewKubernetesError(operation, err).WithResource(resourceType, resourceName)
+	switch {
+	case apierrors.IsNotFound(err), meta.IsNoMatchError(err):
+		toolErr = toolErr.WithErrorCode("K8S_RESOURCE_NOT_FOUND").WithRetryable(false)
+	case apierrors.IsForbidden(err), apierrors.IsUnauthorized(err):
+		toolErr = toolErr.WithErrorCode("K8S_PERMISSION_ERROR").WithRetryable(false)
+	case apierrors.IsAlreadyExists(err):
+		toolErr = toolErr.WithErrorCode("K8S_RESOURCE_EXISTS").WithRetryable(false)
+	case apierrors.IsConflict(err):
+		toolErr = toolErr.WithErrorCode("K8S_CONFLICT").WithRetryable(true)
+	case apierrors.IsInvalid(err), apierrors.IsBadRequest(err):
+		toolErr = toolErr.WithErrorCode("K8S_INVALID_REQUEST").WithRetryable(false)
+	case apierrors.IsTimeout(err), apierrors.IsServerTimeout(err), apierrors.IsTooManyRequests(err):
+		toolErr = toolErr.WithErrorCode("K8S_TIMEOUT").WithRetryable(true)
+	}
+	if status, ok := err.(apierrors.APIStatus); ok {
+		toolErr = toolErr.WithContext("reason", string(status.Status().Reason))
+	}
+	return toolErr
+}
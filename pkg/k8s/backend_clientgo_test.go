@@ -0,0 +1,379 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/kagent-dev/tools/internal/cmd"
+	toolerrors "github.com/kagent-dev/tools/internal/errors"
+)
+
+var (
+	podsGVR        = schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	deploymentsGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	namespacesGVR  = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+)
+
+func newUnstructured(apiVersion, kind, namespace, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata":   map[string]interface{}{"name": name},
+	}}
+	if namespace != "" {
+		obj.SetNamespace(namespace)
+	}
+	if spec != nil {
+		obj.Object["spec"] = spec
+	}
+	return obj
+}
+
+// newTestClientGoBackend creates a client-go backend over fake clients. restClient is only
+// needed by tests exercising server-side table printing.
+func newTestClientGoBackend(t *testing.T, restClient rest.Interface, objects ...runtime.Object) *clientGoBackend {
+	t.Helper()
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Pod"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		podsGVR:        "PodList",
+		deploymentsGVR: "DeploymentList",
+		namespacesGVR:  "NamespaceList",
+	}, objects...)
+	typedClient := kubefake.NewClientset()
+
+	clients := &clientGoClients{dynamic: dynamicClient, typed: typedClient, rest: restClient}
+	return &clientGoBackend{
+		defaultTarget: &clientGoTarget{
+			mapper:           mapper,
+			discovery:        typedClient.Discovery(),
+			defaultNamespace: "default",
+			clientsFor: func(CallOptions) (*clientGoClients, error) {
+				return clients, nil
+			},
+		},
+	}
+}
+
+func TestNewBackend(t *testing.T) {
+	backend, err := NewBackend("", "")
+	require.NoError(t, err)
+	assert.Equal(t, BackendKubectl, backend.Name())
+
+	backend, err = NewBackend("kubectl", "/tmp/kubeconfig")
+	require.NoError(t, err)
+	assert.Equal(t, BackendKubectl, backend.Name())
+
+	_, err = NewBackend("unknown", "")
+	assert.Error(t, err)
+}
+
+func TestClientGoBackendGet(t *testing.T) {
+	ctx := context.Background()
+	backend := newTestClientGoBackend(t, nil,
+		newUnstructured("v1", "Pod", "default", "web-1", nil),
+		newUnstructured("v1", "Pod", "default", "web-2", nil),
+		newUnstructured("v1", "Pod", "other", "db-1", nil),
+		newUnstructured("apps/v1", "Deployment", "default", "web", map[string]interface{}{"replicas": int64(2)}),
+	)
+
+	t.Run("json list uses kubectl envelope", func(t *testing.T) {
+		out, err := backend.Get(ctx, CallOptions{}, GetOptions{ResourceType: "pods", Output: "json"})
+		require.NoError(t, err)
+
+		var list map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(out), &list))
+		assert.Equal(t, "List", list["kind"])
+		assert.Len(t, list["items"], 2)
+	})
+
+	t.Run("all namespaces", func(t *testing.T) {
+		out, err := backend.Get(ctx, CallOptions{}, GetOptions{ResourceType: "pod", AllNamespaces: true, Output: "name"})
+		require.NoError(t, err)
+		assert.Contains(t, out, "pod/db-1")
+		assert.Contains(t, out, "pod/web-1")
+	})
+
+	t.Run("single object yaml", func(t *testing.T) {
+		out, err := backend.Get(ctx, CallOptions{}, GetOptions{ResourceType: "deployments.apps", Name: "web", Output: "yaml"})
+		require.NoError(t, err)
+		assert.Contains(t, out, "kind: Deployment")
+		assert.Contains(t, out, "replicas: 2")
+	})
+
+	t.Run("unsupported output", func(t *testing.T) {
+		_, err := backend.Get(ctx, CallOptions{}, GetOptions{ResourceType: "pods", Output: "jsonpath={.items[*].metadata.name}"})
+		assert.ErrorIs(t, err, ErrUnsupported)
+
+		_, err = backend.Get(ctx, CallOptions{}, GetOptions{ResourceType: "pods,deployments", Output: "json"})
+		assert.ErrorIs(t, err, ErrUnsupported)
+	})
+
+	t.Run("not found is structured", func(t *testing.T) {
+		_, err := backend.Get(ctx, CallOptions{}, GetOptions{ResourceType: "pod", Name: "missing", Output: "json"})
+		var toolErr *toolerrors.ToolError
+		require.ErrorAs(t, err, &toolErr)
+		assert.Equal(t, "K8S_RESOURCE_NOT_FOUND", toolErr.ErrorCode)
+		assert.Equal(t, "missing", toolErr.ResourceName)
+		assert.Equal(t, "NotFound", toolErr.Context["reason"])
+	})
+
+	t.Run("unknown resource type", func(t *testing.T) {
+		_, err := backend.Get(ctx, CallOptions{}, GetOptions{ResourceType: "widgets", Output: "json"})
+		var toolErr *toolerrors.ToolError
+		require.ErrorAs(t, err, &toolErr)
+		assert.Equal(t, "K8S_RESOURCE_NOT_FOUND", toolErr.ErrorCode)
+	})
+}
+
+func TestClientGoBackendWideTable(t *testing.T) {
+	var gotPath, gotAccept string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAccept = r.Header.Get("Accept")
+		table := metav1.Table{
+			TypeMeta: metav1.TypeMeta{APIVersion: "meta.k8s.io/v1", Kind: "Table"},
+			ColumnDefinitions: []metav1.TableColumnDefinition{
+				{Name: "Name", Type: "string"},
+				{Name: "Ready", Type: "string"},
+				{Name: "Restarts", Type: "integer"},
+				{Name: "Nominated Node", Type: "string", Priority: 1},
+			},
+			Rows: []metav1.TableRow{
+				{
+					Cells:  []interface{}{"web-1", "1/1", 3, nil},
+					Object: runtime.RawExtension{Raw: []byte(`{"kind":"PartialObjectMetadata","apiVersion":"meta.k8s.io/v1","metadata":{"name":"web-1","namespace":"shop"}}`)},
+				},
+			},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(table)
+	}))
+	defer server.Close()
+
+	clients, err := newClientGoClients(&rest.Config{Host: server.URL})
+	require.NoError(t, err)
+	backend := newTestClientGoBackend(t, clients.rest)
+
+	out, err := backend.Get(context.Background(), CallOptions{}, GetOptions{ResourceType: "pods", AllNamespaces: true, Output: "wide"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "/api/v1/pods", gotPath)
+	assert.Contains(t, gotAccept, "as=Table")
+	assert.Contains(t, out, "NAMESPACE")
+	assert.Contains(t, out, "NOMINATED NODE")
+	assert.Contains(t, out, "shop")
+	assert.Contains(t, out, "web-1")
+	assert.Contains(t, out, "<none>")
+}
+
+func TestClientGoBackendMutations(t *testing.T) {
+	ctx := context.Background()
+	backend := newTestClientGoBackend(t, nil,
+		newUnstructured("apps/v1", "Deployment", "default", "web", map[string]interface{}{"replicas": int64(1)}),
+		newUnstructured("v1", "Namespace", "", "scratch", nil),
+	)
+	clients, _ := backend.defaultTarget.clientsFor(CallOptions{})
+
+	t.Run("scale", func(t *testing.T) {
+		out, err := backend.Scale(ctx, CallOptions{}, ScaleOptions{ResourceType: "deployment", Name: "web", Namespace: "default", Replicas: 4})
+		require.NoError(t, err)
+		assert.Equal(t, "deployment.apps/web scaled\n", out)
+
+		obj, err := clients.dynamic.Resource(deploymentsGVR).Namespace("default").Get(ctx, "web", metav1.GetOptions{})
+		require.NoError(t, err)
+		replicas, _, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+		assert.Equal(t, int64(4), replicas)
+	})
+
+	t.Run("scale dry run", func(t *testing.T) {
+		var dryRun []string
+		clients.dynamic.(*dynamicfake.FakeDynamicClient).PrependReactor("patch", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			dryRun = action.(k8stesting.PatchActionImpl).PatchOptions.DryRun
+			return false, nil, nil
+		})
+
+		out, err := backend.Scale(ctx, CallOptions{}, ScaleOptions{ResourceType: "deployment", Name: "web", Namespace: "default", Replicas: 4, DryRun: true})
+		require.NoError(t, err)
+		assert.Equal(t, "deployment.apps/web scaled (server dry run)\n", out)
+		assert.Equal(t, []string{metav1.DryRunAll}, dryRun)
+	})
+
+	t.Run("patch accepts yaml", func(t *testing.T) {
+		out, err := backend.Patch(ctx, CallOptions{}, PatchOptions{
+			ResourceType: "deployment",
+			Name:         "web",
+			Namespace:    "default",
+			Patch:        "metadata:\n  labels:\n    tier: frontend\n",
+			PatchType:    "merge",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "deployment.apps/web patched\n", out)
+
+		obj, err := clients.dynamic.Resource(deploymentsGVR).Namespace("default").Get(ctx, "web", metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "frontend", obj.GetLabels()["tier"])
+	})
+
+	t.Run("invalid patch type", func(t *testing.T) {
+		_, err := backend.Patch(ctx, CallOptions{}, PatchOptions{ResourceType: "deployment", Name: "web", Patch: "{}", PatchType: "apply"})
+		assert.Error(t, err)
+	})
+
+	t.Run("delete cluster-scoped ignores namespace", func(t *testing.T) {
+		out, err := backend.Delete(ctx, CallOptions{}, DeleteOptions{ResourceType: "namespace", Name: "scratch", Namespace: "default"})
+		require.NoError(t, err)
+		assert.Equal(t, "namespace \"scratch\" deleted\n", out)
+
+		_, err = clients.dynamic.Resource(namespacesGVR).Get(ctx, "scratch", metav1.GetOptions{})
+		assert.Error(t, err)
+	})
+}
+
+func TestClientGoBackendLogsAndAPIResources(t *testing.T) {
+	ctx := context.Background()
+	backend := newTestClientGoBackend(t, nil)
+
+	out, err := backend.Logs(ctx, CallOptions{}, LogsOptions{PodName: "web-1", Namespace: "default", TailLines: 10})
+	require.NoError(t, err)
+	assert.Equal(t, "fake logs", out)
+
+	backend.defaultTarget.discovery.(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "pods", ShortNames: []string{"po"}, Namespaced: true, Kind: "Pod"},
+				{Name: "pods/log", Namespaced: true, Kind: "Pod"},
+			},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", ShortNames: []string{"deploy"}, Namespaced: true, Kind: "Deployment"},
+			},
+		},
+	}
+
+	out, err = backend.APIResources(ctx, CallOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, out, "NAME")
+	assert.Contains(t, out, "deploy")
+	assert.NotContains(t, out, "pods/log")
+}
+
+func TestK8sToolGetResourcesPaging(t *testing.T) {
+	var gotQuery url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query()
+		remaining := int64(4)
+		table := metav1.Table{
+			TypeMeta:          metav1.TypeMeta{APIVersion: "meta.k8s.io/v1", Kind: "Table"},
+			ListMeta:          metav1.ListMeta{Continue: "next-page", RemainingItemCount: &remaining},
+			ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Name", Type: "string"}, {Name: "Ready", Type: "string"}},
+			Rows:              []metav1.TableRow{{Cells: []interface{}{"web-1", "1/1"}}},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(table)
+	}))
+	defer server.Close()
+
+	clients, err := newClientGoClients(&rest.Config{Host: server.URL})
+	require.NoError(t, err)
+	// The kubectl backend is configured: paging uses client-go regardless
+	k8sTool := NewK8sToolWithBackend("", nil, newKubectlBackend(""))
+	k8sTool.native = newTestClientGoBackend(t, clients.rest)
+
+	call := func(args map[string]interface{}) *mcp.CallToolResult {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = args
+		result, err := k8sTool.handleKubectlGetEnhanced(context.Background(), req)
+		require.NoError(t, err)
+		return result
+	}
+
+	result := call(map[string]interface{}{"resource_type": "pods", "limit": float64(1), "continue": "this-page"})
+	require.False(t, result.IsError, getResultText(result))
+	assert.Equal(t, "1", gotQuery.Get("limit"))
+	assert.Equal(t, "this-page", gotQuery.Get("continue"))
+	assert.Contains(t, getResultText(result), "web-1")
+	assert.Contains(t, getResultText(result), `4 more objects remain: call k8s_get_resources again with the same arguments and continue="next-page"`)
+
+	list, ok := result.StructuredContent.(ResourceList)
+	require.True(t, ok)
+	assert.Equal(t, "next-page", list.Continue)
+	require.NotNil(t, list.RemainingItemCount)
+	assert.Equal(t, int64(4), *list.RemainingItemCount)
+	require.Len(t, list.Rows, 1)
+	assert.Equal(t, "web-1", list.Rows[0]["name"])
+
+	for name, args := range map[string]map[string]interface{}{
+		"named resource":    {"resource_type": "pods", "resource_name": "web-1", "limit": float64(1)},
+		"unpageable output": {"resource_type": "pods", "output": "jsonpath={.items}", "limit": float64(1)},
+		"negative limit":    {"resource_type": "pods", "limit": float64(-1)},
+	} {
+		assert.True(t, call(args).IsError, name)
+	}
+}
+
+func TestK8sToolBackendFallback(t *testing.T) {
+	backend := newTestClientGoBackend(t, nil, newUnstructured("v1", "Pod", "default", "web-1", nil))
+	k8sTool := NewK8sToolWithBackend("", nil, backend)
+
+	t.Run("served by client-go", func(t *testing.T) {
+		mock := cmd.NewMockShellExecutor()
+		ctx := cmd.WithShellExecutor(context.Background(), mock)
+
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]interface{}{"resource_type": "pods", "output": "name"}
+		result, err := k8sTool.handleKubectlGetEnhanced(ctx, req)
+		require.NoError(t, err)
+		assert.False(t, result.IsError)
+		assert.Equal(t, "pod/web-1\n", getResultText(result))
+		assert.Empty(t, mock.GetCallLog())
+	})
+
+	t.Run("unsupported output falls back to kubectl", func(t *testing.T) {
+		mock := cmd.NewMockShellExecutor()
+		mock.AddCommandString("kubectl", []string{"get", "pods", "-o", "custom-columns=NAME:.metadata.name"}, "NAME\nweb-1", nil)
+		ctx := cmd.WithShellExecutor(context.Background(), mock)
+
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]interface{}{"resource_type": "pods", "output": "custom-columns=NAME:.metadata.name"}
+		result, err := k8sTool.handleKubectlGetEnhanced(ctx, req)
+		require.NoError(t, err)
+		assert.False(t, result.IsError)
+		assert.Equal(t, "NAME\nweb-1", getResultText(result))
+	})
+
+	t.Run("errors are structured", func(t *testing.T) {
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]interface{}{"resource_type": "pod", "resource_name": "missing"}
+		result, err := k8sTool.handleDeleteResource(context.Background(), req)
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, getResultText(result), "K8S_RESOURCE_NOT_FOUND")
+	})
+}
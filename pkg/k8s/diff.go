@@ -0,0 +1,226 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/pmezard/go-difflib/difflib"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/tools/internal/security"
+)
+
+// diffFieldManager is the field manager recorded on the dry-run apply used to compute a diff.
+// Nothing is persisted, so it never shows up on live objects.
+const diffFieldManager = "kagent-tools-diff"
+
+// Diff a manifest against the live cluster state
+func (k *K8sTool) handleDiffManifest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	manifest := mcp.ParseString(request, "manifest", "")
+	namespace := mcp.ParseString(request, "namespace", "")
+
+	if manifest == "" {
+		return mcp.NewToolResultError("manifest parameter is required"), nil
+	}
+
+	if err := security.ValidateYAMLContent(manifest); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid manifest content: %v", err)), nil
+	}
+
+	objects, err := decodeManifest(manifest)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid manifest content: %v", err)), nil
+	}
+	if len(objects) == 0 {
+		return mcp.NewToolResultError("manifest contains no objects"), nil
+	}
+
+	target, clients, errResult := k.connectNative(ctx, request)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	var diffs []string
+	for _, obj := range objects {
+		diff, err := diffObject(ctx, target, clients, obj, namespace)
+		if err != nil {
+			return clientGoErrorResult(err), nil
+		}
+		if diff != "" {
+			diffs = append(diffs, diff)
+		}
+	}
+
+	if len(diffs) == 0 {
+		return mcp.NewToolResultText("No differences: the live objects already match the manifest\n"), nil
+	}
+	return mcp.NewToolResultText(strings.Join(diffs, "")), nil
+}
+
+// decodeManifest splits a multi-document YAML or JSON manifest into objects, expanding v1 Lists
+func decodeManifest(manifest string) ([]*unstructured.Unstructured, error) {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(strings.NewReader(manifest), 4096)
+
+	var objects []*unstructured.Unstructured
+	for {
+		var raw map[string]interface{}
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		if len(raw) == 0 {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{Object: raw}
+		if obj.IsList() {
+			list, err := obj.ToList()
+			if err != nil {
+				return nil, err
+			}
+			for i := range list.Items {
+				objects = append(objects, &list.Items[i])
+			}
+			continue
+		}
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
+
+// diffObject returns a unified diff between the live object and the result of applying obj
+// with a server-side dry run, or "" when nothing would change. Admission webhooks and
+// defaulting run as part of the dry run, so the diff shows what the server would store.
+func diffObject(ctx context.Context, target *clientGoTarget, clients *clientGoClients, obj *unstructured.Unstructured, namespace string) (string, error) {
+	gvk := obj.GroupVersionKind()
+	name := obj.GetName()
+	operation := "diff " + gvk.Kind
+
+	if gvk.Kind == "" || gvk.Version == "" {
+		return "", newClientGoError(operation, fmt.Errorf("object %q is missing apiVersion or kind", name), gvk.Kind, name)
+	}
+	if name == "" {
+		return "", newClientGoError(operation, fmt.Errorf("%s object is missing metadata.name", gvk.Kind), gvk.Kind, name)
+	}
+
+	mapping, err := target.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return "", newClientGoError(operation, err, gvk.Kind, name)
+	}
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		if obj.GetNamespace() == "" {
+			obj.SetNamespace(target.namespaceFor(mapping, namespace))
+		}
+	} else {
+		obj.SetNamespace("")
+	}
+	client := resourceClient(clients, mapping, obj.GetNamespace())
+
+	var liveObject map[string]interface{}
+	live, err := client.Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		liveObject = live.Object
+	case !apierrors.IsNotFound(err):
+		return "", newClientGoError(operation, err, gvk.Kind, name)
+	}
+
+	proposed, err := client.Apply(ctx, name, obj, metav1.ApplyOptions{
+		FieldManager: diffFieldManager,
+		Force:        true,
+		DryRun:       []string{metav1.DryRunAll},
+	})
+	if err != nil {
+		return "", newClientGoError(operation, err, gvk.Kind, name)
+	}
+	proposedObject := proposed.Object
+
+	liveObject = normalizeForDiff(liveObject)
+	proposedObject = normalizeForDiff(proposedObject)
+	if gvk.Group == "" && gvk.Kind == "Secret" {
+		maskSecretData(liveObject, proposedObject)
+	}
+
+	liveYAML, err := yamlForDiff(liveObject)
+	if err != nil {
+		return "", err
+	}
+	proposedYAML, err := yamlForDiff(proposedObject)
+	if err != nil {
+		return "", err
+	}
+
+	// Same file naming as kubectl diff: group.version.Kind.namespace.name
+	var parts []string
+	for _, part := range []string{gvk.Group, gvk.Version, gvk.Kind, obj.GetNamespace(), name} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	file := strings.Join(parts, ".")
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(liveYAML),
+		B:        difflib.SplitLines(proposedYAML),
+		FromFile: "live/" + file,
+		ToFile:   "proposed/" + file,
+		Context:  3,
+	})
+}
+
+// normalizeForDiff drops fields that change on every write and only add noise to a diff
+func normalizeForDiff(obj map[string]interface{}) map[string]interface{} {
+	if obj == nil {
+		return nil
+	}
+	obj = runtime.DeepCopyJSON(obj)
+	unstructured.RemoveNestedField(obj, "metadata", "managedFields")
+	return obj
+}
+
+// maskSecretData hides secret values while still showing which keys change, as kubectl diff does
+func maskSecretData(live, proposed map[string]interface{}) {
+	liveData, _, _ := unstructured.NestedMap(live, "data")
+	proposedData, _, _ := unstructured.NestedMap(proposed, "data")
+
+	mask := func(data, other map[string]interface{}, changed string) map[string]interface{} {
+		masked := make(map[string]interface{}, len(data))
+		for key, value := range data {
+			if otherValue, ok := other[key]; ok && otherValue == value {
+				masked[key] = "***"
+			} else {
+				masked[key] = changed
+			}
+		}
+		return masked
+	}
+
+	if live != nil && liveData != nil {
+		_ = unstructured.SetNestedMap(live, mask(liveData, proposedData, "*** (before)"), "data")
+	}
+	if proposed != nil && proposedData != nil {
+		_ = unstructured.SetNestedMap(proposed, mask(proposedData, liveData, "*** (after)"), "data")
+	}
+}
+
+func yamlForDiff(obj map[string]interface{}) (string, error) {
+	if obj == nil {
+		return "", nil
+	}
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return "", fmt.Errorf("failed to render object: %w", err)
+	}
+	return string(data), nil
+}
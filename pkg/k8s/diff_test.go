@@ -0,0 +1,163 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newTestDiffTool returns a K8sTool whose diff runs against fake clients. The fake clients
+// cannot evaluate server-side apply, so the dry-run apply echoes the submitted object.
+func newTestDiffTool(t *testing.T, objects ...runtime.Object) (*K8sTool, *[]k8stesting.PatchActionImpl) {
+	t.Helper()
+
+	backend := newTestClientGoBackend(t, nil, objects...)
+	clients, err := backend.defaultTarget.clientsFor(CallOptions{})
+	require.NoError(t, err)
+
+	var applies []k8stesting.PatchActionImpl
+	clients.dynamic.(*dynamicfake.FakeDynamicClient).PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch := action.(k8stesting.PatchActionImpl)
+		applies = append(applies, patch)
+
+		obj := &unstructured.Unstructured{}
+		if err := json.Unmarshal(patch.Patch, &obj.Object); err != nil {
+			return true, nil, err
+		}
+		obj.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: diffFieldManager}})
+		return true, obj, nil
+	})
+
+	tool := newTestK8sTool()
+	tool.native = backend
+	return tool, &applies
+}
+
+func TestHandleDiffManifest(t *testing.T) {
+	ctx := context.Background()
+	live := newUnstructured("apps/v1", "Deployment", "default", "web", map[string]interface{}{"replicas": int64(1)})
+
+	t.Run("changed and new objects", func(t *testing.T) {
+		tool, applies := newTestDiffTool(t, live)
+
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]interface{}{
+			"manifest": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: debug
+  namespace: tools
+`,
+		}
+
+		result, err := tool.handleDiffManifest(ctx, req)
+		require.NoError(t, err)
+		require.False(t, result.IsError, getResultText(result))
+
+		diff := getResultText(result)
+		assert.Contains(t, diff, "--- live/apps.v1.Deployment.default.web")
+		assert.Contains(t, diff, "+++ proposed/apps.v1.Deployment.default.web")
+		assert.Contains(t, diff, "-  replicas: 1")
+		assert.Contains(t, diff, "+  replicas: 3")
+		assert.Contains(t, diff, "+++ proposed/v1.Pod.tools.debug")
+		assert.Contains(t, diff, "+  name: debug")
+		assert.NotContains(t, diff, "managedFields")
+
+		require.Len(t, *applies, 2)
+		for _, apply := range *applies {
+			assert.Equal(t, []string{metav1.DryRunAll}, apply.PatchOptions.DryRun)
+			assert.Equal(t, diffFieldManager, apply.PatchOptions.FieldManager)
+		}
+	})
+
+	t.Run("no differences", func(t *testing.T) {
+		tool, _ := newTestDiffTool(t, live)
+
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]interface{}{
+			"manifest": "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n  namespace: default\nspec:\n  replicas: 1\n",
+		}
+
+		result, err := tool.handleDiffManifest(ctx, req)
+		require.NoError(t, err)
+		assert.False(t, result.IsError)
+		assert.Contains(t, getResultText(result), "No differences")
+	})
+
+	t.Run("unknown kind", func(t *testing.T) {
+		tool, _ := newTestDiffTool(t)
+
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]interface{}{
+			"manifest": "apiVersion: example.com/v1\nkind: Widget\nmetadata:\n  name: w\n",
+		}
+
+		result, err := tool.handleDiffManifest(ctx, req)
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, getResultText(result), "K8S_RESOURCE_NOT_FOUND")
+	})
+
+	t.Run("missing manifest", func(t *testing.T) {
+		tool, _ := newTestDiffTool(t)
+
+		result, err := tool.handleDiffManifest(ctx, mcp.CallToolRequest{})
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+	})
+}
+
+func TestDecodeManifest(t *testing.T) {
+	objects, err := decodeManifest(`---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: a
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: b
+---
+---
+{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "c"}}
+`)
+	require.NoError(t, err)
+	require.Len(t, objects, 3)
+	assert.Equal(t, "a", objects[0].GetName())
+	assert.Equal(t, "b", objects[1].GetName())
+	assert.Equal(t, "Namespace", objects[2].GetKind())
+}
+
+func TestMaskSecretData(t *testing.T) {
+	live := map[string]interface{}{"data": map[string]interface{}{"user": "YWRtaW4=", "pass": "b2xk"}}
+	proposed := map[string]interface{}{"data": map[string]interface{}{"user": "YWRtaW4=", "pass": "bmV3", "token": "dA=="}}
+
+	maskSecretData(live, proposed)
+
+	assert.Equal(t, map[string]interface{}{"user": "***", "pass": "*** (before)"}, live["data"])
+	assert.Equal(t, map[string]interface{}{"user": "***", "pass": "*** (after)", "token": "*** (after)"}, proposed["data"])
+
+	// A secret that does not exist yet only has proposed data
+	created := map[string]interface{}{"data": map[string]interface{}{"key": "dmFsdWU="}}
+	maskSecretData(nil, created)
+	assert.Equal(t, map[string]interface{}{"key": "*** (after)"}, created["data"])
+}
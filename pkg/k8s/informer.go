@@ -0,0 +1,420 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	toolscache "k8s.io/client-go/tools/cache"
+
+	"github.com/kagent-dev/tools/internal/cache"
+	"github.com/kagent-dev/tools/internal/clusters"
+	"github.com/kagent-dev/tools/internal/logger"
+)
+
+// Defaults for InformerOptions
+const (
+	DefaultInformerMaxInformers = 50
+	DefaultInformerMaxObjects   = 10000
+	DefaultInformerIdleTimeout  = 10 * time.Minute
+)
+
+// informerSyncTimeout is how long an informer may take to sync before the cache reports
+// itself unhealthy
+const informerSyncTimeout = time.Minute
+
+// informerResources are the hot resources k8s_get_resources and k8s_get_events serve from
+// informers. Each has a wide printer.
+var informerResources = []schema.GroupResource{
+	{Resource: "pods"},
+	{Group: "apps", Resource: "deployments"},
+	{Resource: "services"},
+	{Resource: "events"},
+	{Resource: "nodes"},
+}
+
+// InformerOptions configures the informer cache, which serves reads of hot resources from
+// shared informers instead of the API server
+type InformerOptions struct {
+	Enabled bool
+	// MaxInformers bounds the informers running at once, one per cluster, resource and
+	// namespace. The least recently used one stops to make room for a new one.
+	MaxInformers int
+	// MaxObjects bounds the objects one informer holds. Larger resources are read from the
+	// API server instead.
+	MaxObjects int
+	// IdleTimeout stops informers that served no read for this long
+	IdleTimeout time.Duration
+}
+
+// informerKey identifies an informer. The namespace is empty for informers covering all
+// namespaces and for cluster-scoped resources.
+type informerKey struct {
+	cluster   string
+	resource  schema.GroupVersionResource
+	namespace string
+}
+
+func (k informerKey) String() string {
+	return strings.Join([]string{k.cluster, k.namespace, k.resource.GroupResource().String()}, "/")
+}
+
+// informerState tracks one informer. Informers of resources over MaxObjects are stopped
+// and kept as markers so that reads go to the API server without restarting them.
+type informerState struct {
+	informer  toolscache.SharedIndexInformer
+	stop      chan struct{}
+	started   time.Time
+	lastUsed  time.Time
+	tooLarge  bool
+	lastError error
+}
+
+// stopInformer stops the informer and releases the objects it holds
+func (s *informerState) stopInformer() {
+	if s.informer != nil {
+		close(s.stop)
+		s.informer = nil
+	}
+}
+
+// informerCache starts informers lazily on the first read of a resource in a namespace.
+// Reads are served once the informer has synced; until then they go to the API server.
+type informerCache struct {
+	opts      InformerOptions
+	targetFor func(ctx context.Context) (*clientGoTarget, error)
+	lookups   *cache.Lookups
+
+	mu        sync.Mutex
+	informers map[informerKey]*informerState
+}
+
+func newInformerCache(opts InformerOptions, targetFor func(ctx context.Context) (*clientGoTarget, error)) *informerCache {
+	if opts.MaxInformers <= 0 {
+		opts.MaxInformers = DefaultInformerMaxInformers
+	}
+	if opts.MaxObjects <= 0 {
+		opts.MaxObjects = DefaultInformerMaxObjects
+	}
+	if opts.IdleTimeout <= 0 {
+		opts.IdleTimeout = DefaultInformerIdleTimeout
+	}
+	return &informerCache{
+		opts:      opts,
+		targetFor: targetFor,
+		lookups:   cache.NewLookups("informer"),
+		informers: make(map[informerKey]*informerState),
+	}
+}
+
+// get serves a read from an informer. It returns false when the read has to go to the API
+// server: informers do not cover it, or its informer has not synced yet.
+func (c *informerCache) get(ctx context.Context, call CallOptions, opts GetOptions) (string, bool) {
+	// Informers read as the server, so reads as the caller must reach the API server
+	if c == nil || call.Token != "" || call.Impersonation.User != "" {
+		return "", false
+	}
+	// A caller bypassing the cache reads from the API server rather than a lagging watch
+	if maxAge, ok := cache.MaxAgeFromContext(ctx); ok && maxAge == 0 {
+		return "", false
+	}
+	output := opts.Output
+	if output == "" {
+		output = "json"
+	}
+	if !slices.Contains([]string{"json", "yaml", "name", "wide"}, output) || opts.ResourceType == "" || strings.ContainsAny(opts.ResourceType, ",/") {
+		return "", false
+	}
+
+	target, err := c.targetFor(ctx)
+	if err != nil {
+		return "", false
+	}
+	mapping, err := target.resolve(opts.ResourceType)
+	if err != nil || !slices.Contains(informerResources, mapping.Resource.GroupResource()) {
+		return "", false
+	}
+	namespace := target.namespaceFor(mapping, opts.Namespace)
+	if opts.AllNamespaces {
+		if opts.Name != "" {
+			return "", false
+		}
+		namespace = metav1.NamespaceAll
+	}
+
+	cluster, _ := clusters.FromContext(ctx)
+	key := informerKey{cluster: cluster.Name, resource: mapping.Resource, namespace: namespace}
+	store, ok := c.store(key, target)
+	if !ok {
+		c.lookups.Miss(key.String())
+		return "", false
+	}
+
+	var items []unstructured.Unstructured
+	if opts.Name != "" {
+		storeKey := opts.Name
+		if namespace != "" {
+			storeKey = namespace + "/" + opts.Name
+		}
+		obj, exists, err := store.GetByKey(storeKey)
+		if err != nil || !exists {
+			// The API server reports missing objects the way callers expect
+			c.lookups.Miss(key.String())
+			return "", false
+		}
+		items = append(items, *obj.(*unstructured.Unstructured))
+	} else {
+		for _, obj := range store.List() {
+			item := obj.(*unstructured.Unstructured)
+			if namespace == "" || item.GetNamespace() == namespace {
+				items = append(items, *item)
+			}
+		}
+		sortObjects(items)
+	}
+
+	var result string
+	if output == "wide" {
+		table, _, err := wideTable(mapping.Resource.GroupResource(), items)
+		if err != nil {
+			c.lookups.Miss(key.String())
+			return "", false
+		}
+		result = printWide(table, mapping, namespace, opts.AllNamespaces)
+	} else if result, err = printObjects(mapping, items, opts.Name != "", output); err != nil {
+		c.lookups.Miss(key.String())
+		return "", false
+	}
+	c.lookups.Hit(key.String())
+	return result, true
+}
+
+// store returns the synced store holding the objects of key, starting its informer when
+// there is none. An informer covering all namespaces also serves reads of one namespace.
+func (c *informerCache) store(key informerKey, target *clientGoTarget) (toolscache.Store, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.stopIdle(now)
+
+	if key.namespace != "" {
+		all := key
+		all.namespace = ""
+		if state, ok := c.informers[all]; ok && c.serving(state) {
+			state.lastUsed = now
+			return state.informer.GetStore(), true
+		}
+	}
+
+	state, ok := c.informers[key]
+	if !ok {
+		var err error
+		if state, err = c.start(key, target); err != nil {
+			logger.Get().Warn("Failed to start informer", "informer", key.String(), "error", err)
+			return nil, false
+		}
+	}
+	state.lastUsed = now
+	if !c.serving(state) {
+		return nil, false
+	}
+	return state.informer.GetStore(), true
+}
+
+// serving reports whether an informer has synced and holds no more than MaxObjects
+func (c *informerCache) serving(state *informerState) bool {
+	if state.tooLarge || state.informer == nil || !state.informer.HasSynced() {
+		return false
+	}
+	if len(state.informer.GetStore().ListKeys()) > c.opts.MaxObjects {
+		state.tooLarge = true
+		state.stopInformer()
+		return false
+	}
+	return true
+}
+
+// start runs an informer for key, stopping the least recently used one when MaxInformers
+// are running. The caller holds c.mu.
+func (c *informerCache) start(key informerKey, target *clientGoTarget) (*informerState, error) {
+	clients, err := target.clientsFor(CallOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	running := 0
+	var lru informerKey
+	var lruState *informerState
+	for k, state := range c.informers {
+		if state.informer == nil {
+			continue
+		}
+		running++
+		if lruState == nil || state.lastUsed.Before(lruState.lastUsed) {
+			lru, lruState = k, state
+		}
+	}
+	if running >= c.opts.MaxInformers && lruState != nil {
+		lruState.stopInformer()
+		delete(c.informers, lru)
+		logger.Get().Debug("Stopped least recently used informer", "informer", lru.String())
+	}
+
+	informer := dynamicinformer.NewFilteredDynamicInformer(clients.dynamic, key.resource, key.namespace, 0, toolscache.Indexers{}, nil).Informer()
+	state := &informerState{informer: informer, stop: make(chan struct{}), started: time.Now()}
+	// Managed fields are large and kubectl hides them
+	_ = informer.SetTransform(func(obj interface{}) (interface{}, error) {
+		if object, ok := obj.(*unstructured.Unstructured); ok {
+			object.SetManagedFields(nil)
+		}
+		return obj, nil
+	})
+	_ = informer.SetWatchErrorHandlerWithContext(func(ctx context.Context, r *toolscache.Reflector, err error) {
+		c.mu.Lock()
+		state.lastError = err
+		c.mu.Unlock()
+		toolscache.DefaultWatchErrorHandler(ctx, r, err)
+	})
+	c.informers[key] = state
+
+	go func() {
+		if c.exceedsMaxObjects(clients.dynamic, key) {
+			c.mu.Lock()
+			state.tooLarge = true
+			state.stopInformer()
+			c.mu.Unlock()
+			return
+		}
+		go informer.Run(state.stop)
+		if toolscache.WaitForCacheSync(state.stop, informer.HasSynced) {
+			// Release resources over MaxObjects without waiting for a read
+			c.mu.Lock()
+			c.serving(state)
+			c.mu.Unlock()
+		}
+	}()
+	logger.Get().Info("Started informer", "informer", key.String())
+	return state, nil
+}
+
+// exceedsMaxObjects asks the API server how many objects a resource holds before listing
+// them all. Servers that do not count remaining items are checked once the informer syncs.
+func (c *informerCache) exceedsMaxObjects(client dynamic.Interface, key informerKey) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	list, err := client.Resource(key.resource).Namespace(key.namespace).List(ctx, metav1.ListOptions{Limit: 1})
+	if err != nil {
+		return false
+	}
+	remaining := list.GetRemainingItemCount()
+	return remaining != nil && int(*remaining)+len(list.Items) > c.opts.MaxObjects
+}
+
+// stopIdle stops informers that served no read for IdleTimeout, and forgets resources found
+// too large after as long so that they are tried again. The caller holds c.mu.
+func (c *informerCache) stopIdle(now time.Time) {
+	for key, state := range c.informers {
+		since := state.lastUsed
+		if state.tooLarge {
+			since = state.started
+		}
+		if now.Sub(since) > c.opts.IdleTimeout {
+			state.stopInformer()
+			delete(c.informers, key)
+			logger.Get().Debug("Stopped idle informer", "informer", key.String())
+		}
+	}
+}
+
+// stopAll stops every informer
+func (c *informerCache) stopAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, state := range c.informers {
+		state.stopInformer()
+		delete(c.informers, key)
+	}
+}
+
+// informerStatus reports the state of one informer
+type informerStatus struct {
+	Cluster   string    `json:"cluster,omitempty"`
+	Resource  string    `json:"resource"`
+	Namespace string    `json:"namespace,omitempty"`
+	State     string    `json:"state"` // syncing, synced, failing or too_large
+	Objects   int       `json:"objects"`
+	Error     string    `json:"error,omitempty"`
+	Started   time.Time `json:"started"`
+	LastUsed  time.Time `json:"last_used"`
+}
+
+// informerHealth is the result of k8s_cache_health
+type informerHealth struct {
+	Healthy      bool             `json:"healthy"`
+	MaxInformers int              `json:"max_informers"`
+	MaxObjects   int              `json:"max_objects"`
+	Informers    []informerStatus `json:"informers"`
+}
+
+// health reports the sync state of every informer. The cache is unhealthy while an informer
+// fails to sync.
+func (c *informerCache) health() informerHealth {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	health := informerHealth{Healthy: true, MaxInformers: c.opts.MaxInformers, MaxObjects: c.opts.MaxObjects, Informers: []informerStatus{}}
+	for key, state := range c.informers {
+		status := informerStatus{
+			Cluster:   key.cluster,
+			Resource:  key.resource.GroupResource().String(),
+			Namespace: key.namespace,
+			Started:   state.started,
+			LastUsed:  state.lastUsed,
+		}
+		if state.lastError != nil {
+			status.Error = state.lastError.Error()
+		}
+		switch {
+		case state.tooLarge:
+			status.State = "too_large"
+		case state.informer.HasSynced():
+			status.State = "synced"
+			status.Objects = len(state.informer.GetStore().ListKeys())
+		case state.lastError != nil || time.Since(state.started) > informerSyncTimeout:
+			status.State = "failing"
+			health.Healthy = false
+		default:
+			status.State = "syncing"
+		}
+		health.Informers = append(health.Informers, status)
+	}
+	sort.Slice(health.Informers, func(i, j int) bool {
+		a, b := health.Informers[i], health.Informers[j]
+		return a.Cluster+"/"+a.Namespace+"/"+a.Resource < b.Cluster+"/"+b.Namespace+"/"+b.Resource
+	})
+	return health
+}
+
+// Report the sync state of the informer cache
+func (k *K8sTool) handleCacheHealth(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if k.informers == nil {
+		return mcp.NewToolResultError("informer cache is not enabled"), nil
+	}
+	content, err := json.MarshalIndent(k.informers.health(), "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal cache health: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(content)), nil
+}
@@ -0,0 +1,220 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kagent-dev/tools/internal/cache"
+	"github.com/kagent-dev/tools/internal/credentials"
+)
+
+// newTestInformerCache returns an informer cache over the fake clients of a client-go backend
+func newTestInformerCache(t *testing.T, opts InformerOptions, objects ...runtime.Object) (*informerCache, *clientGoBackend) {
+	t.Helper()
+	backend := newTestClientGoBackend(t, nil, objects...)
+	c := newInformerCache(opts, func(context.Context) (*clientGoTarget, error) {
+		return backend.defaultTarget, nil
+	})
+	t.Cleanup(c.stopAll)
+	return c, backend
+}
+
+// eventuallyGet waits for the informer serving a read to sync and returns its output
+func eventuallyGet(t *testing.T, c *informerCache, opts GetOptions) string {
+	t.Helper()
+	var output string
+	require.Eventually(t, func() bool {
+		var ok bool
+		output, ok = c.get(context.Background(), CallOptions{}, opts)
+		return ok
+	}, 5*time.Second, 10*time.Millisecond)
+	return output
+}
+
+func TestInformerCacheGet(t *testing.T) {
+	ctx := context.Background()
+	c, backend := newTestInformerCache(t, InformerOptions{Enabled: true},
+		newUnstructured("v1", "Pod", "default", "web-1", nil),
+		newUnstructured("v1", "Pod", "default", "web-2", nil),
+		newUnstructured("v1", "Pod", "other", "db-1", nil),
+	)
+
+	_, ok := c.get(ctx, CallOptions{}, GetOptions{ResourceType: "pods", Output: "json"})
+	assert.False(t, ok, "reads go to the API server until the informer has synced")
+
+	var list map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(eventuallyGet(t, c, GetOptions{ResourceType: "pods", Output: "json"})), &list))
+	assert.Equal(t, "List", list["kind"])
+	assert.Len(t, list["items"], 2)
+
+	t.Run("always fresh", func(t *testing.T) {
+		clients, err := backend.defaultTarget.clientsFor(CallOptions{})
+		require.NoError(t, err)
+		_, err = clients.dynamic.Resource(podsGVR).Namespace("default").Create(ctx, newUnstructured("v1", "Pod", "default", "web-3", nil), metav1.CreateOptions{})
+		require.NoError(t, err)
+		assert.Eventually(t, func() bool {
+			output, _ := c.get(ctx, CallOptions{}, GetOptions{ResourceType: "pods", Output: "name"})
+			return output == "pod/web-1\npod/web-2\npod/web-3\n"
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("single object", func(t *testing.T) {
+		output, ok := c.get(ctx, CallOptions{}, GetOptions{ResourceType: "pod", Name: "web-1", Output: "yaml"})
+		require.True(t, ok)
+		assert.Contains(t, output, "name: web-1")
+		assert.NotContains(t, output, "kind: List")
+
+		_, ok = c.get(ctx, CallOptions{}, GetOptions{ResourceType: "pods", Name: "missing", Output: "yaml"})
+		assert.False(t, ok, "missing objects are reported by the API server")
+	})
+
+	t.Run("all namespaces serve namespaced reads", func(t *testing.T) {
+		output := eventuallyGet(t, c, GetOptions{ResourceType: "pods", AllNamespaces: true, Output: "wide"})
+		assert.Contains(t, output, "NAMESPACE")
+		assert.Contains(t, output, "db-1")
+
+		output, ok := c.get(ctx, CallOptions{}, GetOptions{ResourceType: "pods", Namespace: "other", Output: "name"})
+		require.True(t, ok)
+		assert.Equal(t, "pod/db-1\n", output)
+	})
+
+	t.Run("reads the informers do not serve", func(t *testing.T) {
+		for name, opts := range map[string]GetOptions{
+			"jsonpath":      {ResourceType: "pods", Output: "jsonpath={.items[*].metadata.name}"},
+			"several kinds": {ResourceType: "pods,deployments", Output: "json"},
+			"cold resource": {ResourceType: "namespaces", Output: "json"},
+		} {
+			_, ok := c.get(ctx, CallOptions{}, opts)
+			assert.False(t, ok, name)
+		}
+
+		_, ok := c.get(ctx, CallOptions{Token: "caller-token"}, GetOptions{ResourceType: "pods", Output: "json"})
+		assert.False(t, ok, "the caller's permissions apply to reads with its token")
+		_, ok = c.get(ctx, CallOptions{Impersonation: credentials.Impersonation{User: "alice"}}, GetOptions{ResourceType: "pods", Output: "json"})
+		assert.False(t, ok, "the caller's permissions apply to impersonated reads")
+		_, ok = c.get(cache.WithMaxAge(ctx, 0), CallOptions{}, GetOptions{ResourceType: "pods", Output: "json"})
+		assert.False(t, ok, "callers bypassing the cache read from the API server")
+	})
+
+	var nilCache *informerCache
+	_, ok = nilCache.get(ctx, CallOptions{}, GetOptions{ResourceType: "pods"})
+	assert.False(t, ok)
+}
+
+func TestInformerCacheBounds(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("max informers", func(t *testing.T) {
+		c, _ := newTestInformerCache(t, InformerOptions{Enabled: true, MaxInformers: 1},
+			newUnstructured("v1", "Pod", "default", "web-1", nil),
+			newUnstructured("apps/v1", "Deployment", "default", "web", nil),
+		)
+		eventuallyGet(t, c, GetOptions{ResourceType: "pods", Output: "name"})
+		eventuallyGet(t, c, GetOptions{ResourceType: "deployments", Output: "name"})
+
+		health := c.health()
+		require.Len(t, health.Informers, 1, "the least recently used informer stops")
+		assert.Equal(t, "deployments.apps", health.Informers[0].Resource)
+	})
+
+	t.Run("max objects", func(t *testing.T) {
+		c, _ := newTestInformerCache(t, InformerOptions{Enabled: true, MaxObjects: 1},
+			newUnstructured("v1", "Pod", "default", "web-1", nil),
+			newUnstructured("v1", "Pod", "default", "web-2", nil),
+		)
+		_, _ = c.get(ctx, CallOptions{}, GetOptions{ResourceType: "pods", Output: "name"})
+		assert.Eventually(t, func() bool {
+			health := c.health()
+			return len(health.Informers) == 1 && health.Informers[0].State == "too_large"
+		}, 5*time.Second, 10*time.Millisecond)
+
+		_, ok := c.get(ctx, CallOptions{}, GetOptions{ResourceType: "pods", Output: "name"})
+		assert.False(t, ok, "resources over the limit are read from the API server")
+		assert.True(t, c.health().Healthy)
+	})
+
+	t.Run("idle informers stop", func(t *testing.T) {
+		c, _ := newTestInformerCache(t, InformerOptions{Enabled: true, IdleTimeout: time.Hour},
+			newUnstructured("v1", "Pod", "default", "web-1", nil),
+		)
+		eventuallyGet(t, c, GetOptions{ResourceType: "pods", Output: "name"})
+		c.mu.Lock()
+		c.stopIdle(time.Now().Add(2 * time.Hour))
+		c.mu.Unlock()
+		assert.Empty(t, c.health().Informers)
+	})
+}
+
+func TestHandleCacheHealth(t *testing.T) {
+	ctx := context.Background()
+	tool := newTestK8sTool()
+
+	result, err := tool.handleCacheHealth(ctx, mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	tool.informers, _ = newTestInformerCache(t, InformerOptions{Enabled: true},
+		newUnstructured("v1", "Pod", "default", "web-1", nil),
+	)
+	eventuallyGet(t, tool.informers, GetOptions{ResourceType: "pods", Output: "name"})
+
+	result, err = tool.handleCacheHealth(ctx, mcp.CallToolRequest{})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var health informerHealth
+	require.NoError(t, json.Unmarshal([]byte(getResultText(result)), &health))
+	assert.True(t, health.Healthy)
+	require.Len(t, health.Informers, 1)
+	assert.Equal(t, "pods", health.Informers[0].Resource)
+	assert.Equal(t, "default", health.Informers[0].Namespace)
+	assert.Equal(t, "synced", health.Informers[0].State)
+	assert.Equal(t, 1, health.Informers[0].Objects)
+}
+
+func TestWideTable(t *testing.T) {
+	started := true
+	pod := &corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-1", CreationTimestamp: metav1.NewTime(time.Now().Add(-5 * time.Hour))},
+		Spec: corev1.PodSpec{
+			NodeName:   "node-a",
+			Containers: []corev1.Container{{Name: "app"}, {Name: "sidecar"}},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			PodIP: "10.0.0.7",
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "app", RestartCount: 3, State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}},
+				{Name: "sidecar", Ready: true, Started: &started, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+			},
+		},
+	}
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(pod)
+	require.NoError(t, err)
+
+	table, ok, err := wideTable(schema.GroupResource{Resource: "pods"}, []unstructured.Unstructured{{Object: object}})
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, []interface{}{"web-1", "1/2", "CrashLoopBackOff", "3", "5h", "10.0.0.7", "node-a", "<none>", "<none>"}, table.Rows[0].Cells)
+
+	output := printTable(table, true)
+	assert.Contains(t, output, "NAMESPACE")
+	assert.Contains(t, output, "NOMINATED NODE")
+	assert.Contains(t, output, "default")
+
+	_, ok, err = wideTable(schema.GroupResource{Resource: "configmaps"}, nil)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -3,6 +3,8 @@ package k8s
 import (
 	"context"
 	_ "embed"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"maps"
 	"math/rand"
@@ -10,6 +12,7 @@ import (
 	"os"
 	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -17,7 +20,10 @@ import (
 	"github.com/tmc/langchaingo/llms"
 
 	"github.com/kagent-dev/tools/internal/cache"
+	"github.com/kagent-dev/tools/internal/clusters"
 	"github.com/kagent-dev/tools/internal/commands"
+	"github.com/kagent-dev/tools/internal/credentials"
+	toolerrors "github.com/kagent-dev/tools/internal/errors"
 	"github.com/kagent-dev/tools/internal/logger"
 	"github.com/kagent-dev/tools/internal/security"
 	"github.com/kagent-dev/tools/internal/telemetry"
@@ -27,31 +33,26 @@ import (
 type K8sTool struct {
 	kubeconfig       string
 	llmModel         llms.Model
-	tokenPassthrough bool // when true, require Bearer token and pass it to kubectl; when false, do not use token
+	tokenPassthrough bool    // when true, require Bearer token and pass it to kubectl; when false, do not use token
+	backend          Backend // serves get/logs/events/scale/patch/delete; everything else runs kubectl
+
+	nativeMu sync.Mutex
+	native   *clientGoBackend // serves watch, follow and diff when backend is not client-go
+
+	informers *informerCache // serves hot resource reads from memory when enabled
 }
 
 func NewK8sTool(llmModel llms.Model) *K8sTool {
-	return &K8sTool{llmModel: llmModel, tokenPassthrough: os.Getenv("TOKEN_PASSTHROUGH") == "true"}
+	return NewK8sToolWithConfig("", llmModel)
 }
 
 func NewK8sToolWithConfig(kubeconfig string, llmModel llms.Model) *K8sTool {
-	return &K8sTool{kubeconfig: kubeconfig, llmModel: llmModel, tokenPassthrough: os.Getenv("TOKEN_PASSTHROUGH") == "true"}
+	return NewK8sToolWithBackend(kubeconfig, llmModel, newKubectlBackend(kubeconfig))
 }
 
-// runKubectlCommandWithCacheInvalidation runs a kubectl command and invalidates cache if it's a modification operation
-func (k *K8sTool) runKubectlCommandWithCacheInvalidation(ctx context.Context, headers http.Header, args ...string) (*mcp.CallToolResult, error) {
-	result, err := k.runKubectlCommand(ctx, headers, args...)
-
-	// If command succeeded and it's a modification command, invalidate cache
-	if err == nil && len(args) > 0 {
-		subcommand := args[0]
-		switch subcommand {
-		case "apply", "delete", "patch", "scale", "annotate", "label", "create", "run", "rollout":
-			cache.InvalidateKubernetesCache()
-		}
-	}
-
-	return result, err
+// NewK8sToolWithBackend creates a K8sTool that routes supported operations through the given backend
+func NewK8sToolWithBackend(kubeconfig string, llmModel llms.Model, backend Backend) *K8sTool {
+	return &K8sTool{kubeconfig: kubeconfig, llmModel: llmModel, tokenPassthrough: credentials.PassthroughEnabled(), backend: backend}
 }
 
 // Enhanced kubectl get
@@ -61,30 +62,71 @@ func (k *K8sTool) handleKubectlGetEnhanced(ctx context.Context, request mcp.Call
 	namespace := mcp.ParseString(request, "namespace", "")
 	allNamespaces := mcp.ParseString(request, "all_namespaces", "") == "true"
 	output := mcp.ParseString(request, "output", "wide")
+	limit := mcp.ParseInt64(request, "limit", 0)
+	continueToken := mcp.ParseString(request, "continue", "")
 
 	if resourceType == "" {
 		return mcp.NewToolResultError("resource_type parameter is required"), nil
 	}
+	if limit < 0 {
+		return mcp.NewToolResultError("limit must not be negative"), nil
+	}
 
-	args := []string{"get", resourceType}
-
-	if resourceName != "" {
-		args = append(args, resourceName)
+	opts := GetOptions{
+		ResourceType:  resourceType,
+		Name:          resourceName,
+		Namespace:     namespace,
+		AllNamespaces: allNamespaces,
+		Output:        output,
+		Limit:         limit,
+		Continue:      continueToken,
 	}
+	if limit > 0 || continueToken != "" {
+		return k.getResourcePage(ctx, request, opts)
+	}
+	result, err := k.runBackend(ctx, request.Header, nil, func(b Backend, call CallOptions) (string, error) {
+		if output, ok := k.informers.get(ctx, call, opts); ok {
+			return output, nil
+		}
+		return b.Get(ctx, call, opts)
+	})
+	return withResourceList(result, resourceType, output), err
+}
 
-	if allNamespaces {
-		args = append(args, "--all-namespaces")
-	} else if namespace != "" {
-		args = append(args, "-n", namespace)
+// getResourcePage serves a k8s_get_resources call with limit or continue. kubectl cannot
+// page a list, so these calls use client-go whatever --k8s-backend is, and skip the caches.
+func (k *K8sTool) getResourcePage(ctx context.Context, request mcp.CallToolRequest, opts GetOptions) (*mcp.CallToolResult, error) {
+	call, err := k.callOptions(ctx, request.Header)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	b, err := k.nativeBackend()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to create kubernetes client: %v", err)), nil
 	}
 
-	if output != "" {
-		args = append(args, "-o", output)
-	} else {
-		args = append(args, "-o", "json")
+	output, listMeta, err := b.GetPage(ctx, call, opts)
+	if errors.Is(err, ErrUnsupported) {
+		return mcp.NewToolResultError(fmt.Sprintf("output %q cannot be paged: use json, yaml, wide or name with limit and continue", opts.Output)), nil
+	}
+	if err != nil {
+		var toolErr *toolerrors.ToolError
+		if errors.As(err, &toolErr) {
+			return toolErr.ToMCPResult(), nil
+		}
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	return k.runKubectlCommand(ctx, request.Header, args...)
+	list := resourceList(opts.ResourceType, opts.Output, output)
+	list.Continue, list.RemainingItemCount = listMeta.Continue, listMeta.RemainingItemCount
+	if listMeta.Continue != "" {
+		remaining := "More objects remain"
+		if listMeta.RemainingItemCount != nil {
+			remaining = fmt.Sprintf("%d more objects remain", *listMeta.RemainingItemCount)
+		}
+		output += fmt.Sprintf("\n%s: call k8s_get_resources again with the same arguments and continue=%q for the next page.\n", remaining, listMeta.Continue)
+	}
+	return mcp.NewToolResultStructured(list, output), nil
 }
 
 // Get pod logs
@@ -98,17 +140,10 @@ func (k *K8sTool) handleKubectlLogsEnhanced(ctx context.Context, request mcp.Cal
 		return mcp.NewToolResultError("pod_name parameter is required"), nil
 	}
 
-	args := []string{"logs", podName, "-n", namespace}
-
-	if container != "" {
-		args = append(args, "-c", container)
-	}
-
-	if tailLines > 0 {
-		args = append(args, "--tail", fmt.Sprintf("%d", tailLines))
-	}
-
-	return k.runKubectlCommand(ctx, request.Header, args...)
+	opts := LogsOptions{PodName: podName, Namespace: namespace, Container: container, TailLines: tailLines}
+	return k.runBackend(ctx, request.Header, nil, func(b Backend, call CallOptions) (string, error) {
+		return b.Logs(ctx, call, opts)
+	})
 }
 
 // Scale deployment
@@ -116,14 +151,16 @@ func (k *K8sTool) handleScaleDeployment(ctx context.Context, request mcp.CallToo
 	deploymentName := mcp.ParseString(request, "name", "")
 	namespace := mcp.ParseString(request, "namespace", "default")
 	replicas := mcp.ParseInt(request, "replicas", 1)
+	dryRun := mcp.ParseBoolean(request, "dry_run", false)
 
 	if deploymentName == "" {
 		return mcp.NewToolResultError("name parameter is required"), nil
 	}
 
-	args := []string{"scale", "deployment", deploymentName, "--replicas", fmt.Sprintf("%d", replicas), "-n", namespace}
-
-	return k.runKubectlCommandWithCacheInvalidation(ctx, request.Header, args...)
+	opts := ScaleOptions{ResourceType: "deployment", Name: deploymentName, Namespace: namespace, Replicas: replicas, DryRun: dryRun}
+	return k.runBackend(ctx, request.Header, changes(dryRun, cache.ResourceTag(namespace, "deployment", deploymentName)), func(b Backend, call CallOptions) (string, error) {
+		return b.Scale(ctx, call, opts)
+	})
 }
 
 // Patch resource
@@ -133,6 +170,7 @@ func (k *K8sTool) handlePatchResource(ctx context.Context, request mcp.CallToolR
 	patch := mcp.ParseString(request, "patch", "")
 	namespace := mcp.ParseString(request, "namespace", "default")
 	patchType := mcp.ParseString(request, "patch_type", "strategic")
+	dryRun := mcp.ParseBoolean(request, "dry_run", false)
 
 	if resourceType == "" || resourceName == "" || patch == "" {
 		return mcp.NewToolResultError("resource_type, resource_name, and patch parameters are required"), nil
@@ -161,9 +199,17 @@ func (k *K8sTool) handlePatchResource(ctx context.Context, request mcp.CallToolR
 		return mcp.NewToolResultError(fmt.Sprintf("Invalid patch content: %v", err)), nil
 	}
 
-	args := []string{"patch", resourceType, resourceName, "--type=" + patchType, "-p", patch, "-n", namespace}
-
-	return k.runKubectlCommandWithCacheInvalidation(ctx, request.Header, args...)
+	opts := PatchOptions{
+		ResourceType: resourceType,
+		Name:         resourceName,
+		Namespace:    namespace,
+		Patch:        patch,
+		PatchType:    patchType,
+		DryRun:       dryRun,
+	}
+	return k.runBackend(ctx, request.Header, changes(dryRun, cache.ResourceTag(namespace, resourceType, resourceName)), func(b Backend, call CallOptions) (string, error) {
+		return b.Patch(ctx, call, opts)
+	})
 }
 
 // Patch resource status
@@ -172,6 +218,7 @@ func (k *K8sTool) handlePatchStatus(ctx context.Context, request mcp.CallToolReq
 	resourceName := mcp.ParseString(request, "resource_name", "")
 	patch := mcp.ParseString(request, "patch", "")
 	namespace := mcp.ParseString(request, "namespace", "default")
+	dryRun := mcp.ParseBoolean(request, "dry_run", false)
 
 	if resourceType == "" || resourceName == "" || patch == "" {
 		return mcp.NewToolResultError("resource_type, resource_name, and patch parameters are required"), nil
@@ -192,24 +239,24 @@ func (k *K8sTool) handlePatchStatus(ctx context.Context, request mcp.CallToolReq
 		return mcp.NewToolResultError(fmt.Sprintf("Invalid patch content: %v", err)), nil
 	}
 
-	args := []string{
-		"patch",
-		resourceType,
-		resourceName,
-		"--subresource=status",
-		"--type=merge",
-		"-p",
-		patch,
-		"-n",
-		namespace,
+	opts := PatchOptions{
+		ResourceType: resourceType,
+		Name:         resourceName,
+		Namespace:    namespace,
+		Patch:        patch,
+		PatchType:    "merge",
+		Subresource:  "status",
+		DryRun:       dryRun,
 	}
-
-	return k.runKubectlCommandWithCacheInvalidation(ctx, request.Header, args...)
+	return k.runBackend(ctx, request.Header, changes(dryRun, cache.ResourceTag(namespace, resourceType, resourceName)), func(b Backend, call CallOptions) (string, error) {
+		return b.Patch(ctx, call, opts)
+	})
 }
 
 // Apply manifest from content
 func (k *K8sTool) handleApplyManifest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	manifest := mcp.ParseString(request, "manifest", "")
+	dryRun := mcp.ParseBoolean(request, "dry_run", false)
 
 	if manifest == "" {
 		return mcp.NewToolResultError("manifest parameter is required"), nil
@@ -249,7 +296,7 @@ func (k *K8sTool) handleApplyManifest(ctx context.Context, request mcp.CallToolR
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to close temp file: %v", err)), nil
 	}
 
-	return k.runKubectlCommandWithCacheInvalidation(ctx, request.Header, "apply", "-f", tmpFile.Name())
+	return k.runKubectlWriteChanging(ctx, request.Header, dryRun, manifestTags(manifest), "apply", "-f", tmpFile.Name())
 }
 
 // Delete resource
@@ -257,20 +304,23 @@ func (k *K8sTool) handleDeleteResource(ctx context.Context, request mcp.CallTool
 	resourceType := mcp.ParseString(request, "resource_type", "")
 	resourceName := mcp.ParseString(request, "resource_name", "")
 	namespace := mcp.ParseString(request, "namespace", "default")
+	dryRun := mcp.ParseBoolean(request, "dry_run", false)
 
 	if resourceType == "" || resourceName == "" {
 		return mcp.NewToolResultError("resource_type and resource_name parameters are required"), nil
 	}
 
-	args := []string{"delete", resourceType, resourceName, "-n", namespace}
-
-	return k.runKubectlCommandWithCacheInvalidation(ctx, request.Header, args...)
+	opts := DeleteOptions{ResourceType: resourceType, Name: resourceName, Namespace: namespace, DryRun: dryRun}
+	return k.runBackend(ctx, request.Header, changes(dryRun, cache.ResourceTag(namespace, resourceType, resourceName)), func(b Backend, call CallOptions) (string, error) {
+		return b.Delete(ctx, call, opts)
+	})
 }
 
 // Check service connectivity
 func (k *K8sTool) handleCheckServiceConnectivity(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	serviceName := mcp.ParseString(request, "service_name", "")
 	namespace := mcp.ParseString(request, "namespace", "default")
+	dryRun := mcp.ParseBoolean(request, "dry_run", false)
 
 	if serviceName == "" {
 		return mcp.NewToolResultError("service_name parameter is required"), nil
@@ -278,38 +328,44 @@ func (k *K8sTool) handleCheckServiceConnectivity(ctx context.Context, request mc
 
 	// Create a temporary curl pod for connectivity check
 	podName := fmt.Sprintf("curl-test-%d", rand.Intn(10000))
+	runArgs := []string{"run", podName, "--image=curlimages/curl", "-n", namespace, "--restart=Never", "--", "sleep", "3600"}
+	waitArgs := []string{"wait", "--for=condition=ready", "pod/" + podName, "-n", namespace}
+	execArgs := []string{"exec", podName, "-n", namespace, "--", "curl", "-s", serviceName}
+	deleteArgs := []string{"delete", "pod", podName, "-n", namespace, "--ignore-not-found"}
+	if dryRun {
+		return dryRunPlan(runArgs, waitArgs, execArgs, deleteArgs), nil
+	}
 	defer func() {
-		_, _ = k.runKubectlCommand(ctx, request.Header, "delete", "pod", podName, "-n", namespace, "--ignore-not-found")
+		_, _ = k.runKubectlCommand(ctx, request.Header, deleteArgs...)
 	}()
 
 	// Create the curl pod
-	_, err := k.runKubectlCommand(ctx, request.Header, "run", podName, "--image=curlimages/curl", "-n", namespace, "--restart=Never", "--", "sleep", "3600")
+	_, err := k.runKubectlCommand(ctx, request.Header, runArgs...)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to create curl pod: %v", err)), nil
 	}
 
 	// Wait for pod to be ready
-	_, err = k.runKubectlCommandWithTimeout(ctx, request.Header, 60*time.Second, "wait", "--for=condition=ready", "pod/"+podName, "-n", namespace)
+	_, err = k.runKubectlCommandWithTimeout(ctx, request.Header, 60*time.Second, waitArgs...)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to wait for curl pod: %v", err)), nil
 	}
 
 	// Execute kubectl command
-	return k.runKubectlCommand(ctx, request.Header, "exec", podName, "-n", namespace, "--", "curl", "-s", serviceName)
+	return k.runKubectlCommand(ctx, request.Header, execArgs...)
 }
 
 // Get cluster events
 func (k *K8sTool) handleGetEvents(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	namespace := mcp.ParseString(request, "namespace", "")
 
-	args := []string{"get", "events", "-o", "json"}
-	if namespace != "" {
-		args = append(args, "-n", namespace)
-	} else {
-		args = append(args, "--all-namespaces")
-	}
-
-	return k.runKubectlCommand(ctx, request.Header, args...)
+	return k.runBackend(ctx, request.Header, nil, func(b Backend, call CallOptions) (string, error) {
+		opts := GetOptions{ResourceType: "events", Namespace: namespace, AllNamespaces: namespace == "", Output: "json"}
+		if output, ok := k.informers.get(ctx, call, opts); ok {
+			return output, nil
+		}
+		return b.Events(ctx, call, namespace)
+	})
 }
 
 // Execute command in pod
@@ -317,6 +373,7 @@ func (k *K8sTool) handleExecCommand(ctx context.Context, request mcp.CallToolReq
 	podName := mcp.ParseString(request, "pod_name", "")
 	namespace := mcp.ParseString(request, "namespace", "default")
 	command := mcp.ParseString(request, "command", "")
+	dryRun := mcp.ParseBoolean(request, "dry_run", false)
 
 	if podName == "" || command == "" {
 		return mcp.NewToolResultError("pod_name and command parameters are required"), nil
@@ -338,13 +395,18 @@ func (k *K8sTool) handleExecCommand(ctx context.Context, request mcp.CallToolReq
 	}
 
 	args := []string{"exec", podName, "-n", namespace, "--", command}
+	if dryRun {
+		return dryRunPlan(args), nil
+	}
 
 	return k.runKubectlCommand(ctx, request.Header, args...)
 }
 
 // Get available API resources
 func (k *K8sTool) handleGetAvailableAPIResources(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	return k.runKubectlCommand(ctx, request.Header, "api-resources")
+	return k.runBackend(ctx, request.Header, nil, func(b Backend, call CallOptions) (string, error) {
+		return b.APIResources(ctx, call)
+	})
 }
 
 // Kubectl describe tool
@@ -371,17 +433,26 @@ func (k *K8sTool) handleRollout(ctx context.Context, request mcp.CallToolRequest
 	resourceType := mcp.ParseString(request, "resource_type", "")
 	resourceName := mcp.ParseString(request, "resource_name", "")
 	namespace := mcp.ParseString(request, "namespace", "")
+	dryRun := mcp.ParseBoolean(request, "dry_run", false)
 
 	if action == "" || resourceType == "" || resourceName == "" {
 		return mcp.NewToolResultError("action, resource_type, and resource_name parameters are required"), nil
 	}
 
+	// Only restart and undo change the object in a way kubectl can dry-run
+	if dryRun && action != "restart" && action != "undo" {
+		return mcp.NewToolResultError(fmt.Sprintf("dry_run is not supported for rollout %s: use restart or undo", action)), nil
+	}
+
 	args := []string{"rollout", action, fmt.Sprintf("%s/%s", resourceType, resourceName)}
 	if namespace != "" {
 		args = append(args, "-n", namespace)
 	}
 
-	return k.runKubectlCommand(ctx, request.Header, args...)
+	if action == "status" || action == "history" {
+		return k.runKubectlCommand(ctx, request.Header, args...)
+	}
+	return k.runKubectlWrite(ctx, request.Header, dryRun, args...)
 }
 
 // Get cluster configuration
@@ -389,12 +460,27 @@ func (k *K8sTool) handleGetClusterConfiguration(ctx context.Context, request mcp
 	return k.runKubectlCommand(ctx, request.Header, "config", "view", "-o", "json")
 }
 
+// clusterCheckTimeout bounds how long k8s_list_clusters waits for each API server
+const clusterCheckTimeout = 5 * time.Second
+
+// List the clusters available for per-call selection and whether each is reachable
+func (k *K8sTool) handleListClusters(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	statuses := clusters.GetRegistry().CheckAll(clusterCheckTimeout)
+
+	data, err := json.MarshalIndent(statuses, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal cluster list: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
 // Remove annotation
 func (k *K8sTool) handleRemoveAnnotation(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	resourceType := mcp.ParseString(request, "resource_type", "")
 	resourceName := mcp.ParseString(request, "resource_name", "")
 	annotationKey := mcp.ParseString(request, "annotation_key", "")
 	namespace := mcp.ParseString(request, "namespace", "")
+	dryRun := mcp.ParseBoolean(request, "dry_run", false)
 
 	if resourceType == "" || resourceName == "" || annotationKey == "" {
 		return mcp.NewToolResultError("resource_type, resource_name, and annotation_key parameters are required"), nil
@@ -405,7 +491,7 @@ func (k *K8sTool) handleRemoveAnnotation(ctx context.Context, request mcp.CallTo
 		args = append(args, "-n", namespace)
 	}
 
-	return k.runKubectlCommand(ctx, request.Header, args...)
+	return k.runKubectlWrite(ctx, request.Header, dryRun, args...)
 }
 
 // Remove label
@@ -414,6 +500,7 @@ func (k *K8sTool) handleRemoveLabel(ctx context.Context, request mcp.CallToolReq
 	resourceName := mcp.ParseString(request, "resource_name", "")
 	labelKey := mcp.ParseString(request, "label_key", "")
 	namespace := mcp.ParseString(request, "namespace", "")
+	dryRun := mcp.ParseBoolean(request, "dry_run", false)
 
 	if resourceType == "" || resourceName == "" || labelKey == "" {
 		return mcp.NewToolResultError("resource_type, resource_name, and label_key parameters are required"), nil
@@ -424,7 +511,7 @@ func (k *K8sTool) handleRemoveLabel(ctx context.Context, request mcp.CallToolReq
 		args = append(args, "-n", namespace)
 	}
 
-	return k.runKubectlCommand(ctx, request.Header, args...)
+	return k.runKubectlWrite(ctx, request.Header, dryRun, args...)
 }
 
 // Annotate resource
@@ -433,6 +520,7 @@ func (k *K8sTool) handleAnnotateResource(ctx context.Context, request mcp.CallTo
 	resourceName := mcp.ParseString(request, "resource_name", "")
 	annotations := mcp.ParseString(request, "annotations", "")
 	namespace := mcp.ParseString(request, "namespace", "")
+	dryRun := mcp.ParseBoolean(request, "dry_run", false)
 
 	if resourceType == "" || resourceName == "" || annotations == "" {
 		return mcp.NewToolResultError("resource_type, resource_name, and annotations parameters are required"), nil
@@ -445,7 +533,7 @@ func (k *K8sTool) handleAnnotateResource(ctx context.Context, request mcp.CallTo
 		args = append(args, "-n", namespace)
 	}
 
-	return k.runKubectlCommand(ctx, request.Header, args...)
+	return k.runKubectlWrite(ctx, request.Header, dryRun, args...)
 }
 
 // Label resource
@@ -454,6 +542,7 @@ func (k *K8sTool) handleLabelResource(ctx context.Context, request mcp.CallToolR
 	resourceName := mcp.ParseString(request, "resource_name", "")
 	labels := mcp.ParseString(request, "labels", "")
 	namespace := mcp.ParseString(request, "namespace", "")
+	dryRun := mcp.ParseBoolean(request, "dry_run", false)
 
 	if resourceType == "" || resourceName == "" || labels == "" {
 		return mcp.NewToolResultError("resource_type, resource_name, and labels parameters are required"), nil
@@ -466,13 +555,14 @@ func (k *K8sTool) handleLabelResource(ctx context.Context, request mcp.CallToolR
 		args = append(args, "-n", namespace)
 	}
 
-	return k.runKubectlCommand(ctx, request.Header, args...)
+	return k.runKubectlWrite(ctx, request.Header, dryRun, args...)
 }
 
 // Create resource from URL
 func (k *K8sTool) handleCreateResourceFromURL(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	url := mcp.ParseString(request, "url", "")
 	namespace := mcp.ParseString(request, "namespace", "")
+	dryRun := mcp.ParseBoolean(request, "dry_run", false)
 
 	if url == "" {
 		return mcp.NewToolResultError("url parameter is required"), nil
@@ -483,7 +573,7 @@ func (k *K8sTool) handleCreateResourceFromURL(ctx context.Context, request mcp.C
 		args = append(args, "-n", namespace)
 	}
 
-	return k.runKubectlCommand(ctx, request.Header, args...)
+	return k.runKubectlWrite(ctx, request.Header, dryRun, args...)
 }
 
 // Resource generation embeddings
@@ -580,21 +670,11 @@ func (k *K8sTool) handleGenerateResource(ctx context.Context, request mcp.CallTo
 	return mcp.NewToolResultText(responseText), nil
 }
 
-// extractBearerToken extracts the Bearer token from the Authorization header
-func extractBearerToken(headers http.Header) string {
-	if auth := headers.Get("Authorization"); auth != "" {
-		if strings.HasPrefix(auth, "Bearer ") {
-			return strings.TrimPrefix(auth, "Bearer ")
-		}
-	}
-	return ""
-}
-
 // tokenForKubectl returns the token to pass to kubectl and an error if passthrough is true but token is missing.
 func (k *K8sTool) tokenForKubectl(headers http.Header) (string, error) {
-	token := extractBearerToken(headers)
+	token := credentials.BearerToken(headers)
 	if k.tokenPassthrough && token == "" {
-		return "", fmt.Errorf("Bearer token required when TOKEN_PASSTHROUGH is true")
+		return "", credentials.ErrTokenRequired
 	}
 	if k.tokenPassthrough {
 		return token, nil
@@ -602,6 +682,110 @@ func (k *K8sTool) tokenForKubectl(headers http.Header) (string, error) {
 	return "", nil // do not use token when passthrough is false
 }
 
+// callOptions resolves the identity a backend call runs as: the caller's token with
+// passthrough, or the impersonated user with impersonation
+func (k *K8sTool) callOptions(ctx context.Context, headers http.Header) (CallOptions, error) {
+	token, err := k.tokenForKubectl(headers)
+	if err != nil {
+		return CallOptions{}, err
+	}
+	imp, ok := credentials.ImpersonationFromContext(ctx)
+	if !ok && credentials.ImpersonationEnabled() {
+		return CallOptions{}, credentials.ErrImpersonationRequired
+	}
+	return CallOptions{Token: token, Impersonation: imp}, nil
+}
+
+// changes returns the objects a write changes, or none for a dry run, which persists nothing
+func changes(dryRun bool, tags ...cache.Tag) []cache.Tag {
+	if dryRun {
+		return nil
+	}
+	return tags
+}
+
+// manifestTags describes the objects a manifest creates or changes. A manifest that cannot
+// be decoded is tagged with the whole cluster.
+func manifestTags(manifest string) []cache.Tag {
+	objects, err := decodeManifest(manifest)
+	if err != nil || len(objects) == 0 {
+		return []cache.Tag{{}}
+	}
+	tags := make([]cache.Tag, 0, len(objects))
+	for _, obj := range objects {
+		tags = append(tags, cache.ResourceTag(obj.GetNamespace(), obj.GetKind(), obj.GetName()))
+	}
+	return tags
+}
+
+// runBackend runs an operation on the configured backend, retrying with kubectl when the
+// backend reports ErrUnsupported. Operations that change objects evict the cached results
+// computed from them on success.
+func (k *K8sTool) runBackend(ctx context.Context, headers http.Header, changes []cache.Tag, op func(Backend, CallOptions) (string, error)) (*mcp.CallToolResult, error) {
+	call, err := k.callOptions(ctx, headers)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	backend := k.backend
+	output, err := op(backend, call)
+	if errors.Is(err, ErrUnsupported) && backend.Name() != BackendKubectl {
+		logger.Get().Debug("Backend cannot serve request, falling back to kubectl", "backend", backend.Name())
+		backend = newKubectlBackend(k.kubeconfig)
+		output, err = op(backend, call)
+	}
+	if err != nil {
+		// kubectl errors keep their historical plain-text form
+		var toolErr *toolerrors.ToolError
+		if backend.Name() != BackendKubectl && errors.As(err, &toolErr) {
+			return toolErr.ToMCPResult(), nil
+		}
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if len(changes) > 0 {
+		commands.InvalidateCache(ctx, changes...)
+	}
+	return mcp.NewToolResultText(output), nil
+}
+
+// nativeBackend returns the client-go backend for operations kubectl cannot serve well,
+// such as watches, log follows and diffs. These use client-go whatever --k8s-backend is.
+func (k *K8sTool) nativeBackend() (*clientGoBackend, error) {
+	if b, ok := k.backend.(*clientGoBackend); ok {
+		return b, nil
+	}
+
+	k.nativeMu.Lock()
+	defer k.nativeMu.Unlock()
+	if k.native == nil {
+		b, err := newClientGoBackend(k.kubeconfig)
+		if err != nil {
+			return nil, err
+		}
+		k.native = b
+	}
+	return k.native, nil
+}
+
+// connectNative resolves the client-go clients for a call, honouring token passthrough,
+// impersonation and per-call cluster selection
+func (k *K8sTool) connectNative(ctx context.Context, request mcp.CallToolRequest) (*clientGoTarget, *clientGoClients, *mcp.CallToolResult) {
+	call, err := k.callOptions(ctx, request.Header)
+	if err != nil {
+		return nil, nil, mcp.NewToolResultError(err.Error())
+	}
+	b, err := k.nativeBackend()
+	if err != nil {
+		return nil, nil, mcp.NewToolResultError(fmt.Sprintf("failed to create kubernetes client: %v", err))
+	}
+	target, clients, err := b.connect(ctx, call)
+	if err != nil {
+		return nil, nil, mcp.NewToolResultError(fmt.Sprintf("failed to create kubernetes client: %v", err))
+	}
+	return target, clients, nil
+}
+
 // runKubectlCommand is a helper function to execute kubectl commands
 func (k *K8sTool) runKubectlCommand(ctx context.Context, headers http.Header, args ...string) (*mcp.CallToolResult, error) {
 	token, err := k.tokenForKubectl(headers)
@@ -621,6 +805,36 @@ func (k *K8sTool) runKubectlCommand(ctx context.Context, headers http.Header, ar
 	return mcp.NewToolResultText(output), nil
 }
 
+// runKubectlWrite runs a mutating kubectl command. With dryRun the API server validates and
+// admits the change without persisting it, so the cache is left alone.
+func (k *K8sTool) runKubectlWrite(ctx context.Context, headers http.Header, dryRun bool, args ...string) (*mcp.CallToolResult, error) {
+	return k.runKubectlWriteChanging(ctx, headers, dryRun, commands.CacheTags("kubectl", args), args...)
+}
+
+// runKubectlWriteChanging runs a mutating kubectl command that changes the tagged objects,
+// for commands whose arguments do not tell them, such as those applying a manifest file
+func (k *K8sTool) runKubectlWriteChanging(ctx context.Context, headers http.Header, dryRun bool, changes []cache.Tag, args ...string) (*mcp.CallToolResult, error) {
+	token, err := k.tokenForKubectl(headers)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	builder := commands.NewCommandBuilder("kubectl").
+		WithArgs(args...).
+		WithKubeconfig(k.kubeconfig).
+		WithServerDryRun(dryRun)
+	if token != "" {
+		builder = builder.WithToken(token)
+	}
+	output, err := builder.Execute(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if !dryRun {
+		commands.InvalidateCache(ctx, changes...)
+	}
+	return mcp.NewToolResultText(output), nil
+}
+
 // runKubectlCommandWithTimeout is a helper function to execute kubectl commands with a timeout
 func (k *K8sTool) runKubectlCommandWithTimeout(ctx context.Context, headers http.Header, timeout time.Duration, args ...string) (*mcp.CallToolResult, error) {
 	token, err := k.tokenForKubectl(headers)
@@ -641,9 +855,63 @@ func (k *K8sTool) runKubectlCommandWithTimeout(ctx context.Context, headers http
 	return mcp.NewToolResultText(output), nil
 }
 
+// dryRunDescription documents the dry_run argument shared by the write tools
+const dryRunDescription = "Validate the change with a server-side dry run (admission and defaulting run, nothing is persisted)"
+
+// planDescription documents the dry_run argument of the tools that run commands, which the
+// server cannot dry-run
+const planDescription = "Return the kubectl commands the tool would run without running them"
+
+// dryRunPlan is the result of a dry run of a tool that runs commands: the commands it would run
+func dryRunPlan(commands ...[]string) *mcp.CallToolResult {
+	var b strings.Builder
+	b.WriteString("Dry run, nothing was run. The tool would run:\n")
+	for _, args := range commands {
+		b.WriteString("kubectl " + strings.Join(args, " ") + "\n")
+	}
+	return mcp.NewToolResultText(b.String())
+}
+
 // RegisterK8sTools registers all k8s tools with the MCP server
 func RegisterTools(s *server.MCPServer, llm llms.Model, kubeconfig string, readOnly bool) {
-	k8sTool := NewK8sToolWithConfig(kubeconfig, llm)
+	RegisterToolsWithBackend(s, llm, kubeconfig, BackendKubectl, readOnly)
+}
+
+// RegisterToolsWithBackend registers all k8s tools using the named backend (kubectl or client-go).
+// If the backend cannot be created the tools fall back to kubectl.
+func RegisterToolsWithBackend(s *server.MCPServer, llm llms.Model, kubeconfig string, backendName string, readOnly bool) {
+	RegisterToolsWithOptions(s, llm, kubeconfig, Options{Backend: backendName, ReadOnly: readOnly})
+}
+
+// Options configures the k8s tools
+type Options struct {
+	Backend       string // kubectl or client-go
+	ReadOnly      bool
+	InformerCache InformerOptions
+}
+
+// RegisterToolsWithOptions registers all k8s tools with the given options
+func RegisterToolsWithOptions(s *server.MCPServer, llm llms.Model, kubeconfig string, opts Options) {
+	backendName, readOnly := opts.Backend, opts.ReadOnly
+	backend, err := NewBackend(backendName, kubeconfig)
+	if err != nil {
+		logger.Get().Error("Failed to create k8s backend, falling back to kubectl", "backend", backendName, "error", err)
+		backend = newKubectlBackend(kubeconfig)
+	}
+	logger.Get().Info("Using k8s backend", "backend", backend.Name())
+	k8sTool := NewK8sToolWithBackend(kubeconfig, llm, backend)
+	if opts.InformerCache.Enabled {
+		k8sTool.informers = newInformerCache(opts.InformerCache, func(ctx context.Context) (*clientGoTarget, error) {
+			native, err := k8sTool.nativeBackend()
+			if err != nil {
+				return nil, err
+			}
+			return native.targetFor(ctx)
+		})
+		s.AddTool(mcp.NewTool("k8s_cache_health",
+			mcp.WithDescription("Report the sync state and size of the informers serving k8s_get_resources and k8s_get_events from memory"),
+		), telemetry.AdaptToolHandler(telemetry.WithTracing("k8s_cache_health", k8sTool.handleCacheHealth)))
+	}
 
 	// Read-only tools - always registered
 	s.AddTool(mcp.NewTool("k8s_get_resources",
@@ -653,6 +921,9 @@ func RegisterTools(s *server.MCPServer, llm llms.Model, kubeconfig string, readO
 		mcp.WithString("namespace", mcp.Description("Namespace to query (optional)")),
 		mcp.WithString("all_namespaces", mcp.Description("Query all namespaces (true/false)")),
 		mcp.WithString("output", mcp.Description("Output format (json, yaml, wide)"), mcp.DefaultString("wide")),
+		mcp.WithNumber("limit", mcp.Description("List at most this many objects and return a continue token for the rest (optional)")),
+		mcp.WithString("continue", mcp.Description("Continue token from a previous page, to list the next page with the same arguments (optional)")),
+		mcp.WithOutputSchema[ResourceList](),
 	), telemetry.AdaptToolHandler(telemetry.WithTracing("k8s_get_resources", k8sTool.handleKubectlGetEnhanced)))
 
 	s.AddTool(mcp.NewTool("k8s_get_pod_logs",
@@ -663,6 +934,40 @@ func RegisterTools(s *server.MCPServer, llm llms.Model, kubeconfig string, readO
 		mcp.WithNumber("tail_lines", mcp.Description("Number of lines to show from the end (default: 50)")),
 	), telemetry.AdaptToolHandler(telemetry.WithTracing("k8s_get_pod_logs", k8sTool.handleKubectlLogsEnhanced)))
 
+	s.AddTool(mcp.NewTool("k8s_follow_pod_logs",
+		mcp.WithDescription("Follow logs from a pod, streaming each line as a progress notification until the duration, line limit or until_pattern is reached"),
+		mcp.WithString("pod_name", mcp.Description("Name of the pod"), mcp.Required()),
+		mcp.WithString("namespace", mcp.Description("Namespace of the pod (default: default)")),
+		mcp.WithString("container", mcp.Description("Container name (for multi-container pods)")),
+		mcp.WithNumber("tail_lines", mcp.Description("Number of existing lines to start from (default: 0, only new lines)")),
+		mcp.WithString("until_pattern", mcp.Description("Stop when a line matches this regular expression")),
+		mcp.WithNumber("max_lines", mcp.Description("Stop after this many lines (default: 1000, max: 10000)")),
+		mcp.WithNumber("duration_seconds", mcp.Description("Stop after this many seconds (default: 60, max: 600)")),
+	), telemetry.AdaptToolHandler(telemetry.WithTracing("k8s_follow_pod_logs", k8sTool.handleFollowPodLogs)))
+
+	s.AddTool(mcp.NewTool("k8s_watch_resources",
+		mcp.WithDescription("Watch a Kubernetes resource type, streaming each change as a progress notification until the duration, event limit or until condition is reached. Use it to wait for a rollout or a pod to become ready instead of polling."),
+		mcp.WithString("resource_type", mcp.Description("Type of resource (deployment, pod, ...)"), mcp.Required()),
+		mcp.WithString("resource_name", mcp.Description("Only watch the resource with this name")),
+		mcp.WithString("namespace", mcp.Description("Namespace to watch (default: the current namespace)")),
+		mcp.WithBoolean("all_namespaces", mcp.Description("Watch across all namespaces")),
+		mcp.WithString("label_selector", mcp.Description("Label selector to filter resources (e.g. app=web)")),
+		mcp.WithString("until", mcp.Description("Stop condition in kubectl wait --for syntax: delete, create, condition=Available, condition=Ready=False or jsonpath={.status.phase}=Running")),
+		mcp.WithNumber("max_events", mcp.Description("Stop after this many changes (default: 100, max: 1000)")),
+		mcp.WithNumber("duration_seconds", mcp.Description("Stop after this many seconds (default: 60, max: 600)")),
+	), telemetry.AdaptToolHandler(telemetry.WithTracing("k8s_watch_resources", k8sTool.handleWatchResources)))
+
+	s.AddTool(mcp.NewTool("k8s_watch_events",
+		mcp.WithDescription("Watch Kubernetes events, streaming each one as a progress notification until the duration, event limit or until_reason is reached"),
+		mcp.WithString("namespace", mcp.Description("Namespace to watch (default: all namespaces)")),
+		mcp.WithString("involved_object_kind", mcp.Description("Only events for objects of this kind (e.g. Pod)")),
+		mcp.WithString("involved_object_name", mcp.Description("Only events for the object with this name")),
+		mcp.WithString("event_type", mcp.Description("Only events of this type (Normal or Warning)")),
+		mcp.WithString("until_reason", mcp.Description("Stop when an event with this reason arrives (e.g. BackOff, Killing)")),
+		mcp.WithNumber("max_events", mcp.Description("Stop after this many events (default: 100, max: 1000)")),
+		mcp.WithNumber("duration_seconds", mcp.Description("Stop after this many seconds (default: 60, max: 600)")),
+	), telemetry.AdaptToolHandler(telemetry.WithTracing("k8s_watch_events", k8sTool.handleWatchEvents)))
+
 	s.AddTool(mcp.NewTool("k8s_get_events",
 		mcp.WithDescription("Get events from a Kubernetes namespace"),
 		mcp.WithString("namespace", mcp.Description("Namespace to get events from (default: default)")),
@@ -676,6 +981,10 @@ func RegisterTools(s *server.MCPServer, llm llms.Model, kubeconfig string, readO
 		mcp.WithDescription("Get cluster configuration details"),
 	), telemetry.AdaptToolHandler(telemetry.WithTracing("k8s_get_cluster_configuration", k8sTool.handleGetClusterConfiguration)))
 
+	s.AddTool(mcp.NewTool("k8s_list_clusters",
+		mcp.WithDescription("List the clusters that can be selected with the cluster argument, with their reachability and server version"),
+	), telemetry.AdaptToolHandler(telemetry.WithTracing("k8s_list_clusters", k8sTool.handleListClusters)))
+
 	s.AddTool(mcp.NewTool("k8s_get_resource_yaml",
 		mcp.WithDescription("Get the YAML representation of a Kubernetes resource"),
 		mcp.WithString("resource_type", mcp.Description("Type of resource"), mcp.Required()),
@@ -690,12 +999,10 @@ func RegisterTools(s *server.MCPServer, llm llms.Model, kubeconfig string, readO
 			return mcp.NewToolResultError("resource_type and resource_name are required"), nil
 		}
 
-		args := []string{"get", resourceType, resourceName, "-o", "yaml"}
-		if namespace != "" {
-			args = append(args, "-n", namespace)
-		}
-
-		result, err := k8sTool.runKubectlCommand(ctx, request.Header, args...)
+		opts := GetOptions{ResourceType: resourceType, Name: resourceName, Namespace: namespace, Output: "yaml"}
+		result, err := k8sTool.runBackend(ctx, request.Header, nil, func(b Backend, call CallOptions) (string, error) {
+			return b.Get(ctx, call, opts)
+		})
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Get YAML command failed: %v", err)), nil
 		}
@@ -703,6 +1010,12 @@ func RegisterTools(s *server.MCPServer, llm llms.Model, kubeconfig string, readO
 		return result, nil
 	})))
 
+	s.AddTool(mcp.NewTool("k8s_diff_manifest",
+		mcp.WithDescription("Show a unified diff between the live objects and what applying a manifest would produce, using a server-side dry run. Nothing is changed in the cluster."),
+		mcp.WithString("manifest", mcp.Description("YAML manifest to compare, may contain several documents"), mcp.Required()),
+		mcp.WithString("namespace", mcp.Description("Namespace for objects that do not set one (default: the current namespace)")),
+	), telemetry.AdaptToolHandler(telemetry.WithTracing("k8s_diff_manifest", k8sTool.handleDiffManifest)))
+
 	s.AddTool(mcp.NewTool("k8s_describe_resource",
 		mcp.WithDescription("Describe a Kubernetes resource in detail"),
 		mcp.WithString("resource_type", mcp.Description("Type of resource (deployment, service, pod, node, etc.)"), mcp.Required()),
@@ -723,6 +1036,7 @@ func RegisterTools(s *server.MCPServer, llm llms.Model, kubeconfig string, readO
 			mcp.WithString("name", mcp.Description("Name of the deployment"), mcp.Required()),
 			mcp.WithString("namespace", mcp.Description("Namespace of the deployment (default: default)")),
 			mcp.WithNumber("replicas", mcp.Description("Number of replicas"), mcp.Required()),
+			mcp.WithBoolean("dry_run", mcp.Description(dryRunDescription)),
 		), telemetry.AdaptToolHandler(telemetry.WithTracing("k8s_scale", k8sTool.handleScaleDeployment)))
 
 		s.AddTool(mcp.NewTool("k8s_patch_resource",
@@ -732,6 +1046,7 @@ func RegisterTools(s *server.MCPServer, llm llms.Model, kubeconfig string, readO
 			mcp.WithString("patch", mcp.Description("JSON patch to apply"), mcp.Required()),
 			mcp.WithString("patch_type", mcp.Description("Patch strategy: \"strategic\" (default; built-in Kubernetes types only), \"merge\" (RFC 7386 JSON merge patch; required for CustomResources/CRDs), or \"json\" (RFC 6902 JSON patch).")),
 			mcp.WithString("namespace", mcp.Description("Namespace of the resource (default: default)")),
+			mcp.WithBoolean("dry_run", mcp.Description(dryRunDescription)),
 		), telemetry.AdaptToolHandler(telemetry.WithTracing("k8s_patch_resource", k8sTool.handlePatchResource)))
 
 		s.AddTool(mcp.NewTool("k8s_patch_status",
@@ -740,11 +1055,13 @@ func RegisterTools(s *server.MCPServer, llm llms.Model, kubeconfig string, readO
 			mcp.WithString("resource_name", mcp.Description("Name of the resource"), mcp.Required()),
 			mcp.WithString("patch", mcp.Description("JSON/YAML status patch"), mcp.Required()),
 			mcp.WithString("namespace", mcp.Description("Namespace of the resource (default: default)")),
+			mcp.WithBoolean("dry_run", mcp.Description(dryRunDescription)),
 		), telemetry.AdaptToolHandler(telemetry.WithTracing("k8s_patch_status", k8sTool.handlePatchStatus)))
 
 		s.AddTool(mcp.NewTool("k8s_apply_manifest",
 			mcp.WithDescription("Apply a YAML manifest to the Kubernetes cluster"),
 			mcp.WithString("manifest", mcp.Description("YAML manifest content"), mcp.Required()),
+			mcp.WithBoolean("dry_run", mcp.Description(dryRunDescription)),
 		), telemetry.AdaptToolHandler(telemetry.WithTracing("k8s_apply_manifest", k8sTool.handleApplyManifest)))
 
 		s.AddTool(mcp.NewTool("k8s_delete_resource",
@@ -752,12 +1069,14 @@ func RegisterTools(s *server.MCPServer, llm llms.Model, kubeconfig string, readO
 			mcp.WithString("resource_type", mcp.Description("Type of resource (pod, service, deployment, etc.)"), mcp.Required()),
 			mcp.WithString("resource_name", mcp.Description("Name of the resource"), mcp.Required()),
 			mcp.WithString("namespace", mcp.Description("Namespace of the resource (default: default)")),
+			mcp.WithBoolean("dry_run", mcp.Description(dryRunDescription)),
 		), telemetry.AdaptToolHandler(telemetry.WithTracing("k8s_delete_resource", k8sTool.handleDeleteResource)))
 
 		s.AddTool(mcp.NewTool("k8s_check_service_connectivity",
 			mcp.WithDescription("Check connectivity to a service using a temporary curl pod"),
 			mcp.WithString("service_name", mcp.Description("Service name to test (e.g., my-service.my-namespace.svc.cluster.local:80)"), mcp.Required()),
 			mcp.WithString("namespace", mcp.Description("Namespace to run the check from (default: default)")),
+			mcp.WithBoolean("dry_run", mcp.Description(planDescription)),
 		), telemetry.AdaptToolHandler(telemetry.WithTracing("k8s_check_service_connectivity", k8sTool.handleCheckServiceConnectivity)))
 
 		s.AddTool(mcp.NewTool("k8s_execute_command",
@@ -766,6 +1085,7 @@ func RegisterTools(s *server.MCPServer, llm llms.Model, kubeconfig string, readO
 			mcp.WithString("namespace", mcp.Description("Namespace of the pod (default: default)")),
 			mcp.WithString("container", mcp.Description("Container name (for multi-container pods)")),
 			mcp.WithString("command", mcp.Description("Command to execute"), mcp.Required()),
+			mcp.WithBoolean("dry_run", mcp.Description(planDescription)),
 		), telemetry.AdaptToolHandler(telemetry.WithTracing("k8s_execute_command", k8sTool.handleExecCommand)))
 
 		s.AddTool(mcp.NewTool("k8s_rollout",
@@ -774,6 +1094,7 @@ func RegisterTools(s *server.MCPServer, llm llms.Model, kubeconfig string, readO
 			mcp.WithString("resource_type", mcp.Description("The type of resource to rollout (e.g., deployment)"), mcp.Required()),
 			mcp.WithString("resource_name", mcp.Description("The name of the resource to rollout"), mcp.Required()),
 			mcp.WithString("namespace", mcp.Description("The namespace of the resource")),
+			mcp.WithBoolean("dry_run", mcp.Description(dryRunDescription)),
 		), telemetry.AdaptToolHandler(telemetry.WithTracing("k8s_rollout", k8sTool.handleRollout)))
 
 		s.AddTool(mcp.NewTool("k8s_label_resource",
@@ -782,6 +1103,7 @@ func RegisterTools(s *server.MCPServer, llm llms.Model, kubeconfig string, readO
 			mcp.WithString("resource_name", mcp.Description("The name of the resource"), mcp.Required()),
 			mcp.WithString("labels", mcp.Description("Space-separated key=value pairs for labels"), mcp.Required()),
 			mcp.WithString("namespace", mcp.Description("The namespace of the resource")),
+			mcp.WithBoolean("dry_run", mcp.Description(dryRunDescription)),
 		), telemetry.AdaptToolHandler(telemetry.WithTracing("k8s_label_resource", k8sTool.handleLabelResource)))
 
 		s.AddTool(mcp.NewTool("k8s_annotate_resource",
@@ -790,6 +1112,7 @@ func RegisterTools(s *server.MCPServer, llm llms.Model, kubeconfig string, readO
 			mcp.WithString("resource_name", mcp.Description("The name of the resource"), mcp.Required()),
 			mcp.WithString("annotations", mcp.Description("Space-separated key=value pairs for annotations"), mcp.Required()),
 			mcp.WithString("namespace", mcp.Description("The namespace of the resource")),
+			mcp.WithBoolean("dry_run", mcp.Description(dryRunDescription)),
 		), telemetry.AdaptToolHandler(telemetry.WithTracing("k8s_annotate_resource", k8sTool.handleAnnotateResource)))
 
 		s.AddTool(mcp.NewTool("k8s_remove_annotation",
@@ -798,6 +1121,7 @@ func RegisterTools(s *server.MCPServer, llm llms.Model, kubeconfig string, readO
 			mcp.WithString("resource_name", mcp.Description("The name of the resource"), mcp.Required()),
 			mcp.WithString("annotation_key", mcp.Description("The key of the annotation to remove"), mcp.Required()),
 			mcp.WithString("namespace", mcp.Description("The namespace of the resource")),
+			mcp.WithBoolean("dry_run", mcp.Description(dryRunDescription)),
 		), telemetry.AdaptToolHandler(telemetry.WithTracing("k8s_remove_annotation", k8sTool.handleRemoveAnnotation)))
 
 		s.AddTool(mcp.NewTool("k8s_remove_label",
@@ -806,13 +1130,16 @@ func RegisterTools(s *server.MCPServer, llm llms.Model, kubeconfig string, readO
 			mcp.WithString("resource_name", mcp.Description("The name of the resource"), mcp.Required()),
 			mcp.WithString("label_key", mcp.Description("The key of the label to remove"), mcp.Required()),
 			mcp.WithString("namespace", mcp.Description("The namespace of the resource")),
+			mcp.WithBoolean("dry_run", mcp.Description(dryRunDescription)),
 		), telemetry.AdaptToolHandler(telemetry.WithTracing("k8s_remove_label", k8sTool.handleRemoveLabel)))
 
 		s.AddTool(mcp.NewTool("k8s_create_resource",
 			mcp.WithDescription("Create a Kubernetes resource from YAML content"),
 			mcp.WithString("yaml_content", mcp.Description("YAML content of the resource"), mcp.Required()),
+			mcp.WithBoolean("dry_run", mcp.Description(dryRunDescription)),
 		), telemetry.AdaptToolHandler(telemetry.WithTracing("k8s_create_resource", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			yamlContent := mcp.ParseString(request, "yaml_content", "")
+			dryRun := mcp.ParseBoolean(request, "dry_run", false)
 
 			if yamlContent == "" {
 				return mcp.NewToolResultError("yaml_content is required"), nil
@@ -830,7 +1157,7 @@ func RegisterTools(s *server.MCPServer, llm llms.Model, kubeconfig string, readO
 			}
 			tmpFile.Close()
 
-			result, err := k8sTool.runKubectlCommand(ctx, request.Header, "create", "-f", tmpFile.Name())
+			result, err := k8sTool.runKubectlWriteChanging(ctx, request.Header, dryRun, manifestTags(yamlContent), "create", "-f", tmpFile.Name())
 			if err != nil {
 				return mcp.NewToolResultError(fmt.Sprintf("Create command failed: %v", err)), nil
 			}
@@ -842,6 +1169,7 @@ func RegisterTools(s *server.MCPServer, llm llms.Model, kubeconfig string, readO
 			mcp.WithDescription("Create a Kubernetes resource from a URL pointing to a YAML manifest"),
 			mcp.WithString("url", mcp.Description("The URL of the manifest"), mcp.Required()),
 			mcp.WithString("namespace", mcp.Description("The namespace to create the resource in")),
+			mcp.WithBoolean("dry_run", mcp.Description(dryRunDescription)),
 		), telemetry.AdaptToolHandler(telemetry.WithTracing("k8s_create_resource_from_url", k8sTool.handleCreateResourceFromURL)))
 	}
 }
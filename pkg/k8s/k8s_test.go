@@ -2,10 +2,19 @@ package k8s
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/kagent-dev/tools/internal/cache"
+	"github.com/kagent-dev/tools/internal/clusters"
 	"github.com/kagent-dev/tools/internal/cmd"
+	"github.com/kagent-dev/tools/internal/credentials"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/stretchr/testify/assert"
@@ -138,6 +147,27 @@ func TestHandleScaleDeployment(t *testing.T) {
 		assert.Contains(t, resultText, "scaled")
 	})
 
+	t.Run("server-side dry run", func(t *testing.T) {
+		mock := cmd.NewMockShellExecutor()
+		expectedOutput := `deployment.apps/test-deployment scaled (server dry run)`
+		mock.AddCommandString("kubectl", []string{"scale", "deployment", "test-deployment", "--replicas", "5", "-n", "default", "--dry-run=server"}, expectedOutput, nil)
+		ctx := cmd.WithShellExecutor(ctx, mock)
+
+		k8sTool := newTestK8sTool()
+
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]interface{}{
+			"name":     "test-deployment",
+			"replicas": float64(5),
+			"dry_run":  true,
+		}
+
+		result, err := k8sTool.handleScaleDeployment(ctx, req)
+		assert.NoError(t, err)
+		assert.False(t, result.IsError)
+		assert.Contains(t, getResultText(result), "server dry run")
+	})
+
 	t.Run("missing name parameter", func(t *testing.T) {
 		mock := cmd.NewMockShellExecutor()
 		ctx := cmd.WithShellExecutor(context.Background(), mock)
@@ -474,6 +504,29 @@ func TestHandleCheckServiceConnectivity(t *testing.T) {
 		assert.NotNil(t, result)
 		// Should attempt connectivity check (may succeed or fail but validates params)
 	})
+
+	t.Run("dry run", func(t *testing.T) {
+		mock := cmd.NewMockShellExecutor()
+		ctx := cmd.WithShellExecutor(ctx, mock)
+
+		k8sTool := newTestK8sTool()
+
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]interface{}{
+			"service_name": "test-service.default.svc.cluster.local:80",
+			"dry_run":      true,
+		}
+
+		result, err := k8sTool.handleCheckServiceConnectivity(ctx, req)
+		assert.NoError(t, err)
+		assert.False(t, result.IsError)
+		content := getResultText(result)
+		assert.Contains(t, content, "--image=curlimages/curl -n default")
+		assert.Contains(t, content, "-- curl -s test-service.default.svc.cluster.local:80")
+
+		// Verify no pod was created
+		assert.Empty(t, mock.GetCallLog())
+	})
 }
 
 func TestHandleKubectlDescribeTool(t *testing.T) {
@@ -561,6 +614,93 @@ func TestHandleKubectlGetEnhanced(t *testing.T) {
 		assert.NotNil(t, result)
 		assert.False(t, result.IsError)
 	})
+
+	t.Run("structured table rows", func(t *testing.T) {
+		mock := cmd.NewMockShellExecutor()
+		output := "NAME    READY   UP-TO-DATE   AVAILABLE   AGE   CONTAINERS   IMAGES         SELECTOR\n" +
+			"nginx   2/3     3            2           5d    nginx        nginx:1.25.0   app=nginx\n"
+		mock.AddCommandString("kubectl", []string{"get", "deployments", "-n", "web", "-o", "wide"}, output, nil)
+		ctx := cmd.WithShellExecutor(ctx, mock)
+
+		k8sTool := newTestK8sTool()
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]interface{}{"resource_type": "deployments", "namespace": "web"}
+		result, err := k8sTool.handleKubectlGetEnhanced(ctx, req)
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+
+		assert.Equal(t, output, getResultText(result), "text rendering is kept")
+		list, ok := result.StructuredContent.(ResourceList)
+		require.True(t, ok)
+		assert.Equal(t, "deployments", list.ResourceType)
+		require.Len(t, list.Rows, 1)
+		assert.Equal(t, "2/3", list.Rows[0]["ready"])
+		assert.Equal(t, "3", list.Rows[0]["up_to_date"])
+		assert.Equal(t, "nginx:1.25.0", list.Rows[0]["images"])
+	})
+
+	t.Run("structured json items", func(t *testing.T) {
+		mock := cmd.NewMockShellExecutor()
+		output := `{"apiVersion":"v1","kind":"List","items":[{"kind":"Pod","metadata":{"name":"nginx"}},{"kind":"Pod","metadata":{"name":"redis"}}]}`
+		mock.AddCommandString("kubectl", []string{"get", "pods", "-o", "json"}, output, nil)
+		ctx := cmd.WithShellExecutor(ctx, mock)
+
+		k8sTool := newTestK8sTool()
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]interface{}{"resource_type": "pods", "output": "json"}
+		result, err := k8sTool.handleKubectlGetEnhanced(ctx, req)
+		require.NoError(t, err)
+
+		list, ok := result.StructuredContent.(ResourceList)
+		require.True(t, ok)
+		require.Len(t, list.Items, 2)
+		assert.Equal(t, "redis", list.Items[1]["metadata"].(map[string]any)["name"])
+	})
+
+	t.Run("errors carry no structured content", func(t *testing.T) {
+		mock := cmd.NewMockShellExecutor()
+		mock.AddCommandString("kubectl", []string{"get", "pods", "-o", "wide"}, "", fmt.Errorf("connection refused"))
+		ctx := cmd.WithShellExecutor(ctx, mock)
+
+		k8sTool := newTestK8sTool()
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]interface{}{"resource_type": "pods"}
+		result, err := k8sTool.handleKubectlGetEnhanced(ctx, req)
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Nil(t, result.StructuredContent)
+	})
+}
+
+func TestResourceList(t *testing.T) {
+	t.Run("single object", func(t *testing.T) {
+		list := resourceList("pod", "json", `{"kind":"Pod","metadata":{"name":"nginx"}}`)
+		require.Len(t, list.Items, 1)
+		assert.Equal(t, "Pod", list.Items[0]["kind"])
+	})
+
+	t.Run("yaml list", func(t *testing.T) {
+		list := resourceList("pods", "yaml", "apiVersion: v1\nkind: List\nitems:\n- kind: Pod\n  metadata:\n    name: nginx\n")
+		require.Len(t, list.Items, 1)
+		assert.Equal(t, "Pod", list.Items[0]["kind"])
+	})
+
+	t.Run("unparsed output", func(t *testing.T) {
+		list := resourceList("pods", "name", "pod/nginx\npod/redis\n")
+		assert.Equal(t, "name", list.Output)
+		assert.Empty(t, list.Rows)
+		assert.Empty(t, list.Items)
+	})
+}
+
+func TestK8sGetResourcesOutputSchema(t *testing.T) {
+	s := server.NewMCPServer("test", "1.0.0")
+	RegisterTools(s, nil, "", true)
+
+	tool := s.ListTools()["k8s_get_resources"].Tool
+	assert.Equal(t, "object", tool.OutputSchema.Type)
+	assert.Contains(t, tool.OutputSchema.Properties, "rows")
+	assert.Contains(t, tool.OutputSchema.Properties, "items")
 }
 
 func TestHandleKubectlLogsEnhanced(t *testing.T) {
@@ -644,6 +784,26 @@ spec:
 		assert.Contains(t, callLog[0].Args[2], "manifest-")
 	})
 
+	t.Run("apply manifest dry run", func(t *testing.T) {
+		mock := cmd.NewMockShellExecutor()
+		mock.AddPartialMatcherString("kubectl", []string{"apply", "-f"}, "pod/test-pod created (server dry run)", nil)
+		ctx := cmd.WithShellExecutor(ctx, mock)
+
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]interface{}{
+			"manifest": "apiVersion: v1\nkind: Pod\nmetadata:\n  name: test-pod\n",
+			"dry_run":  true,
+		}
+
+		result, err := newTestK8sTool().handleApplyManifest(ctx, req)
+		assert.NoError(t, err)
+		assert.False(t, result.IsError)
+
+		callLog := mock.GetCallLog()
+		require.Len(t, callLog, 1)
+		assert.Equal(t, "--dry-run=server", callLog[0].Args[len(callLog[0].Args)-1])
+	})
+
 	t.Run("missing manifest parameter", func(t *testing.T) {
 		mock := cmd.NewMockShellExecutor()
 		ctx := cmd.WithShellExecutor(ctx, mock)
@@ -726,6 +886,26 @@ drwxr-xr-x 1 root root 4096 Jan  1 12:00 ..`
 		callLog := mock.GetCallLog()
 		assert.Len(t, callLog, 0)
 	})
+
+	t.Run("dry run", func(t *testing.T) {
+		mock := cmd.NewMockShellExecutor()
+		ctx := cmd.WithShellExecutor(context.Background(), mock)
+
+		k8sTool := newTestK8sTool()
+
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]interface{}{
+			"pod_name": "mypod",
+			"command":  "ls -la",
+			"dry_run":  true,
+		}
+
+		result, err := k8sTool.handleExecCommand(ctx, req)
+		assert.NoError(t, err)
+		assert.False(t, result.IsError)
+		assert.Contains(t, getResultText(result), "kubectl exec mypod -n default -- ls -la")
+		assert.Empty(t, mock.GetCallLog())
+	})
 }
 
 func TestHandleRollout(t *testing.T) {
@@ -763,6 +943,45 @@ func TestHandleRollout(t *testing.T) {
 		assert.Equal(t, []string{"rollout", "restart", "deployment/myapp", "-n", "default"}, callLog[0].Args)
 	})
 
+	t.Run("rollout restart dry run", func(t *testing.T) {
+		mock := cmd.NewMockShellExecutor()
+		mock.AddCommandString("kubectl", []string{"rollout", "restart", "deployment/myapp", "-n", "default", "--dry-run=server"}, "deployment.apps/myapp restarted (server dry run)", nil)
+		ctx := cmd.WithShellExecutor(ctx, mock)
+
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]interface{}{
+			"action":        "restart",
+			"resource_type": "deployment",
+			"resource_name": "myapp",
+			"namespace":     "default",
+			"dry_run":       true,
+		}
+
+		result, err := newTestK8sTool().handleRollout(ctx, req)
+		assert.NoError(t, err)
+		assert.False(t, result.IsError)
+		assert.Contains(t, getResultText(result), "server dry run")
+	})
+
+	t.Run("dry run rejected for pause", func(t *testing.T) {
+		mock := cmd.NewMockShellExecutor()
+		ctx := cmd.WithShellExecutor(ctx, mock)
+
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]interface{}{
+			"action":        "pause",
+			"resource_type": "deployment",
+			"resource_name": "myapp",
+			"dry_run":       true,
+		}
+
+		result, err := newTestK8sTool().handleRollout(ctx, req)
+		assert.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, getResultText(result), "dry_run is not supported")
+		assert.Len(t, mock.GetCallLog(), 0)
+	})
+
 	t.Run("missing required parameters", func(t *testing.T) {
 		mock := cmd.NewMockShellExecutor()
 		ctx := cmd.WithShellExecutor(context.Background(), mock)
@@ -989,6 +1208,26 @@ func TestHandleLabelResource(t *testing.T) {
 		assert.Contains(t, resultText, "labeled")
 	})
 
+	t.Run("label dry run", func(t *testing.T) {
+		mock := cmd.NewMockShellExecutor()
+		mock.AddCommandString("kubectl", []string{"label", "deployment", "web", "env=prod", "-n", "default", "--dry-run=server"}, "deployment.apps/web labeled (server dry run)", nil)
+		ctx := cmd.WithShellExecutor(ctx, mock)
+
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]interface{}{
+			"resource_type": "deployment",
+			"resource_name": "web",
+			"labels":        "env=prod",
+			"namespace":     "default",
+			"dry_run":       true,
+		}
+
+		result, err := newTestK8sTool().handleLabelResource(ctx, req)
+		assert.NoError(t, err)
+		assert.False(t, result.IsError, getResultText(result))
+		assert.Contains(t, getResultText(result), "server dry run")
+	})
+
 	t.Run("missing parameters", func(t *testing.T) {
 		mock := cmd.NewMockShellExecutor()
 		ctx := cmd.WithShellExecutor(context.Background(), mock)
@@ -1203,6 +1442,51 @@ users:
 	})
 }
 
+func TestHandleListClusters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"major":"1","minor":"35","gitVersion":"v1.35.1"}`))
+	}))
+	defer server.Close()
+
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(kubeconfig, []byte(`apiVersion: v1
+kind: Config
+current-context: prod
+clusters:
+- name: prod
+  cluster:
+    server: `+server.URL+`
+contexts:
+- name: prod
+  context:
+    cluster: prod
+    user: prod
+users:
+- name: prod
+  user:
+    token: test
+`), 0600))
+
+	registry, err := clusters.Load(kubeconfig)
+	require.NoError(t, err)
+	original := clusters.GetRegistry()
+	clusters.SetRegistry(registry)
+	defer clusters.SetRegistry(original)
+
+	result, err := newTestK8sTool().handleListClusters(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var statuses []clusters.Status
+	require.NoError(t, json.Unmarshal([]byte(getResultText(result)), &statuses))
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "prod", statuses[0].Name)
+	assert.True(t, statuses[0].Default)
+	assert.True(t, statuses[0].Reachable)
+	assert.Equal(t, "v1.35.1", statuses[0].Version)
+}
+
 // Tests for Bearer token passing to kubectl commands
 func TestBearerTokenPassthrough(t *testing.T) {
 	ctx := context.Background()
@@ -1651,3 +1935,70 @@ metadata:
 		assert.NotContains(t, callLog[0].Args, "--token")
 	})
 }
+
+func TestWritesInvalidateAffectedCacheEntries(t *testing.T) {
+	k8sCache := cache.GetCacheByType(cache.CacheTypeKubernetes)
+	k8sCache.Clear()
+	defer k8sCache.Clear()
+	k8sCache.SetWithTags("web-pods", "v", time.Minute, []cache.Tag{cache.ResourceTag("web", "pods", "")})
+	k8sCache.SetWithTags("db-pods", "v", time.Minute, []cache.Tag{cache.ResourceTag("db", "pods", "")})
+	k8sCache.SetWithTags("web-config", "v", time.Minute, []cache.Tag{cache.ResourceTag("web", "configmap", "settings")})
+
+	mock := cmd.NewMockShellExecutor()
+	mock.AddCommandString("kubectl", []string{"delete", "deployment", "api", "-n", "web"}, "deployment.apps/api deleted", nil)
+	mock.AddCommandString("kubectl", []string{"delete", "deployment", "api", "-n", "web", "--dry-run=server"}, "deployment.apps/api deleted (server dry run)", nil)
+	ctx := cmd.WithShellExecutor(context.Background(), mock)
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"resource_type": "deployment", "resource_name": "api", "namespace": "web", "dry_run": true}
+	result, err := newTestK8sTool().handleDeleteResource(ctx, req)
+	require.NoError(t, err)
+	require.False(t, result.IsError, getResultText(result))
+	assert.Equal(t, 3, k8sCache.Size(), "a dry run changes nothing")
+
+	req.Params.Arguments = map[string]interface{}{"resource_type": "deployment", "resource_name": "api", "namespace": "web"}
+	result, err = newTestK8sTool().handleDeleteResource(ctx, req)
+	require.NoError(t, err)
+	require.False(t, result.IsError, getResultText(result))
+
+	_, found := k8sCache.Get("web-pods")
+	assert.False(t, found, "deleting a deployment deletes its pods")
+	_, found = k8sCache.Get("db-pods")
+	assert.True(t, found)
+	_, found = k8sCache.Get("web-config")
+	assert.True(t, found)
+}
+
+func TestManifestTags(t *testing.T) {
+	assert.Equal(t, []cache.Tag{
+		{Namespace: "web", Kind: "configmap", Name: "settings"},
+		{Kind: "namespace", Name: "web"},
+	}, manifestTags("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\n  namespace: web\n---\napiVersion: v1\nkind: Namespace\nmetadata:\n  name: web\n"))
+	assert.Equal(t, []cache.Tag{{}}, manifestTags("not: [valid"))
+}
+
+// Tests for impersonating the caller on kubectl commands
+func TestImpersonation(t *testing.T) {
+	credentials.EnableImpersonation(credentials.ImpersonationHeaders{User: "X-Remote-User", Group: "X-Remote-Group"})
+	defer credentials.DisableImpersonation()
+
+	mock := cmd.NewMockShellExecutor()
+	mock.AddCommandString("kubectl", []string{"delete", "deployment", "test-deployment", "-n", "default", "--as=alice", "--as-group=sre"}, "deployment.apps/test-deployment deleted", nil)
+	ctx := cmd.WithShellExecutor(context.Background(), mock)
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{
+		"resource_type": "deployment",
+		"resource_name": "test-deployment",
+	}
+
+	k8sTool := newTestK8sTool()
+	result, err := k8sTool.handleDeleteResource(credentials.WithImpersonation(ctx, credentials.Impersonation{User: "alice", Groups: []string{"sre"}}), req)
+	require.NoError(t, err)
+	assert.False(t, result.IsError, getResultText(result))
+
+	result, err = k8sTool.handleDeleteResource(ctx, req)
+	require.NoError(t, err)
+	assert.True(t, result.IsError, "calls without an impersonated user must not run as the server")
+	assert.Len(t, mock.GetCallLog(), 1)
+}
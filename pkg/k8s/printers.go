@@ -0,0 +1,381 @@
+package k8s
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// widePrinter renders one object as the cells of a wide table row
+type widePrinter struct {
+	columns []string
+	row     func(obj *unstructured.Unstructured) ([]interface{}, error)
+}
+
+// widePrinters render the kinds served from informers with the columns the API server uses
+// for kubectl's wide output, so results do not depend on where they were read from
+var widePrinters = map[schema.GroupResource]widePrinter{
+	{Resource: "pods"}: {
+		columns: []string{"Name", "Ready", "Status", "Restarts", "Age", "IP", "Node", "Nominated Node", "Readiness Gates"},
+		row:     typedRow(podRow),
+	},
+	{Group: "apps", Resource: "deployments"}: {
+		columns: []string{"Name", "Ready", "Up-to-date", "Available", "Age", "Containers", "Images", "Selector"},
+		row:     typedRow(deploymentRow),
+	},
+	{Resource: "services"}: {
+		columns: []string{"Name", "Type", "Cluster-IP", "External-IP", "Port(s)", "Age", "Selector"},
+		row:     typedRow(serviceRow),
+	},
+	{Resource: "nodes"}: {
+		columns: []string{"Name", "Status", "Roles", "Age", "Version", "Internal-IP", "External-IP", "OS-Image", "Kernel-Version", "Container-Runtime"},
+		row:     typedRow(nodeRow),
+	},
+	{Resource: "events"}: {
+		columns: []string{"Last Seen", "Type", "Reason", "Object", "Subobject", "Source", "Message", "First Seen", "Count", "Name"},
+		row:     typedRow(eventRow),
+	},
+}
+
+// typedRow adapts a printer of a typed object to unstructured input
+func typedRow[T any](row func(*T) []interface{}) func(*unstructured.Unstructured) ([]interface{}, error) {
+	return func(obj *unstructured.Unstructured) ([]interface{}, error) {
+		typed := new(T)
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, typed); err != nil {
+			return nil, err
+		}
+		return row(typed), nil
+	}
+}
+
+// wideTable renders objects as the Table the API server would return for them. It returns
+// false for kinds without a printer.
+func wideTable(resource schema.GroupResource, items []unstructured.Unstructured) (*metav1.Table, bool, error) {
+	printer, ok := widePrinters[resource]
+	if !ok {
+		return nil, false, nil
+	}
+
+	table := &metav1.Table{}
+	for _, column := range printer.columns {
+		table.ColumnDefinitions = append(table.ColumnDefinitions, metav1.TableColumnDefinition{Name: column})
+	}
+	for i := range items {
+		cells, err := printer.row(&items[i])
+		if err != nil {
+			return nil, true, err
+		}
+		table.Rows = append(table.Rows, metav1.TableRow{Cells: cells, Object: runtime.RawExtension{Object: &items[i]}})
+	}
+	return table, true, nil
+}
+
+// sortObjects orders objects by namespace and name, as the API server lists them
+func sortObjects(items []unstructured.Unstructured) {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].GetNamespace() != items[j].GetNamespace() {
+			return items[i].GetNamespace() < items[j].GetNamespace()
+		}
+		return items[i].GetName() < items[j].GetName()
+	})
+}
+
+// age prints the time since a timestamp the way kubectl does
+func age(timestamp metav1.Time) string {
+	if timestamp.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(time.Since(timestamp.Time))
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+	return value
+}
+
+func podRow(pod *corev1.Pod) []interface{} {
+	reason := string(pod.Status.Phase)
+	if pod.Status.Reason != "" {
+		reason = pod.Status.Reason
+	}
+
+	var restarts int32
+	var lastRestart metav1.Time
+	countRestart := func(status corev1.ContainerStatus) {
+		restarts += status.RestartCount
+		if terminated := status.LastTerminationState.Terminated; terminated != nil && terminated.FinishedAt.After(lastRestart.Time) {
+			lastRestart = terminated.FinishedAt
+		}
+	}
+
+	initializing := false
+	for i, status := range pod.Status.InitContainerStatuses {
+		countRestart(status)
+		switch {
+		case status.State.Terminated != nil && status.State.Terminated.ExitCode == 0:
+			continue
+		case isRestartableInitContainer(pod, status.Name) && status.Started != nil && *status.Started:
+			continue
+		case status.State.Terminated != nil:
+			reason = "Init:" + terminatedReason(status.State.Terminated)
+		case status.State.Waiting != nil && status.State.Waiting.Reason != "" && status.State.Waiting.Reason != "PodInitializing":
+			reason = "Init:" + status.State.Waiting.Reason
+		default:
+			reason = fmt.Sprintf("Init:%d/%d", i, len(pod.Spec.InitContainers))
+		}
+		initializing = true
+		break
+	}
+
+	ready, hasRunning := 0, false
+	for i := len(pod.Status.ContainerStatuses) - 1; i >= 0; i-- {
+		status := pod.Status.ContainerStatuses[i]
+		countRestart(status)
+		if status.Ready {
+			ready++
+		}
+		if initializing {
+			continue
+		}
+		switch {
+		case status.State.Waiting != nil && status.State.Waiting.Reason != "":
+			reason = status.State.Waiting.Reason
+		case status.State.Terminated != nil:
+			reason = terminatedReason(status.State.Terminated)
+		case status.Ready && status.State.Running != nil:
+			hasRunning = true
+		}
+	}
+	if !initializing && reason == "Completed" && hasRunning {
+		reason = "NotReady"
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
+				reason = "Running"
+			}
+		}
+	}
+	if pod.DeletionTimestamp != nil {
+		if pod.Status.Reason == "NodeLost" {
+			reason = "Unknown"
+		} else {
+			reason = "Terminating"
+		}
+	}
+
+	restartText := fmt.Sprintf("%d", restarts)
+	if restarts > 0 && !lastRestart.IsZero() {
+		restartText = fmt.Sprintf("%d (%s ago)", restarts, age(lastRestart))
+	}
+
+	readinessGates := "<none>"
+	if len(pod.Spec.ReadinessGates) > 0 {
+		passed := 0
+		for _, gate := range pod.Spec.ReadinessGates {
+			for _, condition := range pod.Status.Conditions {
+				if condition.Type == gate.ConditionType && condition.Status == corev1.ConditionTrue {
+					passed++
+				}
+			}
+		}
+		readinessGates = fmt.Sprintf("%d/%d", passed, len(pod.Spec.ReadinessGates))
+	}
+
+	return []interface{}{
+		pod.Name,
+		fmt.Sprintf("%d/%d", ready, len(pod.Spec.Containers)),
+		reason,
+		restartText,
+		age(pod.CreationTimestamp),
+		orNone(pod.Status.PodIP),
+		orNone(pod.Spec.NodeName),
+		orNone(pod.Status.NominatedNodeName),
+		readinessGates,
+	}
+}
+
+func isRestartableInitContainer(pod *corev1.Pod, name string) bool {
+	for _, container := range pod.Spec.InitContainers {
+		if container.Name == name {
+			return container.RestartPolicy != nil && *container.RestartPolicy == corev1.ContainerRestartPolicyAlways
+		}
+	}
+	return false
+}
+
+func terminatedReason(state *corev1.ContainerStateTerminated) string {
+	switch {
+	case state.Reason != "":
+		return state.Reason
+	case state.Signal != 0:
+		return fmt.Sprintf("Signal:%d", state.Signal)
+	default:
+		return fmt.Sprintf("ExitCode:%d", state.ExitCode)
+	}
+}
+
+func deploymentRow(deployment *appsv1.Deployment) []interface{} {
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+
+	var names, images []string
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		names = append(names, container.Name)
+		images = append(images, container.Image)
+	}
+
+	return []interface{}{
+		deployment.Name,
+		fmt.Sprintf("%d/%d", deployment.Status.ReadyReplicas, replicas),
+		int64(deployment.Status.UpdatedReplicas),
+		int64(deployment.Status.AvailableReplicas),
+		age(deployment.CreationTimestamp),
+		strings.Join(names, ","),
+		strings.Join(images, ","),
+		metav1.FormatLabelSelector(deployment.Spec.Selector),
+	}
+}
+
+func serviceRow(service *corev1.Service) []interface{} {
+	externalIP := strings.Join(service.Spec.ExternalIPs, ",")
+	switch service.Spec.Type {
+	case corev1.ServiceTypeLoadBalancer:
+		addresses := make([]string, 0, len(service.Status.LoadBalancer.Ingress)+len(service.Spec.ExternalIPs))
+		for _, ingress := range service.Status.LoadBalancer.Ingress {
+			if ingress.IP != "" {
+				addresses = append(addresses, ingress.IP)
+			} else if ingress.Hostname != "" {
+				addresses = append(addresses, ingress.Hostname)
+			}
+		}
+		addresses = append(addresses, service.Spec.ExternalIPs...)
+		externalIP = strings.Join(addresses, ",")
+		if externalIP == "" {
+			externalIP = "<pending>"
+		}
+	case corev1.ServiceTypeExternalName:
+		externalIP = service.Spec.ExternalName
+	}
+
+	ports := make([]string, 0, len(service.Spec.Ports))
+	for _, port := range service.Spec.Ports {
+		if port.NodePort != 0 {
+			ports = append(ports, fmt.Sprintf("%d:%d/%s", port.Port, port.NodePort, port.Protocol))
+		} else {
+			ports = append(ports, fmt.Sprintf("%d/%s", port.Port, port.Protocol))
+		}
+	}
+
+	return []interface{}{
+		service.Name,
+		string(service.Spec.Type),
+		orNone(service.Spec.ClusterIP),
+		orNone(externalIP),
+		orNone(strings.Join(ports, ",")),
+		age(service.CreationTimestamp),
+		labels.FormatLabels(service.Spec.Selector),
+	}
+}
+
+func nodeRow(node *corev1.Node) []interface{} {
+	var status []string
+	for _, condition := range node.Status.Conditions {
+		if condition.Type != corev1.NodeReady {
+			continue
+		}
+		if condition.Status == corev1.ConditionTrue {
+			status = append(status, "Ready")
+		} else {
+			status = append(status, "NotReady")
+		}
+	}
+	if len(status) == 0 {
+		status = append(status, "Unknown")
+	}
+	if node.Spec.Unschedulable {
+		status = append(status, "SchedulingDisabled")
+	}
+
+	var roles []string
+	for label, value := range node.Labels {
+		switch {
+		case strings.HasPrefix(label, "node-role.kubernetes.io/"):
+			if role := strings.TrimPrefix(label, "node-role.kubernetes.io/"); role != "" {
+				roles = append(roles, role)
+			}
+		case label == "kubernetes.io/role" && value != "":
+			roles = append(roles, value)
+		}
+	}
+	sort.Strings(roles)
+
+	address := func(addressType corev1.NodeAddressType) string {
+		for _, address := range node.Status.Addresses {
+			if address.Type == addressType {
+				return address.Address
+			}
+		}
+		return "<none>"
+	}
+
+	info := node.Status.NodeInfo
+	return []interface{}{
+		node.Name,
+		strings.Join(status, ","),
+		orNone(strings.Join(roles, ",")),
+		age(node.CreationTimestamp),
+		info.KubeletVersion,
+		address(corev1.NodeInternalIP),
+		address(corev1.NodeExternalIP),
+		orNone(info.OSImage),
+		orNone(info.KernelVersion),
+		orNone(info.ContainerRuntimeVersion),
+	}
+}
+
+func eventRow(event *corev1.Event) []interface{} {
+	firstSeen := event.FirstTimestamp
+	if firstSeen.IsZero() {
+		firstSeen = metav1.NewTime(event.EventTime.Time)
+	}
+	lastSeen, count := event.LastTimestamp, event.Count
+	if event.Series != nil {
+		lastSeen, count = metav1.NewTime(event.Series.LastObservedTime.Time), event.Series.Count
+	} else if lastSeen.IsZero() {
+		lastSeen = firstSeen
+	}
+
+	source := event.Source.Component
+	if source == "" {
+		source = event.ReportingController
+	}
+	if event.Source.Host != "" {
+		source += ", " + event.Source.Host
+	}
+
+	return []interface{}{
+		age(lastSeen),
+		event.Type,
+		event.Reason,
+		strings.ToLower(event.InvolvedObject.Kind) + "/" + event.InvolvedObject.Name,
+		event.InvolvedObject.FieldPath,
+		source,
+		strings.TrimSpace(event.Message),
+		age(firstSeen),
+		int64(count),
+		event.Name,
+	}
+}
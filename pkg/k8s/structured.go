@@ -0,0 +1,76 @@
+package k8s
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/tools/pkg/utils"
+)
+
+// ResourceList is the structured content of k8s_get_resources. Table output, such as the
+// default wide output, fills Columns and Rows; json and yaml output fill Items with the
+// objects read.
+type ResourceList struct {
+	ResourceType string              `json:"resource_type" jsonschema_description:"Resource type that was read"`
+	Output       string              `json:"output" jsonschema_description:"Output format the objects were rendered in"`
+	Columns      []string            `json:"columns,omitempty" jsonschema_description:"Row keys of table output, in the order kubectl prints the columns"`
+	Rows         []map[string]string `json:"rows,omitempty" jsonschema_description:"Cells of each table row by column key, e.g. name, ready, status, restarts"`
+	Items        []map[string]any    `json:"items,omitempty" jsonschema_description:"Objects read, for json and yaml output"`
+
+	Continue           string `json:"continue,omitempty" jsonschema_description:"Token that lists the next page when limit was set and more objects remain"`
+	RemainingItemCount *int64 `json:"remaining_item_count,omitempty" jsonschema_description:"Objects left after this page, when the API server knows"`
+}
+
+// resourceList parses the text k8s_get_resources returns. Output it cannot parse, such as
+// jsonpath or name output, leaves Rows and Items empty.
+func resourceList(resourceType, output, text string) ResourceList {
+	list := ResourceList{ResourceType: resourceType, Output: output}
+
+	trimmed := strings.TrimSpace(text)
+	var object map[string]any
+	switch {
+	case strings.HasPrefix(trimmed, "{"):
+		if err := json.Unmarshal([]byte(trimmed), &object); err != nil {
+			return list
+		}
+	case strings.EqualFold(output, "yaml"):
+		if err := yaml.Unmarshal([]byte(trimmed), &object); err != nil {
+			return list
+		}
+	default:
+		if table, ok := utils.ParseTable(text); ok {
+			list.Columns, list.Rows = table.Columns, table.Rows
+		}
+		return list
+	}
+
+	// Lists carry their objects in items, single reads are the object itself
+	items, isList := object["items"].([]any)
+	if !isList {
+		list.Items = []map[string]any{object}
+		return list
+	}
+	list.Items = make([]map[string]any, 0, len(items))
+	for _, item := range items {
+		if item, ok := item.(map[string]any); ok {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list
+}
+
+// withResourceList adds the parsed resource list to a successful k8s_get_resources result,
+// keeping its text for clients that do not read structured content
+func withResourceList(result *mcp.CallToolResult, resourceType, output string) *mcp.CallToolResult {
+	if result == nil || result.IsError || len(result.Content) != 1 {
+		return result
+	}
+	text, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		return result
+	}
+	return mcp.NewToolResultStructured(resourceList(resourceType, output, text.Text), text.Text)
+}
@@ -0,0 +1,547 @@
+package k8s
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/util/jsonpath"
+
+	toolerrors "github.com/kagent-dev/tools/internal/errors"
+	"github.com/kagent-dev/tools/internal/logger"
+)
+
+// Bounds for the streaming tools. A call always ends when the first limit is hit.
+const (
+	defaultWatchDuration = 60 * time.Second
+	maxWatchDuration     = 10 * time.Minute
+	defaultMaxLogLines   = 1000
+	maxLogLines          = 10000
+	defaultMaxEvents     = 100
+	maxEvents            = 1000
+)
+
+// Reasons a streaming call stopped, reported in the tool result
+const (
+	stopConditionMet = "condition_met"
+	stopDuration     = "duration_elapsed"
+	stopLimit        = "limit_reached"
+	stopStreamClosed = "stream_closed"
+	stopCancelled    = "cancelled"
+)
+
+// progressReporter forwards streamed items to the client as MCP progress notifications.
+// Notifications are only sent when the caller supplied a progress token; the final tool
+// result always carries everything that was streamed.
+type progressReporter struct {
+	ctx      context.Context
+	server   *server.MCPServer
+	token    mcp.ProgressToken
+	progress int
+}
+
+func newProgressReporter(ctx context.Context, request mcp.CallToolRequest) *progressReporter {
+	reporter := &progressReporter{ctx: ctx, server: server.ServerFromContext(ctx)}
+	if request.Params.Meta != nil {
+		reporter.token = request.Params.Meta.ProgressToken
+	}
+	return reporter
+}
+
+func (p *progressReporter) report(message string) {
+	p.progress++
+	if p.server == nil || p.token == nil {
+		return
+	}
+	err := p.server.SendNotificationToClient(p.ctx, "notifications/progress", map[string]any{
+		"progressToken": p.token,
+		"progress":      p.progress,
+		"message":       message,
+	})
+	if err != nil {
+		logger.Get().Debug("Failed to send progress notification", "error", err)
+	}
+}
+
+// streamDuration reads duration_seconds, applying the default and the upper bound
+func streamDuration(request mcp.CallToolRequest) time.Duration {
+	seconds := mcp.ParseInt(request, "duration_seconds", int(defaultWatchDuration/time.Second))
+	duration := time.Duration(seconds) * time.Second
+	if duration <= 0 {
+		return defaultWatchDuration
+	}
+	return min(duration, maxWatchDuration)
+}
+
+// streamLimit reads an item limit argument, applying the default and the upper bound
+func streamLimit(request mcp.CallToolRequest, name string, def, upper int) int {
+	limit := mcp.ParseInt(request, name, def)
+	if limit <= 0 {
+		return def
+	}
+	return min(limit, upper)
+}
+
+// stopReasonFor reports why a stream ended once its context is done
+func stopReasonFor(ctx context.Context) string {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return stopDuration
+	}
+	return stopCancelled
+}
+
+// clientGoErrorResult renders an error from newClientGoError as a tool result
+func clientGoErrorResult(err error) *mcp.CallToolResult {
+	var toolErr *toolerrors.ToolError
+	if errors.As(err, &toolErr) {
+		return toolErr.ToMCPResult()
+	}
+	return mcp.NewToolResultError(err.Error())
+}
+
+func jsonResult(v any) (*mcp.CallToolResult, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// followLogsResult is the result of k8s_follow_pod_logs
+type followLogsResult struct {
+	StopReason  string `json:"stop_reason"`
+	Lines       int    `json:"lines"`
+	MatchedLine string `json:"matched_line,omitempty"`
+	Logs        string `json:"logs"`
+}
+
+// Follow pod logs until a duration, line count or pattern is hit
+func (k *K8sTool) handleFollowPodLogs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	podName := mcp.ParseString(request, "pod_name", "")
+	namespace := mcp.ParseString(request, "namespace", "default")
+	container := mcp.ParseString(request, "container", "")
+	tailLines := mcp.ParseInt(request, "tail_lines", 0)
+	untilPattern := mcp.ParseString(request, "until_pattern", "")
+	maxLines := streamLimit(request, "max_lines", defaultMaxLogLines, maxLogLines)
+
+	if podName == "" {
+		return mcp.NewToolResultError("pod_name parameter is required"), nil
+	}
+
+	var until *regexp.Regexp
+	if untilPattern != "" {
+		var err error
+		if until, err = regexp.Compile(untilPattern); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid until_pattern: %v", err)), nil
+		}
+	}
+
+	_, clients, errResult := k.connectNative(ctx, request)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, streamDuration(request))
+	defer cancel()
+
+	// Only new lines are followed unless tail_lines asks for history, so until_pattern is not
+	// met by a line logged before the call
+	tail := int64(max(tailLines, 0))
+	logOptions := &corev1.PodLogOptions{Container: container, Follow: true, TailLines: &tail}
+	stream, err := clients.typed.CoreV1().Pods(namespace).GetLogs(podName, logOptions).Stream(ctx)
+	if err != nil {
+		return clientGoErrorResult(newClientGoError("logs "+podName, err, "pod", podName)), nil
+	}
+	defer func() { _ = stream.Close() }()
+
+	reporter := newProgressReporter(ctx, request)
+	result := followLogsResult{StopReason: stopStreamClosed}
+	var logs strings.Builder
+
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		logs.WriteString(line)
+		logs.WriteByte('\n')
+		result.Lines++
+		reporter.report(line)
+
+		if until != nil && until.MatchString(line) {
+			result.StopReason = stopConditionMet
+			result.MatchedLine = line
+			break
+		}
+		if result.Lines >= maxLines {
+			result.StopReason = stopLimit
+			break
+		}
+	}
+	if result.StopReason == stopStreamClosed && ctx.Err() != nil {
+		result.StopReason = stopReasonFor(ctx)
+	}
+
+	result.Logs = logs.String()
+	return jsonResult(result)
+}
+
+// watchedObject is one change observed by k8s_watch_resources
+type watchedObject struct {
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Summary   string `json:"summary,omitempty"`
+}
+
+// watchResourcesResult is the result of k8s_watch_resources
+type watchResourcesResult struct {
+	StopReason string                 `json:"stop_reason"`
+	Existing   int                    `json:"existing"` // objects that existed when the watch started
+	Events     []watchedObject        `json:"events"`
+	Object     map[string]interface{} `json:"object,omitempty"` // the object that met the condition
+}
+
+// watchCondition decides when k8s_watch_resources stops. It accepts the same forms as
+// kubectl wait --for: delete, create, condition=Type[=value] and jsonpath={.path}=value.
+type watchCondition struct {
+	kind     string // delete, create, condition or jsonpath
+	name     string // condition type
+	value    string
+	jsonPath *jsonpath.JSONPath
+}
+
+func parseWatchCondition(until string) (*watchCondition, error) {
+	switch {
+	case until == "":
+		return nil, nil
+	case until == "delete" || until == "create":
+		return &watchCondition{kind: until}, nil
+	case strings.HasPrefix(until, "condition="):
+		spec := strings.TrimPrefix(until, "condition=")
+		name, value, found := strings.Cut(spec, "=")
+		if !found {
+			value = "True"
+		}
+		if name == "" {
+			return nil, fmt.Errorf("condition name is required")
+		}
+		return &watchCondition{kind: "condition", name: name, value: value}, nil
+	case strings.HasPrefix(until, "jsonpath="):
+		spec := strings.TrimPrefix(until, "jsonpath=")
+		// The value follows the last '=' outside the braces of the expression
+		end := strings.LastIndex(spec, "}")
+		path, value := spec, ""
+		if end >= 0 {
+			path = spec[:end+1]
+			value = strings.TrimPrefix(spec[end+1:], "=")
+		} else if i := strings.LastIndex(spec, "="); i >= 0 {
+			path, value = spec[:i], spec[i+1:]
+		}
+		if !strings.HasPrefix(path, "{") {
+			path = "{" + path + "}"
+		}
+		parser := jsonpath.New("until").AllowMissingKeys(true)
+		if err := parser.Parse(path); err != nil {
+			return nil, fmt.Errorf("invalid jsonpath: %w", err)
+		}
+		return &watchCondition{kind: "jsonpath", value: value, jsonPath: parser}, nil
+	default:
+		return nil, fmt.Errorf("unsupported condition %q: use delete, create, condition=<type>[=<status>] or jsonpath={<path>}=<value>", until)
+	}
+}
+
+// met reports whether a watch event satisfies the condition
+func (c *watchCondition) met(eventType watch.EventType, obj *unstructured.Unstructured) bool {
+	switch c.kind {
+	case "delete":
+		return eventType == watch.Deleted
+	case "create":
+		return eventType == watch.Added
+	case "condition":
+		if eventType == watch.Deleted {
+			return false
+		}
+		conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+		for _, raw := range conditions {
+			condition, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if strings.EqualFold(fmt.Sprint(condition["type"]), c.name) {
+				return strings.EqualFold(fmt.Sprint(condition["status"]), c.value)
+			}
+		}
+		return false
+	case "jsonpath":
+		if eventType == watch.Deleted {
+			return false
+		}
+		results, err := c.jsonPath.FindResults(obj.Object)
+		if err != nil {
+			return false
+		}
+		for _, values := range results {
+			for _, v := range values {
+				if c.value == "" || fmt.Sprint(v.Interface()) == c.value {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// metByExisting reports whether an object that existed when the watch started satisfies the
+// condition. Deletion is only met by a change; creation only when watching a single named
+// resource, as kubectl wait --for=create does.
+func (c *watchCondition) metByExisting(obj *unstructured.Unstructured, named bool) bool {
+	switch c.kind {
+	case "delete":
+		return false
+	case "create":
+		return named
+	}
+	return c.met(watch.Modified, obj)
+}
+
+// summarizeObject renders the status fields an agent usually waits on in one line
+func summarizeObject(obj *unstructured.Unstructured) string {
+	var parts []string
+	if phase, ok, _ := unstructured.NestedString(obj.Object, "status", "phase"); ok {
+		parts = append(parts, "phase="+phase)
+	}
+	if replicas, ok, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas"); ok {
+		ready, _, _ := unstructured.NestedInt64(obj.Object, "status", "readyReplicas")
+		parts = append(parts, fmt.Sprintf("ready=%d/%d", ready, replicas))
+	}
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, raw := range conditions {
+		if condition, ok := raw.(map[string]interface{}); ok {
+			parts = append(parts, fmt.Sprintf("%v=%v", condition["type"], condition["status"]))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// Watch a resource type until a duration, event count or condition is hit
+func (k *K8sTool) handleWatchResources(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	resourceType := mcp.ParseString(request, "resource_type", "")
+	resourceName := mcp.ParseString(request, "resource_name", "")
+	namespace := mcp.ParseString(request, "namespace", "")
+	allNamespaces := mcp.ParseBoolean(request, "all_namespaces", false)
+	labelSelector := mcp.ParseString(request, "label_selector", "")
+	until := mcp.ParseString(request, "until", "")
+	limit := streamLimit(request, "max_events", defaultMaxEvents, maxEvents)
+
+	if resourceType == "" {
+		return mcp.NewToolResultError("resource_type parameter is required"), nil
+	}
+	condition, err := parseWatchCondition(until)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid until: %v", err)), nil
+	}
+
+	target, clients, errResult := k.connectNative(ctx, request)
+	if errResult != nil {
+		return errResult, nil
+	}
+	mapping, err := target.resolve(resourceType)
+	if err != nil {
+		return clientGoErrorResult(newClientGoError("watch "+resourceType, err, resourceType, resourceName)), nil
+	}
+	if allNamespaces {
+		namespace = metav1.NamespaceAll
+	} else {
+		namespace = target.namespaceFor(mapping, namespace)
+	}
+
+	listOptions := metav1.ListOptions{LabelSelector: labelSelector}
+	if resourceName != "" {
+		listOptions.FieldSelector = fields.OneTermEqualSelector("metadata.name", resourceName).String()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, streamDuration(request))
+	defer cancel()
+
+	// List first and watch from the resource version of the list, so existing objects are
+	// checked against the condition once instead of being replayed as ADDED
+	client := resourceClient(clients, mapping, namespace)
+	list, err := client.List(ctx, listOptions)
+	if err != nil {
+		return clientGoErrorResult(newClientGoError("watch "+resourceType, err, resourceType, resourceName)), nil
+	}
+	result := watchResourcesResult{Existing: len(list.Items), Events: []watchedObject{}}
+	if condition != nil {
+		for i := range list.Items {
+			if condition.metByExisting(&list.Items[i], resourceName != "") {
+				result.StopReason = stopConditionMet
+				result.Object = list.Items[i].Object
+				return jsonResult(result)
+			}
+		}
+	}
+
+	listOptions.ResourceVersion = list.GetResourceVersion()
+	watcher, err := client.Watch(ctx, listOptions)
+	if err != nil {
+		return clientGoErrorResult(newClientGoError("watch "+resourceType, err, resourceType, resourceName)), nil
+	}
+	defer watcher.Stop()
+
+	kind := qualifiedKind(mapping)
+	reporter := newProgressReporter(ctx, request)
+
+	result.StopReason = drainWatch(ctx, watcher, func(event watch.Event) bool {
+		obj, ok := event.Object.(*unstructured.Unstructured)
+		if !ok {
+			return false
+		}
+		observed := watchedObject{
+			Type:      string(event.Type),
+			Name:      obj.GetName(),
+			Namespace: obj.GetNamespace(),
+			Summary:   summarizeObject(obj),
+		}
+		result.Events = append(result.Events, observed)
+		reporter.report(strings.TrimSpace(fmt.Sprintf("%s %s/%s %s", observed.Type, kind, observed.Name, observed.Summary)))
+
+		if condition != nil && condition.met(event.Type, obj) {
+			result.StopReason = stopConditionMet
+			result.Object = obj.Object
+			return true
+		}
+		if len(result.Events) >= limit {
+			result.StopReason = stopLimit
+			return true
+		}
+		return false
+	}, func() string { return result.StopReason })
+
+	return jsonResult(result)
+}
+
+// drainWatch feeds watch events to handle until it returns true, the watch closes or ctx is
+// done, and returns the stop reason. stopReason reports the reason recorded by handle.
+func drainWatch(ctx context.Context, watcher watch.Interface, handle func(watch.Event) bool, stopReason func() string) string {
+	for {
+		select {
+		case <-ctx.Done():
+			return stopReasonFor(ctx)
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return stopStreamClosed
+			}
+			if event.Type == watch.Error {
+				logger.Get().Warn("Watch returned an error event", "error", fmt.Sprint(event.Object))
+				continue
+			}
+			if handle(event) {
+				return stopReason()
+			}
+		}
+	}
+}
+
+// watchedEvent is one Kubernetes event observed by k8s_watch_events
+type watchedEvent struct {
+	Type           string `json:"type"`
+	Reason         string `json:"reason"`
+	InvolvedObject string `json:"involved_object"`
+	Namespace      string `json:"namespace,omitempty"`
+	Message        string `json:"message"`
+	Count          int32  `json:"count,omitempty"`
+}
+
+// watchEventsResult is the result of k8s_watch_events
+type watchEventsResult struct {
+	StopReason string         `json:"stop_reason"`
+	Events     []watchedEvent `json:"events"`
+}
+
+// Watch events, optionally filtered by involved object, until a duration, count or reason is hit
+func (k *K8sTool) handleWatchEvents(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	namespace := mcp.ParseString(request, "namespace", "")
+	objectKind := mcp.ParseString(request, "involved_object_kind", "")
+	objectName := mcp.ParseString(request, "involved_object_name", "")
+	eventType := mcp.ParseString(request, "event_type", "")
+	untilReason := mcp.ParseString(request, "until_reason", "")
+	limit := streamLimit(request, "max_events", defaultMaxEvents, maxEvents)
+
+	selector := fields.Set{}
+	if objectKind != "" {
+		selector["involvedObject.kind"] = objectKind
+	}
+	if objectName != "" {
+		selector["involvedObject.name"] = objectName
+	}
+	if eventType != "" {
+		selector["type"] = eventType
+	}
+
+	_, clients, errResult := k.connectNative(ctx, request)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, streamDuration(request))
+	defer cancel()
+
+	// Events that happened before the call are skipped by watching from the resource version
+	// of a list, so they neither count towards max_events nor meet until_reason
+	listOptions := metav1.ListOptions{FieldSelector: selector.AsSelector().String()}
+	list, err := clients.typed.CoreV1().Events(namespace).List(ctx, listOptions)
+	if err != nil {
+		return clientGoErrorResult(newClientGoError("watch events", err, "events", "")), nil
+	}
+	listOptions.ResourceVersion = list.ResourceVersion
+	watcher, err := clients.typed.CoreV1().Events(namespace).Watch(ctx, listOptions)
+	if err != nil {
+		return clientGoErrorResult(newClientGoError("watch events", err, "events", "")), nil
+	}
+	defer watcher.Stop()
+
+	reporter := newProgressReporter(ctx, request)
+	result := watchEventsResult{Events: []watchedEvent{}}
+
+	result.StopReason = drainWatch(ctx, watcher, func(event watch.Event) bool {
+		ev, ok := event.Object.(*corev1.Event)
+		if !ok || event.Type == watch.Deleted {
+			return false
+		}
+		observed := watchedEvent{
+			Type:           ev.Type,
+			Reason:         ev.Reason,
+			InvolvedObject: ev.InvolvedObject.Kind + "/" + ev.InvolvedObject.Name,
+			Namespace:      ev.Namespace,
+			Message:        ev.Message,
+			Count:          ev.Count,
+		}
+		result.Events = append(result.Events, observed)
+		reporter.report(fmt.Sprintf("%s %s %s: %s", observed.Type, observed.Reason, observed.InvolvedObject, observed.Message))
+
+		if untilReason != "" && strings.EqualFold(ev.Reason, untilReason) {
+			result.StopReason = stopConditionMet
+			return true
+		}
+		if len(result.Events) >= limit {
+			result.StopReason = stopLimit
+			return true
+		}
+		return false
+	}, func() string { return result.StopReason })
+
+	return jsonResult(result)
+}
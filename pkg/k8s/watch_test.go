@@ -0,0 +1,424 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newTestStreamingTool returns a K8sTool whose streaming tools run against fake clients,
+// with the given events queued on every watch
+func newTestStreamingTool(t *testing.T, events ...watch.Event) (*K8sTool, *clientGoClients) {
+	t.Helper()
+
+	backend := newTestClientGoBackend(t, nil)
+	clients, err := backend.defaultTarget.clientsFor(CallOptions{})
+	require.NoError(t, err)
+
+	reactor := func(action k8stesting.Action) (bool, watch.Interface, error) {
+		watcher := watch.NewFakeWithChanSize(len(events), false)
+		for _, event := range events {
+			watcher.Action(event.Type, event.Object)
+		}
+		watcher.Stop()
+		return true, watcher, nil
+	}
+	clients.dynamic.(*dynamicfake.FakeDynamicClient).PrependWatchReactor("*", reactor)
+	clients.typed.(*kubefake.Clientset).PrependWatchReactor("*", reactor)
+
+	tool := newTestK8sTool()
+	tool.native = backend
+	return tool, clients
+}
+
+func newDeployment(name string, ready int64, available string) *unstructured.Unstructured {
+	obj := newUnstructured("apps/v1", "Deployment", "default", name, map[string]interface{}{"replicas": int64(3)})
+	obj.Object["status"] = map[string]interface{}{
+		"readyReplicas": ready,
+		"conditions": []interface{}{
+			map[string]interface{}{"type": "Available", "status": available},
+		},
+	}
+	return obj
+}
+
+func TestParseWatchCondition(t *testing.T) {
+	deployment := newDeployment("web", 3, "True")
+	pod := newUnstructured("v1", "Pod", "default", "web-0", nil)
+	pod.Object["status"] = map[string]interface{}{"phase": "Running"}
+
+	tests := []struct {
+		until     string
+		eventType watch.EventType
+		obj       *unstructured.Unstructured
+		met       bool
+	}{
+		{"delete", watch.Deleted, pod, true},
+		{"delete", watch.Modified, pod, false},
+		{"create", watch.Added, pod, true},
+		{"condition=Available", watch.Modified, deployment, true},
+		{"condition=available=true", watch.Modified, deployment, true},
+		{"condition=Available=False", watch.Modified, deployment, false},
+		{"condition=Progressing", watch.Modified, deployment, false},
+		{"jsonpath={.status.phase}=Running", watch.Modified, pod, true},
+		{"jsonpath=.status.phase=Pending", watch.Modified, pod, false},
+		{"jsonpath={.status.readyReplicas}=3", watch.Modified, deployment, true},
+		{"jsonpath={.status.phase}=Running", watch.Deleted, pod, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.until, func(t *testing.T) {
+			condition, err := parseWatchCondition(tt.until)
+			require.NoError(t, err)
+			assert.Equal(t, tt.met, condition.met(tt.eventType, tt.obj))
+		})
+	}
+
+	condition, err := parseWatchCondition("")
+	assert.NoError(t, err)
+	assert.Nil(t, condition)
+
+	for _, until := range []string{"ready", "condition=", "jsonpath={.status["} {
+		_, err := parseWatchCondition(until)
+		assert.Error(t, err, until)
+	}
+}
+
+func TestHandleWatchResources(t *testing.T) {
+	t.Run("stops when the condition is met", func(t *testing.T) {
+		tool, _ := newTestStreamingTool(t,
+			watch.Event{Type: watch.Added, Object: newDeployment("web", 1, "False")},
+			watch.Event{Type: watch.Modified, Object: newDeployment("web", 3, "True")},
+			watch.Event{Type: watch.Modified, Object: newDeployment("web", 3, "True")},
+		)
+
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]interface{}{
+			"resource_type": "deployments",
+			"resource_name": "web",
+			"until":         "condition=Available",
+		}
+		result, err := tool.handleWatchResources(context.Background(), req)
+		require.NoError(t, err)
+		require.False(t, result.IsError, getResultText(result))
+
+		var response watchResourcesResult
+		require.NoError(t, json.Unmarshal([]byte(getResultText(result)), &response))
+		assert.Equal(t, stopConditionMet, response.StopReason)
+		require.Len(t, response.Events, 2)
+		assert.Equal(t, "ADDED", response.Events[0].Type)
+		assert.Equal(t, "ready=1/3 Available=False", response.Events[0].Summary)
+		assert.Equal(t, "web", response.Object["metadata"].(map[string]interface{})["name"])
+	})
+
+	t.Run("stops at the event limit", func(t *testing.T) {
+		tool, _ := newTestStreamingTool(t,
+			watch.Event{Type: watch.Added, Object: newDeployment("a", 0, "False")},
+			watch.Event{Type: watch.Added, Object: newDeployment("b", 0, "False")},
+		)
+
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]interface{}{"resource_type": "deployments", "max_events": float64(1)}
+		result, err := tool.handleWatchResources(context.Background(), req)
+		require.NoError(t, err)
+
+		var response watchResourcesResult
+		require.NoError(t, json.Unmarshal([]byte(getResultText(result)), &response))
+		assert.Equal(t, stopLimit, response.StopReason)
+		assert.Len(t, response.Events, 1)
+	})
+
+	t.Run("reports a closed watch", func(t *testing.T) {
+		tool, _ := newTestStreamingTool(t)
+
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]interface{}{"resource_type": "pods", "until": "delete"}
+		result, err := tool.handleWatchResources(context.Background(), req)
+		require.NoError(t, err)
+
+		var response watchResourcesResult
+		require.NoError(t, json.Unmarshal([]byte(getResultText(result)), &response))
+		assert.Equal(t, stopStreamClosed, response.StopReason)
+		assert.Empty(t, response.Events)
+	})
+
+	t.Run("does not replay existing objects", func(t *testing.T) {
+		tool, clients := newTestStreamingTool(t,
+			watch.Event{Type: watch.Added, Object: newDeployment("new", 0, "False")},
+		)
+		require.NoError(t, clients.dynamic.(*dynamicfake.FakeDynamicClient).Tracker().Add(newDeployment("old", 0, "False")))
+
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]interface{}{"resource_type": "deployments", "until": "condition=Available"}
+		result, err := tool.handleWatchResources(context.Background(), req)
+		require.NoError(t, err)
+
+		var response watchResourcesResult
+		require.NoError(t, json.Unmarshal([]byte(getResultText(result)), &response))
+		assert.Equal(t, stopStreamClosed, response.StopReason)
+		assert.Equal(t, 1, response.Existing)
+		require.Len(t, response.Events, 1)
+		assert.Equal(t, "new", response.Events[0].Name)
+	})
+
+	t.Run("create is not met by an existing object", func(t *testing.T) {
+		tool, clients := newTestStreamingTool(t)
+		require.NoError(t, clients.dynamic.(*dynamicfake.FakeDynamicClient).Tracker().Add(newDeployment("old", 0, "False")))
+
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]interface{}{"resource_type": "deployments", "until": "create"}
+		result, err := tool.handleWatchResources(context.Background(), req)
+		require.NoError(t, err)
+
+		var response watchResourcesResult
+		require.NoError(t, json.Unmarshal([]byte(getResultText(result)), &response))
+		assert.Equal(t, stopStreamClosed, response.StopReason)
+		assert.Empty(t, response.Events)
+	})
+
+	t.Run("stops when an existing object meets the condition", func(t *testing.T) {
+		var watched bool
+		tool, clients := newTestStreamingTool(t)
+		fake := clients.dynamic.(*dynamicfake.FakeDynamicClient)
+		require.NoError(t, fake.Tracker().Add(newDeployment("web", 3, "True")))
+		fake.PrependWatchReactor("*", func(action k8stesting.Action) (bool, watch.Interface, error) {
+			watched = true
+			return false, nil, nil
+		})
+
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]interface{}{"resource_type": "deployments", "until": "condition=Available"}
+		result, err := tool.handleWatchResources(context.Background(), req)
+		require.NoError(t, err)
+
+		var response watchResourcesResult
+		require.NoError(t, json.Unmarshal([]byte(getResultText(result)), &response))
+		assert.Equal(t, stopConditionMet, response.StopReason)
+		assert.Empty(t, response.Events)
+		assert.Equal(t, "web", response.Object["metadata"].(map[string]interface{})["name"])
+		assert.False(t, watched)
+	})
+
+	t.Run("validates arguments", func(t *testing.T) {
+		tool, _ := newTestStreamingTool(t)
+
+		result, err := tool.handleWatchResources(context.Background(), mcp.CallToolRequest{})
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]interface{}{"resource_type": "pods", "until": "ready"}
+		result, err = tool.handleWatchResources(context.Background(), req)
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, getResultText(result), "unsupported condition")
+	})
+}
+
+func TestHandleWatchEvents(t *testing.T) {
+	newEvent := func(reason string) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "web-0." + reason, Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web-0"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         reason,
+			Message:        reason + " happened",
+		}
+	}
+
+	var observed []k8stesting.Action
+	tool, clients := newTestStreamingTool(t,
+		watch.Event{Type: watch.Added, Object: newEvent("Pulling")},
+		watch.Event{Type: watch.Added, Object: newEvent("BackOff")},
+		watch.Event{Type: watch.Added, Object: newEvent("Killing")},
+	)
+	clients.typed.(*kubefake.Clientset).PrependWatchReactor("events", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		observed = append(observed, action)
+		return false, nil, nil
+	})
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{
+		"namespace":            "default",
+		"involved_object_kind": "Pod",
+		"involved_object_name": "web-0",
+		"until_reason":         "BackOff",
+	}
+	result, err := tool.handleWatchEvents(context.Background(), req)
+	require.NoError(t, err)
+	require.False(t, result.IsError, getResultText(result))
+
+	var response watchEventsResult
+	require.NoError(t, json.Unmarshal([]byte(getResultText(result)), &response))
+	assert.Equal(t, stopConditionMet, response.StopReason)
+	require.Len(t, response.Events, 2)
+	assert.Equal(t, "Pod/web-0", response.Events[1].InvolvedObject)
+	assert.Equal(t, "BackOff happened", response.Events[1].Message)
+
+	require.Len(t, observed, 1)
+	restrictions := observed[0].(k8stesting.WatchAction).GetWatchRestrictions()
+	assert.Equal(t, "involvedObject.kind=Pod,involvedObject.name=web-0", restrictions.Fields.String())
+}
+
+func TestHandleWatchEventsSkipsPastEvents(t *testing.T) {
+	past := &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "web-0.old", Namespace: "default"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web-0"},
+		Reason:         "BackOff",
+	}
+
+	var observed []k8stesting.Action
+	tool, clients := newTestStreamingTool(t)
+	fake := clients.typed.(*kubefake.Clientset)
+	fake.PrependReactor("list", "events", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &corev1.EventList{ListMeta: metav1.ListMeta{ResourceVersion: "42"}, Items: []corev1.Event{*past}}, nil
+	})
+	fake.PrependWatchReactor("events", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		observed = append(observed, action)
+		return false, nil, nil
+	})
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"namespace": "default", "until_reason": "BackOff"}
+	result, err := tool.handleWatchEvents(context.Background(), req)
+	require.NoError(t, err)
+
+	var response watchEventsResult
+	require.NoError(t, json.Unmarshal([]byte(getResultText(result)), &response))
+	assert.Equal(t, stopStreamClosed, response.StopReason)
+	assert.Empty(t, response.Events)
+
+	require.Len(t, observed, 1)
+	assert.Equal(t, "42", observed[0].(k8stesting.WatchAction).GetWatchRestrictions().ResourceVersion)
+}
+
+func TestHandleFollowPodLogs(t *testing.T) {
+	tool, _ := newTestStreamingTool(t)
+
+	t.Run("stops on the until pattern", func(t *testing.T) {
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]interface{}{"pod_name": "web-0", "until_pattern": "fake"}
+		result, err := tool.handleFollowPodLogs(context.Background(), req)
+		require.NoError(t, err)
+		require.False(t, result.IsError, getResultText(result))
+
+		var response followLogsResult
+		require.NoError(t, json.Unmarshal([]byte(getResultText(result)), &response))
+		assert.Equal(t, stopConditionMet, response.StopReason)
+		assert.Equal(t, "fake logs", response.MatchedLine)
+		assert.Equal(t, 1, response.Lines)
+	})
+
+	t.Run("reports the end of the stream", func(t *testing.T) {
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]interface{}{"pod_name": "web-0", "until_pattern": "never"}
+		result, err := tool.handleFollowPodLogs(context.Background(), req)
+		require.NoError(t, err)
+
+		var response followLogsResult
+		require.NoError(t, json.Unmarshal([]byte(getResultText(result)), &response))
+		assert.Equal(t, stopStreamClosed, response.StopReason)
+		assert.Equal(t, "fake logs\n", response.Logs)
+	})
+
+	t.Run("follows only new lines by default", func(t *testing.T) {
+		tool, clients := newTestStreamingTool(t)
+		fake := clients.typed.(*kubefake.Clientset)
+
+		for args, want := range map[string]int64{"": 0, "20": 20} {
+			fake.ClearActions()
+			req := mcp.CallToolRequest{}
+			req.Params.Arguments = map[string]interface{}{"pod_name": "web-0"}
+			if args != "" {
+				req.Params.Arguments.(map[string]interface{})["tail_lines"] = args
+			}
+			_, err := tool.handleFollowPodLogs(context.Background(), req)
+			require.NoError(t, err)
+
+			require.Len(t, fake.Actions(), 1)
+			options := fake.Actions()[0].(k8stesting.GenericAction).GetValue().(*corev1.PodLogOptions)
+			require.NotNil(t, options.TailLines)
+			assert.Equal(t, want, *options.TailLines)
+		}
+	})
+
+	t.Run("validates arguments", func(t *testing.T) {
+		result, err := tool.handleFollowPodLogs(context.Background(), mcp.CallToolRequest{})
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]interface{}{"pod_name": "web-0", "until_pattern": "("}
+		result, err = tool.handleFollowPodLogs(context.Background(), req)
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+	})
+}
+
+// testSession is a client session that records the notifications sent to it
+type testSession struct {
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (s *testSession) Initialize()       {}
+func (s *testSession) Initialized() bool { return true }
+func (s *testSession) SessionID() string { return "test-session" }
+func (s *testSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+func TestWatchSendsProgressNotifications(t *testing.T) {
+	tool, _ := newTestStreamingTool(t,
+		watch.Event{Type: watch.Added, Object: newDeployment("web", 1, "False")},
+		watch.Event{Type: watch.Modified, Object: newDeployment("web", 3, "True")},
+	)
+
+	s := server.NewMCPServer("test", "v0.0.1")
+	s.AddTool(mcp.NewTool("k8s_watch_resources"), tool.handleWatchResources)
+
+	session := &testSession{notifications: make(chan mcp.JSONRPCNotification, 10)}
+	require.NoError(t, s.RegisterSession(context.Background(), session))
+	ctx := s.WithContext(context.Background(), session)
+
+	message := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"k8s_watch_resources",` +
+		`"arguments":{"resource_type":"deployments","until":"condition=Available"},"_meta":{"progressToken":"watch-1"}}}`
+	response := s.HandleMessage(ctx, []byte(message))
+	require.IsType(t, mcp.JSONRPCResponse{}, response)
+
+	var received []mcp.JSONRPCNotification
+	timeout := time.After(time.Second)
+	for len(received) < 2 {
+		select {
+		case notification := <-session.notifications:
+			received = append(received, notification)
+		case <-timeout:
+			t.Fatalf("expected 2 progress notifications, got %d", len(received))
+		}
+	}
+
+	assert.Equal(t, "notifications/progress", received[0].Method)
+	assert.Equal(t, "watch-1", received[0].Params.AdditionalFields["progressToken"])
+	assert.Equal(t, 1, received[0].Params.AdditionalFields["progress"])
+	assert.Equal(t, "ADDED deployment.apps/web ready=1/3 Available=False", received[0].Params.AdditionalFields["message"])
+	assert.Equal(t, 2, received[1].Params.AdditionalFields["progress"])
+}
+
+func TestProgressReporterWithoutToken(t *testing.T) {
+	reporter := newProgressReporter(context.Background(), mcp.CallToolRequest{})
+	reporter.report("line")
+	assert.Equal(t, 1, reporter.progress)
+	assert.Nil(t, reporter.token)
+}
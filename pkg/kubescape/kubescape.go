@@ -4,9 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/kagent-dev/tools/internal/clusters"
+	"github.com/kagent-dev/tools/internal/credentials"
 	"github.com/kagent-dev/tools/internal/errors"
 	"github.com/kagent-dev/tools/internal/telemetry"
 	helpersv1 "github.com/kubescape/k8s-interface/instanceidhandler/v1/helpers"
@@ -36,6 +40,11 @@ const (
 	// Pod labels
 	operatorPodLabel = "app.kubernetes.io/name=kubescape-operator"
 	storagePodLabel  = "app.kubernetes.io/name=storage"
+
+	// SBOM paging
+	sbomListPageSize        = 20
+	defaultSBOMPackageLimit = 100
+	maxSBOMPackageLimit     = 1000
 )
 
 // KubescapeTool holds the clients for Kubescape and Kubernetes APIs
@@ -43,18 +52,72 @@ type KubescapeTool struct {
 	spdxClient   spdxv1beta1.SpdxV1beta1Interface
 	k8sClient    kubernetes.Interface
 	apiExtClient apiextensionsclientset.Interface
+	config       *rest.Config
 	initError    error
+
+	// Tools for clusters selected per call, created on first use
+	clusterToolsMu sync.Mutex
+	clusterTools   map[string]*KubescapeTool
 }
 
 // NewKubescapeTool creates a new KubescapeTool with Kubernetes clients
 func NewKubescapeTool(kubeconfig string) *KubescapeTool {
-	tool := &KubescapeTool{}
-
 	config, err := getKubeConfig(kubeconfig)
 	if err != nil {
-		tool.initError = fmt.Errorf("failed to create kubernetes config: %w", err)
+		return &KubescapeTool{initError: fmt.Errorf("failed to create kubernetes config: %w", err)}
+	}
+	return newKubescapeToolForConfig(config)
+}
+
+// forCluster returns the tool for the cluster selected on the call, or k when none was
+// selected. With token passthrough or impersonation the returned tool acts as the caller.
+func (k *KubescapeTool) forCluster(ctx context.Context) *KubescapeTool {
+	if err := credentials.Required(ctx); err != nil {
+		return &KubescapeTool{initError: err}
+	}
+
+	tool := k.clusterTool(ctx)
+	if tool.config == nil {
 		return tool
 	}
+	if token := credentials.TokenFromContext(ctx); token != "" {
+		return newKubescapeToolForConfig(credentials.RESTConfig(tool.config, token))
+	}
+	if imp, ok := credentials.ImpersonationFromContext(ctx); ok {
+		return newKubescapeToolForConfig(credentials.ImpersonatingConfig(tool.config, imp))
+	}
+	return tool
+}
+
+// clusterTool returns the tool for the cluster selected on the call, creating it on first use
+func (k *KubescapeTool) clusterTool(ctx context.Context) *KubescapeTool {
+	cluster, ok := clusters.FromContext(ctx)
+	if !ok {
+		return k
+	}
+
+	k.clusterToolsMu.Lock()
+	defer k.clusterToolsMu.Unlock()
+	if tool, ok := k.clusterTools[cluster.Name]; ok {
+		return tool
+	}
+
+	var tool *KubescapeTool
+	config, _, err := clusters.RESTConfig(cluster)
+	if err != nil {
+		tool = &KubescapeTool{initError: fmt.Errorf("failed to create kubernetes config for cluster %s: %w", cluster.Name, err)}
+	} else {
+		tool = newKubescapeToolForConfig(config)
+	}
+	if k.clusterTools == nil {
+		k.clusterTools = make(map[string]*KubescapeTool)
+	}
+	k.clusterTools[cluster.Name] = tool
+	return tool
+}
+
+func newKubescapeToolForConfig(config *rest.Config) *KubescapeTool {
+	tool := &KubescapeTool{config: config}
 
 	// Create standard Kubernetes client
 	k8sClient, err := kubernetes.NewForConfig(config)
@@ -116,6 +179,7 @@ type CheckStatus struct {
 
 // handleCheckHealth verifies Kubescape operator installation and readiness
 func (k *KubescapeTool) handleCheckHealth(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	k = k.forCluster(ctx)
 	if k.initError != nil {
 		toolErr := errors.NewKubescapeError("check_health", k.initError)
 		return toolErr.ToMCPResult(), nil
@@ -443,8 +507,45 @@ func (k *KubescapeTool) handleCheckHealth(ctx context.Context, request mcp.CallT
 		}
 	}
 
-	// NOTE: SBOM checks are disabled as SBOM tools are disabled (too large for LLM context)
-	// Check 10: SBOMSyfts CRD exists - DISABLED
+	// Check 10: SBOMSyfts CRD exists
+	_, err = k.apiExtClient.ApiextensionsV1().CustomResourceDefinitions().Get(ctx, sbomSyftsCRD, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			result.Checks["sbom_crd"] = CheckStatus{
+				Status:  "warning",
+				Message: "SBOMSyfts CRD not installed - SBOM generation may not be enabled",
+			}
+		} else {
+			result.Checks["sbom_crd"] = CheckStatus{
+				Status:  "error",
+				Message: fmt.Sprintf("Failed to check CRD: %v", err),
+			}
+		}
+	} else {
+		result.Checks["sbom_crd"] = CheckStatus{
+			Status:  "ok",
+			Message: "CRD installed",
+		}
+
+		// Check for SBOM data. SBOMs are large, so they are not counted.
+		sboms, listErr := k.spdxClient.SBOMSyfts(metav1.NamespaceAll).List(ctx, metav1.ListOptions{Limit: 1})
+		if listErr != nil {
+			result.Checks["sbom_data"] = CheckStatus{
+				Status:  "warning",
+				Message: fmt.Sprintf("Failed to list SBOMs: %v", listErr),
+			}
+		} else if len(sboms.Items) == 0 {
+			result.Checks["sbom_data"] = CheckStatus{
+				Status:  "warning",
+				Message: "No SBOMs found - image scans may not have completed yet",
+			}
+		} else {
+			result.Checks["sbom_data"] = CheckStatus{
+				Status:  "ok",
+				Message: "SBOMs found",
+			}
+		}
+	}
 
 	// Set summary
 	if result.Healthy {
@@ -454,16 +555,12 @@ func (k *KubescapeTool) handleCheckHealth(ctx context.Context, request mcp.CallT
 		result.Recommendations = recommendations
 	}
 
-	content, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
-	}
-
-	return mcp.NewToolResultText(string(content)), nil
+	return structuredResult(result), nil
 }
 
 // handleListVulnerabilityManifests lists vulnerability manifests at image and workload levels
 func (k *KubescapeTool) handleListVulnerabilityManifests(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	k = k.forCluster(ctx)
 	if k.initError != nil {
 		toolErr := errors.NewKubescapeError("list_vulnerability_manifests", k.initError)
 		return toolErr.ToMCPResult(), nil
@@ -502,38 +599,33 @@ func (k *KubescapeTool) handleListVulnerabilityManifests(ctx context.Context, re
 	}
 
 	// Build response
-	vulnerabilityManifests := []map[string]interface{}{}
+	vulnerabilityManifests := []VulnerabilityManifestSummary{}
 	for _, manifest := range manifests.Items {
 		isImageLevel := manifest.Annotations[helpersv1.WlidMetadataKey] == ""
-		manifestMap := map[string]interface{}{
-			"namespace":               manifest.Namespace,
-			"manifest_name":           manifest.Name,
-			"image_level":             isImageLevel,
-			"workload_level":          !isImageLevel,
-			"image_id":                manifest.Annotations[helpersv1.ImageIDMetadataKey],
-			"image_tag":               manifest.Annotations[helpersv1.ImageTagMetadataKey],
-			"workload_id":             manifest.Annotations[helpersv1.WlidMetadataKey],
-			"workload_container_name": manifest.Annotations[helpersv1.ContainerNameMetadataKey],
-			"vulnerability_count":     len(manifest.Spec.Payload.Matches),
-		}
-		vulnerabilityManifests = append(vulnerabilityManifests, manifestMap)
+		vulnerabilityManifests = append(vulnerabilityManifests, VulnerabilityManifestSummary{
+			Namespace:             manifest.Namespace,
+			ManifestName:          manifest.Name,
+			ImageLevel:            isImageLevel,
+			WorkloadLevel:         !isImageLevel,
+			ImageID:               manifest.Annotations[helpersv1.ImageIDMetadataKey],
+			ImageTag:              manifest.Annotations[helpersv1.ImageTagMetadataKey],
+			WorkloadID:            manifest.Annotations[helpersv1.WlidMetadataKey],
+			WorkloadContainerName: manifest.Annotations[helpersv1.ContainerNameMetadataKey],
+			VulnerabilityCount:    len(manifest.Spec.Payload.Matches),
+		})
 	}
 
-	result := map[string]interface{}{
-		"vulnerability_manifests": vulnerabilityManifests,
-		"total_count":             len(vulnerabilityManifests),
+	result := VulnerabilityManifestList{
+		VulnerabilityManifests: vulnerabilityManifests,
+		TotalCount:             len(vulnerabilityManifests),
 	}
 
-	content, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
-	}
-
-	return mcp.NewToolResultText(string(content)), nil
+	return structuredResult(result), nil
 }
 
 // handleListVulnerabilitiesInManifest lists all CVEs in a specific manifest
 func (k *KubescapeTool) handleListVulnerabilitiesInManifest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	k = k.forCluster(ctx)
 	if k.initError != nil {
 		toolErr := errors.NewKubescapeError("list_vulnerabilities", k.initError)
 		return toolErr.ToMCPResult(), nil
@@ -555,57 +647,43 @@ func (k *KubescapeTool) handleListVulnerabilitiesInManifest(ctx context.Context,
 	}
 
 	// Extract vulnerabilities with summary info
-	vulnerabilities := []map[string]interface{}{}
-	severityCounts := map[string]int{
-		"Critical": 0,
-		"High":     0,
-		"Medium":   0,
-		"Low":      0,
-		"Unknown":  0,
-	}
+	vulnerabilities := []VulnerabilitySummary{}
+	severityCounts := newSeverityCounts()
 
 	for _, match := range manifest.Spec.Payload.Matches {
 		vuln := match.Vulnerability
 		severity := string(vuln.Severity)
-		if _, exists := severityCounts[severity]; exists {
-			severityCounts[severity]++
-		} else {
-			severityCounts["Unknown"]++
-		}
+		countSeverity(severityCounts, severity)
 
-		vulnInfo := map[string]interface{}{
-			"id":          vuln.ID,
-			"severity":    severity,
-			"description": truncateString(vuln.Description, 200),
-			"data_source": vuln.DataSource,
+		vulnInfo := VulnerabilitySummary{
+			ID:          vuln.ID,
+			Severity:    severity,
+			Description: truncateString(vuln.Description, 200),
+			DataSource:  vuln.DataSource,
 		}
 
 		if vuln.Fix.State != "" {
-			vulnInfo["fix_state"] = vuln.Fix.State
-			vulnInfo["fix_versions"] = vuln.Fix.Versions
+			vulnInfo.FixState = vuln.Fix.State
+			vulnInfo.FixVersions = vuln.Fix.Versions
 		}
 
 		vulnerabilities = append(vulnerabilities, vulnInfo)
 	}
 
-	result := map[string]interface{}{
-		"manifest_name":    manifestName,
-		"namespace":        namespace,
-		"total_count":      len(vulnerabilities),
-		"severity_summary": severityCounts,
-		"vulnerabilities":  vulnerabilities,
+	result := VulnerabilityList{
+		ManifestName:    manifestName,
+		Namespace:       namespace,
+		TotalCount:      len(vulnerabilities),
+		SeveritySummary: severityCounts,
+		Vulnerabilities: vulnerabilities,
 	}
 
-	content, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
-	}
-
-	return mcp.NewToolResultText(string(content)), nil
+	return structuredResult(result), nil
 }
 
 // handleGetVulnerabilityDetails gets detailed info about a specific CVE in a manifest
 func (k *KubescapeTool) handleGetVulnerabilityDetails(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	k = k.forCluster(ctx)
 	if k.initError != nil {
 		toolErr := errors.NewKubescapeError("get_vulnerability_details", k.initError)
 		return toolErr.ToMCPResult(), nil
@@ -647,11 +725,14 @@ func (k *KubescapeTool) handleGetVulnerabilityDetails(ctx context.Context, reque
 		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
 	}
 
-	return mcp.NewToolResultText(string(content)), nil
+	// Structured content must be an object, the text stays the array of matches
+	details := VulnerabilityDetails{CVEID: cveID, ManifestName: manifestName, Matches: matches}
+	return mcp.NewToolResultStructured(details, string(content)), nil
 }
 
 // handleListConfigurationScans lists configuration security scan results
 func (k *KubescapeTool) handleListConfigurationScans(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	k = k.forCluster(ctx)
 	if k.initError != nil {
 		toolErr := errors.NewKubescapeError("list_configuration_scans", k.initError)
 		return toolErr.ToMCPResult(), nil
@@ -671,31 +752,26 @@ func (k *KubescapeTool) handleListConfigurationScans(ctx context.Context, reques
 		return toolErr.ToMCPResult(), nil
 	}
 
-	configManifests := []map[string]interface{}{}
+	configManifests := []ConfigurationScanSummary{}
 	for _, manifest := range manifests.Items {
-		item := map[string]interface{}{
-			"namespace":     manifest.Namespace,
-			"manifest_name": manifest.Name,
-			"created_at":    manifest.CreationTimestamp.Format(time.RFC3339),
-		}
-		configManifests = append(configManifests, item)
+		configManifests = append(configManifests, ConfigurationScanSummary{
+			Namespace:    manifest.Namespace,
+			ManifestName: manifest.Name,
+			CreatedAt:    manifest.CreationTimestamp.Format(time.RFC3339),
+		})
 	}
 
-	result := map[string]interface{}{
-		"configuration_scans": configManifests,
-		"total_count":         len(configManifests),
+	result := ConfigurationScanList{
+		ConfigurationScans: configManifests,
+		TotalCount:         len(configManifests),
 	}
 
-	content, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
-	}
-
-	return mcp.NewToolResultText(string(content)), nil
+	return structuredResult(result), nil
 }
 
 // handleGetConfigurationScan gets details of a specific configuration scan
 func (k *KubescapeTool) handleGetConfigurationScan(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	k = k.forCluster(ctx)
 	if k.initError != nil {
 		toolErr := errors.NewKubescapeError("get_configuration_scan", k.initError)
 		return toolErr.ToMCPResult(), nil
@@ -716,16 +792,12 @@ func (k *KubescapeTool) handleGetConfigurationScan(ctx context.Context, request
 		return toolErr.ToMCPResult(), nil
 	}
 
-	content, err := json.MarshalIndent(manifest, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
-	}
-
-	return mcp.NewToolResultText(string(content)), nil
+	return structuredResult(manifest), nil
 }
 
 // handleListApplicationProfiles lists application profiles showing runtime behavior data
 func (k *KubescapeTool) handleListApplicationProfiles(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	k = k.forCluster(ctx)
 	if k.initError != nil {
 		toolErr := errors.NewKubescapeError("list_application_profiles", k.initError)
 		return toolErr.ToMCPResult(), nil
@@ -745,7 +817,7 @@ func (k *KubescapeTool) handleListApplicationProfiles(ctx context.Context, reque
 		return toolErr.ToMCPResult(), nil
 	}
 
-	profileList := []map[string]interface{}{}
+	profileList := []ApplicationProfileSummary{}
 	for _, profile := range profiles.Items {
 		// Summarize what data is captured per container
 		containersCount := len(profile.Spec.Containers)
@@ -766,41 +838,36 @@ func (k *KubescapeTool) handleListApplicationProfiles(ctx context.Context, reque
 			totalEndpoints += len(c.Endpoints)
 		}
 
-		profileMap := map[string]interface{}{
-			"namespace":                  profile.Namespace,
-			"name":                       profile.Name,
-			"containers_count":           containersCount,
-			"init_containers_count":      initContainersCount,
-			"ephemeral_containers_count": ephemeralContainersCount,
-			"total_execs":                totalExecs,
-			"total_opens":                totalOpens,
-			"total_syscalls":             totalSyscalls,
-			"total_capabilities":         totalCapabilities,
-			"total_endpoints":            totalEndpoints,
-			"created_at":                 profile.CreationTimestamp.Format(time.RFC3339),
-		}
-		profileList = append(profileList, profileMap)
-	}
-
-	result := map[string]interface{}{
-		"application_profiles": profileList,
-		"total_count":          len(profileList),
-		"description": "ApplicationProfiles capture runtime behavior of workloads including: " +
+		profileList = append(profileList, ApplicationProfileSummary{
+			Namespace:                profile.Namespace,
+			Name:                     profile.Name,
+			ContainersCount:          containersCount,
+			InitContainersCount:      initContainersCount,
+			EphemeralContainersCount: ephemeralContainersCount,
+			TotalExecs:               totalExecs,
+			TotalOpens:               totalOpens,
+			TotalSyscalls:            totalSyscalls,
+			TotalCapabilities:        totalCapabilities,
+			TotalEndpoints:           totalEndpoints,
+			CreatedAt:                profile.CreationTimestamp.Format(time.RFC3339),
+		})
+	}
+
+	result := ApplicationProfileList{
+		ApplicationProfiles: profileList,
+		TotalCount:          len(profileList),
+		Description: "ApplicationProfiles capture runtime behavior of workloads including: " +
 			"executed processes (Execs), file access patterns (Opens), system calls (Syscalls), " +
 			"Linux capabilities used, and HTTP endpoints accessed. " +
 			"Use this data to prioritize vulnerabilities - a CVE in an unused package is lower priority than one in an actively running process.",
 	}
 
-	content, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
-	}
-
-	return mcp.NewToolResultText(string(content)), nil
+	return structuredResult(result), nil
 }
 
 // handleGetApplicationProfile gets detailed runtime behavior for a specific workload
 func (k *KubescapeTool) handleGetApplicationProfile(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	k = k.forCluster(ctx)
 	if k.initError != nil {
 		toolErr := errors.NewKubescapeError("get_application_profile", k.initError)
 		return toolErr.ToMCPResult(), nil
@@ -825,58 +892,39 @@ func (k *KubescapeTool) handleGetApplicationProfile(ctx context.Context, request
 	}
 
 	// Build detailed response with container behaviors
-	containers := []map[string]interface{}{}
+	containers := []ContainerBehavior{}
 	for _, c := range profile.Spec.Containers {
-		containerInfo := map[string]interface{}{
-			"name":         c.Name,
-			"execs":        c.Execs,
-			"opens":        c.Opens,
-			"syscalls":     c.Syscalls,
-			"capabilities": c.Capabilities,
-			"endpoints":    c.Endpoints,
-		}
+		containerInfo := newContainerBehavior(c)
 		if c.SeccompProfile.Name != "" || c.SeccompProfile.Path != "" {
-			containerInfo["seccomp_profile"] = c.SeccompProfile
+			containerInfo.SeccompProfile = &c.SeccompProfile
 		}
 		containers = append(containers, containerInfo)
 	}
 
-	initContainers := []map[string]interface{}{}
+	initContainers := []ContainerBehavior{}
 	for _, c := range profile.Spec.InitContainers {
-		containerInfo := map[string]interface{}{
-			"name":         c.Name,
-			"execs":        c.Execs,
-			"opens":        c.Opens,
-			"syscalls":     c.Syscalls,
-			"capabilities": c.Capabilities,
-			"endpoints":    c.Endpoints,
-		}
-		initContainers = append(initContainers, containerInfo)
+		initContainers = append(initContainers, newContainerBehavior(c))
 	}
 
-	result := map[string]interface{}{
-		"namespace":       namespace,
-		"name":            name,
-		"containers":      containers,
-		"init_containers": initContainers,
-		"annotations":     profile.Annotations,
-		"labels":          profile.Labels,
-		"description": "This ApplicationProfile shows what the workload containers actually execute at runtime. " +
+	result := ApplicationProfileDetails{
+		Namespace:      namespace,
+		Name:           name,
+		Containers:     containers,
+		InitContainers: initContainers,
+		Annotations:    profile.Annotations,
+		Labels:         profile.Labels,
+		Description: "This ApplicationProfile shows what the workload containers actually execute at runtime. " +
 			"Execs: processes that run; Opens: files read/written; Syscalls: kernel-level operations; " +
 			"Capabilities: special Linux privileges; Endpoints: HTTP APIs called. " +
 			"Compare this with vulnerability findings to prioritize remediation - focus on CVEs affecting actively used components.",
 	}
 
-	content, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
-	}
-
-	return mcp.NewToolResultText(string(content)), nil
+	return structuredResult(result), nil
 }
 
 // handleListNetworkNeighborhoods lists network communication patterns for workloads
 func (k *KubescapeTool) handleListNetworkNeighborhoods(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	k = k.forCluster(ctx)
 	if k.initError != nil {
 		toolErr := errors.NewKubescapeError("list_network_neighborhoods", k.initError)
 		return toolErr.ToMCPResult(), nil
@@ -896,7 +944,7 @@ func (k *KubescapeTool) handleListNetworkNeighborhoods(ctx context.Context, requ
 		return toolErr.ToMCPResult(), nil
 	}
 
-	neighborhoodList := []map[string]interface{}{}
+	neighborhoodList := []NetworkNeighborhoodSummary{}
 	for _, nn := range neighborhoods.Items {
 		totalIngress := 0
 		totalEgress := 0
@@ -905,36 +953,31 @@ func (k *KubescapeTool) handleListNetworkNeighborhoods(ctx context.Context, requ
 			totalEgress += len(c.Egress)
 		}
 
-		nnMap := map[string]interface{}{
-			"namespace":        nn.Namespace,
-			"name":             nn.Name,
-			"containers_count": len(nn.Spec.Containers),
-			"total_ingress":    totalIngress,
-			"total_egress":     totalEgress,
-			"created_at":       nn.CreationTimestamp.Format(time.RFC3339),
-		}
-		neighborhoodList = append(neighborhoodList, nnMap)
+		neighborhoodList = append(neighborhoodList, NetworkNeighborhoodSummary{
+			Namespace:       nn.Namespace,
+			Name:            nn.Name,
+			ContainersCount: len(nn.Spec.Containers),
+			TotalIngress:    totalIngress,
+			TotalEgress:     totalEgress,
+			CreatedAt:       nn.CreationTimestamp.Format(time.RFC3339),
+		})
 	}
 
-	result := map[string]interface{}{
-		"network_neighborhoods": neighborhoodList,
-		"total_count":           len(neighborhoodList),
-		"description": "NetworkNeighborhoods capture actual network communication patterns of workloads. " +
+	result := NetworkNeighborhoodList{
+		NetworkNeighborhoods: neighborhoodList,
+		TotalCount:           len(neighborhoodList),
+		Description: "NetworkNeighborhoods capture actual network communication patterns of workloads. " +
 			"Ingress: connections coming INTO the workload; Egress: connections going OUT from the workload. " +
 			"Includes DNS names, IP addresses, ports, and protocols. " +
 			"Use this data to understand attack surface and prioritize network-related security findings.",
 	}
 
-	content, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
-	}
-
-	return mcp.NewToolResultText(string(content)), nil
+	return structuredResult(result), nil
 }
 
 // handleGetNetworkNeighborhood gets detailed network connections for a specific workload
 func (k *KubescapeTool) handleGetNetworkNeighborhood(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	k = k.forCluster(ctx)
 	if k.initError != nil {
 		toolErr := errors.NewKubescapeError("get_network_neighborhood", k.initError)
 		return toolErr.ToMCPResult(), nil
@@ -959,83 +1002,290 @@ func (k *KubescapeTool) handleGetNetworkNeighborhood(ctx context.Context, reques
 	}
 
 	// Build detailed response with container network data
-	containers := []map[string]interface{}{}
+	containers := []ContainerConnections{}
 	for _, c := range nn.Spec.Containers {
-		// Format ingress connections
-		ingressList := []map[string]interface{}{}
+		container := ContainerConnections{Name: c.Name, Ingress: []Connection{}, Egress: []Connection{}}
 		for _, ing := range c.Ingress {
-			ingressInfo := map[string]interface{}{
-				"identifier": ing.Identifier,
-				"type":       ing.Type,
-			}
-			if ing.DNS != "" {
-				ingressInfo["dns"] = ing.DNS
-			}
-			if len(ing.Ports) > 0 {
-				ingressInfo["ports"] = ing.Ports
-			}
-			if len(ing.IPAddress) > 0 {
-				ingressInfo["ip_address"] = ing.IPAddress
-			}
-			if ing.PodSelector != nil {
-				ingressInfo["pod_selector"] = ing.PodSelector
-			}
-			if ing.NamespaceSelector != nil {
-				ingressInfo["namespace_selector"] = ing.NamespaceSelector
-			}
-			ingressList = append(ingressList, ingressInfo)
+			container.Ingress = append(container.Ingress, newConnection(ing))
 		}
-
-		// Format egress connections
-		egressList := []map[string]interface{}{}
 		for _, egr := range c.Egress {
-			egressInfo := map[string]interface{}{
-				"identifier": egr.Identifier,
-				"type":       egr.Type,
-			}
-			if egr.DNS != "" {
-				egressInfo["dns"] = egr.DNS
-			}
-			if len(egr.Ports) > 0 {
-				egressInfo["ports"] = egr.Ports
-			}
-			if len(egr.IPAddress) > 0 {
-				egressInfo["ip_address"] = egr.IPAddress
+			container.Egress = append(container.Egress, newConnection(egr))
+		}
+		containers = append(containers, container)
+	}
+
+	result := NetworkNeighborhoodDetails{
+		Namespace:   namespace,
+		Name:        name,
+		Containers:  containers,
+		Annotations: nn.Annotations,
+		Labels:      nn.Labels,
+		Description: "This NetworkNeighborhood shows actual network connections observed for this workload. " +
+			"Ingress connections show what talks TO this workload. Egress connections show what this workload talks TO. " +
+			"Use this to verify if a workload with a vulnerability is actually exposed to the network.",
+	}
+
+	return structuredResult(result), nil
+}
+
+// handleListSBOMs lists the image SBOMs with their package counts
+func (k *KubescapeTool) handleListSBOMs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	k = k.forCluster(ctx)
+	if k.initError != nil {
+		toolErr := errors.NewKubescapeError("list_sboms", k.initError)
+		return toolErr.ToMCPResult(), nil
+	}
+
+	namespace := mcp.ParseString(request, "namespace", "")
+
+	queryNamespace := metav1.NamespaceAll
+	if namespace != "" {
+		queryNamespace = namespace
+	}
+
+	sboms := []SBOMSummary{}
+	err := k.forEachSBOM(ctx, queryNamespace, func(sbom *v1beta1.SBOMSyft) {
+		packageCount := len(sbom.Spec.Syft.Artifacts)
+		if packageCount == 0 {
+			// Lists may leave out the catalog, whose size is then only in the annotations
+			packageCount, _ = strconv.Atoi(sbom.Annotations[helpersv1.ResourceSizeMetadataKey])
+		}
+		sboms = append(sboms, SBOMSummary{
+			Namespace:    sbom.Namespace,
+			Name:         sbom.Name,
+			ImageID:      sbom.Annotations[helpersv1.ImageIDMetadataKey],
+			ImageTag:     sbom.Annotations[helpersv1.ImageTagMetadataKey],
+			PackageCount: packageCount,
+			CreatedAt:    sbom.CreationTimestamp.Format(time.RFC3339),
+		})
+	})
+	if err != nil {
+		toolErr := errors.NewKubescapeError("list_sboms", err).
+			WithContext("namespace", namespace)
+		return toolErr.ToMCPResult(), nil
+	}
+
+	result := SBOMList{
+		SBOMs:      sboms,
+		TotalCount: len(sboms),
+	}
+
+	return structuredResult(result), nil
+}
+
+// handleGetSBOM summarizes the packages and licenses of an SBOM and returns a page of its packages
+func (k *KubescapeTool) handleGetSBOM(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	k = k.forCluster(ctx)
+	if k.initError != nil {
+		toolErr := errors.NewKubescapeError("get_sbom", k.initError)
+		return toolErr.ToMCPResult(), nil
+	}
+
+	namespace := mcp.ParseString(request, "namespace", defaultKubescapeNamespace)
+	name := mcp.ParseString(request, "name", "")
+	offset := mcp.ParseInt(request, "offset", 0)
+	limit := mcp.ParseInt(request, "limit", defaultSBOMPackageLimit)
+
+	if name == "" {
+		return mcp.NewToolResultError("name parameter is required"), nil
+	}
+	if offset < 0 {
+		return mcp.NewToolResultError("offset must not be negative"), nil
+	}
+	if limit <= 0 || limit > maxSBOMPackageLimit {
+		return mcp.NewToolResultError(fmt.Sprintf("limit must be between 1 and %d", maxSBOMPackageLimit)), nil
+	}
+
+	sbom, err := k.spdxClient.SBOMSyfts(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		toolErr := errors.NewKubescapeError("get_sbom", err).
+			WithContext("namespace", namespace).
+			WithContext("name", name)
+		return toolErr.ToMCPResult(), nil
+	}
+
+	document := sbom.Spec.Syft
+	result := SBOMDetails{
+		Namespace: namespace,
+		Name:      name,
+		ImageID:   sbom.Annotations[helpersv1.ImageIDMetadataKey],
+		ImageTag:  sbom.Annotations[helpersv1.ImageTagMetadataKey],
+		Source:    strings.TrimSpace(document.SyftSource.Name + " " + document.SyftSource.Version),
+		Distro:    strings.TrimSpace(document.Distro.ID + " " + document.Distro.VersionID),
+		Offset:    offset,
+	}
+	packages := summarizeSBOM(document, &result)
+
+	end := min(offset+limit, len(packages))
+	result.Packages = []SBOMPackage{}
+	if offset < end {
+		result.Packages = packages[offset:end]
+	}
+	if end < len(packages) {
+		result.NextOffset = &end
+	}
+
+	return structuredResult(result), nil
+}
+
+// handleFindPackage searches the packages of every SBOM and lists the workloads running the
+// images that ship them
+func (k *KubescapeTool) handleFindPackage(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	k = k.forCluster(ctx)
+	if k.initError != nil {
+		toolErr := errors.NewKubescapeError("find_package", k.initError)
+		return toolErr.ToMCPResult(), nil
+	}
+
+	namespace := mcp.ParseString(request, "namespace", "")
+	name := strings.ToLower(mcp.ParseString(request, "name", ""))
+	version := mcp.ParseString(request, "version", "")
+	purl := strings.ToLower(mcp.ParseString(request, "purl", ""))
+
+	if name == "" && purl == "" {
+		return mcp.NewToolResultError("name or purl parameter is required"), nil
+	}
+
+	queryNamespace := metav1.NamespaceAll
+	if namespace != "" {
+		queryNamespace = namespace
+	}
+
+	result := PackageSearchResult{Matches: []PackageMatch{}}
+	err := k.forEachSBOM(ctx, queryNamespace, func(sbom *v1beta1.SBOMSyft) {
+		if len(sbom.Spec.Syft.Artifacts) == 0 {
+			// Lists may leave out the catalog, so read the SBOM itself
+			full, err := k.spdxClient.SBOMSyfts(sbom.Namespace).Get(ctx, sbom.Name, metav1.GetOptions{})
+			if err != nil {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("failed to read SBOM %s/%s: %v", sbom.Namespace, sbom.Name, err))
+				return
 			}
-			if egr.PodSelector != nil {
-				egressInfo["pod_selector"] = egr.PodSelector
+			sbom = full
+		}
+		result.SBOMsSearched++
+
+		match := PackageMatch{
+			Namespace: sbom.Namespace,
+			SBOMName:  sbom.Name,
+			ImageID:   sbom.Annotations[helpersv1.ImageIDMetadataKey],
+			ImageTag:  sbom.Annotations[helpersv1.ImageTagMetadataKey],
+			Packages:  []SBOMPackage{},
+			Workloads: []Workload{},
+		}
+		for _, artifact := range sbom.Spec.Syft.Artifacts {
+			if packageMatches(artifact, name, version, purl) {
+				match.Packages = append(match.Packages, newSBOMPackage(artifact))
 			}
-			if egr.NamespaceSelector != nil {
-				egressInfo["namespace_selector"] = egr.NamespaceSelector
+		}
+		if len(match.Packages) > 0 {
+			result.Matches = append(result.Matches, match)
+			result.TotalPackages += len(match.Packages)
+		}
+	})
+	if err != nil {
+		toolErr := errors.NewKubescapeError("find_package", err).
+			WithContext("namespace", namespace)
+		return toolErr.ToMCPResult(), nil
+	}
+
+	if len(result.Matches) > 0 {
+		workloads, err := k.workloadsByImage(ctx)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("failed to list the workloads running the images: %v", err))
+		}
+		for i := range result.Matches {
+			if running, ok := workloads[imageDigest(result.Matches[i].ImageID)]; ok {
+				result.Matches[i].Workloads = running
 			}
-			egressList = append(egressList, egressInfo)
 		}
+	}
 
-		containerInfo := map[string]interface{}{
-			"name":    c.Name,
-			"ingress": ingressList,
-			"egress":  egressList,
+	return structuredResult(result), nil
+}
+
+// forEachSBOM calls fn for every SBOM in namespace. SBOMs are listed a page at a time since
+// each holds the package catalog of a whole image.
+func (k *KubescapeTool) forEachSBOM(ctx context.Context, namespace string, fn func(*v1beta1.SBOMSyft)) error {
+	opts := metav1.ListOptions{Limit: sbomListPageSize}
+	for {
+		sboms, err := k.spdxClient.SBOMSyfts(namespace).List(ctx, opts)
+		if err != nil {
+			return err
 		}
-		containers = append(containers, containerInfo)
+		for i := range sboms.Items {
+			fn(&sboms.Items[i])
+		}
+		if sboms.Continue == "" {
+			return nil
+		}
+		opts.Continue = sboms.Continue
 	}
+}
 
-	result := map[string]interface{}{
-		"namespace":   namespace,
-		"name":        name,
-		"containers":  containers,
-		"annotations": nn.Annotations,
-		"labels":      nn.Labels,
-		"description": "This NetworkNeighborhood shows actual network connections observed for this workload. " +
-			"Ingress connections show what talks TO this workload. Egress connections show what this workload talks TO. " +
-			"Use this to verify if a workload with a vulnerability is actually exposed to the network.",
+// packageMatches reports whether pkg matches a search. name and purl are lowercase and match
+// anywhere in the package name and URL; version matches the version or a release of it, so
+// 2.14 matches 2.14.1 but not 2.140.
+func packageMatches(pkg v1beta1.SyftPackage, name, version, purl string) bool {
+	if name != "" && !strings.Contains(strings.ToLower(pkg.Name), name) {
+		return false
+	}
+	if purl != "" && !strings.Contains(strings.ToLower(pkg.PURL), purl) {
+		return false
+	}
+	if version != "" && pkg.Version != version && !strings.HasPrefix(pkg.Version, version+".") {
+		return false
 	}
+	return true
+}
 
-	content, err := json.MarshalIndent(result, "", "  ")
+// workloadsByImage returns the workloads running each image, by image digest
+func (k *KubescapeTool) workloadsByImage(ctx context.Context) (map[string][]Workload, error) {
+	pods, err := k.k8sClient.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
+		return nil, err
+	}
+
+	workloads := map[string][]Workload{}
+	seen := map[string]bool{}
+	for _, pod := range pods.Items {
+		kind, name := podWorkload(pod)
+		statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+		for _, status := range statuses {
+			digest := imageDigest(status.ImageID)
+			if digest == "" {
+				continue
+			}
+			workload := Workload{Namespace: pod.Namespace, Kind: kind, Name: name, Container: status.Name}
+			key := digest + "/" + workload.Namespace + "/" + workload.Kind + "/" + workload.Name + "/" + workload.Container
+			if !seen[key] {
+				seen[key] = true
+				workloads[digest] = append(workloads[digest], workload)
+			}
+		}
+	}
+	return workloads, nil
+}
+
+// podWorkload returns the kind and name of the workload that owns pod, following the
+// ReplicaSets of Deployments to their Deployment
+func podWorkload(pod corev1.Pod) (string, string) {
+	owner := metav1.GetControllerOf(&pod)
+	if owner == nil {
+		return "Pod", pod.Name
 	}
+	if hash := pod.Labels["pod-template-hash"]; owner.Kind == "ReplicaSet" && strings.HasSuffix(owner.Name, "-"+hash) {
+		return "Deployment", strings.TrimSuffix(owner.Name, "-"+hash)
+	}
+	return owner.Kind, owner.Name
+}
 
-	return mcp.NewToolResultText(string(content)), nil
+// imageDigest returns the digest of an image reference such as
+// docker.io/library/nginx@sha256:abc, or the reference itself when it has none
+func imageDigest(image string) string {
+	if _, digest, ok := strings.Cut(image, "@"); ok {
+		return digest
+	}
+	return image
 }
 
 // Helper function to truncate strings
@@ -1054,6 +1304,7 @@ func RegisterTools(s *server.MCPServer, kubeconfig string, readOnly bool) {
 	s.AddTool(mcp.NewTool("kubescape_check_health",
 		mcp.WithDescription("Check if Kubescape operator is installed and operational. Verifies namespace, operator pods, storage pods, CRDs, and scan data availability."),
 		mcp.WithString("namespace", mcp.Description("Namespace to check (default: kubescape)")),
+		mcp.WithOutputSchema[HealthCheckResult](),
 	), telemetry.AdaptToolHandler(telemetry.WithTracing("kubescape_check_health", tool.handleCheckHealth)))
 
 	// List vulnerability manifests
@@ -1061,6 +1312,7 @@ func RegisterTools(s *server.MCPServer, kubeconfig string, readOnly bool) {
 		mcp.WithDescription("List vulnerability manifests from Kubescape operator. Returns vulnerability scan results at image or workload level."),
 		mcp.WithString("namespace", mcp.Description("Filter by namespace (optional, defaults to all namespaces)")),
 		mcp.WithString("level", mcp.Description("Type of manifests to list: 'image', 'workload', or 'both' (default: both)")),
+		mcp.WithOutputSchema[VulnerabilityManifestList](),
 	), telemetry.AdaptToolHandler(telemetry.WithTracing("kubescape_list_vulnerability_manifests", tool.handleListVulnerabilityManifests)))
 
 	// List vulnerabilities in a manifest
@@ -1068,6 +1320,7 @@ func RegisterTools(s *server.MCPServer, kubeconfig string, readOnly bool) {
 		mcp.WithDescription("List all CVEs/vulnerabilities found in a specific vulnerability manifest. Returns severity summary and vulnerability details."),
 		mcp.WithString("namespace", mcp.Description("Namespace of the manifest (default: kubescape)")),
 		mcp.WithString("manifest_name", mcp.Description("Name of the vulnerability manifest"), mcp.Required()),
+		mcp.WithOutputSchema[VulnerabilityList](),
 	), telemetry.AdaptToolHandler(telemetry.WithTracing("kubescape_list_vulnerabilities", tool.handleListVulnerabilitiesInManifest)))
 
 	// Get detailed vulnerability info
@@ -1076,12 +1329,14 @@ func RegisterTools(s *server.MCPServer, kubeconfig string, readOnly bool) {
 		mcp.WithString("namespace", mcp.Description("Namespace of the manifest (default: kubescape)")),
 		mcp.WithString("manifest_name", mcp.Description("Name of the vulnerability manifest"), mcp.Required()),
 		mcp.WithString("cve_id", mcp.Description("CVE identifier (e.g., CVE-2023-12345)"), mcp.Required()),
+		mcp.WithOutputSchema[VulnerabilityDetails](),
 	), telemetry.AdaptToolHandler(telemetry.WithTracing("kubescape_get_vulnerability_details", tool.handleGetVulnerabilityDetails)))
 
 	// List configuration scans
 	s.AddTool(mcp.NewTool("kubescape_list_configuration_scans",
 		mcp.WithDescription("List configuration security scan results from Kubescape operator. Shows workloads that have been scanned for security misconfigurations."),
 		mcp.WithString("namespace", mcp.Description("Filter by namespace (optional, defaults to all namespaces)")),
+		mcp.WithOutputSchema[ConfigurationScanList](),
 	), telemetry.AdaptToolHandler(telemetry.WithTracing("kubescape_list_configuration_scans", tool.handleListConfigurationScans)))
 
 	// Get configuration scan details
@@ -1089,6 +1344,7 @@ func RegisterTools(s *server.MCPServer, kubeconfig string, readOnly bool) {
 		mcp.WithDescription("Get detailed configuration security scan results for a specific workload, including failed controls and remediation guidance."),
 		mcp.WithString("namespace", mcp.Description("Namespace of the scan (default: kubescape)")),
 		mcp.WithString("manifest_name", mcp.Description("Name of the configuration scan manifest"), mcp.Required()),
+		mcp.WithOutputSchema[v1beta1.WorkloadConfigurationScan](),
 	), telemetry.AdaptToolHandler(telemetry.WithTracing("kubescape_get_configuration_scan", tool.handleGetConfigurationScan)))
 
 	// List application profiles (runtime observability)
@@ -1098,6 +1354,7 @@ func RegisterTools(s *server.MCPServer, kubeconfig string, readOnly bool) {
 			"Use this data to prioritize vulnerability findings - a CVE in an unused package is lower priority than one in an actively running process. "+
 			"Requires 'capabilities.runtimeObservability=enable' in Kubescape Helm chart."),
 		mcp.WithString("namespace", mcp.Description("Filter by namespace (optional, defaults to all namespaces)")),
+		mcp.WithOutputSchema[ApplicationProfileList](),
 	), telemetry.AdaptToolHandler(telemetry.WithTracing("kubescape_list_application_profiles", tool.handleListApplicationProfiles)))
 
 	// Get application profile details
@@ -1107,6 +1364,7 @@ func RegisterTools(s *server.MCPServer, kubeconfig string, readOnly bool) {
 			"Compare with CVE findings to prioritize remediation - focus on vulnerabilities affecting actively used components."),
 		mcp.WithString("namespace", mcp.Description("Namespace of the profile"), mcp.Required()),
 		mcp.WithString("name", mcp.Description("Name of the application profile"), mcp.Required()),
+		mcp.WithOutputSchema[ApplicationProfileDetails](),
 	), telemetry.AdaptToolHandler(telemetry.WithTracing("kubescape_get_application_profile", tool.handleGetApplicationProfile)))
 
 	// List network neighborhoods (runtime observability)
@@ -1117,6 +1375,7 @@ func RegisterTools(s *server.MCPServer, kubeconfig string, readOnly bool) {
 			"Use this to understand attack surface and prioritize network-related security findings. "+
 			"Requires 'capabilities.runtimeObservability=enable' in Kubescape Helm chart."),
 		mcp.WithString("namespace", mcp.Description("Filter by namespace (optional, defaults to all namespaces)")),
+		mcp.WithOutputSchema[NetworkNeighborhoodList](),
 	), telemetry.AdaptToolHandler(telemetry.WithTracing("kubescape_list_network_neighborhoods", tool.handleListNetworkNeighborhoods)))
 
 	// Get network neighborhood details
@@ -1125,14 +1384,51 @@ func RegisterTools(s *server.MCPServer, kubeconfig string, readOnly bool) {
 			"with DNS names, IPs, ports, and protocols. Use this to verify if a workload with a vulnerability is actually exposed to the network."),
 		mcp.WithString("namespace", mcp.Description("Namespace of the network neighborhood"), mcp.Required()),
 		mcp.WithString("name", mcp.Description("Name of the network neighborhood"), mcp.Required()),
+		mcp.WithOutputSchema[NetworkNeighborhoodDetails](),
 	), telemetry.AdaptToolHandler(telemetry.WithTracing("kubescape_get_network_neighborhood", tool.handleGetNetworkNeighborhood)))
 
-	// NOTE: SBOM tools are disabled as they return too much data for LLM context windows.
-	// SBOMs contain detailed package information that can be very large.
-	// To enable in the future, uncomment the handlers and tool registrations below.
-	//
-	// s.AddTool(mcp.NewTool("kubescape_list_sboms", ...))
-	// s.AddTool(mcp.NewTool("kubescape_get_sbom", ...))
+	// Cluster-wide vulnerability summary
+	s.AddTool(mcp.NewTool("kubescape_vulnerability_summary",
+		mcp.WithDescription("Aggregate the CVEs of all workloads: severity counts overall and by namespace, fixable versus unfixable findings, "+
+			"and the top CVEs by blast radius (number of affected workload containers). "+
+			"With prioritize, joins ApplicationProfiles (did the container execute or open a file of the vulnerable package?) "+
+			"and NetworkNeighborhoods (does the container receive connections from outside the cluster?) "+
+			"and ranks CVEs in used packages of exposed workloads first."),
+		mcp.WithString("namespace", mcp.Description("Only summarize workloads in this namespace (optional, defaults to all namespaces)")),
+		mcp.WithNumber("top", mcp.Description(fmt.Sprintf("Number of top CVEs to return (default: %d, max: %d)", defaultTopCVEs, maxTopCVEs))),
+		mcp.WithBoolean("prioritize", mcp.Description("Rank CVEs by runtime usage and network exposure (requires runtime observability, default: false)")),
+		mcp.WithOutputSchema[ClusterVulnerabilitySummary](),
+	), telemetry.AdaptToolHandler(telemetry.WithTracing("kubescape_vulnerability_summary", tool.handleVulnerabilitySummary)))
+
+	// List SBOMs
+	s.AddTool(mcp.NewTool("kubescape_list_sboms",
+		mcp.WithDescription("List the SBOMs (software bills of materials) Kubescape generated for container images, with their package counts. "+
+			"Use kubescape_get_sbom for the packages of one image and kubescape_find_package to search packages across all images."),
+		mcp.WithString("namespace", mcp.Description("Filter by namespace (optional, defaults to all namespaces)")),
+		mcp.WithOutputSchema[SBOMList](),
+	), telemetry.AdaptToolHandler(telemetry.WithTracing("kubescape_list_sboms", tool.handleListSBOMs)))
+
+	// Get SBOM summary and packages
+	s.AddTool(mcp.NewTool("kubescape_get_sbom",
+		mcp.WithDescription("Summarize the SBOM of a container image: package counts by type and ecosystem, and the licenses in use. "+
+			"Lists the packages a page at a time, sorted by name; pass next_offset as offset for the next page."),
+		mcp.WithString("namespace", mcp.Description("Namespace of the SBOM (default: kubescape)")),
+		mcp.WithString("name", mcp.Description("Name of the SBOM"), mcp.Required()),
+		mcp.WithNumber("offset", mcp.Description("Index of the first package to list (default: 0)")),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Number of packages to list (default: %d, max: %d)", defaultSBOMPackageLimit, maxSBOMPackageLimit))),
+		mcp.WithOutputSchema[SBOMDetails](),
+	), telemetry.AdaptToolHandler(telemetry.WithTracing("kubescape_get_sbom", tool.handleGetSBOM)))
+
+	// Find a package across SBOMs
+	s.AddTool(mcp.NewTool("kubescape_find_package",
+		mcp.WithDescription("Search the packages of all SBOMs by name, version or package URL, and list the workloads running the images that ship them. "+
+			"For example, name 'log4j-core' with version '2.14' finds the workloads shipping log4j 2.14.x."),
+		mcp.WithString("name", mcp.Description("Part of the package name, case-insensitive (name or purl is required)")),
+		mcp.WithString("version", mcp.Description("Package version or version prefix, such as 2.14 for 2.14.1 (optional)")),
+		mcp.WithString("purl", mcp.Description("Part of the package URL, case-insensitive, such as pkg:maven/org.apache.logging.log4j (name or purl is required)")),
+		mcp.WithString("namespace", mcp.Description("Namespace of the SBOMs to search (optional, defaults to all namespaces)")),
+		mcp.WithOutputSchema[PackageSearchResult](),
+	), telemetry.AdaptToolHandler(telemetry.WithTracing("kubescape_find_package", tool.handleFindPackage)))
 }
 
 // Interfaces for testing - allows mocking the Kubernetes clients
@@ -1147,9 +1443,10 @@ type KubescapeToolInterface interface {
 	HandleGetApplicationProfile(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
 	HandleListNetworkNeighborhoods(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
 	HandleGetNetworkNeighborhood(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
-	// NOTE: SBOM handlers are disabled as they return too much data for LLM context
-	// HandleListSBOMs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
-	// HandleGetSBOM(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+	HandleVulnerabilitySummary(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+	HandleListSBOMs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+	HandleGetSBOM(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+	HandleFindPackage(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
 }
 
 // Ensure KubescapeTool implements the interface
@@ -1196,11 +1493,18 @@ func (k *KubescapeTool) HandleGetNetworkNeighborhood(ctx context.Context, reques
 	return k.handleGetNetworkNeighborhood(ctx, request)
 }
 
-// NOTE: SBOM handlers are disabled as they return too much data for LLM context
-// func (k *KubescapeTool) HandleListSBOMs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-// 	return k.handleListSBOMs(ctx, request)
-// }
-//
-// func (k *KubescapeTool) HandleGetSBOM(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-// 	return k.handleGetSBOM(ctx, request)
-// }
+func (k *KubescapeTool) HandleVulnerabilitySummary(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return k.handleVulnerabilitySummary(ctx, request)
+}
+
+func (k *KubescapeTool) HandleListSBOMs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return k.handleListSBOMs(ctx, request)
+}
+
+func (k *KubescapeTool) HandleGetSBOM(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return k.handleGetSBOM(ctx, request)
+}
+
+func (k *KubescapeTool) HandleFindPackage(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return k.handleFindPackage(ctx, request)
+}
@@ -6,6 +6,8 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/kagent-dev/tools/internal/clusters"
+	"github.com/kagent-dev/tools/internal/credentials"
 	"github.com/kubescape/storage/pkg/apis/softwarecomposition/v1beta1"
 	kubescapefake "github.com/kubescape/storage/pkg/generated/clientset/versioned/fake"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -17,6 +19,7 @@ import (
 	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
 )
 
 // Helper function to create a CallToolRequest with arguments
@@ -46,9 +49,8 @@ func TestRegisterTools(t *testing.T) {
 	})
 
 	// Verify tools are registered by checking the server has tools
-	// NOTE: SBOM tools are disabled (too large for LLM context), so we expect 10 tools
 	tools := s.ListTools()
-	assert.Len(t, tools, 10)
+	assert.Len(t, tools, 14)
 
 	expectedTools := map[string]bool{
 		"kubescape_check_health":                 false,
@@ -61,9 +63,10 @@ func TestRegisterTools(t *testing.T) {
 		"kubescape_get_application_profile":      false,
 		"kubescape_list_network_neighborhoods":   false,
 		"kubescape_get_network_neighborhood":     false,
-		// NOTE: SBOM tools disabled - too large for LLM context
-		// "kubescape_list_sboms":                   false,
-		// "kubescape_get_sbom":                     false,
+		"kubescape_vulnerability_summary":        false,
+		"kubescape_list_sboms":                   false,
+		"kubescape_get_sbom":                     false,
+		"kubescape_find_package":                 false,
 	}
 
 	for name := range tools {
@@ -75,6 +78,12 @@ func TestRegisterTools(t *testing.T) {
 	for name, found := range expectedTools {
 		assert.True(t, found, "Tool %s not found", name)
 	}
+
+	// Every tool declares the schema of its structured content
+	for name, tool := range tools {
+		assert.Equal(t, "object", tool.Tool.OutputSchema.Type, "Tool %s has no output schema", name)
+		assert.NotEmpty(t, tool.Tool.OutputSchema.Properties, "Tool %s has no output properties", name)
+	}
 }
 
 func TestHandleCheckHealth_AllComponentsHealthy(t *testing.T) {
@@ -117,7 +126,9 @@ func TestHandleCheckHealth_AllComponentsHealthy(t *testing.T) {
 		&apiextensionsv1.CustomResourceDefinition{
 			ObjectMeta: metav1.ObjectMeta{Name: networkNeighborhoodsCRD},
 		},
-		// NOTE: SBOM CRD check is disabled (SBOM tools are too large for LLM context)
+		&apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: sbomSyftsCRD},
+		},
 	)
 
 	spdxClient := kubescapefake.NewClientset(
@@ -145,7 +156,12 @@ func TestHandleCheckHealth_AllComponentsHealthy(t *testing.T) {
 				Namespace: "kubescape",
 			},
 		},
-		// NOTE: SBOM data check is disabled (SBOM tools are too large for LLM context)
+		&v1beta1.SBOMSyft{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-sbom",
+				Namespace: "kubescape",
+			},
+		},
 	)
 
 	tool := NewKubescapeToolWithClients(k8sClient, apiExtClient, spdxClient.SpdxV1beta1())
@@ -171,9 +187,8 @@ func TestHandleCheckHealth_AllComponentsHealthy(t *testing.T) {
 	assert.Equal(t, "ok", health.Checks["application_profiles_data"].Status)
 	assert.Equal(t, "ok", health.Checks["network_neighborhoods_crd"].Status)
 	assert.Equal(t, "ok", health.Checks["network_neighborhoods_data"].Status)
-	// NOTE: SBOM checks are disabled (SBOM tools are too large for LLM context)
-	// assert.Equal(t, "ok", health.Checks["sbom_crd"].Status)
-	// assert.Equal(t, "ok", health.Checks["sbom_data"].Status)
+	assert.Equal(t, "ok", health.Checks["sbom_crd"].Status)
+	assert.Equal(t, "ok", health.Checks["sbom_data"].Status)
 	assert.Equal(t, "Kubescape is fully operational", health.Summary)
 }
 
@@ -404,6 +419,46 @@ func TestHandleCheckHealth_InitError(t *testing.T) {
 	assert.True(t, result.IsError)
 }
 
+func TestHandleCheckHealth_ClusterConfigError(t *testing.T) {
+	tool := NewKubescapeToolWithClients(kubefake.NewClientset(), nil, nil)
+	ctx := clusters.WithCluster(context.Background(), clusters.Cluster{
+		Name:       "prod",
+		Context:    "prod",
+		Kubeconfig: "/nonexistent/kubeconfig",
+	})
+
+	result, err := tool.HandleCheckHealth(ctx, makeRequest(nil))
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.True(t, result.IsError)
+	assert.Contains(t, getResultText(result), "cluster prod")
+
+	// The per-cluster tool is cached and the default tool is left untouched
+	assert.Same(t, tool.forCluster(ctx), tool.forCluster(ctx))
+	assert.Same(t, tool, tool.forCluster(context.Background()))
+}
+
+func TestForClusterTokenPassthrough(t *testing.T) {
+	tool := newKubescapeToolForConfig(&rest.Config{Host: "https://prod.example.com:6443", BearerToken: "server-token"})
+	require.NoError(t, tool.initError)
+
+	caller := tool.forCluster(credentials.WithToken(context.Background(), "caller-token"))
+	require.NoError(t, caller.initError)
+	assert.Equal(t, "caller-token", caller.config.BearerToken)
+	assert.Equal(t, "server-token", tool.config.BearerToken)
+
+	impersonating := tool.forCluster(credentials.WithImpersonation(context.Background(), credentials.Impersonation{User: "alice"}))
+	assert.Equal(t, "alice", impersonating.config.Impersonate.UserName)
+	assert.Equal(t, "server-token", impersonating.config.BearerToken)
+
+	// With passthrough on, a call without a token fails instead of using the server's credentials
+	t.Setenv(credentials.EnvTokenPassthrough, "true")
+	result, err := tool.HandleCheckHealth(context.Background(), makeRequest(nil))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, getResultText(result), "Bearer token required")
+}
+
 func TestHandleListVulnerabilityManifests_Success(t *testing.T) {
 	spdxClient := kubescapefake.NewClientset(
 		&v1beta1.VulnerabilityManifest{
@@ -616,6 +671,12 @@ func TestHandleGetVulnerabilityDetails_Success(t *testing.T) {
 
 	assert.Len(t, matches, 1)
 	assert.Equal(t, "CVE-2021-1234", matches[0].Vulnerability.ID)
+
+	details, ok := result.StructuredContent.(VulnerabilityDetails)
+	require.True(t, ok, "structured content is %T", result.StructuredContent)
+	assert.Equal(t, "CVE-2021-1234", details.CVEID)
+	assert.Equal(t, "test-manifest", details.ManifestName)
+	assert.Equal(t, matches, details.Matches)
 }
 
 func TestHandleGetVulnerabilityDetails_MissingManifestName(t *testing.T) {
@@ -959,6 +1020,14 @@ func TestHandleGetApplicationProfile_Success(t *testing.T) {
 	assert.Equal(t, "default", response["namespace"])
 	assert.Equal(t, "test-profile", response["name"])
 	assert.Contains(t, response["description"], "ApplicationProfile shows what the workload containers actually execute")
+
+	profile, ok := result.StructuredContent.(ApplicationProfileDetails)
+	require.True(t, ok, "structured content is %T", result.StructuredContent)
+	require.Len(t, profile.Containers, 1)
+	assert.Equal(t, "/bin/bash", profile.Containers[0].Execs[0].Path)
+	assert.Equal(t, []string{"read", "write"}, profile.Containers[0].Syscalls)
+	assert.Nil(t, profile.Containers[0].SeccompProfile)
+	assert.Empty(t, profile.InitContainers)
 }
 
 func TestHandleGetApplicationProfile_MissingName(t *testing.T) {
@@ -1137,6 +1206,17 @@ func TestHandleGetNetworkNeighborhood_Success(t *testing.T) {
 	assert.Equal(t, "default", response["namespace"])
 	assert.Equal(t, "test-nn", response["name"])
 	assert.Contains(t, response["description"], "NetworkNeighborhood shows actual network connections")
+
+	neighborhood, ok := result.StructuredContent.(NetworkNeighborhoodDetails)
+	require.True(t, ok, "structured content is %T", result.StructuredContent)
+	require.Len(t, neighborhood.Containers, 1)
+	assert.Equal(t, []Connection{{Identifier: "pod-1", Type: "internal"}}, neighborhood.Containers[0].Ingress)
+	assert.Equal(t, []Connection{{Identifier: "api.example.com", Type: "external", DNS: "api.example.com"}}, neighborhood.Containers[0].Egress)
+
+	// Empty fields stay out of the text, as before
+	egress := response["containers"].([]interface{})[0].(map[string]interface{})["egress"].([]interface{})[0].(map[string]interface{})
+	assert.NotContains(t, egress, "ports")
+	assert.NotContains(t, egress, "ip_address")
 }
 
 func TestHandleGetNetworkNeighborhood_MissingName(t *testing.T) {
@@ -1178,16 +1258,268 @@ func TestHandleGetNetworkNeighborhood_NotFound(t *testing.T) {
 	assert.True(t, result.IsError)
 }
 
-// NOTE: SBOM tests are disabled as SBOM tools are too large for LLM context windows.
-// The handlers still exist in the code but are not registered or exported.
-//
-// Tests for SBOM handlers - DISABLED
-//
-// func TestHandleListSBOMs_Success(t *testing.T) { ... }
-// func TestHandleListSBOMs_FilterByNamespace(t *testing.T) { ... }
-// func TestHandleListSBOMs_EmptyResults(t *testing.T) { ... }
-// func TestHandleListSBOMs_InitError(t *testing.T) { ... }
-// func TestHandleGetSBOM_Success(t *testing.T) { ... }
-// func TestHandleGetSBOM_MissingName(t *testing.T) { ... }
-// func TestHandleGetSBOM_MissingNamespace(t *testing.T) { ... }
-// func TestHandleGetSBOM_NotFound(t *testing.T) { ... }
+// newTestSBOM creates an image SBOM with the given packages
+func newTestSBOM(namespace, name, imageID string, packages ...v1beta1.PackageBasicData) *v1beta1.SBOMSyft {
+	sbom := &v1beta1.SBOMSyft{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Annotations: map[string]string{
+				"kubescape.io/image-id":  imageID,
+				"kubescape.io/image-tag": name + ":latest",
+			},
+		},
+	}
+	for _, pkg := range packages {
+		sbom.Spec.Syft.Artifacts = append(sbom.Spec.Syft.Artifacts, v1beta1.SyftPackage{PackageBasicData: pkg})
+	}
+	return sbom
+}
+
+var (
+	log4jPackage = v1beta1.PackageBasicData{
+		Name: "log4j-core", Version: "2.14.1", Type: "java-archive", Language: "java",
+		PURL:     "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1",
+		Licenses: v1beta1.Licenses{{Value: "Apache 2", SPDXExpression: "Apache-2.0"}},
+	}
+	opensslPackage = v1beta1.PackageBasicData{
+		Name: "openssl", Version: "3.0.2", Type: "deb",
+		PURL:     "pkg:deb/ubuntu/openssl@3.0.2",
+		Licenses: v1beta1.Licenses{{Value: "Apache-2.0"}, {Value: "Apache-2.0"}},
+	}
+	bashPackage = v1beta1.PackageBasicData{
+		Name: "bash", Version: "5.1", Type: "deb",
+		PURL: "pkg:deb/ubuntu/bash@5.1",
+	}
+)
+
+func TestHandleListSBOMs_Success(t *testing.T) {
+	listedOnly := newTestSBOM("kubescape", "redis", "docker.io/library/redis@sha256:def")
+	listedOnly.Annotations["kubescape.io/resource-size"] = "42"
+	spdxClient := kubescapefake.NewClientset(
+		newTestSBOM("kubescape", "app", "docker.io/library/app@sha256:abc", log4jPackage, opensslPackage),
+		listedOnly,
+	)
+
+	tool := NewKubescapeToolWithClients(nil, nil, spdxClient.SpdxV1beta1())
+
+	result, err := tool.HandleListSBOMs(context.Background(), makeRequest(nil))
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.False(t, result.IsError)
+
+	list, ok := result.StructuredContent.(SBOMList)
+	require.True(t, ok, "structured content is %T", result.StructuredContent)
+	assert.Equal(t, 2, list.TotalCount)
+	counts := map[string]int{}
+	for _, sbom := range list.SBOMs {
+		counts[sbom.Name] = sbom.PackageCount
+	}
+	assert.Equal(t, map[string]int{"app": 2, "redis": 42}, counts)
+
+	// The text carries no package catalog
+	assert.NotContains(t, getResultText(result), "log4j")
+}
+
+func TestHandleListSBOMs_FilterByNamespace(t *testing.T) {
+	spdxClient := kubescapefake.NewClientset(
+		newTestSBOM("kubescape", "app", "sha256:abc"),
+		newTestSBOM("other", "redis", "sha256:def"),
+	)
+
+	tool := NewKubescapeToolWithClients(nil, nil, spdxClient.SpdxV1beta1())
+
+	result, err := tool.HandleListSBOMs(context.Background(), makeRequest(map[string]interface{}{
+		"namespace": "other",
+	}))
+	require.NoError(t, err)
+	require.NotNil(t, result)
+
+	var response map[string]interface{}
+	err = json.Unmarshal([]byte(getResultText(result)), &response)
+	require.NoError(t, err)
+	assert.Equal(t, float64(1), response["total_count"])
+}
+
+func TestHandleListSBOMs_InitError(t *testing.T) {
+	tool := NewKubescapeToolWithError(errors.New("failed to connect"))
+
+	result, err := tool.HandleListSBOMs(context.Background(), makeRequest(nil))
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.True(t, result.IsError)
+}
+
+func TestHandleGetSBOM_Success(t *testing.T) {
+	sbom := newTestSBOM("kubescape", "app", "sha256:abc", opensslPackage, log4jPackage, bashPackage)
+	sbom.Spec.Syft.SyftSource = v1beta1.SyftSource{Name: "docker.io/library/app", Version: "sha256:abc"}
+	sbom.Spec.Syft.Distro = v1beta1.LinuxRelease{ID: "ubuntu", VersionID: "22.04"}
+	spdxClient := kubescapefake.NewClientset(sbom)
+
+	tool := NewKubescapeToolWithClients(nil, nil, spdxClient.SpdxV1beta1())
+
+	result, err := tool.HandleGetSBOM(context.Background(), makeRequest(map[string]interface{}{
+		"name":  "app",
+		"limit": 2,
+	}))
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.False(t, result.IsError)
+
+	details, ok := result.StructuredContent.(SBOMDetails)
+	require.True(t, ok, "structured content is %T", result.StructuredContent)
+	assert.Equal(t, "docker.io/library/app sha256:abc", details.Source)
+	assert.Equal(t, "ubuntu 22.04", details.Distro)
+	assert.Equal(t, 3, details.TotalPackages)
+	assert.Equal(t, map[string]int{"deb": 2, "java-archive": 1}, details.PackagesByType)
+	assert.Equal(t, map[string]int{"deb": 2, "maven": 1}, details.PackagesByEcosystem)
+	// A license declared twice by a package counts once
+	assert.Equal(t, []LicenseCount{{License: "Apache-2.0", Packages: 2}}, details.Licenses)
+	assert.Equal(t, 1, details.PackagesWithoutLicense)
+
+	// Packages are sorted by name and paged
+	require.Len(t, details.Packages, 2)
+	assert.Equal(t, "bash", details.Packages[0].Name)
+	assert.Equal(t, "log4j-core", details.Packages[1].Name)
+	require.NotNil(t, details.NextOffset)
+	assert.Equal(t, 2, *details.NextOffset)
+
+	result, err = tool.HandleGetSBOM(context.Background(), makeRequest(map[string]interface{}{
+		"name":   "app",
+		"offset": *details.NextOffset,
+		"limit":  2,
+	}))
+	require.NoError(t, err)
+	details = result.StructuredContent.(SBOMDetails)
+	require.Len(t, details.Packages, 1)
+	assert.Equal(t, "openssl", details.Packages[0].Name)
+	assert.Nil(t, details.NextOffset)
+}
+
+func TestHandleGetSBOM_InvalidParameters(t *testing.T) {
+	spdxClient := kubescapefake.NewClientset(newTestSBOM("kubescape", "app", "sha256:abc"))
+	tool := NewKubescapeToolWithClients(nil, nil, spdxClient.SpdxV1beta1())
+
+	tests := []struct {
+		name string
+		args map[string]interface{}
+		want string
+	}{
+		{name: "missing name", args: map[string]interface{}{}, want: "name parameter is required"},
+		{name: "negative offset", args: map[string]interface{}{"name": "app", "offset": -1}, want: "offset must not be negative"},
+		{name: "limit too large", args: map[string]interface{}{"name": "app", "limit": 5000}, want: "limit must be between"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tool.HandleGetSBOM(context.Background(), makeRequest(tt.args))
+			require.NoError(t, err)
+			require.NotNil(t, result)
+			assert.True(t, result.IsError)
+			assert.Contains(t, getResultText(result), tt.want)
+		})
+	}
+}
+
+func TestHandleGetSBOM_NotFound(t *testing.T) {
+	spdxClient := kubescapefake.NewClientset()
+	tool := NewKubescapeToolWithClients(nil, nil, spdxClient.SpdxV1beta1())
+
+	result, err := tool.HandleGetSBOM(context.Background(), makeRequest(map[string]interface{}{
+		"name": "nonexistent",
+	}))
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.True(t, result.IsError)
+}
+
+func TestHandleFindPackage_Success(t *testing.T) {
+	patched := log4jPackage
+	patched.Version = "2.17.0"
+	spdxClient := kubescapefake.NewClientset(
+		newTestSBOM("kubescape", "app", "docker.io/library/app@sha256:abc", log4jPackage, opensslPackage),
+		newTestSBOM("kubescape", "patched", "docker.io/library/patched@sha256:def", patched),
+	)
+
+	controller := true
+	//nolint:staticcheck // NewSimpleClientset is deprecated but NewClientset requires generated apply configs
+	k8sClient := kubefake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "app-7d9f8b-x2x4z",
+				Namespace: "shop",
+				Labels:    map[string]string{"pod-template-hash": "7d9f8b"},
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: "ReplicaSet", Name: "app-7d9f8b", Controller: &controller},
+				},
+			},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "app", ImageID: "docker-pullable://docker.io/library/app@sha256:abc"},
+				},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "shop"},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "other", ImageID: "docker.io/library/other@sha256:123"},
+				},
+			},
+		},
+	)
+
+	tool := NewKubescapeToolWithClients(k8sClient, nil, spdxClient.SpdxV1beta1())
+
+	result, err := tool.HandleFindPackage(context.Background(), makeRequest(map[string]interface{}{
+		"name":    "LOG4J",
+		"version": "2.14",
+	}))
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.False(t, result.IsError)
+
+	search, ok := result.StructuredContent.(PackageSearchResult)
+	require.True(t, ok, "structured content is %T", result.StructuredContent)
+	assert.Equal(t, 2, search.SBOMsSearched)
+	assert.Equal(t, 1, search.TotalPackages)
+	require.Len(t, search.Matches, 1)
+	assert.Equal(t, "app", search.Matches[0].SBOMName)
+	assert.Equal(t, "2.14.1", search.Matches[0].Packages[0].Version)
+	assert.Equal(t, []Workload{{Namespace: "shop", Kind: "Deployment", Name: "app", Container: "app"}}, search.Matches[0].Workloads)
+
+	// A purl search finds both versions
+	result, err = tool.HandleFindPackage(context.Background(), makeRequest(map[string]interface{}{
+		"purl": "pkg:maven/org.apache.logging.log4j/",
+	}))
+	require.NoError(t, err)
+	search = result.StructuredContent.(PackageSearchResult)
+	assert.Equal(t, 2, search.TotalPackages)
+	assert.Len(t, search.Matches, 2)
+}
+
+func TestHandleFindPackage_MissingQuery(t *testing.T) {
+	spdxClient := kubescapefake.NewClientset()
+	tool := NewKubescapeToolWithClients(nil, nil, spdxClient.SpdxV1beta1())
+
+	result, err := tool.HandleFindPackage(context.Background(), makeRequest(map[string]interface{}{
+		"version": "2.14",
+	}))
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.True(t, result.IsError)
+	assert.Contains(t, getResultText(result), "name or purl parameter is required")
+}
+
+func TestPackageMatches(t *testing.T) {
+	pkg := v1beta1.SyftPackage{PackageBasicData: log4jPackage}
+
+	assert.True(t, packageMatches(pkg, "log4j", "", ""))
+	assert.True(t, packageMatches(pkg, "log4j", "2.14.1", ""))
+	assert.True(t, packageMatches(pkg, "", "2", "log4j-core@"))
+	assert.False(t, packageMatches(pkg, "log4j", "2.1", ""))
+	assert.False(t, packageMatches(pkg, "openssl", "", ""))
+	assert.False(t, packageMatches(pkg, "", "", "pkg:npm/"))
+
+	assert.Equal(t, "maven", packageEcosystem(log4jPackage.PURL))
+	assert.Equal(t, "unknown", packageEcosystem(""))
+}
@@ -0,0 +1,411 @@
+package kubescape
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition/v1beta1"
+	"github.com/mark3labs/mcp-go/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VulnerabilityManifestSummary describes one vulnerability manifest
+type VulnerabilityManifestSummary struct {
+	Namespace             string `json:"namespace"`
+	ManifestName          string `json:"manifest_name"`
+	ImageLevel            bool   `json:"image_level" jsonschema_description:"Manifest covers every package of the image"`
+	WorkloadLevel         bool   `json:"workload_level" jsonschema_description:"Manifest is filtered to the packages the workload loads"`
+	ImageID               string `json:"image_id"`
+	ImageTag              string `json:"image_tag"`
+	WorkloadID            string `json:"workload_id"`
+	WorkloadContainerName string `json:"workload_container_name"`
+	VulnerabilityCount    int    `json:"vulnerability_count"`
+}
+
+// VulnerabilityManifestList is the result of kubescape_list_vulnerability_manifests
+type VulnerabilityManifestList struct {
+	VulnerabilityManifests []VulnerabilityManifestSummary `json:"vulnerability_manifests"`
+	TotalCount             int                            `json:"total_count"`
+}
+
+// VulnerabilitySummary describes one CVE match of a manifest
+type VulnerabilitySummary struct {
+	ID          string   `json:"id"`
+	Severity    string   `json:"severity"`
+	Description string   `json:"description" jsonschema_description:"Description truncated to 200 characters"`
+	DataSource  string   `json:"data_source"`
+	FixState    string   `json:"fix_state,omitempty"`
+	FixVersions []string `json:"fix_versions,omitempty"`
+}
+
+// VulnerabilityList is the result of kubescape_list_vulnerabilities
+type VulnerabilityList struct {
+	ManifestName    string                 `json:"manifest_name"`
+	Namespace       string                 `json:"namespace"`
+	TotalCount      int                    `json:"total_count"`
+	SeveritySummary map[string]int         `json:"severity_summary" jsonschema_description:"Number of matches by severity"`
+	Vulnerabilities []VulnerabilitySummary `json:"vulnerabilities"`
+}
+
+// VulnerabilityDetails is the structured content of kubescape_get_vulnerability_details.
+// Its text stays the JSON array of matches.
+type VulnerabilityDetails struct {
+	CVEID        string          `json:"cve_id"`
+	ManifestName string          `json:"manifest_name"`
+	Matches      []v1beta1.Match `json:"matches" jsonschema_description:"Grype matches of the CVE, one per affected package"`
+}
+
+// ConfigurationScanSummary describes one workload configuration scan
+type ConfigurationScanSummary struct {
+	Namespace    string `json:"namespace"`
+	ManifestName string `json:"manifest_name"`
+	CreatedAt    string `json:"created_at"`
+}
+
+// ConfigurationScanList is the result of kubescape_list_configuration_scans
+type ConfigurationScanList struct {
+	ConfigurationScans []ConfigurationScanSummary `json:"configuration_scans"`
+	TotalCount         int                        `json:"total_count"`
+}
+
+// ApplicationProfileSummary counts the runtime behavior captured for one workload
+type ApplicationProfileSummary struct {
+	Namespace                string `json:"namespace"`
+	Name                     string `json:"name"`
+	ContainersCount          int    `json:"containers_count"`
+	InitContainersCount      int    `json:"init_containers_count"`
+	EphemeralContainersCount int    `json:"ephemeral_containers_count"`
+	TotalExecs               int    `json:"total_execs"`
+	TotalOpens               int    `json:"total_opens"`
+	TotalSyscalls            int    `json:"total_syscalls"`
+	TotalCapabilities        int    `json:"total_capabilities"`
+	TotalEndpoints           int    `json:"total_endpoints"`
+	CreatedAt                string `json:"created_at"`
+}
+
+// ApplicationProfileList is the result of kubescape_list_application_profiles
+type ApplicationProfileList struct {
+	ApplicationProfiles []ApplicationProfileSummary `json:"application_profiles"`
+	TotalCount          int                         `json:"total_count"`
+	Description         string                      `json:"description"`
+}
+
+// ContainerBehavior is the runtime behavior captured for one container. It lists the
+// fields of v1beta1.ApplicationProfileContainer the tools return, whose call stacks are
+// recursive and cannot be described by a schema.
+type ContainerBehavior struct {
+	Name           string                        `json:"name"`
+	Execs          []v1beta1.ExecCalls           `json:"execs" jsonschema_description:"Processes the container executed"`
+	Opens          []v1beta1.OpenCalls           `json:"opens" jsonschema_description:"Files the container opened"`
+	Syscalls       []string                      `json:"syscalls"`
+	Capabilities   []string                      `json:"capabilities"`
+	Endpoints      []v1beta1.HTTPEndpoint        `json:"endpoints" jsonschema_description:"HTTP endpoints the container served or called"`
+	SeccompProfile *v1beta1.SingleSeccompProfile `json:"seccomp_profile,omitempty"`
+}
+
+// ApplicationProfileDetails is the result of kubescape_get_application_profile
+type ApplicationProfileDetails struct {
+	Namespace      string              `json:"namespace"`
+	Name           string              `json:"name"`
+	Containers     []ContainerBehavior `json:"containers"`
+	InitContainers []ContainerBehavior `json:"init_containers"`
+	Annotations    map[string]string   `json:"annotations"`
+	Labels         map[string]string   `json:"labels"`
+	Description    string              `json:"description"`
+}
+
+// NetworkNeighborhoodSummary counts the connections captured for one workload
+type NetworkNeighborhoodSummary struct {
+	Namespace       string `json:"namespace"`
+	Name            string `json:"name"`
+	ContainersCount int    `json:"containers_count"`
+	TotalIngress    int    `json:"total_ingress"`
+	TotalEgress     int    `json:"total_egress"`
+	CreatedAt       string `json:"created_at"`
+}
+
+// NetworkNeighborhoodList is the result of kubescape_list_network_neighborhoods
+type NetworkNeighborhoodList struct {
+	NetworkNeighborhoods []NetworkNeighborhoodSummary `json:"network_neighborhoods"`
+	TotalCount           int                          `json:"total_count"`
+	Description          string                       `json:"description"`
+}
+
+// Connection is one ingress or egress peer of a container
+type Connection struct {
+	Identifier        string                `json:"identifier"`
+	Type              string                `json:"type"`
+	DNS               string                `json:"dns,omitempty"`
+	Ports             []v1beta1.NetworkPort `json:"ports,omitempty"`
+	IPAddress         string                `json:"ip_address,omitempty"`
+	PodSelector       *metav1.LabelSelector `json:"pod_selector,omitempty"`
+	NamespaceSelector *metav1.LabelSelector `json:"namespace_selector,omitempty"`
+}
+
+// ContainerConnections are the connections captured for one container
+type ContainerConnections struct {
+	Name    string       `json:"name"`
+	Ingress []Connection `json:"ingress"`
+	Egress  []Connection `json:"egress"`
+}
+
+// NetworkNeighborhoodDetails is the result of kubescape_get_network_neighborhood
+type NetworkNeighborhoodDetails struct {
+	Namespace   string                 `json:"namespace"`
+	Name        string                 `json:"name"`
+	Containers  []ContainerConnections `json:"containers"`
+	Annotations map[string]string      `json:"annotations"`
+	Labels      map[string]string      `json:"labels"`
+	Description string                 `json:"description"`
+}
+
+// newContainerBehavior copies the behavior the tools return from a profile container
+func newContainerBehavior(container v1beta1.ApplicationProfileContainer) ContainerBehavior {
+	return ContainerBehavior{
+		Name:         container.Name,
+		Execs:        container.Execs,
+		Opens:        container.Opens,
+		Syscalls:     container.Syscalls,
+		Capabilities: container.Capabilities,
+		Endpoints:    container.Endpoints,
+	}
+}
+
+// newConnection converts a network neighbor into the connection the tools return
+func newConnection(neighbor v1beta1.NetworkNeighbor) Connection {
+	return Connection{
+		Identifier:        neighbor.Identifier,
+		Type:              string(neighbor.Type),
+		DNS:               neighbor.DNS,
+		Ports:             neighbor.Ports,
+		IPAddress:         neighbor.IPAddress,
+		PodSelector:       neighbor.PodSelector,
+		NamespaceSelector: neighbor.NamespaceSelector,
+	}
+}
+
+// structuredResult returns result as indented JSON text, with the same value as structured
+// content
+func structuredResult(result any) *mcp.CallToolResult {
+	content, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err))
+	}
+	return mcp.NewToolResultStructured(result, string(content))
+}
+
+// AffectedWorkload is a workload container with a CVE
+type AffectedWorkload struct {
+	Workload
+	InUse   *bool `json:"in_use,omitempty" jsonschema_description:"Container executed or opened a file of a vulnerable package; unset without an application profile"`
+	Exposed *bool `json:"exposed,omitempty" jsonschema_description:"Container received connections from outside the cluster; unset without a network neighborhood"`
+}
+
+// CVEImpact is one CVE with the workloads it affects
+type CVEImpact struct {
+	ID                string             `json:"id"`
+	Severity          string             `json:"severity"`
+	Fixable           bool               `json:"fixable"`
+	FixVersions       []string           `json:"fix_versions,omitempty"`
+	Packages          []string           `json:"packages" jsonschema_description:"Vulnerable packages as name@version"`
+	WorkloadCount     int                `json:"workload_count" jsonschema_description:"Number of affected workload containers"`
+	InUseCount        int                `json:"in_use_count,omitempty"`
+	ExposedCount      int                `json:"exposed_count,omitempty"`
+	InUseExposedCount int                `json:"in_use_exposed_count,omitempty" jsonschema_description:"Number of affected containers that use a vulnerable package and are exposed"`
+	Workloads         []AffectedWorkload `json:"workloads" jsonschema_description:"Affected workload containers, at most 20"`
+}
+
+// NamespaceVulnerabilities counts the findings of the workloads of one namespace
+type NamespaceVulnerabilities struct {
+	Namespace       string         `json:"namespace"`
+	Workloads       int            `json:"workloads" jsonschema_description:"Number of scanned workload containers"`
+	SeveritySummary map[string]int `json:"severity_summary"`
+}
+
+// ClusterVulnerabilitySummary is the result of kubescape_vulnerability_summary. A finding is
+// one CVE in one workload container, however many of its packages the CVE matches.
+type ClusterVulnerabilitySummary struct {
+	Level            string                     `json:"level" jsonschema_description:"workload when built from the relevancy-filtered workload manifests, image when built from image manifests and the pods running the images"`
+	ManifestsScanned int                        `json:"manifests_scanned"`
+	WorkloadsScanned int                        `json:"workloads_scanned" jsonschema_description:"Number of workload containers with a manifest"`
+	UniqueCVEs       int                        `json:"unique_cves"`
+	TotalFindings    int                        `json:"total_findings"`
+	SeveritySummary  map[string]int             `json:"severity_summary" jsonschema_description:"Number of findings by severity"`
+	Fixable          int                        `json:"fixable" jsonschema_description:"Number of findings with a fixed version available"`
+	Unfixable        int                        `json:"unfixable"`
+	Namespaces       []NamespaceVulnerabilities `json:"namespaces"`
+	TopCVEs          []CVEImpact                `json:"top_cves" jsonschema_description:"CVEs by blast radius, or by priority when prioritized"`
+	Prioritized      bool                       `json:"prioritized"`
+	Warnings         []string                   `json:"warnings,omitempty"`
+}
+
+// SBOMSummary describes one image SBOM
+type SBOMSummary struct {
+	Namespace    string `json:"namespace"`
+	Name         string `json:"name"`
+	ImageID      string `json:"image_id"`
+	ImageTag     string `json:"image_tag"`
+	PackageCount int    `json:"package_count"`
+	CreatedAt    string `json:"created_at"`
+}
+
+// SBOMList is the result of kubescape_list_sboms
+type SBOMList struct {
+	SBOMs      []SBOMSummary `json:"sboms"`
+	TotalCount int           `json:"total_count"`
+}
+
+// SBOMPackage is one package of an SBOM, without its file locations and CPEs
+type SBOMPackage struct {
+	Name     string   `json:"name"`
+	Version  string   `json:"version"`
+	Type     string   `json:"type" jsonschema_description:"Syft package type, such as deb, apk, java-archive, npm or go-module"`
+	Language string   `json:"language,omitempty"`
+	PURL     string   `json:"purl,omitempty"`
+	Licenses []string `json:"licenses,omitempty"`
+}
+
+// LicenseCount is the number of packages declaring a license
+type LicenseCount struct {
+	License  string `json:"license"`
+	Packages int    `json:"packages"`
+}
+
+// SBOMDetails is the result of kubescape_get_sbom: the package and license summary of the
+// SBOM with one page of its packages
+type SBOMDetails struct {
+	Namespace              string         `json:"namespace"`
+	Name                   string         `json:"name"`
+	ImageID                string         `json:"image_id"`
+	ImageTag               string         `json:"image_tag"`
+	Source                 string         `json:"source" jsonschema_description:"Name and version of the cataloged image"`
+	Distro                 string         `json:"distro,omitempty" jsonschema_description:"Linux distribution of the image"`
+	TotalPackages          int            `json:"total_packages"`
+	PackagesByType         map[string]int `json:"packages_by_type"`
+	PackagesByEcosystem    map[string]int `json:"packages_by_ecosystem" jsonschema_description:"Number of packages by package URL type, such as maven, npm, pypi or deb"`
+	Licenses               []LicenseCount `json:"licenses" jsonschema_description:"Licenses by number of packages declaring them, most used first"`
+	PackagesWithoutLicense int            `json:"packages_without_license"`
+	Packages               []SBOMPackage  `json:"packages" jsonschema_description:"Page of the packages sorted by name and version"`
+	Offset                 int            `json:"offset"`
+	NextOffset             *int           `json:"next_offset,omitempty" jsonschema_description:"Offset of the next page of packages, unset on the last page"`
+}
+
+// Workload is a workload running a container from an image
+type Workload struct {
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Container string `json:"container"`
+}
+
+// PackageMatch lists the packages of one SBOM that match a search, with the workloads
+// running its image
+type PackageMatch struct {
+	Namespace string        `json:"namespace"`
+	SBOMName  string        `json:"sbom_name"`
+	ImageID   string        `json:"image_id"`
+	ImageTag  string        `json:"image_tag"`
+	Packages  []SBOMPackage `json:"packages"`
+	Workloads []Workload    `json:"workloads" jsonschema_description:"Running workloads whose container image is the SBOM image"`
+}
+
+// PackageSearchResult is the result of kubescape_find_package
+type PackageSearchResult struct {
+	Matches       []PackageMatch `json:"matches"`
+	TotalPackages int            `json:"total_packages" jsonschema_description:"Number of matching packages across all SBOMs"`
+	SBOMsSearched int            `json:"sboms_searched"`
+	Warnings      []string       `json:"warnings,omitempty"`
+}
+
+// newSBOMPackage converts a Syft package into the package the tools return
+func newSBOMPackage(pkg v1beta1.SyftPackage) SBOMPackage {
+	converted := SBOMPackage{
+		Name:     pkg.Name,
+		Version:  pkg.Version,
+		Type:     pkg.Type,
+		Language: pkg.Language,
+		PURL:     pkg.PURL,
+	}
+	for _, license := range pkg.Licenses {
+		converted.Licenses = append(converted.Licenses, licenseName(license))
+	}
+	return converted
+}
+
+// licenseName prefers the SPDX expression of a license over its declared value
+func licenseName(license v1beta1.License) string {
+	if license.SPDXExpression != "" {
+		return license.SPDXExpression
+	}
+	return license.Value
+}
+
+// packageEcosystem returns the type of a package URL, such as maven in
+// pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1
+func packageEcosystem(purl string) string {
+	rest, ok := strings.CutPrefix(purl, "pkg:")
+	if !ok {
+		return "unknown"
+	}
+	ecosystem, _, _ := strings.Cut(rest, "/")
+	if ecosystem == "" {
+		return "unknown"
+	}
+	return ecosystem
+}
+
+// summarizeSBOM counts the packages of document by type, ecosystem and license and returns
+// the packages sorted by name and version
+func summarizeSBOM(document v1beta1.SyftDocument, details *SBOMDetails) []SBOMPackage {
+	details.TotalPackages = len(document.Artifacts)
+	details.PackagesByType = map[string]int{}
+	details.PackagesByEcosystem = map[string]int{}
+	details.Licenses = []LicenseCount{}
+
+	licenses := map[string]int{}
+	packages := make([]SBOMPackage, 0, len(document.Artifacts))
+	for _, artifact := range document.Artifacts {
+		pkg := newSBOMPackage(artifact)
+		details.PackagesByType[pkg.Type]++
+		details.PackagesByEcosystem[packageEcosystem(pkg.PURL)]++
+		if len(pkg.Licenses) == 0 {
+			details.PackagesWithoutLicense++
+		}
+		for _, license := range uniqueStrings(pkg.Licenses) {
+			licenses[license]++
+		}
+		packages = append(packages, pkg)
+	}
+
+	for license, count := range licenses {
+		details.Licenses = append(details.Licenses, LicenseCount{License: license, Packages: count})
+	}
+	sort.Slice(details.Licenses, func(i, j int) bool {
+		if details.Licenses[i].Packages != details.Licenses[j].Packages {
+			return details.Licenses[i].Packages > details.Licenses[j].Packages
+		}
+		return details.Licenses[i].License < details.Licenses[j].License
+	})
+	sort.SliceStable(packages, func(i, j int) bool {
+		if packages[i].Name != packages[j].Name {
+			return packages[i].Name < packages[j].Name
+		}
+		return packages[i].Version < packages[j].Version
+	})
+	return packages
+}
+
+// uniqueStrings returns values without duplicates, in their first order
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := values[:0:0]
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,399 @@
+package kubescape
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/kagent-dev/tools/internal/errors"
+	helpersv1 "github.com/kubescape/k8s-interface/instanceidhandler/v1/helpers"
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition/v1beta1"
+	"github.com/mark3labs/mcp-go/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	defaultTopCVEs       = 10
+	maxTopCVEs           = 100
+	maxAffectedWorkloads = 20
+)
+
+// severityRank orders the severities of Grype from least to most severe
+var severityRank = map[string]int{
+	"Unknown":    0,
+	"Negligible": 1,
+	"Low":        2,
+	"Medium":     3,
+	"High":       4,
+	"Critical":   5,
+}
+
+// workloadKinds restores the kind of workload IDs, which are lowercase
+var workloadKinds = map[string]string{
+	"cronjob":     "CronJob",
+	"daemonset":   "DaemonSet",
+	"deployment":  "Deployment",
+	"job":         "Job",
+	"pod":         "Pod",
+	"replicaset":  "ReplicaSet",
+	"statefulset": "StatefulSet",
+}
+
+// containerRuntime is what the application profile and network neighborhood of a workload
+// captured for one container
+type containerRuntime struct {
+	hasProfile      bool
+	paths           map[string]bool
+	hasNeighborhood bool
+	exposed         bool
+}
+
+// handleVulnerabilitySummary aggregates the CVEs of all workloads, optionally prioritized by the
+// runtime behavior of the workloads
+func (k *KubescapeTool) handleVulnerabilitySummary(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	k = k.forCluster(ctx)
+	if k.initError != nil {
+		toolErr := errors.NewKubescapeError("vulnerability_summary", k.initError)
+		return toolErr.ToMCPResult(), nil
+	}
+
+	namespace := mcp.ParseString(request, "namespace", "")
+	top := mcp.ParseInt(request, "top", defaultTopCVEs)
+	prioritize := mcp.ParseBoolean(request, "prioritize", false)
+
+	if top <= 0 || top > maxTopCVEs {
+		return mcp.NewToolResultError(fmt.Sprintf("top must be between 1 and %d", maxTopCVEs)), nil
+	}
+
+	// Manifests are listed in every namespace, since image manifests live in the Kubescape
+	// namespace; the namespace filter applies to the workloads
+	manifests, err := k.spdxClient.VulnerabilityManifests(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		toolErr := errors.NewKubescapeError("vulnerability_summary", err).
+			WithContext("namespace", namespace)
+		return toolErr.ToMCPResult(), nil
+	}
+
+	result := ClusterVulnerabilitySummary{
+		Level:           "workload",
+		SeveritySummary: newSeverityCounts(),
+		Namespaces:      []NamespaceVulnerabilities{},
+		TopCVEs:         []CVEImpact{},
+		Prioritized:     prioritize,
+	}
+
+	// findings holds the matches of each CVE by workload container
+	findings := map[string]map[Workload][]v1beta1.Match{}
+	scanned := map[Workload]bool{}
+	addManifest := func(workload Workload, manifest *v1beta1.VulnerabilityManifest) bool {
+		if namespace != "" && workload.Namespace != namespace {
+			return false
+		}
+		scanned[workload] = true
+		for _, match := range manifest.Spec.Payload.Matches {
+			id := match.Vulnerability.ID
+			if findings[id] == nil {
+				findings[id] = map[Workload][]v1beta1.Match{}
+			}
+			findings[id][workload] = append(findings[id][workload], match)
+		}
+		return true
+	}
+
+	// Workload manifests only hold the packages the workload loads. Without them, the image
+	// manifests are joined to the pods running the images.
+	var imageManifests []*v1beta1.VulnerabilityManifest
+	workloadManifests := 0
+	for i := range manifests.Items {
+		manifest := &manifests.Items[i]
+		wlid := manifest.Annotations[helpersv1.WlidMetadataKey]
+		if wlid == "" {
+			imageManifests = append(imageManifests, manifest)
+			continue
+		}
+		workload, ok := workloadFromWLID(wlid, manifest.Annotations[helpersv1.ContainerNameMetadataKey])
+		if !ok {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("skipped manifest %s/%s with unknown workload ID %q", manifest.Namespace, manifest.Name, wlid))
+			continue
+		}
+		workloadManifests++
+		if addManifest(workload, manifest) {
+			result.ManifestsScanned++
+		}
+	}
+	if workloadManifests == 0 && len(imageManifests) > 0 {
+		result.Level = "image"
+		running, err := k.workloadsByImage(ctx)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("failed to list the workloads running the images: %v", err))
+		}
+		for _, manifest := range imageManifests {
+			added := false
+			for _, workload := range running[imageDigest(manifest.Annotations[helpersv1.ImageIDMetadataKey])] {
+				added = addManifest(workload, manifest) || added
+			}
+			if added {
+				result.ManifestsScanned++
+			}
+		}
+	}
+
+	var runtime map[Workload]*containerRuntime
+	if prioritize {
+		runtime = k.workloadRuntime(ctx, namespace, &result)
+	}
+
+	// Aggregate the findings by CVE and namespace
+	namespaceCounts := map[string]*NamespaceVulnerabilities{}
+	for workload := range scanned {
+		counts, ok := namespaceCounts[workload.Namespace]
+		if !ok {
+			counts = &NamespaceVulnerabilities{Namespace: workload.Namespace, SeveritySummary: newSeverityCounts()}
+			namespaceCounts[workload.Namespace] = counts
+		}
+		counts.Workloads++
+	}
+
+	impacts := make([]CVEImpact, 0, len(findings))
+	for id, affected := range findings {
+		impact := CVEImpact{ID: id, Severity: "Unknown", Packages: []string{}, Workloads: []AffectedWorkload{}}
+		packages := map[string]bool{}
+		workloads := make([]Workload, 0, len(affected))
+		for workload, matches := range affected {
+			workloads = append(workloads, workload)
+			for _, match := range matches {
+				vuln := match.Vulnerability
+				if severityRank[string(vuln.Severity)] > severityRank[impact.Severity] {
+					impact.Severity = string(vuln.Severity)
+				}
+				if vuln.Fix.State == "fixed" {
+					impact.Fixable = true
+					impact.FixVersions = appendUnique(impact.FixVersions, vuln.Fix.Versions...)
+				}
+				packages[match.Artifact.Name+"@"+match.Artifact.Version] = true
+			}
+		}
+		for pkg := range packages {
+			impact.Packages = append(impact.Packages, pkg)
+		}
+		sort.Strings(impact.Packages)
+		sort.Slice(workloads, func(i, j int) bool { return workloadLess(workloads[i], workloads[j]) })
+
+		for _, workload := range workloads {
+			affectedWorkload := AffectedWorkload{Workload: workload}
+			if state, ok := runtime[workload]; ok {
+				if state.hasProfile {
+					inUse := packagesInUse(affected[workload], state.paths)
+					affectedWorkload.InUse = &inUse
+				}
+				if state.hasNeighborhood {
+					exposed := state.exposed
+					affectedWorkload.Exposed = &exposed
+				}
+			}
+			inUse := affectedWorkload.InUse != nil && *affectedWorkload.InUse
+			exposed := affectedWorkload.Exposed != nil && *affectedWorkload.Exposed
+			if inUse {
+				impact.InUseCount++
+			}
+			if exposed {
+				impact.ExposedCount++
+			}
+			if inUse && exposed {
+				impact.InUseExposedCount++
+			}
+			if len(impact.Workloads) < maxAffectedWorkloads {
+				impact.Workloads = append(impact.Workloads, affectedWorkload)
+			}
+
+			countSeverity(result.SeveritySummary, impact.Severity)
+			countSeverity(namespaceCounts[workload.Namespace].SeveritySummary, impact.Severity)
+			if impact.Fixable {
+				result.Fixable++
+			} else {
+				result.Unfixable++
+			}
+		}
+		impact.WorkloadCount = len(workloads)
+		result.TotalFindings += len(workloads)
+		impacts = append(impacts, impact)
+	}
+
+	sort.Slice(impacts, func(i, j int) bool { return impactLess(impacts[i], impacts[j], prioritize) })
+	result.UniqueCVEs = len(impacts)
+	result.WorkloadsScanned = len(scanned)
+	result.TopCVEs = impacts[:min(top, len(impacts))]
+	for _, counts := range namespaceCounts {
+		result.Namespaces = append(result.Namespaces, *counts)
+	}
+	sort.Slice(result.Namespaces, func(i, j int) bool { return result.Namespaces[i].Namespace < result.Namespaces[j].Namespace })
+
+	return structuredResult(result), nil
+}
+
+// workloadRuntime returns the files each workload container executed or opened and whether
+// it received connections from outside the cluster. Failures become warnings of result.
+func (k *KubescapeTool) workloadRuntime(ctx context.Context, namespace string, result *ClusterVulnerabilitySummary) map[Workload]*containerRuntime {
+	runtime := map[Workload]*containerRuntime{}
+	stateOf := func(wlid, container string) *containerRuntime {
+		workload, ok := workloadFromWLID(wlid, container)
+		if !ok {
+			return nil
+		}
+		state, ok := runtime[workload]
+		if !ok {
+			state = &containerRuntime{paths: map[string]bool{}}
+			runtime[workload] = state
+		}
+		return state
+	}
+
+	profiles, err := k.spdxClient.ApplicationProfiles(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("failed to list application profiles: %v", err))
+	} else {
+		for _, profile := range profiles.Items {
+			containers := append(append([]v1beta1.ApplicationProfileContainer{}, profile.Spec.InitContainers...), profile.Spec.Containers...)
+			for _, container := range containers {
+				state := stateOf(profile.Annotations[helpersv1.WlidMetadataKey], container.Name)
+				if state == nil {
+					continue
+				}
+				state.hasProfile = true
+				for _, exec := range container.Execs {
+					state.paths[exec.Path] = true
+				}
+				for _, open := range container.Opens {
+					state.paths[open.Path] = true
+				}
+			}
+		}
+	}
+
+	neighborhoods, err := k.spdxClient.NetworkNeighborhoods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("failed to list network neighborhoods: %v", err))
+	} else {
+		for _, nn := range neighborhoods.Items {
+			for _, container := range nn.Spec.Containers {
+				state := stateOf(nn.Annotations[helpersv1.WlidMetadataKey], container.Name)
+				if state == nil {
+					continue
+				}
+				state.hasNeighborhood = true
+				for _, ingress := range container.Ingress {
+					if ingress.Type == "external" {
+						state.exposed = true
+					}
+				}
+			}
+		}
+	}
+
+	if len(runtime) == 0 {
+		result.Warnings = append(result.Warnings, "no application profiles or network neighborhoods found; enable capabilities.runtimeObservability to prioritize by runtime behavior")
+	}
+	return runtime
+}
+
+// workloadFromWLID returns the workload container of a workload ID such as
+// wlid://cluster-prod/namespace-shop/deployment-app
+func workloadFromWLID(wlid, container string) (Workload, bool) {
+	parts := strings.Split(strings.TrimPrefix(wlid, "wlid://"), "/")
+	if len(parts) != 3 || !strings.HasPrefix(parts[1], "namespace-") {
+		return Workload{}, false
+	}
+	kind, name, ok := strings.Cut(parts[2], "-")
+	if !ok || name == "" {
+		return Workload{}, false
+	}
+	if known, ok := workloadKinds[kind]; ok {
+		kind = known
+	}
+	return Workload{
+		Namespace: strings.TrimPrefix(parts[1], "namespace-"),
+		Kind:      kind,
+		Name:      name,
+		Container: container,
+	}, true
+}
+
+// packagesInUse reports whether the container executed or opened a file of a matched package
+func packagesInUse(matches []v1beta1.Match, paths map[string]bool) bool {
+	for _, match := range matches {
+		for _, location := range match.Artifact.Locations {
+			if paths[location.RealPath] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// impactLess orders CVEs by blast radius and severity. Prioritized, CVEs come first by how
+// many affected containers use a vulnerable package while exposed, then use one at all.
+func impactLess(a, b CVEImpact, prioritize bool) bool {
+	if prioritize {
+		if a.InUseExposedCount != b.InUseExposedCount {
+			return a.InUseExposedCount > b.InUseExposedCount
+		}
+		if a.InUseCount != b.InUseCount {
+			return a.InUseCount > b.InUseCount
+		}
+		if severityRank[a.Severity] != severityRank[b.Severity] {
+			return severityRank[a.Severity] > severityRank[b.Severity]
+		}
+	}
+	if a.WorkloadCount != b.WorkloadCount {
+		return a.WorkloadCount > b.WorkloadCount
+	}
+	if severityRank[a.Severity] != severityRank[b.Severity] {
+		return severityRank[a.Severity] > severityRank[b.Severity]
+	}
+	return a.ID < b.ID
+}
+
+func workloadLess(a, b Workload) bool {
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	if a.Kind != b.Kind {
+		return a.Kind < b.Kind
+	}
+	if a.Name != b.Name {
+		return a.Name < b.Name
+	}
+	return a.Container < b.Container
+}
+
+// newSeverityCounts returns the severity buckets of the vulnerability tools
+func newSeverityCounts() map[string]int {
+	return map[string]int{
+		"Critical": 0,
+		"High":     0,
+		"Medium":   0,
+		"Low":      0,
+		"Unknown":  0,
+	}
+}
+
+// countSeverity counts severity in its bucket, or as Unknown when it has none
+func countSeverity(counts map[string]int, severity string) {
+	if _, exists := counts[severity]; exists {
+		counts[severity]++
+	} else {
+		counts["Unknown"]++
+	}
+}
+
+// appendUnique appends the values not yet in values
+func appendUnique(values []string, more ...string) []string {
+	for _, value := range more {
+		if !slices.Contains(values, value) {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,209 @@
+package kubescape
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition/v1beta1"
+	kubescapefake "github.com/kubescape/storage/pkg/generated/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+// newTestMatch creates a match of CVE id in a package installed at path
+func newTestMatch(id, severity, fixState, pkg, path string) v1beta1.Match {
+	match := v1beta1.Match{
+		Artifact: v1beta1.GrypePackage{
+			Name:      pkg,
+			Version:   "1.0",
+			Locations: []v1beta1.SyftCoordinates{{RealPath: path}},
+		},
+	}
+	match.Vulnerability.ID = id
+	match.Vulnerability.Severity = severity
+	match.Vulnerability.Fix.State = fixState
+	if fixState == "fixed" {
+		match.Vulnerability.Fix.Versions = []string{"1.1"}
+	}
+	return match
+}
+
+// newTestManifest creates a vulnerability manifest, for a workload when wlid is set
+func newTestManifest(namespace, name, wlid, container string, matches ...v1beta1.Match) *v1beta1.VulnerabilityManifest {
+	manifest := &v1beta1.VulnerabilityManifest{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Annotations: map[string]string{}},
+	}
+	if wlid != "" {
+		manifest.Annotations["kubescape.io/wlid"] = wlid
+		manifest.Annotations["kubescape.io/workload-container-name"] = container
+	}
+	manifest.Spec.Payload.Matches = matches
+	return manifest
+}
+
+var (
+	log4jMatch   = newTestMatch("CVE-2021-44228", "Critical", "fixed", "log4j-core", "/app/lib/log4j-core.jar")
+	bashMatch    = newTestMatch("CVE-2022-3715", "Low", "not-fixed", "bash", "/var/lib/dpkg/status")
+	opensslMatch = newTestMatch("CVE-2022-3602", "High", "not-fixed", "openssl", "/usr/lib/libssl.so.3")
+)
+
+func newTestSummaryObjects() []runtime.Object {
+	return []runtime.Object{
+		newTestManifest("shop", "app", "wlid://cluster-prod/namespace-shop/deployment-app", "app", log4jMatch, bashMatch),
+		newTestManifest("shop", "worker", "wlid://cluster-prod/namespace-shop/deployment-worker", "worker", bashMatch, opensslMatch),
+		newTestManifest("other", "db", "wlid://cluster-prod/namespace-other/statefulset-db", "db", bashMatch),
+		newTestManifest("kubescape", "image", "", "", log4jMatch),
+	}
+}
+
+func summarize(t *testing.T, tool *KubescapeTool, args map[string]interface{}) ClusterVulnerabilitySummary {
+	t.Helper()
+	result, err := tool.HandleVulnerabilitySummary(context.Background(), makeRequest(args))
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.False(t, result.IsError, getResultText(result))
+	summary, ok := result.StructuredContent.(ClusterVulnerabilitySummary)
+	require.True(t, ok, "structured content is %T", result.StructuredContent)
+	return summary
+}
+
+func cveIDs(impacts []CVEImpact) []string {
+	ids := []string{}
+	for _, impact := range impacts {
+		ids = append(ids, impact.ID)
+	}
+	return ids
+}
+
+func TestHandleVulnerabilitySummary_BlastRadius(t *testing.T) {
+	spdxClient := kubescapefake.NewClientset(newTestSummaryObjects()...)
+	tool := NewKubescapeToolWithClients(nil, nil, spdxClient.SpdxV1beta1())
+
+	summary := summarize(t, tool, nil)
+
+	assert.Equal(t, "workload", summary.Level)
+	assert.Equal(t, 3, summary.ManifestsScanned)
+	assert.Equal(t, 3, summary.WorkloadsScanned)
+	assert.Equal(t, 3, summary.UniqueCVEs)
+	assert.Equal(t, 5, summary.TotalFindings)
+	assert.Equal(t, map[string]int{"Critical": 1, "High": 1, "Medium": 0, "Low": 3, "Unknown": 0}, summary.SeveritySummary)
+	assert.Equal(t, 1, summary.Fixable)
+	assert.Equal(t, 4, summary.Unfixable)
+
+	// The CVE in most workloads comes first, then by severity
+	assert.Equal(t, []string{"CVE-2022-3715", "CVE-2021-44228", "CVE-2022-3602"}, cveIDs(summary.TopCVEs))
+	assert.Equal(t, 3, summary.TopCVEs[0].WorkloadCount)
+	assert.Equal(t, Workload{Namespace: "other", Kind: "StatefulSet", Name: "db", Container: "db"}, summary.TopCVEs[0].Workloads[0].Workload)
+	assert.Nil(t, summary.TopCVEs[0].Workloads[0].InUse)
+	assert.Equal(t, []string{"1.1"}, summary.TopCVEs[1].FixVersions)
+
+	require.Len(t, summary.Namespaces, 2)
+	assert.Equal(t, "other", summary.Namespaces[0].Namespace)
+	assert.Equal(t, 2, summary.Namespaces[1].Workloads)
+
+	// top and namespace narrow the summary
+	summary = summarize(t, tool, map[string]interface{}{"top": 1, "namespace": "shop"})
+	assert.Equal(t, 2, summary.WorkloadsScanned)
+	assert.Equal(t, []string{"CVE-2022-3715"}, cveIDs(summary.TopCVEs))
+}
+
+func TestHandleVulnerabilitySummary_Prioritize(t *testing.T) {
+	objects := append(newTestSummaryObjects(),
+		&v1beta1.ApplicationProfile{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "replicaset-app-7d9f8b", Namespace: "shop",
+				Annotations: map[string]string{"kubescape.io/wlid": "wlid://cluster-prod/namespace-shop/deployment-app"},
+			},
+			Spec: v1beta1.ApplicationProfileSpec{Containers: []v1beta1.ApplicationProfileContainer{
+				{Name: "app", Execs: []v1beta1.ExecCalls{{Path: "/usr/bin/java"}}, Opens: []v1beta1.OpenCalls{{Path: "/app/lib/log4j-core.jar"}}},
+			}},
+		},
+		&v1beta1.NetworkNeighborhood{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "replicaset-app-7d9f8b", Namespace: "shop",
+				Annotations: map[string]string{"kubescape.io/wlid": "wlid://cluster-prod/namespace-shop/deployment-app"},
+			},
+			Spec: v1beta1.NetworkNeighborhoodSpec{Containers: []v1beta1.NetworkNeighborhoodContainer{
+				{Name: "app", Ingress: []v1beta1.NetworkNeighbor{{Identifier: "internet", Type: "external"}}},
+			}},
+		},
+	)
+	spdxClient := kubescapefake.NewClientset(objects...)
+	tool := NewKubescapeToolWithClients(nil, nil, spdxClient.SpdxV1beta1())
+
+	summary := summarize(t, tool, map[string]interface{}{"prioritize": true})
+
+	assert.True(t, summary.Prioritized)
+	// The CVE in a package the exposed app loads comes first, then by severity
+	assert.Equal(t, []string{"CVE-2021-44228", "CVE-2022-3602", "CVE-2022-3715"}, cveIDs(summary.TopCVEs))
+	assert.Equal(t, 1, summary.TopCVEs[0].InUseExposedCount)
+
+	app := summary.TopCVEs[0].Workloads[0]
+	require.NotNil(t, app.InUse)
+	require.NotNil(t, app.Exposed)
+	assert.True(t, *app.InUse)
+	assert.True(t, *app.Exposed)
+
+	// bash is installed in the app but none of its files were used
+	for _, workload := range summary.TopCVEs[2].Workloads {
+		if workload.Name == "app" {
+			require.NotNil(t, workload.InUse)
+			assert.False(t, *workload.InUse)
+		} else {
+			assert.Nil(t, workload.InUse, "no profile for %s", workload.Name)
+		}
+	}
+}
+
+func TestHandleVulnerabilitySummary_ImageManifests(t *testing.T) {
+	image := newTestManifest("kubescape", "image", "", "", log4jMatch)
+	image.Annotations["kubescape.io/image-id"] = "docker.io/library/app@sha256:abc"
+	spdxClient := kubescapefake.NewClientset(image)
+
+	controller := true
+	//nolint:staticcheck // NewSimpleClientset is deprecated but NewClientset requires generated apply configs
+	k8sClient := kubefake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "app-7d9f8b-x2x4z", Namespace: "shop",
+				Labels:          map[string]string{"pod-template-hash": "7d9f8b"},
+				OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "app-7d9f8b", Controller: &controller}},
+			},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "app", ImageID: "docker.io/library/app@sha256:abc"},
+			}},
+		},
+	)
+	tool := NewKubescapeToolWithClients(k8sClient, nil, spdxClient.SpdxV1beta1())
+
+	summary := summarize(t, tool, nil)
+
+	assert.Equal(t, "image", summary.Level)
+	assert.Equal(t, 1, summary.ManifestsScanned)
+	require.Len(t, summary.TopCVEs, 1)
+	assert.Equal(t, []AffectedWorkload{{Workload: Workload{Namespace: "shop", Kind: "Deployment", Name: "app", Container: "app"}}}, summary.TopCVEs[0].Workloads)
+}
+
+func TestHandleVulnerabilitySummary_InvalidTop(t *testing.T) {
+	tool := NewKubescapeToolWithClients(nil, nil, kubescapefake.NewClientset().SpdxV1beta1())
+
+	result, err := tool.HandleVulnerabilitySummary(context.Background(), makeRequest(map[string]interface{}{"top": 0}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, getResultText(result), "top must be between")
+}
+
+func TestWorkloadFromWLID(t *testing.T) {
+	workload, ok := workloadFromWLID("wlid://cluster-prod/namespace-shop/deployment-checkout-api", "api")
+	assert.True(t, ok)
+	assert.Equal(t, Workload{Namespace: "shop", Kind: "Deployment", Name: "checkout-api", Container: "api"}, workload)
+
+	for _, wlid := range []string{"", "wlid://cluster-prod/shop/deployment-app", "wlid://cluster-prod/namespace-shop/deployment"} {
+		_, ok := workloadFromWLID(wlid, "app")
+		assert.False(t, ok, wlid)
+	}
+}
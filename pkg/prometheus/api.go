@@ -0,0 +1,147 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kagent-dev/tools/internal/errors"
+	"github.com/kagent-dev/tools/internal/security"
+)
+
+// getAPI sends a GET request for path, e.g. /api/v1/series, with params to the Prometheus
+// server of the call and returns the response body. Failures are returned as a tool error
+// result carrying the server, endpoint and parameters.
+func getAPI(ctx context.Context, request mcp.CallToolRequest, path string, params url.Values) ([]byte, *mcp.CallToolResult) {
+	prometheusURL, client, errResult := resolveTarget(ctx, request)
+	if errResult != nil {
+		return nil, errResult
+	}
+
+	apiURL := prometheusURL + path
+	fullURL := apiURL
+	if len(params) > 0 {
+		fullURL = fmt.Sprintf("%s?%s", apiURL, params.Encode())
+	}
+
+	newError := func(operation string, cause error) *errors.ToolError {
+		toolErr := errors.NewPrometheusError(operation, cause).
+			WithContext("prometheus_url", prometheusURL).
+			WithContext("api_url", apiURL)
+		for name, values := range params {
+			toolErr = toolErr.WithContext(name, strings.Join(values, ", "))
+		}
+		return toolErr
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
+	if err != nil {
+		return nil, newError("create_request", err).ToMCPResult()
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, newError("query_execution", err).ToMCPResult()
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, newError("read_response", err).WithContext("status_code", resp.StatusCode).ToMCPResult()
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		cause := fmt.Errorf("Prometheus API error (%d): %s", resp.StatusCode, apiErrorMessage(body))
+		return nil, newError("api_error", cause).
+			WithContext("status_code", resp.StatusCode).
+			WithContext("response_body", string(body)).
+			ToMCPResult()
+	}
+	return body, nil
+}
+
+// apiErrorMessage returns the error of a failed API response, e.g. the PromQL parse error of
+// a bad_data response, or the body when it is not an API error
+func apiErrorMessage(body []byte) string {
+	var response struct {
+		ErrorType string `json:"errorType"`
+		Error     string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil || response.Error == "" {
+		return string(body)
+	}
+	return fmt.Sprintf("%s: %s", response.ErrorType, response.Error)
+}
+
+// jsonToolResult returns an API response as pretty-printed JSON text, or as is when it is
+// not JSON
+func jsonToolResult(body []byte) *mcp.CallToolResult {
+	var result interface{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return mcp.NewToolResultText(string(body))
+	}
+
+	prettyJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultText(string(body))
+	}
+	return mcp.NewToolResultText(string(prettyJSON))
+}
+
+// selectorParams adds the series selectors of the match argument, a string or an array of
+// strings, to params as match[]
+func selectorParams(request mcp.CallToolRequest, params url.Values) *mcp.CallToolResult {
+	selectors := request.GetStringSlice("match", nil)
+	if selector := mcp.ParseString(request, "match", ""); selector != "" {
+		selectors = []string{selector}
+	}
+	for _, selector := range selectors {
+		if err := security.ValidatePromQLQuery(selector); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid series selector %q: %v", selector, err))
+		}
+		params.Add("match[]", selector)
+	}
+	return nil
+}
+
+// timeArg parses the time argument name (see parseTime), returning false when it is not set
+func timeArg(request mcp.CallToolRequest, name string, now time.Time) (time.Time, bool, *mcp.CallToolResult) {
+	value := mcp.ParseString(request, name, "")
+	if value == "" {
+		return time.Time{}, false, nil
+	}
+	t, err := parseTime(value, now)
+	if err != nil {
+		return time.Time{}, false, mcp.NewToolResultError(fmt.Sprintf("Invalid %s time: %v", name, err))
+	}
+	return t, true, nil
+}
+
+// timeParams adds the start and end arguments that are set to params
+func timeParams(request mcp.CallToolRequest, params url.Values) *mcp.CallToolResult {
+	now := time.Now()
+	for _, name := range []string{"start", "end"} {
+		t, ok, errResult := timeArg(request, name, now)
+		if errResult != nil {
+			return errResult
+		}
+		if ok {
+			params.Set(name, formatTime(t))
+		}
+	}
+	return nil
+}
+
+// limitParam adds the limit argument to params when it is positive
+func limitParam(request mcp.CallToolRequest, params url.Values) {
+	if limit := mcp.ParseInt(request, "limit", 0); limit > 0 {
+		params.Set("limit", fmt.Sprintf("%d", limit))
+	}
+}
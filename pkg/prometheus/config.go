@@ -0,0 +1,268 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/tools/internal/httpclient"
+	"github.com/kagent-dev/tools/internal/security"
+)
+
+const (
+	// DefaultURL is the server queried when no data source is configured
+	DefaultURL = "http://localhost:9090"
+
+	// DefaultDataSourceName names the data source configured by flags and environment
+	DefaultDataSourceName = "default"
+
+	// DefaultTenantHeader carries the tenant of Mimir, Cortex and Loki
+	DefaultTenantHeader = httpclient.DefaultTenantHeader
+
+	// queryTimeout bounds the requests to data sources, the default query timeout of Prometheus
+	queryTimeout = 2 * time.Minute
+)
+
+// Environment variables read for the fields of the flag data source left empty
+const (
+	EnvURL             = "PROMETHEUS_URL"
+	EnvUsername        = "PROMETHEUS_USERNAME"
+	EnvPassword        = "PROMETHEUS_PASSWORD"
+	EnvBearerTokenFile = "PROMETHEUS_BEARER_TOKEN_FILE"
+	EnvCAFile          = "PROMETHEUS_CA_FILE"
+	EnvTenantID        = "PROMETHEUS_TENANT_ID"
+)
+
+// DataSource is a Prometheus-compatible server (Prometheus, Thanos, Mimir, Cortex) and how to
+// reach it. The password is read from PasswordEnv when Password is empty, and the bearer
+// token from BearerTokenFile on every request so that rotated tokens are picked up.
+type DataSource struct {
+	Name               string            `json:"name"`
+	URL                string            `json:"url"`
+	Description        string            `json:"description,omitempty"`
+	Username           string            `json:"username,omitempty"`
+	Password           string            `json:"password,omitempty"`
+	PasswordEnv        string            `json:"passwordEnv,omitempty"`
+	BearerTokenFile    string            `json:"bearerTokenFile,omitempty"`
+	CAFile             string            `json:"caFile,omitempty"`
+	InsecureSkipVerify bool              `json:"insecureSkipVerify,omitempty"`
+	Headers            map[string]string `json:"headers,omitempty"`
+	TenantID           string            `json:"tenantID,omitempty"`
+	TenantHeader       string            `json:"tenantHeader,omitempty"`
+
+	client *http.Client
+}
+
+// Config is the parsed --prometheus-config file: the named data sources the tools can
+// select with their datasource argument, and the one used when none is selected
+type Config struct {
+	Default     string       `json:"default,omitempty"`
+	DataSources []DataSource `json:"datasources"`
+
+	sources map[string]*DataSource
+}
+
+// LoadConfig builds the data sources of the Prometheus tools from the config file, when set,
+// and from the flag data source, whose empty fields are read from the environment. The flag
+// data source is added as "default" when it has a URL and becomes the default unless the
+// file names one.
+func LoadConfig(file string, flags DataSource) (*Config, error) {
+	config := &Config{}
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read prometheus config: %w", err)
+		}
+		if err := yaml.UnmarshalStrict(data, config); err != nil {
+			return nil, fmt.Errorf("failed to parse prometheus config: %w", err)
+		}
+	}
+
+	flags.applyEnv()
+	if flags.URL != "" {
+		flags.Name = DefaultDataSourceName
+		config.DataSources = append(config.DataSources, flags)
+		if config.Default == "" {
+			config.Default = DefaultDataSourceName
+		}
+	}
+
+	if err := config.init(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// init validates the data sources and creates their clients
+func (c *Config) init() error {
+	c.sources = make(map[string]*DataSource, len(c.DataSources))
+	for i := range c.DataSources {
+		source := &c.DataSources[i]
+		if source.Name == "" {
+			return fmt.Errorf("datasources[%d]: name is required", i)
+		}
+		if _, ok := c.sources[source.Name]; ok {
+			return fmt.Errorf("datasource %s is defined twice", source.Name)
+		}
+		if err := source.init(); err != nil {
+			return fmt.Errorf("datasource %s: %w", source.Name, err)
+		}
+		c.sources[source.Name] = source
+	}
+
+	if c.Default == "" && len(c.DataSources) == 1 {
+		c.Default = c.DataSources[0].Name
+	}
+	if _, ok := c.sources[c.Default]; c.Default != "" && !ok {
+		return fmt.Errorf("default datasource %s is not defined", c.Default)
+	}
+	return nil
+}
+
+// Lookup returns the data source called name, or the default data source when name is empty
+func (c *Config) Lookup(name string) (*DataSource, error) {
+	if c == nil || len(c.sources) == 0 {
+		if name != "" {
+			return nil, fmt.Errorf("unknown datasource %q: no datasources are configured", name)
+		}
+		return nil, nil
+	}
+	if name == "" {
+		return c.sources[c.Default], nil
+	}
+	source, ok := c.sources[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown datasource %q, expected one of: %s", name, strings.Join(c.Names(), ", "))
+	}
+	return source, nil
+}
+
+// Names returns the names of the data sources in order
+func (c *Config) Names() []string {
+	if c == nil {
+		return nil
+	}
+	names := make([]string, 0, len(c.sources))
+	for name := range c.sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyEnv reads the fields left empty from the environment
+func (d *DataSource) applyEnv() {
+	httpclient.ApplyEnv(map[*string]string{
+		&d.URL:             EnvURL,
+		&d.Username:        EnvUsername,
+		&d.Password:        EnvPassword,
+		&d.BearerTokenFile: EnvBearerTokenFile,
+		&d.CAFile:          EnvCAFile,
+		&d.TenantID:        EnvTenantID,
+	})
+}
+
+// init validates the data source and creates its client
+func (d *DataSource) init() error {
+	// Accept host:port, as the Helm chart has always documented it
+	if d.URL != "" && !strings.Contains(d.URL, "://") {
+		d.URL = "http://" + d.URL
+	}
+	d.URL = strings.TrimSuffix(d.URL, "/")
+	if err := security.ValidateURL(d.URL); err != nil {
+		return err
+	}
+
+	if d.Password == "" && d.PasswordEnv != "" {
+		d.Password = strings.TrimSpace(os.Getenv(d.PasswordEnv))
+	}
+	if d.TenantID != "" && d.TenantHeader == "" {
+		d.TenantHeader = DefaultTenantHeader
+	}
+
+	client, err := httpclient.New(httpclient.Options{
+		Username:           d.Username,
+		Password:           d.Password,
+		BearerTokenFile:    d.BearerTokenFile,
+		CAFile:             d.CAFile,
+		InsecureSkipVerify: d.InsecureSkipVerify,
+		Headers:            d.Headers,
+		TenantID:           d.TenantID,
+		TenantHeader:       d.TenantHeader,
+		Timeout:            queryTimeout,
+	})
+	if err != nil {
+		return err
+	}
+	d.client = client
+	return nil
+}
+
+// resolveTarget returns the server URL and client of a tool call: the prometheus_url
+// argument with a plain client, else the datasource argument or the default data source,
+// else DefaultURL. The returned result is a tool error when the arguments are invalid.
+func resolveTarget(ctx context.Context, request mcp.CallToolRequest) (string, *http.Client, *mcp.CallToolResult) {
+	config := httpclient.ConfigFromContext[Config](ctx)
+	name := mcp.ParseString(request, "datasource", "")
+	prometheusURL := mcp.ParseString(request, "prometheus_url", "")
+
+	if name != "" && prometheusURL != "" {
+		return "", nil, mcp.NewToolResultError("set either datasource or prometheus_url, not both")
+	}
+	if prometheusURL == "" {
+		source, err := config.Lookup(name)
+		if err != nil {
+			return "", nil, mcp.NewToolResultError(err.Error())
+		}
+		if source != nil {
+			return source.URL, source.client, nil
+		}
+		prometheusURL = DefaultURL
+	}
+
+	if err := security.ValidateURL(prometheusURL); err != nil {
+		return "", nil, mcp.NewToolResultError(fmt.Sprintf("Invalid Prometheus URL: %v", err))
+	}
+	return prometheusURL, getHTTPClient(ctx), nil
+}
+
+func handleListDataSources(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	config := httpclient.ConfigFromContext[Config](ctx)
+
+	list := DataSourceList{DataSources: []DataSourceInfo{}}
+	for _, name := range config.Names() {
+		source := config.sources[name]
+		info := DataSourceInfo{
+			Name:        source.Name,
+			URL:         source.URL,
+			Description: source.Description,
+			Auth:        "none",
+			TenantID:    source.TenantID,
+			Default:     source.Name == config.Default,
+		}
+		switch {
+		case source.Username != "":
+			info.Auth = "basic"
+		case source.BearerTokenFile != "":
+			info.Auth = "bearer"
+		}
+		list.DataSources = append(list.DataSources, info)
+	}
+
+	content, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError("failed to marshal datasources: " + err.Error()), nil
+	}
+	if len(list.DataSources) == 0 {
+		return mcp.NewToolResultStructured(list, fmt.Sprintf("No datasources are configured; the prometheus tools query %s unless prometheus_url is set", DefaultURL)), nil
+	}
+	return mcp.NewToolResultStructured(list, string(content)), nil
+}
@@ -0,0 +1,184 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kagent-dev/tools/internal/httpclient"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv(EnvURL, "")
+
+	t.Run("no data sources", func(t *testing.T) {
+		config, err := LoadConfig("", DataSource{})
+		require.NoError(t, err)
+		source, err := config.Lookup("")
+		require.NoError(t, err)
+		assert.Nil(t, source)
+	})
+
+	t.Run("flags and environment", func(t *testing.T) {
+		t.Setenv(EnvURL, "prometheus.monitoring:9090/")
+		t.Setenv(EnvPassword, "secret")
+		config, err := LoadConfig("", DataSource{Username: "kagent"})
+		require.NoError(t, err)
+		source, err := config.Lookup("")
+		require.NoError(t, err)
+		assert.Equal(t, DefaultDataSourceName, source.Name)
+		assert.Equal(t, "http://prometheus.monitoring:9090", source.URL)
+		assert.Equal(t, "secret", source.Password)
+	})
+
+	t.Run("file and flags", func(t *testing.T) {
+		t.Setenv("THANOS_PASSWORD", "hunter2")
+		file := writeFile(t, "prometheus.yaml", `
+default: prod-thanos
+datasources:
+- name: prod-thanos
+  url: https://thanos.prod:9091
+  username: kagent
+  passwordEnv: THANOS_PASSWORD
+- name: mimir
+  url: https://mimir.monitoring
+  tenantID: team-a
+`)
+		config, err := LoadConfig(file, DataSource{URL: "http://localhost:9090"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"default", "mimir", "prod-thanos"}, config.Names())
+
+		source, err := config.Lookup("")
+		require.NoError(t, err)
+		assert.Equal(t, "prod-thanos", source.Name)
+		assert.Equal(t, "hunter2", source.Password)
+
+		source, err = config.Lookup("mimir")
+		require.NoError(t, err)
+		assert.Equal(t, DefaultTenantHeader, source.TenantHeader)
+
+		_, err = config.Lookup("staging")
+		assert.ErrorContains(t, err, "expected one of: default, mimir, prod-thanos")
+	})
+
+	for name, content := range map[string]string{
+		"unknown field":     "datasources:\n- name: a\n  url: http://a\n  token: x\n",
+		"missing name":      "datasources:\n- url: http://a\n",
+		"duplicate name":    "datasources:\n- name: a\n  url: http://a\n- name: a\n  url: http://b\n",
+		"unknown default":   "default: b\ndatasources:\n- name: a\n  url: http://a\n",
+		"basic and bearer":  "datasources:\n- name: a\n  url: http://a\n  username: u\n  bearerTokenFile: /token\n",
+		"invalid scheme":    "datasources:\n- name: a\n  url: ftp://a\n",
+		"missing CA file":   "datasources:\n- name: a\n  url: https://a\n  caFile: /does/not/exist\n",
+		"CA file is no PEM": "datasources:\n- name: a\n  url: https://a\n  caFile: " + writeFile(t, "ca.pem", "not a certificate") + "\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := LoadConfig(writeFile(t, "prometheus.yaml", content), DataSource{})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestDataSourceRequests(t *testing.T) {
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":["__name__","job"]}`))
+	}))
+	defer server.Close()
+
+	token := writeFile(t, "token", "first\n")
+	config := &Config{DataSources: []DataSource{
+		{Name: "basic", URL: server.URL, Username: "kagent", Password: "secret", Headers: map[string]string{"X-Org": "ops"}},
+		{Name: "bearer", URL: server.URL, BearerTokenFile: token, TenantID: "team-a", TenantHeader: "THANOS-TENANT"},
+		{Name: "unreachable", URL: "http://127.0.0.1:1"},
+	}, Default: "basic"}
+	require.NoError(t, config.init())
+	ctx := httpclient.ContextWithConfig(context.Background(), config)
+
+	call := func(args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = args
+		result, err := handlePrometheusLabelsQueryTool(ctx, req)
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("default data source with basic auth and headers", func(t *testing.T) {
+		result := call(nil)
+		require.False(t, result.IsError, getResultText(result))
+		user, password, ok := got.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "kagent", user)
+		assert.Equal(t, "secret", password)
+		assert.Equal(t, "ops", got.Header.Get("X-Org"))
+	})
+
+	t.Run("named data source with a rotated bearer token and tenant", func(t *testing.T) {
+		call(map[string]any{"datasource": "bearer"})
+		assert.Equal(t, "Bearer first", got.Header.Get("Authorization"))
+		assert.Equal(t, "team-a", got.Header.Get("THANOS-TENANT"))
+
+		require.NoError(t, os.WriteFile(token, []byte("second"), 0o600))
+		call(map[string]any{"datasource": "bearer"})
+		assert.Equal(t, "Bearer second", got.Header.Get("Authorization"))
+	})
+
+	t.Run("explicit URL is queried without credentials", func(t *testing.T) {
+		result := call(map[string]any{"prometheus_url": server.URL})
+		require.False(t, result.IsError, getResultText(result))
+		assert.Empty(t, got.Header.Get("Authorization"))
+	})
+
+	t.Run("invalid selections", func(t *testing.T) {
+		result := call(map[string]any{"datasource": "staging"})
+		assert.True(t, result.IsError)
+		assert.Contains(t, getResultText(result), "expected one of: basic, bearer, unreachable")
+
+		result = call(map[string]any{"datasource": "basic", "prometheus_url": server.URL})
+		assert.True(t, result.IsError)
+
+		result = call(map[string]any{"datasource": "unreachable"})
+		assert.True(t, result.IsError)
+	})
+}
+
+func TestHandleListDataSources(t *testing.T) {
+	config := &Config{DataSources: []DataSource{
+		{Name: "prod-thanos", URL: "https://thanos.prod", Description: "Long-term metrics", Username: "kagent", Password: "secret"},
+		{Name: "mimir", URL: "https://mimir.monitoring", TenantID: "team-a"},
+	}, Default: "prod-thanos"}
+	require.NoError(t, config.init())
+
+	result, err := handleListDataSources(httpclient.ContextWithConfig(context.Background(), config), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.NotContains(t, getResultText(result), "secret")
+
+	var list DataSourceList
+	require.NoError(t, json.Unmarshal([]byte(getResultText(result)), &list))
+	assert.Equal(t, []DataSourceInfo{
+		{Name: "mimir", URL: "https://mimir.monitoring", Auth: "none", TenantID: "team-a"},
+		{Name: "prod-thanos", URL: "https://thanos.prod", Description: "Long-term metrics", Auth: "basic", Default: true},
+	}, list.DataSources)
+
+	result, err = handleListDataSources(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.Contains(t, getResultText(result), "No datasources are configured")
+}
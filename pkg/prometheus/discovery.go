@@ -0,0 +1,146 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/kagent-dev/tools/internal/security"
+)
+
+// Tools for discovering the metrics, labels, alerts and rules of a Prometheus server before
+// writing PromQL against it
+
+// labelNamePattern matches a Prometheus label name
+var labelNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// metricNamePattern matches a Prometheus metric name
+var metricNamePattern = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+func handlePrometheusSeriesTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	params := url.Values{}
+	if errResult := selectorParams(request, params); errResult != nil {
+		return errResult, nil
+	}
+	if !params.Has("match[]") {
+		return mcp.NewToolResultError("match parameter is required"), nil
+	}
+	if errResult := timeParams(request, params); errResult != nil {
+		return errResult, nil
+	}
+	limitParam(request, params)
+
+	body, errResult := getAPI(ctx, request, "/api/v1/series", params)
+	if errResult != nil {
+		return errResult, nil
+	}
+	return jsonToolResult(body), nil
+}
+
+func handlePrometheusLabelValuesTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	label := mcp.ParseString(request, "label", "")
+	if label == "" {
+		return mcp.NewToolResultError("label parameter is required"), nil
+	}
+	if !labelNamePattern.MatchString(label) {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid label name %q", label)), nil
+	}
+
+	params := url.Values{}
+	if errResult := selectorParams(request, params); errResult != nil {
+		return errResult, nil
+	}
+	if errResult := timeParams(request, params); errResult != nil {
+		return errResult, nil
+	}
+	limitParam(request, params)
+
+	body, errResult := getAPI(ctx, request, "/api/v1/label/"+label+"/values", params)
+	if errResult != nil {
+		return errResult, nil
+	}
+	return jsonToolResult(body), nil
+}
+
+func handlePrometheusMetadataTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	params := url.Values{}
+	if metric := mcp.ParseString(request, "metric", ""); metric != "" {
+		if !metricNamePattern.MatchString(metric) {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid metric name %q", metric)), nil
+		}
+		params.Set("metric", metric)
+	}
+	limitParam(request, params)
+
+	body, errResult := getAPI(ctx, request, "/api/v1/metadata", params)
+	if errResult != nil {
+		return errResult, nil
+	}
+	return jsonToolResult(body), nil
+}
+
+func handlePrometheusAlertsTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	body, errResult := getAPI(ctx, request, "/api/v1/alerts", nil)
+	if errResult != nil {
+		return errResult, nil
+	}
+	return jsonToolResult(body), nil
+}
+
+func handlePrometheusRulesTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	params := url.Values{}
+	switch ruleType := mcp.ParseString(request, "type", ""); ruleType {
+	case "":
+	case "alert", "record":
+		params.Set("type", ruleType)
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid rule type %q: expected alert or record", ruleType)), nil
+	}
+	for _, name := range []string{"rule_name", "rule_group"} {
+		if value := mcp.ParseString(request, name, ""); value != "" {
+			params.Add(name+"[]", value)
+		}
+	}
+
+	body, errResult := getAPI(ctx, request, "/api/v1/rules", params)
+	if errResult != nil {
+		return errResult, nil
+	}
+	return jsonToolResult(body), nil
+}
+
+func handlePrometheusTSDBStatusTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	params := url.Values{}
+	limitParam(request, params)
+
+	body, errResult := getAPI(ctx, request, "/api/v1/status/tsdb", params)
+	if errResult != nil {
+		return errResult, nil
+	}
+	return jsonToolResult(body), nil
+}
+
+func handlePrometheusExemplarsTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	query := mcp.ParseString(request, "query", "")
+	if query == "" {
+		return mcp.NewToolResultError("query parameter is required"), nil
+	}
+	if err := security.ValidatePromQLQuery(query); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid PromQL query: %v", err)), nil
+	}
+
+	params := url.Values{}
+	params.Set("query", query)
+	if errResult := timeParams(request, params); errResult != nil {
+		return errResult, nil
+	}
+
+	body, errResult := getAPI(ctx, request, "/api/v1/query_exemplars", params)
+	if errResult != nil {
+		return errResult, nil
+	}
+	return jsonToolResult(body), nil
+}
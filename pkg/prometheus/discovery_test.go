@@ -0,0 +1,152 @@
+package prometheus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoveryTools(t *testing.T) {
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":[]}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error)
+		args    map[string]any
+		path    string
+		query   url.Values
+	}{
+		{
+			name:    "series",
+			handler: handlePrometheusSeriesTool,
+			args:    map[string]any{"match": []any{`up{job="api"}`, "process_start_time_seconds"}, "start": "1700000000", "limit": float64(50)},
+			path:    "/api/v1/series",
+			query:   url.Values{"match[]": {`up{job="api"}`, "process_start_time_seconds"}, "start": {"1700000000"}, "limit": {"50"}},
+		},
+		{
+			name:    "series with a single selector string",
+			handler: handlePrometheusSeriesTool,
+			args:    map[string]any{"match": "up"},
+			path:    "/api/v1/series",
+			query:   url.Values{"match[]": {"up"}},
+		},
+		{
+			name:    "label values",
+			handler: handlePrometheusLabelValuesTool,
+			args:    map[string]any{"label": "namespace", "match": []any{"kube_pod_info"}},
+			path:    "/api/v1/label/namespace/values",
+			query:   url.Values{"match[]": {"kube_pod_info"}},
+		},
+		{
+			name:    "metadata",
+			handler: handlePrometheusMetadataTool,
+			args:    map[string]any{"metric": "http_requests_total"},
+			path:    "/api/v1/metadata",
+			query:   url.Values{"metric": {"http_requests_total"}},
+		},
+		{
+			name:    "alerts",
+			handler: handlePrometheusAlertsTool,
+			path:    "/api/v1/alerts",
+			query:   url.Values{},
+		},
+		{
+			name:    "rules",
+			handler: handlePrometheusRulesTool,
+			args:    map[string]any{"type": "alert", "rule_group": "kubernetes-apps"},
+			path:    "/api/v1/rules",
+			query:   url.Values{"type": {"alert"}, "rule_group[]": {"kubernetes-apps"}},
+		},
+		{
+			name:    "tsdb status",
+			handler: handlePrometheusTSDBStatusTool,
+			args:    map[string]any{"limit": float64(5)},
+			path:    "/api/v1/status/tsdb",
+			query:   url.Values{"limit": {"5"}},
+		},
+		{
+			name:    "exemplars",
+			handler: handlePrometheusExemplarsTool,
+			args:    map[string]any{"query": "http_request_duration_seconds_bucket", "end": "1700003600"},
+			path:    "/api/v1/query_exemplars",
+			query:   url.Values{"query": {"http_request_duration_seconds_bucket"}, "end": {"1700003600"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := map[string]any{"prometheus_url": server.URL}
+			for name, value := range tt.args {
+				args[name] = value
+			}
+			req := mcp.CallToolRequest{}
+			req.Params.Arguments = args
+
+			result, err := tt.handler(context.Background(), req)
+			require.NoError(t, err)
+			require.False(t, result.IsError, getResultText(result))
+			assert.Contains(t, getResultText(result), `"status": "success"`)
+			assert.Equal(t, tt.path, got.URL.Path)
+			assert.Equal(t, tt.query, got.URL.Query())
+		})
+	}
+}
+
+func TestDiscoveryToolsValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error)
+		args    map[string]any
+		want    string
+	}{
+		{name: "series without selectors", handler: handlePrometheusSeriesTool, want: "match parameter is required"},
+		{name: "series with an invalid selector", handler: handlePrometheusSeriesTool, args: map[string]any{"match": []any{"up; drop"}}, want: "Invalid series selector"},
+		{name: "label values without label", handler: handlePrometheusLabelValuesTool, want: "label parameter is required"},
+		{name: "label values of a path", handler: handlePrometheusLabelValuesTool, args: map[string]any{"label": "../../admin"}, want: "Invalid label name"},
+		{name: "metadata of an invalid metric", handler: handlePrometheusMetadataTool, args: map[string]any{"metric": "up&limit=1"}, want: "Invalid metric name"},
+		{name: "rules of an unknown type", handler: handlePrometheusRulesTool, args: map[string]any{"type": "both"}, want: "expected alert or record"},
+		{name: "exemplars without query", handler: handlePrometheusExemplarsTool, want: "query parameter is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mcp.CallToolRequest{}
+			req.Params.Arguments = tt.args
+			result, err := tt.handler(context.Background(), req)
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			assert.Contains(t, getResultText(result), tt.want)
+		})
+	}
+}
+
+func TestGetAPIErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"1:4: parse error: unexpected identifier"}`))
+	}))
+	defer server.Close()
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"prometheus_url": server.URL, "match": "up foo"}
+	result, err := handlePrometheusSeriesTool(context.Background(), req)
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+
+	text := getResultText(result)
+	assert.Contains(t, text, "**Prometheus Error**")
+	assert.Contains(t, text, "Prometheus API error (400): bad_data: 1:4: parse error: unexpected identifier")
+	assert.Contains(t, text, "Check your PromQL query syntax")
+	assert.Contains(t, text, "match[]: up foo")
+}
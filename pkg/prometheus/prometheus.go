@@ -2,14 +2,12 @@ package prometheus
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"time"
 
-	"github.com/kagent-dev/tools/internal/errors"
+	"github.com/kagent-dev/tools/internal/httpclient"
 	"github.com/kagent-dev/tools/internal/security"
 	"github.com/kagent-dev/tools/internal/telemetry"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -29,308 +27,216 @@ func getHTTPClient(ctx context.Context) *http.Client {
 // Prometheus tools using direct HTTP API calls
 
 func handlePrometheusQueryTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	prometheusURL := mcp.ParseString(request, "prometheus_url", "http://localhost:9090")
 	query := mcp.ParseString(request, "query", "")
 
 	if query == "" {
 		return mcp.NewToolResultError("query parameter is required"), nil
 	}
 
-	// Validate prometheus URL
-	if err := security.ValidateURL(prometheusURL); err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Invalid Prometheus URL: %v", err)), nil
-	}
-
 	// Validate PromQL query
 	if err := security.ValidatePromQLQuery(query); err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Invalid PromQL query: %v", err)), nil
 	}
 
-	// Make request to Prometheus API
-	apiURL := fmt.Sprintf("%s/api/v1/query", prometheusURL)
 	params := url.Values{}
 	params.Add("query", query)
 	params.Add("time", fmt.Sprintf("%d", time.Now().Unix()))
 
-	fullURL := fmt.Sprintf("%s?%s", apiURL, params.Encode())
-
-	client := getHTTPClient(ctx)
-	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
-	if err != nil {
-		toolErr := errors.NewPrometheusError("create_request", err).
-			WithContext("prometheus_url", prometheusURL).
-			WithContext("query", query)
-		return toolErr.ToMCPResult(), nil
+	body, errResult := getAPI(ctx, request, "/api/v1/query", params)
+	if errResult != nil {
+		return errResult, nil
 	}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		toolErr := errors.NewPrometheusError("query_execution", err).
-			WithContext("prometheus_url", prometheusURL).
-			WithContext("query", query).
-			WithContext("api_url", apiURL)
-		return toolErr.ToMCPResult(), nil
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		toolErr := errors.NewPrometheusError("read_response", err).
-			WithContext("prometheus_url", prometheusURL).
-			WithContext("query", query).
-			WithContext("status_code", resp.StatusCode)
-		return toolErr.ToMCPResult(), nil
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		toolErr := errors.NewPrometheusError("api_error", fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))).
-			WithContext("prometheus_url", prometheusURL).
-			WithContext("query", query).
-			WithContext("status_code", resp.StatusCode).
-			WithContext("response_body", string(body))
-		return toolErr.ToMCPResult(), nil
-	}
-
-	// Parse the JSON response to pretty-print it
-	var result interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return mcp.NewToolResultText(string(body)), nil
-	}
-
-	prettyJSON, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultText(string(body)), nil
-	}
-
-	return mcp.NewToolResultText(string(prettyJSON)), nil
+	return queryToolResult(body), nil
 }
 
 func handlePrometheusRangeQueryTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	prometheusURL := mcp.ParseString(request, "prometheus_url", "http://localhost:9090")
 	query := mcp.ParseString(request, "query", "")
-	start := mcp.ParseString(request, "start", "")
-	end := mcp.ParseString(request, "end", "")
-	step := mcp.ParseString(request, "step", "15s")
+	output := mcp.ParseString(request, "output", outputRaw)
 
 	if query == "" {
 		return mcp.NewToolResultError("query parameter is required"), nil
 	}
 
-	// Validate prometheus URL
-	if err := security.ValidateURL(prometheusURL); err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Invalid Prometheus URL: %v", err)), nil
-	}
-
 	// Validate PromQL query
 	if err := security.ValidatePromQLQuery(query); err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Invalid PromQL query: %v", err)), nil
 	}
-
-	// Validate time parameters if provided
-	if start != "" {
-		if err := security.ValidateCommandInput(start); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Invalid start time: %v", err)), nil
-		}
+	if output != outputRaw && output != outputSummary {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid output %q: expected %s or %s", output, outputRaw, outputSummary)), nil
 	}
-	if end != "" {
-		if err := security.ValidateCommandInput(end); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Invalid end time: %v", err)), nil
-		}
-	}
-	if step != "" {
-		if err := security.ValidateCommandInput(step); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Invalid step parameter: %v", err)), nil
-		}
+	sortBy := mcp.ParseString(request, "sort_by", sortByAvg)
+	if sortBy != sortByAvg && sortBy != sortByMax && sortBy != sortByLast && sortBy != sortByChange {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid sort_by %q: expected avg, max, last or change", sortBy)), nil
 	}
 
-	// Use default time range if not specified
-	if start == "" {
-		start = fmt.Sprintf("%d", time.Now().Add(-1*time.Hour).Unix())
-	}
-	if end == "" {
-		end = fmt.Sprintf("%d", time.Now().Unix())
+	// Resolve the time range, an hour up to now unless specified, and a step that keeps
+	// every series within max_points points unless one is given
+	r, err := ResolveRange(mcp.ParseString(request, "start", ""), mcp.ParseString(request, "end", ""), mcp.ParseString(request, "step", ""),
+		mcp.ParseInt(request, "max_points", defaultMaxPoints), time.Now())
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
+	start, end, step := r.Start, r.End, r.Step
 
-	// Make request to Prometheus API
-	apiURL := fmt.Sprintf("%s/api/v1/query_range", prometheusURL)
 	params := url.Values{}
 	params.Add("query", query)
-	params.Add("start", start)
-	params.Add("end", end)
-	params.Add("step", step)
-
-	fullURL := fmt.Sprintf("%s?%s", apiURL, params.Encode())
-
-	client := getHTTPClient(ctx)
-	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
-	if err != nil {
-		return mcp.NewToolResultError("failed to create request: " + err.Error()), nil
-	}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return mcp.NewToolResultError("failed to query Prometheus: " + err.Error()), nil
-	}
-	defer resp.Body.Close()
+	params.Add("start", formatTime(start))
+	params.Add("end", formatTime(end))
+	params.Add("step", formatStep(step))
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return mcp.NewToolResultError("failed to read response: " + err.Error()), nil
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return mcp.NewToolResultError(fmt.Sprintf("Prometheus API error (%d): %s", resp.StatusCode, string(body))), nil
-	}
-
-	// Parse the JSON response to pretty-print it
-	var result interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return mcp.NewToolResultText(string(body)), nil
+	body, errResult := getAPI(ctx, request, "/api/v1/query_range", params)
+	if errResult != nil {
+		return errResult, nil
 	}
-
-	prettyJSON, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultText(string(body)), nil
+	if output == outputSummary {
+		topK := max(mcp.ParseInt(request, "top_k", defaultTopK), 1)
+		return summaryToolResult(body, RangeSummary{Start: start.Unix(), End: end.Unix(), Step: step.Seconds()}, topK, sortBy), nil
 	}
-
-	return mcp.NewToolResultText(string(prettyJSON)), nil
+	return queryToolResult(body), nil
 }
 
 func handlePrometheusLabelsQueryTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	prometheusURL := mcp.ParseString(request, "prometheus_url", "http://localhost:9090")
-
-	// Validate prometheus URL
-	if err := security.ValidateURL(prometheusURL); err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Invalid Prometheus URL: %v", err)), nil
-	}
-
-	// Make request to Prometheus API for labels
-	apiURL := fmt.Sprintf("%s/api/v1/labels", prometheusURL)
-
-	client := getHTTPClient(ctx)
-	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
-	if err != nil {
-		toolErr := errors.NewPrometheusError("create_request", err).
-			WithContext("prometheus_url", prometheusURL).
-			WithContext("api_url", apiURL)
-		return toolErr.ToMCPResult(), nil
-	}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		toolErr := errors.NewPrometheusError("query_execution", err).
-			WithContext("prometheus_url", prometheusURL).
-			WithContext("api_url", apiURL)
-		return toolErr.ToMCPResult(), nil
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		toolErr := errors.NewPrometheusError("read_response", err).
-			WithContext("prometheus_url", prometheusURL).
-			WithContext("api_url", apiURL).
-			WithContext("status_code", resp.StatusCode)
-		return toolErr.ToMCPResult(), nil
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		toolErr := errors.NewPrometheusError("api_error", fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))).
-			WithContext("prometheus_url", prometheusURL).
-			WithContext("api_url", apiURL).
-			WithContext("status_code", resp.StatusCode).
-			WithContext("response_body", string(body))
-		return toolErr.ToMCPResult(), nil
+	body, errResult := getAPI(ctx, request, "/api/v1/labels", nil)
+	if errResult != nil {
+		return errResult, nil
 	}
-
-	// Parse the JSON response to pretty-print it
-	var result interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return mcp.NewToolResultText(string(body)), nil
-	}
-
-	prettyJSON, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultText(string(body)), nil
-	}
-
-	return mcp.NewToolResultText(string(prettyJSON)), nil
+	return jsonToolResult(body), nil
 }
 
 func handlePrometheusTargetsQueryTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	prometheusURL := mcp.ParseString(request, "prometheus_url", "http://localhost:9090")
-
-	// Validate prometheus URL
-	if err := security.ValidateURL(prometheusURL); err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Invalid Prometheus URL: %v", err)), nil
-	}
-
-	// Make request to Prometheus API for targets
-	apiURL := fmt.Sprintf("%s/api/v1/targets", prometheusURL)
-
-	client := getHTTPClient(ctx)
-	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
-	if err != nil {
-		return mcp.NewToolResultError("failed to create request: " + err.Error()), nil
-	}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return mcp.NewToolResultError("failed to query Prometheus: " + err.Error()), nil
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return mcp.NewToolResultError("failed to read response: " + err.Error()), nil
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return mcp.NewToolResultError(fmt.Sprintf("Prometheus API error (%d): %s", resp.StatusCode, string(body))), nil
-	}
-
-	// Parse the JSON response to pretty-print it
-	var result interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return mcp.NewToolResultText(string(body)), nil
-	}
-
-	prettyJSON, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultText(string(body)), nil
+	body, errResult := getAPI(ctx, request, "/api/v1/targets", nil)
+	if errResult != nil {
+		return errResult, nil
 	}
+	return jsonToolResult(body), nil
+}
 
-	return mcp.NewToolResultText(string(prettyJSON)), nil
+// QueryRange runs a range query with the arguments of prometheus_query_range_tool against the
+// data sources of config, for providers that render their own queries through Prometheus
+func QueryRange(ctx context.Context, config *Config, arguments map[string]any) (*mcp.CallToolResult, error) {
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "prometheus_query_range_tool"
+	request.Params.Arguments = arguments
+	return httpclient.WithConfig(config, handlePrometheusRangeQueryTool)(ctx, request)
 }
 
+// RegisterTools registers the Prometheus tools against the default server
 func RegisterTools(s *server.MCPServer, readOnly bool) {
+	RegisterToolsWithConfig(s, readOnly, nil)
+}
+
+// RegisterToolsWithConfig registers the Prometheus tools against the data sources of config,
+// which may be nil
+func RegisterToolsWithConfig(s *server.MCPServer, readOnly bool, config *Config) {
+	s.AddTool(mcp.NewTool("prometheus_list_datasources",
+		mcp.WithDescription("List the configured Prometheus data sources that the other prometheus tools accept as datasource"),
+		mcp.WithOutputSchema[DataSourceList](),
+	), telemetry.AdaptToolHandler(telemetry.WithTracing("prometheus_list_datasources", httpclient.WithConfig(config, handleListDataSources))))
+
 	s.AddTool(mcp.NewTool("prometheus_query_tool",
 		mcp.WithDescription("Execute a PromQL query against Prometheus"),
 		mcp.WithString("query", mcp.Description("PromQL query to execute"), mcp.Required()),
-		mcp.WithString("prometheus_url", mcp.Description("Prometheus server URL (default: http://localhost:9090)")),
-	), telemetry.AdaptToolHandler(telemetry.WithTracing("prometheus_query_tool", handlePrometheusQueryTool)))
+		mcp.WithString("datasource", mcp.Description(datasourceDescription)),
+		mcp.WithString("prometheus_url", mcp.Description(prometheusURLDescription)),
+		mcp.WithOutputSchema[QueryResult](),
+	), telemetry.AdaptToolHandler(telemetry.WithTracing("prometheus_query_tool", httpclient.WithConfig(config, handlePrometheusQueryTool))))
 
 	s.AddTool(mcp.NewTool("prometheus_query_range_tool",
 		mcp.WithDescription("Execute a PromQL range query against Prometheus"),
 		mcp.WithString("query", mcp.Description("PromQL query to execute"), mcp.Required()),
-		mcp.WithString("start", mcp.Description("Start time (Unix timestamp or relative time)")),
-		mcp.WithString("end", mcp.Description("End time (Unix timestamp or relative time)")),
-		mcp.WithString("step", mcp.Description("Query resolution step (default: 15s)")),
-		mcp.WithString("prometheus_url", mcp.Description("Prometheus server URL (default: http://localhost:9090)")),
-	), telemetry.AdaptToolHandler(telemetry.WithTracing("prometheus_query_range_tool", handlePrometheusRangeQueryTool)))
+		mcp.WithString("start", mcp.Description(startDescription+" (default: an hour before end)")),
+		mcp.WithString("end", mcp.Description(endDescription+" (default: now)")),
+		mcp.WithString("step", mcp.Description("Query resolution step as a duration such as 30s or seconds (default: chosen to keep series within max_points)")),
+		mcp.WithNumber("max_points", mcp.Description(fmt.Sprintf("Points per series the automatic step aims for (default: %d, max: %d)", defaultMaxPoints, maxPointsLimit))),
+		mcp.WithString("output", mcp.Description("raw returns every sample; summary returns min, max, avg, first, last, change and trend per series (default: raw)"), mcp.Enum(outputRaw, outputSummary)),
+		mcp.WithNumber("top_k", mcp.Description(fmt.Sprintf("Series to summarize with output=summary, ordered by sort_by (default: %d)", defaultTopK))),
+		mcp.WithString("sort_by", mcp.Description("Statistic ordering the series of a summary, highest first; change orders by absolute change (default: avg)"), mcp.Enum(sortByAvg, sortByMax, sortByLast, sortByChange)),
+		mcp.WithString("datasource", mcp.Description(datasourceDescription)),
+		mcp.WithString("prometheus_url", mcp.Description(prometheusURLDescription)),
+		mcp.WithOutputSchema[QueryResult](),
+	), telemetry.AdaptToolHandler(telemetry.WithTracing("prometheus_query_range_tool", httpclient.WithConfig(config, handlePrometheusRangeQueryTool))))
 
 	s.AddTool(mcp.NewTool("prometheus_label_names_tool",
 		mcp.WithDescription("Get all available labels from Prometheus"),
-		mcp.WithString("prometheus_url", mcp.Description("Prometheus server URL (default: http://localhost:9090)")),
-	), telemetry.AdaptToolHandler(telemetry.WithTracing("prometheus_label_names_tool", handlePrometheusLabelsQueryTool)))
+		mcp.WithString("datasource", mcp.Description(datasourceDescription)),
+		mcp.WithString("prometheus_url", mcp.Description(prometheusURLDescription)),
+	), telemetry.AdaptToolHandler(telemetry.WithTracing("prometheus_label_names_tool", httpclient.WithConfig(config, handlePrometheusLabelsQueryTool))))
 
 	s.AddTool(mcp.NewTool("prometheus_targets_tool",
 		mcp.WithDescription("Get all Prometheus targets and their status"),
-		mcp.WithString("prometheus_url", mcp.Description("Prometheus server URL (default: http://localhost:9090)")),
-	), telemetry.AdaptToolHandler(telemetry.WithTracing("prometheus_targets_tool", handlePrometheusTargetsQueryTool)))
+		mcp.WithString("datasource", mcp.Description(datasourceDescription)),
+		mcp.WithString("prometheus_url", mcp.Description(prometheusURLDescription)),
+	), telemetry.AdaptToolHandler(telemetry.WithTracing("prometheus_targets_tool", httpclient.WithConfig(config, handlePrometheusTargetsQueryTool))))
+
+	s.AddTool(mcp.NewTool("prometheus_series_tool",
+		mcp.WithDescription("Find the series, with all their labels, that match series selectors"),
+		mcp.WithArray("match", mcp.WithStringItems(), mcp.Description("Series selectors, e.g. up{job=\"prometheus\"} or a metric name; series matching any selector are returned"), mcp.Required()),
+		mcp.WithString("start", mcp.Description(startDescription)),
+		mcp.WithString("end", mcp.Description(endDescription)),
+		mcp.WithNumber("limit", mcp.Description("Maximum number of series to return")),
+		mcp.WithString("datasource", mcp.Description(datasourceDescription)),
+		mcp.WithString("prometheus_url", mcp.Description(prometheusURLDescription)),
+	), telemetry.AdaptToolHandler(telemetry.WithTracing("prometheus_series_tool", httpclient.WithConfig(config, handlePrometheusSeriesTool))))
+
+	s.AddTool(mcp.NewTool("prometheus_label_values_tool",
+		mcp.WithDescription("Get the values of a label, optionally only those of the series matching selectors"),
+		mcp.WithString("label", mcp.Description("Label name, e.g. job or __name__ for metric names"), mcp.Required()),
+		mcp.WithArray("match", mcp.WithStringItems(), mcp.Description("Series selectors, e.g. up{job=\"prometheus\"} or a metric name; series matching any selector are returned")),
+		mcp.WithString("start", mcp.Description(startDescription)),
+		mcp.WithString("end", mcp.Description(endDescription)),
+		mcp.WithNumber("limit", mcp.Description("Maximum number of values to return")),
+		mcp.WithString("datasource", mcp.Description(datasourceDescription)),
+		mcp.WithString("prometheus_url", mcp.Description(prometheusURLDescription)),
+	), telemetry.AdaptToolHandler(telemetry.WithTracing("prometheus_label_values_tool", httpclient.WithConfig(config, handlePrometheusLabelValuesTool))))
+
+	s.AddTool(mcp.NewTool("prometheus_metadata_tool",
+		mcp.WithDescription("Get the type, help text and unit of metrics"),
+		mcp.WithString("metric", mcp.Description("Metric name (default: all metrics)")),
+		mcp.WithNumber("limit", mcp.Description("Maximum number of metrics to return")),
+		mcp.WithString("datasource", mcp.Description(datasourceDescription)),
+		mcp.WithString("prometheus_url", mcp.Description(prometheusURLDescription)),
+	), telemetry.AdaptToolHandler(telemetry.WithTracing("prometheus_metadata_tool", httpclient.WithConfig(config, handlePrometheusMetadataTool))))
+
+	s.AddTool(mcp.NewTool("prometheus_alerts_tool",
+		mcp.WithDescription("Get the pending and firing alerts of Prometheus"),
+		mcp.WithString("datasource", mcp.Description(datasourceDescription)),
+		mcp.WithString("prometheus_url", mcp.Description(prometheusURLDescription)),
+	), telemetry.AdaptToolHandler(telemetry.WithTracing("prometheus_alerts_tool", httpclient.WithConfig(config, handlePrometheusAlertsTool))))
+
+	s.AddTool(mcp.NewTool("prometheus_rules_tool",
+		mcp.WithDescription("Get the alerting and recording rules with their health, last evaluation and active alerts"),
+		mcp.WithString("type", mcp.Description("Rule type to return: alert or record (default: both)"), mcp.Enum("alert", "record")),
+		mcp.WithString("rule_name", mcp.Description("Only return rules with this name")),
+		mcp.WithString("rule_group", mcp.Description("Only return rules of this group")),
+		mcp.WithString("datasource", mcp.Description(datasourceDescription)),
+		mcp.WithString("prometheus_url", mcp.Description(prometheusURLDescription)),
+	), telemetry.AdaptToolHandler(telemetry.WithTracing("prometheus_rules_tool", httpclient.WithConfig(config, handlePrometheusRulesTool))))
+
+	s.AddTool(mcp.NewTool("prometheus_tsdb_status_tool",
+		mcp.WithDescription("Get TSDB cardinality statistics: series count and the metrics, labels and label pairs with the most series"),
+		mcp.WithNumber("limit", mcp.Description("Number of entries in each top list (default: 10)")),
+		mcp.WithString("datasource", mcp.Description(datasourceDescription)),
+		mcp.WithString("prometheus_url", mcp.Description(prometheusURLDescription)),
+	), telemetry.AdaptToolHandler(telemetry.WithTracing("prometheus_tsdb_status_tool", httpclient.WithConfig(config, handlePrometheusTSDBStatusTool))))
+
+	s.AddTool(mcp.NewTool("prometheus_exemplars_tool",
+		mcp.WithDescription("Get the exemplars, e.g. trace IDs, recorded for the series of a PromQL query"),
+		mcp.WithString("query", mcp.Description("PromQL query selecting the series"), mcp.Required()),
+		mcp.WithString("start", mcp.Description(startDescription)),
+		mcp.WithString("end", mcp.Description(endDescription)),
+		mcp.WithString("datasource", mcp.Description(datasourceDescription)),
+		mcp.WithString("prometheus_url", mcp.Description(prometheusURLDescription)),
+	), telemetry.AdaptToolHandler(telemetry.WithTracing("prometheus_exemplars_tool", httpclient.WithConfig(config, handlePrometheusExemplarsTool))))
 
 	s.AddTool(mcp.NewTool("prometheus_promql_tool",
 		mcp.WithDescription("Generate a PromQL query"),
 		mcp.WithString("query_description", mcp.Description("A string describing the query to generate"), mcp.Required()),
 	), telemetry.AdaptToolHandler(telemetry.WithTracing("prometheus_promql_tool", handlePromql)))
 }
+
+const (
+	datasourceDescription    = "Name of a configured data source, see prometheus_list_datasources (default: the configured default)"
+	startDescription         = "Start time: now-<duration> such as now-6h, a duration before now such as 30m, RFC3339 or a Unix timestamp"
+	endDescription           = "End time: now, now-<duration>, a duration before now, RFC3339 or a Unix timestamp"
+	prometheusURLDescription = "Prometheus server URL, queried without the configured credentials (default: the configured default data source, else http://localhost:9090)"
+)
@@ -10,6 +10,7 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRegisterTools(t *testing.T) {
@@ -207,6 +208,13 @@ func TestHandlePrometheusQueryTool(t *testing.T) {
 		content := getResultText(result)
 		assert.Contains(t, content, "success")
 		assert.Contains(t, content, "up")
+
+		structured, ok := result.StructuredContent.(QueryResult)
+		require.True(t, ok)
+		assert.Equal(t, "vector", structured.ResultType)
+		require.Len(t, structured.Series, 1)
+		assert.Equal(t, "localhost:9090", structured.Series[0].Metric["instance"])
+		assert.Equal(t, &Sample{Timestamp: 1609459200, Value: "1"}, structured.Series[0].Value)
 	})
 
 	t.Run("missing query parameter", func(t *testing.T) {
@@ -328,6 +336,12 @@ func TestHandlePrometheusRangeQueryTool(t *testing.T) {
 
 		content := getResultText(result)
 		assert.Contains(t, content, "matrix")
+
+		structured, ok := result.StructuredContent.(QueryResult)
+		require.True(t, ok)
+		assert.Equal(t, "matrix", structured.ResultType)
+		require.Len(t, structured.Series, 1)
+		assert.Equal(t, []Sample{{Timestamp: 1609459200, Value: "1"}, {Timestamp: 1609459260, Value: "1"}}, structured.Series[0].Values)
 		assert.Contains(t, content, "values")
 	})
 
@@ -362,6 +376,44 @@ func TestHandlePrometheusRangeQueryTool(t *testing.T) {
 	})
 }
 
+func TestParseQueryResult(t *testing.T) {
+	t.Run("scalar", func(t *testing.T) {
+		result, err := parseQueryResult([]byte(`{"status":"success","data":{"resultType":"scalar","result":[1609459200.5,"NaN"]}}`))
+		require.NoError(t, err)
+		assert.Equal(t, &Sample{Timestamp: 1609459200.5, Value: "NaN"}, result.Scalar)
+		assert.Empty(t, result.Series)
+	})
+
+	t.Run("warnings", func(t *testing.T) {
+		result, err := parseQueryResult([]byte(`{"status":"success","data":{"resultType":"vector","result":[]},"warnings":["partial response"]}`))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"partial response"}, result.Warnings)
+		assert.NotNil(t, result.Series)
+	})
+
+	t.Run("not a query result", func(t *testing.T) {
+		for _, body := range []string{
+			`not json`,
+			`{"status":"error","errorType":"bad_data","error":"parse error"}`,
+			`{"status":"success","data":["__name__","job"]}`,
+			`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1]}]}}`,
+		} {
+			_, err := parseQueryResult([]byte(body))
+			assert.Error(t, err, "body %s", body)
+		}
+	})
+
+	t.Run("output schemas", func(t *testing.T) {
+		s := server.NewMCPServer("test-server", "v0.0.1")
+		RegisterTools(s, true)
+		for _, name := range []string{"prometheus_query_tool", "prometheus_query_range_tool"} {
+			tool := s.ListTools()[name].Tool
+			assert.Equal(t, "object", tool.OutputSchema.Type, name)
+			assert.Contains(t, tool.OutputSchema.Properties, "series", name)
+		}
+	})
+}
+
 func TestHandlePrometheusLabelsQueryTool(t *testing.T) {
 	t.Run("successful labels query", func(t *testing.T) {
 		mockResponse := `{
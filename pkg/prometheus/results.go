@@ -0,0 +1,289 @@
+package prometheus
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Sample is one value of a series at a point in time. Values are kept as Prometheus
+// renders them, so NaN and ±Inf survive.
+type Sample struct {
+	Timestamp float64 `json:"timestamp" jsonschema_description:"Unix time of the sample in seconds"`
+	Value     string  `json:"value" jsonschema_description:"Sample value as Prometheus renders it"`
+}
+
+// Series is one labeled series of a query result
+type Series struct {
+	Metric map[string]string `json:"metric" jsonschema_description:"Labels of the series"`
+	Value  *Sample           `json:"value,omitempty" jsonschema_description:"Value of an instant vector series"`
+	Values []Sample          `json:"values,omitempty" jsonschema_description:"Values of a range vector (matrix) series"`
+}
+
+// QueryResult is the structured content of the query tools
+type QueryResult struct {
+	ResultType string        `json:"result_type" jsonschema_description:"vector, matrix, scalar or string"`
+	Series     []Series      `json:"series" jsonschema_description:"Series of vector and matrix results"`
+	Scalar     *Sample       `json:"scalar,omitempty" jsonschema_description:"Value of scalar and string results"`
+	Summary    *RangeSummary `json:"summary,omitempty" jsonschema_description:"Per-series statistics of a range query with output=summary, which returns no series"`
+	Warnings   []string      `json:"warnings,omitempty"`
+}
+
+// SeriesSummary condenses the samples of a range vector series. NaN and ±Inf samples are
+// left out of the statistics.
+type SeriesSummary struct {
+	Metric map[string]string `json:"metric" jsonschema_description:"Labels of the series"`
+	Points int               `json:"points" jsonschema_description:"Number of samples"`
+	Min    float64           `json:"min"`
+	Max    float64           `json:"max"`
+	Avg    float64           `json:"avg"`
+	First  float64           `json:"first"`
+	Last   float64           `json:"last"`
+	Change float64           `json:"change" jsonschema_description:"Last minus first value"`
+	Trend  string            `json:"trend" jsonschema_description:"rising, falling or flat, from a least-squares fit over the samples"`
+}
+
+// RangeSummary is the summary of a range query result
+type RangeSummary struct {
+	Start       int64           `json:"start" jsonschema_description:"Unix time of the start of the range"`
+	End         int64           `json:"end" jsonschema_description:"Unix time of the end of the range"`
+	Step        float64         `json:"step" jsonschema_description:"Query resolution step in seconds"`
+	SeriesCount int             `json:"series_count" jsonschema_description:"Number of series the query returned"`
+	SortBy      string          `json:"sort_by" jsonschema_description:"Statistic the series are ordered by, highest first"`
+	Series      []SeriesSummary `json:"series" jsonschema_description:"Summaries of the top series"`
+}
+
+// apiResponse is the envelope of the Prometheus query API
+type apiResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+	Warnings []string `json:"warnings"`
+}
+
+// parseQueryResult converts a query API response into a QueryResult
+func parseQueryResult(body []byte) (QueryResult, error) {
+	var response apiResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return QueryResult{}, err
+	}
+	if response.Status != "success" {
+		return QueryResult{}, fmt.Errorf("query status %q", response.Status)
+	}
+
+	result := QueryResult{ResultType: response.Data.ResultType, Series: []Series{}, Warnings: response.Warnings}
+	switch response.Data.ResultType {
+	case "vector", "matrix":
+		var series []struct {
+			Metric map[string]string `json:"metric"`
+			Value  []any             `json:"value"`
+			Values [][]any           `json:"values"`
+		}
+		if err := json.Unmarshal(response.Data.Result, &series); err != nil {
+			return QueryResult{}, err
+		}
+		for _, s := range series {
+			converted := Series{Metric: s.Metric}
+			if s.Value != nil {
+				sample, err := parseSample(s.Value)
+				if err != nil {
+					return QueryResult{}, err
+				}
+				converted.Value = &sample
+			}
+			for _, value := range s.Values {
+				sample, err := parseSample(value)
+				if err != nil {
+					return QueryResult{}, err
+				}
+				converted.Values = append(converted.Values, sample)
+			}
+			result.Series = append(result.Series, converted)
+		}
+	case "scalar", "string":
+		var value []any
+		if err := json.Unmarshal(response.Data.Result, &value); err != nil {
+			return QueryResult{}, err
+		}
+		sample, err := parseSample(value)
+		if err != nil {
+			return QueryResult{}, err
+		}
+		result.Scalar = &sample
+	default:
+		return QueryResult{}, fmt.Errorf("unknown result type %q", response.Data.ResultType)
+	}
+	return result, nil
+}
+
+// parseSample converts a [timestamp, "value"] pair
+func parseSample(pair []any) (Sample, error) {
+	if len(pair) != 2 {
+		return Sample{}, fmt.Errorf("sample has %d elements, expected 2", len(pair))
+	}
+	timestamp, ok := pair[0].(float64)
+	if !ok {
+		return Sample{}, fmt.Errorf("sample timestamp %v is not a number", pair[0])
+	}
+	value, ok := pair[1].(string)
+	if !ok {
+		return Sample{}, fmt.Errorf("sample value %v is not a string", pair[1])
+	}
+	return Sample{Timestamp: timestamp, Value: value}, nil
+}
+
+// queryToolResult returns a query API response as pretty-printed JSON text, with the parsed
+// series as structured content when the response is a successful query result
+func queryToolResult(body []byte) *mcp.CallToolResult {
+	// Parse the JSON response to pretty-print it
+	var response interface{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return mcp.NewToolResultText(string(body))
+	}
+
+	prettyJSON, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultText(string(body))
+	}
+
+	result, err := parseQueryResult(body)
+	if err != nil {
+		return mcp.NewToolResultText(string(prettyJSON))
+	}
+	return mcp.NewToolResultStructured(result, string(prettyJSON))
+}
+
+// DataSourceInfo describes a configured data source without its credentials
+type DataSourceInfo struct {
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+	Auth        string `json:"auth" jsonschema_description:"none, basic or bearer"`
+	TenantID    string `json:"tenant_id,omitempty"`
+	Default     bool   `json:"default"`
+}
+
+// DataSourceList is the result of prometheus_list_datasources
+type DataSourceList struct {
+	DataSources []DataSourceInfo `json:"datasources"`
+}
+
+// Output modes and summary orders of prometheus_query_range_tool
+const (
+	outputRaw     = "raw"
+	outputSummary = "summary"
+
+	sortByAvg    = "avg"
+	sortByMax    = "max"
+	sortByLast   = "last"
+	sortByChange = "change"
+
+	// defaultTopK is the number of series a summary holds by default
+	defaultTopK = 10
+
+	// flatTrend is the change of the fitted line over the range, relative to the largest
+	// absolute value, below which a series is flat
+	flatTrend = 0.05
+)
+
+// summaryToolResult returns the topK series of a range query response, ordered by sortBy,
+// as summaries instead of samples
+func summaryToolResult(body []byte, summary RangeSummary, topK int, sortBy string) *mcp.CallToolResult {
+	result, err := parseQueryResult(body)
+	if err != nil || result.ResultType != "matrix" {
+		return queryToolResult(body)
+	}
+
+	summary.SortBy = sortBy
+	summary.SeriesCount = len(result.Series)
+	summary.Series = []SeriesSummary{}
+	for _, series := range result.Series {
+		if seriesSummary, ok := summarizeSeries(series); ok {
+			summary.Series = append(summary.Series, seriesSummary)
+		}
+	}
+	sort.SliceStable(summary.Series, func(i, j int) bool {
+		return summaryValue(summary.Series[i], sortBy) > summaryValue(summary.Series[j], sortBy)
+	})
+	if len(summary.Series) > topK {
+		summary.Series = summary.Series[:topK]
+	}
+
+	result.Series = []Series{}
+	result.Summary = &summary
+	content, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError("failed to marshal summary: " + err.Error())
+	}
+	return mcp.NewToolResultStructured(result, string(content))
+}
+
+// summarizeSeries returns the statistics of the finite samples of series, or false when it
+// has none
+func summarizeSeries(series Series) (SeriesSummary, bool) {
+	summary := SeriesSummary{Metric: series.Metric, Points: len(series.Values)}
+
+	var n, sum, sumT, sumV, sumTT, sumTV float64
+	var firstT, lastT float64
+	for _, sample := range series.Values {
+		value, err := strconv.ParseFloat(sample.Value, 64)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+			continue
+		}
+		if n == 0 {
+			summary.Min, summary.Max, summary.First = value, value, value
+			firstT = sample.Timestamp
+		}
+		summary.Min = math.Min(summary.Min, value)
+		summary.Max = math.Max(summary.Max, value)
+		summary.Last = value
+		lastT = sample.Timestamp
+		sum += value
+
+		// Fit against time relative to the first sample to keep the sums small
+		t := sample.Timestamp - firstT
+		n++
+		sumT += t
+		sumV += value
+		sumTT += t * t
+		sumTV += t * value
+	}
+	if n == 0 {
+		return SeriesSummary{}, false
+	}
+	summary.Avg = sum / n
+	summary.Change = summary.Last - summary.First
+
+	summary.Trend = "flat"
+	if denominator := n*sumTT - sumT*sumT; denominator > 0 {
+		slope := (n*sumTV - sumT*sumV) / denominator
+		fitted := slope * (lastT - firstT)
+		if scale := math.Max(math.Abs(summary.Min), math.Abs(summary.Max)); math.Abs(fitted) > flatTrend*scale {
+			summary.Trend = "rising"
+			if fitted < 0 {
+				summary.Trend = "falling"
+			}
+		}
+	}
+	return summary, true
+}
+
+// summaryValue returns the statistic of summary that sortBy names
+func summaryValue(summary SeriesSummary, sortBy string) float64 {
+	switch sortBy {
+	case sortByMax:
+		return summary.Max
+	case sortByLast:
+		return summary.Last
+	case sortByChange:
+		return math.Abs(summary.Change)
+	default:
+		return summary.Avg
+	}
+}
@@ -0,0 +1,184 @@
+package prometheus
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultRange is the time range of a range query without start
+	defaultRange = time.Hour
+
+	// defaultMaxPoints is the number of points per series the automatic step aims for
+	defaultMaxPoints = 250
+
+	// maxPointsLimit is the resolution limit Prometheus enforces per series
+	maxPointsLimit = 11000
+)
+
+// durationPattern matches a Prometheus duration such as 90s, 1h30m or 2w
+var durationPattern = regexp.MustCompile(`^(\d+(ms|s|m|h|d|w|y))+$`)
+
+var durationPart = regexp.MustCompile(`(\d+)(ms|s|m|h|d|w|y)`)
+
+var durationUnits = map[string]time.Duration{
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  24 * time.Hour,
+	"w":  7 * 24 * time.Hour,
+	"y":  365 * 24 * time.Hour,
+}
+
+// niceSteps are the steps the automatic step is rounded up to, so that the timestamps of
+// consecutive queries line up
+var niceSteps = []time.Duration{
+	time.Second, 5 * time.Second, 10 * time.Second, 15 * time.Second, 30 * time.Second,
+	time.Minute, 2 * time.Minute, 5 * time.Minute, 10 * time.Minute, 15 * time.Minute, 30 * time.Minute,
+	time.Hour, 2 * time.Hour, 3 * time.Hour, 6 * time.Hour, 12 * time.Hour, 24 * time.Hour,
+}
+
+// parseDuration parses a Prometheus duration, which unlike a Go duration has days, weeks
+// and years
+func parseDuration(value string) (time.Duration, error) {
+	if !durationPattern.MatchString(value) {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	var duration time.Duration
+	for _, part := range durationPart.FindAllStringSubmatch(value, -1) {
+		count, err := strconv.ParseInt(part[1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q: %w", value, err)
+		}
+		duration += time.Duration(count) * durationUnits[part[2]]
+	}
+	return duration, nil
+}
+
+// parseTime parses now, now-<duration>, now+<duration>, a bare duration meaning that long
+// before now, an RFC3339 time or a Unix timestamp in seconds
+func parseTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	switch {
+	case value == "now":
+		return now, nil
+	case strings.HasPrefix(value, "now-"), strings.HasPrefix(value, "now+"):
+		duration, err := parseDuration(value[4:])
+		if err != nil {
+			return time.Time{}, err
+		}
+		if value[3] == '-' {
+			return now.Add(-duration), nil
+		}
+		return now.Add(duration), nil
+	}
+
+	if duration, err := parseDuration(value); err == nil {
+		return now.Add(-duration), nil
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && !math.IsNaN(seconds) && !math.IsInf(seconds, 0) {
+		whole, fraction := math.Modf(seconds)
+		return time.Unix(int64(whole), int64(fraction*1e9)), nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: expected now, now-<duration>, a duration such as 30m, an RFC3339 time or a Unix timestamp", value)
+}
+
+// TimeRange is the resolved time range and step of a range query
+type TimeRange struct {
+	Start time.Time
+	End   time.Time
+	Step  time.Duration
+}
+
+// ResolveRange resolves the start, end and step arguments of a range query (see parseTime and
+// parseStep) relative to now. The range is the hour up to now unless specified, and the step
+// keeps every series within maxPoints points unless one is given.
+func ResolveRange(start, end, step string, maxPoints int, now time.Time) (TimeRange, error) {
+	r := TimeRange{End: now}
+	if end != "" {
+		t, err := parseTime(end, now)
+		if err != nil {
+			return TimeRange{}, fmt.Errorf("Invalid end time: %w", err)
+		}
+		r.End = t
+	}
+	r.Start = r.End.Add(-defaultRange)
+	if start != "" {
+		t, err := parseTime(start, now)
+		if err != nil {
+			return TimeRange{}, fmt.Errorf("Invalid start time: %w", err)
+		}
+		r.Start = t
+	}
+	if !r.End.After(r.Start) {
+		return TimeRange{}, fmt.Errorf("Invalid time range: end %s is not after start %s", r.End.Format(time.RFC3339), r.Start.Format(time.RFC3339))
+	}
+	span := r.End.Sub(r.Start)
+
+	maxPoints = min(max(maxPoints, 1), maxPointsLimit)
+	r.Step = autoStep(span, maxPoints)
+	if step != "" {
+		var err error
+		if r.Step, err = parseStep(step); err != nil {
+			return TimeRange{}, fmt.Errorf("Invalid step parameter: %w", err)
+		}
+		if points(span, r.Step) > maxPointsLimit {
+			return TimeRange{}, fmt.Errorf("step %s gives %d points per series, more than the %d Prometheus allows; use a step of at least %s or omit it",
+				step, points(span, r.Step), maxPointsLimit, autoStep(span, maxPointsLimit))
+		}
+	}
+	return r, nil
+}
+
+// parseStep parses a step given as a duration or as seconds
+func parseStep(value string) (time.Duration, error) {
+	step, err := parseDuration(value)
+	if err != nil {
+		seconds, floatErr := strconv.ParseFloat(value, 64)
+		if floatErr != nil || math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+			return 0, fmt.Errorf("invalid step %q: expected a duration such as 30s or seconds", value)
+		}
+		step = time.Duration(seconds * float64(time.Second))
+	}
+	if step <= 0 {
+		return 0, fmt.Errorf("invalid step %q: must be positive", value)
+	}
+	return step, nil
+}
+
+// autoStep returns the smallest nice step that keeps a series over the range within
+// maxPoints points
+func autoStep(span time.Duration, maxPoints int) time.Duration {
+	minimum := span / time.Duration(maxPoints)
+	for _, step := range niceSteps {
+		if step >= minimum {
+			return step
+		}
+	}
+	// Beyond a day, round up to whole days
+	day := 24 * time.Hour
+	return (minimum + day - 1) / day * day
+}
+
+// points returns the number of points of a series over the range at step
+func points(span, step time.Duration) int {
+	return int(span/step) + 1
+}
+
+// formatTime renders t as the Unix timestamp in seconds the Prometheus API takes
+func formatTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixNano())/1e9, 'f', -1, 64)
+}
+
+// formatStep renders step in seconds as the Prometheus API takes it
+func formatStep(step time.Duration) string {
+	return strconv.FormatFloat(step.Seconds(), 'f', -1, 64)
+}
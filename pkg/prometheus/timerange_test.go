@@ -0,0 +1,192 @@
+package prometheus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "now", want: now},
+		{value: "now-1h", want: now.Add(-time.Hour)},
+		{value: "now-1h30m", want: now.Add(-90 * time.Minute)},
+		{value: "now+5m", want: now.Add(5 * time.Minute)},
+		{value: "30m", want: now.Add(-30 * time.Minute)},
+		{value: "2d", want: now.Add(-48 * time.Hour)},
+		{value: "1w", want: now.Add(-7 * 24 * time.Hour)},
+		{value: "1700000000", want: time.Unix(1700000000, 0)},
+		{value: "1700000000.5", want: time.Unix(1700000000, 5e8)},
+		{value: "2026-02-28T10:00:00Z", want: time.Date(2026, 2, 28, 10, 0, 0, 0, time.UTC)},
+		{value: "2026-02-28T10:00:00+02:00", want: time.Date(2026, 2, 28, 8, 0, 0, 0, time.UTC)},
+		{value: "yesterday", wantErr: true},
+		{value: "now-1", wantErr: true},
+		{value: "now-1.5h", wantErr: true},
+		{value: "NaN", wantErr: true},
+		{value: "2026-02-28", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseTime(tt.value, now)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equal(got), "got %s, want %s", got, tt.want)
+		})
+	}
+}
+
+func TestParseStep(t *testing.T) {
+	for value, want := range map[string]time.Duration{"30s": 30 * time.Second, "1m30s": 90 * time.Second, "15": 15 * time.Second, "0.5": 500 * time.Millisecond} {
+		got, err := parseStep(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, want, got, value)
+	}
+	for _, value := range []string{"0", "-15", "0s", "fast", "Inf"} {
+		_, err := parseStep(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestAutoStep(t *testing.T) {
+	tests := []struct {
+		span      time.Duration
+		maxPoints int
+		want      time.Duration
+	}{
+		{span: time.Hour, maxPoints: 250, want: 15 * time.Second},
+		{span: 5 * time.Minute, maxPoints: 250, want: 5 * time.Second},
+		{span: 24 * time.Hour, maxPoints: 250, want: 10 * time.Minute},
+		{span: 7 * 24 * time.Hour, maxPoints: 250, want: time.Hour},
+		{span: 365 * 24 * time.Hour, maxPoints: 100, want: 4 * 24 * time.Hour},
+		{span: time.Hour, maxPoints: maxPointsLimit, want: time.Second},
+	}
+	for _, tt := range tests {
+		step := autoStep(tt.span, tt.maxPoints)
+		assert.Equal(t, tt.want, step, "span %s", tt.span)
+		assert.LessOrEqual(t, points(tt.span, step), tt.maxPoints+1, "span %s", tt.span)
+	}
+}
+
+func TestRangeQueryTimeAndStep(t *testing.T) {
+	var got url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Query()
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[]}}`))
+	}))
+	defer server.Close()
+
+	call := func(args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		args["prometheus_url"] = server.URL
+		args["query"] = "up"
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = args
+		result, err := handlePrometheusRangeQueryTool(context.Background(), req)
+		require.NoError(t, err)
+		return result
+	}
+	param := func(name string) float64 {
+		t.Helper()
+		value, err := strconv.ParseFloat(got.Get(name), 64)
+		require.NoError(t, err, name)
+		return value
+	}
+
+	t.Run("relative start and automatic step", func(t *testing.T) {
+		result := call(map[string]any{"start": "now-24h"})
+		require.False(t, result.IsError, getResultText(result))
+		assert.InDelta(t, 24*3600, param("end")-param("start"), 1)
+		assert.InDelta(t, float64(time.Now().Unix()), param("end"), 5)
+		assert.Equal(t, "600", got.Get("step"))
+	})
+
+	t.Run("max points", func(t *testing.T) {
+		call(map[string]any{"start": "1700000000", "end": "1700003600", "max_points": float64(60)})
+		assert.Equal(t, "1700000000", got.Get("start"))
+		assert.Equal(t, "60", got.Get("step"))
+	})
+
+	t.Run("explicit step", func(t *testing.T) {
+		call(map[string]any{"start": "2026-01-01T00:00:00Z", "end": "2026-01-01T01:00:00Z", "step": "1m"})
+		assert.Equal(t, "1767225600", got.Get("start"))
+		assert.Equal(t, "60", got.Get("step"))
+	})
+
+	for name, args := range map[string]map[string]any{
+		"invalid start":        {"start": "yesterday"},
+		"end before start":     {"start": "now-1h", "end": "now-2h"},
+		"invalid step":         {"step": "fast"},
+		"too many points":      {"start": "now-30d", "step": "1s"},
+		"invalid output":       {"output": "csv"},
+		"invalid summary sort": {"output": "summary", "sort_by": "median"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.True(t, call(args).IsError)
+		})
+	}
+}
+
+func TestRangeQuerySummary(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{"pod":"rising"},"values":[[1000,"1"],[1060,"2"],[1120,"NaN"],[1180,"4"]]},
+			{"metric":{"pod":"falling"},"values":[[1000,"10"],[1060,"8"],[1120,"6"],[1180,"4"]]},
+			{"metric":{"pod":"flat"},"values":[[1000,"100"],[1060,"101"],[1120,"99"],[1180,"100"]]},
+			{"metric":{"pod":"empty"},"values":[[1000,"NaN"]]}
+		]}}`))
+	}))
+	defer server.Close()
+
+	call := func(args map[string]any) RangeSummary {
+		t.Helper()
+		args["prometheus_url"] = server.URL
+		args["query"] = "rate(cpu[5m])"
+		args["output"] = "summary"
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = args
+		result, err := handlePrometheusRangeQueryTool(context.Background(), req)
+		require.NoError(t, err)
+		require.False(t, result.IsError, getResultText(result))
+		assert.NotContains(t, getResultText(result), `"values"`)
+
+		structured, ok := result.StructuredContent.(QueryResult)
+		require.True(t, ok)
+		assert.Empty(t, structured.Series)
+		require.NotNil(t, structured.Summary)
+		return *structured.Summary
+	}
+
+	summary := call(map[string]any{"start": "1000", "end": "1180", "step": "60"})
+	assert.Equal(t, int64(1000), summary.Start)
+	assert.Equal(t, float64(60), summary.Step)
+	assert.Equal(t, 4, summary.SeriesCount)
+	require.Len(t, summary.Series, 3)
+	assert.Equal(t, SeriesSummary{
+		Metric: map[string]string{"pod": "flat"}, Points: 4,
+		Min: 99, Max: 101, Avg: 100, First: 100, Last: 100, Trend: "flat",
+	}, summary.Series[0])
+	assert.Equal(t, "falling", summary.Series[1].Trend)
+	assert.Equal(t, float64(-6), summary.Series[1].Change)
+	assert.Equal(t, "rising", summary.Series[2].Trend)
+	assert.InDelta(t, 7.0/3, summary.Series[2].Avg, 1e-9)
+
+	summary = call(map[string]any{"sort_by": "change", "top_k": float64(1)})
+	require.Len(t, summary.Series, 1)
+	assert.Equal(t, "falling", summary.Series[0].Metric["pod"])
+}
@@ -0,0 +1,145 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Table is a CLI table parsed into rows keyed by column
+type Table struct {
+	// Columns are the row keys in the order the CLI printed them
+	Columns []string `json:"columns"`
+	// Rows hold the cells of each row by column key
+	Rows []map[string]string `json:"rows"`
+}
+
+// columnCell matches the header cells of space-aligned tables. Single spaces belong to the
+// cell, as in NOMINATED NODE or APP VERSION.
+var columnCell = regexp.MustCompile(`\S+( \S+)*`)
+
+// columnKeySeparator matches the punctuation and spaces between the words of a header cell
+var columnKeySeparator = regexp.MustCompile(`[^a-z0-9]+`)
+
+// ParseTable parses the table a CLI prints, such as kubectl get, helm list, istioctl
+// proxy-status or cilium-dbg endpoint list output. Columns are found from the header line and
+// cells cut at the header positions, so cells may contain single spaces. Lines whose first
+// cell is blank continue the header or the previous row. It returns false for text that does
+// not start with a header of at least two capitalized columns.
+func ParseTable(text string) (Table, bool) {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	if len(lines) == 0 {
+		return Table{}, false
+	}
+
+	header := strings.TrimRight(lines[0], " \r")
+	tabbed := strings.Contains(header, "\t")
+	starts, names := headerColumns(header, tabbed)
+	if len(names) < 2 {
+		return Table{}, false
+	}
+	for _, name := range names {
+		if first, _ := utf8.DecodeRuneInString(name); !unicode.IsUpper(first) {
+			return Table{}, false
+		}
+	}
+
+	var cellRows [][]string
+	for _, line := range lines[1:] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		cells := splitRow(line, starts, len(names), tabbed)
+		if cells[0] != "" {
+			cellRows = append(cellRows, cells)
+			continue
+		}
+
+		// A blank first cell continues the header until the first row, and the row after it
+		target := names
+		separator := " "
+		if len(cellRows) > 0 {
+			target = cellRows[len(cellRows)-1]
+			separator = ", "
+		}
+		for i, cell := range cells {
+			if cell == "" {
+				continue
+			}
+			if target[i] == "" {
+				target[i] = cell
+			} else {
+				target[i] += separator + cell
+			}
+		}
+	}
+
+	table := Table{Columns: make([]string, len(names)), Rows: make([]map[string]string, 0, len(cellRows))}
+	for i, name := range names {
+		table.Columns[i] = columnKey(name)
+	}
+	for _, cells := range cellRows {
+		row := make(map[string]string, len(cells))
+		for i, cell := range cells {
+			row[table.Columns[i]] = cell
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table, true
+}
+
+// headerColumns returns the start offset, in runes, and the name of each header cell
+func headerColumns(header string, tabbed bool) ([]int, []string) {
+	if tabbed {
+		var names []string
+		for _, cell := range strings.Split(header, "\t") {
+			names = append(names, strings.TrimSpace(cell))
+		}
+		return nil, names
+	}
+
+	var starts []int
+	var names []string
+	for _, cell := range columnCell.FindAllStringIndex(header, -1) {
+		starts = append(starts, utf8.RuneCountInString(header[:cell[0]]))
+		names = append(names, header[cell[0]:cell[1]])
+	}
+	return starts, names
+}
+
+// splitRow cuts a line into one cell per column. Space-aligned lines are cut at the header
+// positions, tab-separated ones at their tabs, with any extra cells kept in the last one.
+func splitRow(line string, starts []int, columns int, tabbed bool) []string {
+	cells := make([]string, columns)
+	if tabbed {
+		for i, field := range strings.SplitN(line, "\t", columns) {
+			cells[i] = strings.TrimSpace(field)
+		}
+		return cells
+	}
+
+	runes := []rune(strings.TrimRight(line, "\r"))
+	for i, start := range starts {
+		if start >= len(runes) {
+			break
+		}
+		end := len(runes)
+		if i+1 < len(starts) && starts[i+1] < end {
+			end = starts[i+1]
+		}
+		cells[i] = strings.TrimSpace(string(runes[start:end]))
+	}
+	return cells
+}
+
+// columnKey turns a header cell into a snake_case key, e.g. NOMINATED NODE into
+// nominated_node, PORT(S) into ports and POLICY (ingress) ENFORCEMENT into
+// policy_ingress_enforcement
+func columnKey(name string) string {
+	name = strings.ReplaceAll(strings.ToLower(name), "(s)", "s")
+	return strings.Trim(columnKeySeparator.ReplaceAllString(name, "_"), "_")
+}
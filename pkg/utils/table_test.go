@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTable(t *testing.T) {
+	t.Run("kubectl wide output", func(t *testing.T) {
+		text := `NAME                     READY   STATUS    RESTARTS      AGE   IP           NODE     NOMINATED NODE   READINESS GATES
+nginx-7c5ddbdf54-abcde   1/1     Running   2 (5m ago)    3d    10.244.0.5   node-1   <none>           <none>
+redis-0                  0/1     Pending   0             1m    <none>       <none>   <none>           <none>
+`
+		table, ok := ParseTable(text)
+		require.True(t, ok)
+		assert.Equal(t, []string{"name", "ready", "status", "restarts", "age", "ip", "node", "nominated_node", "readiness_gates"}, table.Columns)
+		require.Len(t, table.Rows, 2)
+		assert.Equal(t, "nginx-7c5ddbdf54-abcde", table.Rows[0]["name"])
+		assert.Equal(t, "2 (5m ago)", table.Rows[0]["restarts"])
+		assert.Equal(t, "<none>", table.Rows[0]["nominated_node"])
+		assert.Equal(t, "Pending", table.Rows[1]["status"])
+	})
+
+	t.Run("tab separated output", func(t *testing.T) {
+		text := "NAME   \tNAMESPACE\tREVISION\tUPDATED                                \tSTATUS  \tCHART       \tAPP VERSION\n" +
+			"nginx  \tdefault  \t3       \t2024-05-01 10:00:00.123 +0000 UTC\tdeployed\tnginx-15.0.0\t1.25.0     \n"
+		table, ok := ParseTable(text)
+		require.True(t, ok)
+		assert.Equal(t, []string{"name", "namespace", "revision", "updated", "status", "chart", "app_version"}, table.Columns)
+		require.Len(t, table.Rows, 1)
+		assert.Equal(t, "2024-05-01 10:00:00.123 +0000 UTC", table.Rows[0]["updated"])
+		assert.Equal(t, "1.25.0", table.Rows[0]["app_version"])
+	})
+
+	t.Run("continuation lines", func(t *testing.T) {
+		text := `ENDPOINT   POLICY (ingress)   POLICY (egress)   IDENTITY   LABELS (source:key[=value])               IPv6   IPv4        STATUS
+           ENFORCEMENT        ENFORCEMENT
+1204       Disabled           Disabled          4          reserved:health                                  10.0.0.94   ready
+2471       Disabled           Disabled          8201       k8s:app=web                                      10.0.0.12   ready
+                                                           k8s:io.kubernetes.pod.namespace=default
+`
+		table, ok := ParseTable(text)
+		require.True(t, ok)
+		assert.Equal(t, []string{"endpoint", "policy_ingress_enforcement", "policy_egress_enforcement", "identity", "labels_source_key_value", "ipv6", "ipv4", "status"}, table.Columns)
+		require.Len(t, table.Rows, 2)
+		assert.Equal(t, "reserved:health", table.Rows[0]["labels_source_key_value"])
+		assert.Equal(t, "k8s:app=web, k8s:io.kubernetes.pod.namespace=default", table.Rows[1]["labels_source_key_value"])
+		assert.Equal(t, "10.0.0.12", table.Rows[1]["ipv4"])
+	})
+
+	t.Run("header only", func(t *testing.T) {
+		table, ok := ParseTable("NAME   READY   STATUS\n")
+		require.True(t, ok)
+		assert.Empty(t, table.Rows)
+	})
+
+	t.Run("not a table", func(t *testing.T) {
+		for _, text := range []string{
+			"",
+			"No resources found in default namespace.\n",
+			"{\n  \"kind\": \"List\"\n}\n",
+			"apiVersion: v1\nkind: Pod\n",
+			"Clusters Match\nListeners Match\n",
+		} {
+			_, ok := ParseTable(text)
+			assert.False(t, ok, "text %q", text)
+		}
+	})
+}
+
+func TestColumnKey(t *testing.T) {
+	tests := map[string]string{
+		"NAME":                        "name",
+		"NOMINATED NODE":              "nominated_node",
+		"UP-TO-DATE":                  "up_to_date",
+		"PORT(S)":                     "ports",
+		"APP VERSION":                 "app_version",
+		"LABELS (source:key[=value])": "labels_source_key_value",
+	}
+	for name, want := range tests {
+		assert.Equal(t, want, columnKey(name), "column %q", name)
+	}
+}